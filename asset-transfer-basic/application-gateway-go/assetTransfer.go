@@ -164,10 +164,11 @@ func newSign() identity.Sign {
 func initLedger(contract *client.Contract) {
 	fmt.Printf("Submit Transaction: InitLedger, function creates the initial set of assets on the ledger \n")
 
-	_, err := contract.SubmitTransaction("InitLedger")
+	submitResult, err := contract.SubmitTransaction("InitLedger")
 	if err != nil {
 		panic(fmt.Errorf("failed to submit transaction: %w", err))
 	}
+	unwrapResponse(submitResult)
 
 	fmt.Printf("*** Transaction committed successfully\n")
 }
@@ -180,7 +181,7 @@ func getAllAssets(contract *client.Contract) {
 	if err != nil {
 		panic(fmt.Errorf("failed to evaluate transaction: %w", err))
 	}
-	result := formatJSON(evaluateResult)
+	result := formatJSON(unwrapResponse(evaluateResult))
 
 	fmt.Printf("*** Result:%s\n", result)
 }
@@ -189,10 +190,11 @@ func getAllAssets(contract *client.Contract) {
 func createAsset(contract *client.Contract) {
 	fmt.Printf("Submit Transaction: CreateAsset, creates new asset with ID, Color, Size, Owner and AppraisedValue arguments \n")
 
-	_, err := contract.SubmitTransaction("CreateAsset", assetId, "yellow", "5", "Tom", "1300")
+	submitResult, err := contract.SubmitTransaction("CreateAsset", assetId, "yellow", "5", "Tom", "1300")
 	if err != nil {
 		panic(fmt.Errorf("failed to submit transaction: %w", err))
 	}
+	unwrapResponse(submitResult)
 
 	fmt.Printf("*** Transaction committed successfully\n")
 }
@@ -205,7 +207,7 @@ func readAssetByID(contract *client.Contract) {
 	if err != nil {
 		panic(fmt.Errorf("failed to evaluate transaction: %w", err))
 	}
-	result := formatJSON(evaluateResult)
+	result := formatJSON(unwrapResponse(evaluateResult))
 
 	fmt.Printf("*** Result:%s\n", result)
 }
@@ -222,7 +224,12 @@ func transferAssetAsync(contract *client.Contract) {
 		panic(fmt.Errorf("failed to submit transaction asynchronously: %w", err))
 	}
 
-	fmt.Printf("Successfully submitted transaction to transfer ownership from %s to Mark. \n", string(submitResult))
+	var oldOwner string
+	if err := json.Unmarshal(unwrapResponse(submitResult), &oldOwner); err != nil {
+		panic(fmt.Errorf("failed to parse result: %w", err))
+	}
+
+	fmt.Printf("Successfully submitted transaction to transfer ownership from %s to Mark. \n", oldOwner)
 	fmt.Println("Waiting for transaction commit.")
 
 	if status, err := commit.Status(); err != nil {
@@ -266,6 +273,27 @@ func exampleErrorHandling(contract *client.Contract) {
 	}
 }
 
+// Response envelope in which the smart contract returns every transaction result
+type response struct {
+	Result   json.RawMessage `json:"result"`
+	Warnings []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"warnings"`
+}
+
+// Unwrap the response envelope, reporting any warnings separately from the result
+func unwrapResponse(data []byte) []byte {
+	var envelope response
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		panic(fmt.Errorf("failed to parse response: %w", err))
+	}
+	for _, warning := range envelope.Warnings {
+		fmt.Printf("*** Warning %s: %s\n", warning.Code, warning.Message)
+	}
+	return envelope.Result
+}
+
 //Format JSON data
 func formatJSON(data []byte) string {
 	var prettyJSON bytes.Buffer
@@ -7,17 +7,22 @@ package main
 import (
 	"log"
 
+	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
 )
 
 func main() {
+	if err := chaincode.CheckKeyNamespaces(); err != nil {
+		log.Panicf("Error checking asset-transfer-basic key namespaces: %v", err)
+	}
+
 	assetChaincode, err := contractapi.NewChaincode(&chaincode.SmartContract{})
 	if err != nil {
 		log.Panicf("Error creating asset-transfer-basic chaincode: %v", err)
 	}
 
-	if err := assetChaincode.Start(); err != nil {
+	if err := shim.Start(chaincode.WithResponseEnvelope(assetChaincode)); err != nil {
 		log.Panicf("Error starting asset-transfer-basic chaincode: %v", err)
 	}
 }
@@ -0,0 +1,116 @@
+package chaincode
+
+import (
+	"strings"
+)
+
+// RuleTypeABP marks an asset whose blocklist holds AdBlock Plus rules rather than domains.
+// Assets without a rule type list domains.
+const RuleTypeABP = "abp"
+
+// abpExceptionPrefix starts an AdBlock Plus exception rule
+const abpExceptionPrefix = "@@"
+
+// abpRule is a basic AdBlock Plus rule: "||example.com^" blocks example.com and its
+// subdomains, and the exception "@@||example.com^" allows them despite other rules
+type abpRule struct {
+	domain    string
+	exception bool
+}
+
+// parseABPRule parses a basic AdBlock Plus rule. Rules matching paths, wildcards within the
+// domain or $options cannot be evaluated against a hostname and are rejected.
+func parseABPRule(rule string) (*abpRule, error) {
+	text := strings.TrimSpace(rule)
+	parsed := abpRule{exception: strings.HasPrefix(text, abpExceptionPrefix)}
+	text = strings.TrimPrefix(text, abpExceptionPrefix)
+	if !strings.HasPrefix(text, "||") || !strings.HasSuffix(text, "^") || strings.ContainsAny(text[2:len(text)-1], "*/^|$") {
+		return nil, newError(ErrValidation, map[string]string{"rule": rule}, "invalid rule %q: only ||domain^ and @@||domain^ rules are supported", rule)
+	}
+
+	domain, err := normalizeDomain(text[2 : len(text)-1])
+	if err != nil {
+		return nil, newError(ErrValidation, map[string]string{"rule": rule}, "invalid rule %q: %s", rule, errorMessage(err))
+	}
+	parsed.domain = domain
+
+	return &parsed, nil
+}
+
+// String returns the canonical form of the rule
+func (r *abpRule) String() string {
+	if r.exception {
+		return abpExceptionPrefix + "||" + r.domain + "^"
+	}
+
+	return "||" + r.domain + "^"
+}
+
+// entries returns the list entries matching the same hostnames as the rule: its domain and
+// the wildcard of its subdomains
+func (r *abpRule) entries() []string {
+	return []string{r.domain, wildcardDomain + "." + r.domain}
+}
+
+// normalizeBlocklist normalizes a comma separated blocklist written in the syntax of ruleType
+func normalizeBlocklist(ruleType string, list string) (string, error) {
+	switch ruleType {
+	case "":
+		return normalizeDomainList(list)
+	case RuleTypeABP:
+		return normalizeABPRules(list)
+	}
+
+	return "", newError(ErrValidation, map[string]string{"field": "ruleType"}, "the rule type must be empty or %s", RuleTypeABP)
+}
+
+// normalizeABPRules normalizes every rule of a comma separated list of AdBlock Plus rules,
+// dropping duplicates
+func normalizeABPRules(list string) (string, error) {
+	if strings.TrimSpace(list) == "" {
+		return "", nil
+	}
+
+	var rules []string
+	for _, entry := range strings.Split(list, domainListSeparator) {
+		rule, err := parseABPRule(entry)
+		if err != nil {
+			return "", err
+		}
+		if !stringInSlice(rule.String(), rules) {
+			rules = append(rules, rule.String())
+		}
+	}
+
+	return strings.Join(rules, domainListSeparator), nil
+}
+
+// listEntries returns the allowlist and blocklist entries of asset, translating the rules of
+// an AdBlock Plus asset into the entries they match
+func listEntries(asset *Asset) ([]string, []string, error) {
+	var allowed []string
+	if asset.Allowlist != "" {
+		allowed = append(allowed, asset.Allowlist)
+	}
+	if asset.Blocklist == "" {
+		return allowed, nil, nil
+	}
+	if asset.RuleType != RuleTypeABP {
+		return allowed, strings.Split(asset.Blocklist, domainListSeparator), nil
+	}
+
+	var blocked []string
+	for _, entry := range strings.Split(asset.Blocklist, domainListSeparator) {
+		rule, err := parseABPRule(entry)
+		if err != nil {
+			return nil, nil, err
+		}
+		if rule.exception {
+			allowed = append(allowed, rule.entries()...)
+		} else {
+			blocked = append(blocked, rule.entries()...)
+		}
+	}
+
+	return allowed, blocked, nil
+}
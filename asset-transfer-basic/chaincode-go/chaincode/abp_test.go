@@ -0,0 +1,91 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestABPRules(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err := assetTransfer.CreateAssets(ws.transactionContext, `[{"allowlist":"www.bbc.co.uk","blocklist":"||example.com/ads^","ruleType":"abp"}]`)
+	requireError(t, err, chaincode.ErrValidation, `invalid rule "||example.com/ads^": only ||domain^ and @@||domain^ rules are supported`)
+	_, err = assetTransfer.CreateAssets(ws.transactionContext, `[{"allowlist":"www.bbc.co.uk","blocklist":"example.com","ruleType":"abp"}]`)
+	requireError(t, err, chaincode.ErrValidation, `invalid rule "example.com": only ||domain^ and @@||domain^ rules are supported`)
+	_, err = assetTransfer.CreateAssets(ws.transactionContext, `[{"allowlist":"www.bbc.co.uk","blocklist":"","ruleType":"regex"}]`)
+	requireError(t, err, chaincode.ErrValidation, "the rule type must be empty or abp")
+
+	_, err = assetTransfer.CreateAssets(ws.transactionContext, `[{"allowlist":"www.bbc.co.uk","blocklist":"||Doubleclick.net^, @@||ads.doubleclick.net^,||tracker.ads.doubleclick.net^","ruleType":"abp"}]`)
+	require.NoError(t, err)
+	ws.commit()
+
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "||doubleclick.net^,@@||ads.doubleclick.net^,||tracker.ads.doubleclick.net^", asset.Blocklist)
+	require.Equal(t, chaincode.RuleTypeABP, asset.RuleType)
+
+	for _, expected := range []struct {
+		hostname string
+		entry    string
+		rule     string
+		verdict  string
+	}{
+		{"doubleclick.net", "doubleclick.net", "||doubleclick.net^", chaincode.DomainBlocked},
+		{"stats.doubleclick.net", "*.doubleclick.net", "||doubleclick.net^", chaincode.DomainBlocked},
+		{"ads.doubleclick.net", "ads.doubleclick.net", "@@||ads.doubleclick.net^", chaincode.DomainAllowed},
+		// the exception overrides the more specific blocking rule
+		{"tracker.ads.doubleclick.net", "*.ads.doubleclick.net", "@@||ads.doubleclick.net^", chaincode.DomainAllowed},
+		{"www.bbc.co.uk", "www.bbc.co.uk", "", chaincode.DomainAllowed},
+	} {
+		match, err := assetTransfer.MatchDomain(ws.transactionContext, expected.hostname)
+		require.NoError(t, err)
+		require.Equal(t, expected.entry, match.Entry, expected.hostname)
+		require.Equal(t, expected.rule, match.Rule, expected.hostname)
+		require.Equal(t, expected.verdict, match.Verdict, expected.hostname)
+	}
+
+	// updates keep the rule type and its syntax
+	err = assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 1, "", 100)
+	requireError(t, err, chaincode.ErrValidation, `invalid rule "www.xxx.com": only ||domain^ and @@||domain^ rules are supported`)
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "||xxx.com^", 1, "", 100))
+	ws.commit()
+	match, err := assetTransfer.MatchDomain(ws.transactionContext, "doubleclick.net")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.DomainUnlisted, match.Verdict)
+	match, err = assetTransfer.MatchDomain(ws.transactionContext, "www.xxx.com")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.DomainBlocked, match.Verdict)
+	require.Equal(t, "||xxx.com^", match.Rule)
+}
+
+func TestABPDocument(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	err := assetTransfer.CreateAssetFromJSON(ws.transactionContext, `{"allowlist":"www.bbc.co.uk","blocklist":"www.xxx.com","ruleType":"abp","webfilterlist":100}`)
+	requireError(t, err, chaincode.ErrValidation, `invalid asset document: blocklist: invalid rule "www.xxx.com": only ||domain^ and @@||domain^ rules are supported`)
+	err = assetTransfer.CreateAssetFromJSON(ws.transactionContext, `{"allowlist":"www.bbc.co.uk","blocklist":"www.xxx.com","ruleType":"regex","webfilterlist":100}`)
+	requireError(t, err, chaincode.ErrValidation, "invalid asset document: ruleType: the rule type must be empty or abp")
+
+	require.NoError(t, assetTransfer.CreateAssetFromJSON(ws.transactionContext, `{"allowlist":"www.bbc.co.uk","blocklist":"||xxx.com^","ruleType":"abp","webfilterlist":100}`))
+	ws.commit()
+
+	err = assetTransfer.UpdateAssetFromJSON(ws.transactionContext, "www.bbc.co.uk", `{"blocklist":"www.xxx.com","ruleType":"","webfilterlist":100}`)
+	requireError(t, err, chaincode.ErrValidation, `invalid asset document: blocklist: invalid rule "www.xxx.com": only ||domain^ and @@||domain^ rules are supported; ruleType: the field cannot be updated`)
+	require.NoError(t, assetTransfer.UpdateAssetFromJSON(ws.transactionContext, "www.bbc.co.uk", `{"blocklist":"@@||www.xxx.com^","webfilterlist":100}`))
+	ws.commit()
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.CreatePolicy(ws.transactionContext, "year7", "Year 7 policy"))
+	ws.commit()
+	require.NoError(t, assetTransfer.AddAssetToPolicy(ws.transactionContext, "year7", "www.bbc.co.uk"))
+	ws.commit()
+	resolved, err := assetTransfer.ResolvePolicy(ws.transactionContext, "year7")
+	require.NoError(t, err)
+	require.Equal(t, []string{"*.www.xxx.com", "www.bbc.co.uk", "www.xxx.com"}, resolved.Allowlist)
+	require.Empty(t, resolved.Blocklist)
+}
@@ -0,0 +1,83 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// adminAttribute is the certificate attribute that marks a client as a web filter administrator
+const adminAttribute = "webfilter.admin"
+
+// callerMSPID returns the MSP ID of the client submitting the transaction
+func callerMSPID(ctx contractapi.TransactionContextInterface) (string, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+
+	return mspID, nil
+}
+
+// callerID returns the unique ID of the client submitting the transaction
+func callerID(ctx contractapi.TransactionContextInterface) (string, error) {
+	id, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	return id, nil
+}
+
+// assertAdmin returns an error unless the submitting client carries the admin attribute or
+// holds an administrator delegation that has not lapsed. The admin role of the role registry
+// is scoped to its organization and does not count, see assertOwnerOrAdmin.
+func assertAdmin(ctx contractapi.TransactionContextInterface) error {
+	err := assertCertifiedAdmin(ctx)
+	if err == nil {
+		return nil
+	}
+
+	delegated, delegationErr := hasActiveDelegation(ctx)
+	if delegationErr != nil {
+		return delegationErr
+	}
+	if delegated {
+		return nil
+	}
+
+	return err
+}
+
+// assertCertifiedAdmin returns an error unless the submitting client carries the admin attribute
+func assertCertifiedAdmin(ctx contractapi.TransactionContextInterface) error {
+	err := ctx.GetClientIdentity().AssertAttributeValue(adminAttribute, "true")
+	if err != nil {
+		return newError(ErrUnauthorized, nil, "the client is not authorized to perform this operation: %v", err)
+	}
+
+	return nil
+}
+
+// assertOwnerOrAdmin returns an error unless the submitting client is an administrator or is
+// the owner, identified by either its client ID or its MSP ID. Clients with the admin role of
+// the role registry also pass for owners registered in the registry of their organization.
+func assertOwnerOrAdmin(ctx contractapi.TransactionContextInterface, owner string) error {
+	if assertAdmin(ctx) == nil {
+		return nil
+	}
+
+	id, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if owner != "" && (owner == id || owner == mspID) {
+		return nil
+	}
+
+	return assertRegistryAdminOf(ctx, owner)
+}
@@ -0,0 +1,259 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	agreementObjectType         = "agreement"
+	agreementConsumerObjectType = "agreement~consumer"
+)
+
+// Agreement lifecycle states
+const (
+	AgreementProposed   = "PROPOSED"
+	AgreementActive     = "ACTIVE"
+	AgreementTerminated = "TERMINATED"
+)
+
+// Agreement records that a provider organization allows a consumer organization to use
+// a set of lists under the stated terms
+type Agreement struct {
+	ConsumerMSPID string    `json:"consumerMSPID"`
+	ID            string    `json:"id"`
+	ListIDs       []string  `json:"listIDs"`
+	ProposedAt    time.Time `json:"proposedAt"`
+	ProviderMSPID string    `json:"providerMSPID"`
+	Status        string    `json:"status"`
+	Terms         string    `json:"terms"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// ProposeAgreement offers the consumer organization access to the given lists and returns the ID
+// of the new agreement. The submitting organization becomes the provider and must own every list,
+// either as the organization itself or through the submitting client, and the agreement takes
+// effect once the consumer countersigns it. Active agreements let the consumer read the private
+// blocklists of the lists with ReadPrivateAsset.
+func (s *SmartContract) ProposeAgreement(ctx contractapi.TransactionContextInterface, consumerMSPID string, listIDs []string, terms string) (string, error) {
+	if len(listIDs) == 0 {
+		return "", newError(ErrValidation, map[string]string{"field": "listIDs"}, "an agreement must cover at least one list")
+	}
+
+	providerMSPID, err := callerMSPID(ctx)
+	if err != nil {
+		return "", err
+	}
+	if consumerMSPID == "" || consumerMSPID == providerMSPID {
+		return "", newError(ErrValidation, map[string]string{"field": "consumerMSPID"}, "an agreement requires a consumer organization other than %s", providerMSPID)
+	}
+
+	clientID, err := callerID(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, listID := range listIDs {
+		asset, err := s.ReadAsset(ctx, listID)
+		if err != nil {
+			return "", err
+		}
+		if asset.Owner != providerMSPID && asset.Owner != clientID {
+			return "", newError(ErrUnauthorized, map[string]string{"id": listID}, "the asset %s does not belong to %s and cannot be offered in an agreement", listID, providerMSPID)
+		}
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	agreementID := newRecordID(ctx, "agreement")
+	key, err := ctx.GetStub().CreateCompositeKey(agreementObjectType, []string{agreementID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	agreement := Agreement{
+		ConsumerMSPID: consumerMSPID,
+		ID:            agreementID,
+		ListIDs:       listIDs,
+		ProposedAt:    now,
+		ProviderMSPID: providerMSPID,
+		Status:        AgreementProposed,
+		Terms:         terms,
+		UpdatedAt:     now,
+	}
+	err = putRecord(ctx, key, agreement)
+	if err != nil {
+		return "", err
+	}
+
+	indexKey, err := ctx.GetStub().CreateCompositeKey(agreementConsumerObjectType, []string{consumerMSPID, agreementID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+	err = ctx.GetStub().PutState(indexKey, []byte{0x00})
+	if err != nil {
+		return "", fmt.Errorf("failed to put to world state: %v", err)
+	}
+
+	err = notify(ctx, consumerMSPID, "CountersignAgreement", agreementID)
+	if err != nil {
+		return "", err
+	}
+
+	return agreementID, nil
+}
+
+// CountersignAgreement activates a proposed agreement and clears the consumer's request to
+// countersign it. Only the consumer organization may countersign.
+func (s *SmartContract) CountersignAgreement(ctx contractapi.TransactionContextInterface, agreementID string) error {
+	agreement, err := s.ReadAgreement(ctx, agreementID)
+	if err != nil {
+		return err
+	}
+
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if mspID != agreement.ConsumerMSPID {
+		return newError(ErrUnauthorized, map[string]string{"agreementID": agreementID}, "only %s may countersign the agreement %s", agreement.ConsumerMSPID, agreementID)
+	}
+	if agreement.Status != AgreementProposed {
+		return newError(ErrValidation, map[string]string{"agreementID": agreementID, "status": agreement.Status}, "the agreement %s is %s and cannot be countersigned", agreementID, agreement.Status)
+	}
+
+	agreement.Status = AgreementActive
+	err = putAgreement(ctx, agreement)
+	if err != nil {
+		return err
+	}
+
+	return resolveNotifications(ctx, agreement.ConsumerMSPID, "CountersignAgreement", agreementID)
+}
+
+// TerminateAgreement ends a proposed or active agreement, clearing the consumer's request to
+// countersign it if it was still proposed. Either party may terminate it.
+func (s *SmartContract) TerminateAgreement(ctx contractapi.TransactionContextInterface, agreementID string) error {
+	agreement, err := s.ReadAgreement(ctx, agreementID)
+	if err != nil {
+		return err
+	}
+
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if mspID != agreement.ConsumerMSPID && mspID != agreement.ProviderMSPID {
+		return newError(ErrUnauthorized, map[string]string{"agreementID": agreementID}, "only the parties to the agreement %s may terminate it", agreementID)
+	}
+	if agreement.Status == AgreementTerminated {
+		return newError(ErrValidation, map[string]string{"agreementID": agreementID}, "the agreement %s has already been terminated", agreementID)
+	}
+
+	agreement.Status = AgreementTerminated
+	err = putAgreement(ctx, agreement)
+	if err != nil {
+		return err
+	}
+
+	return resolveNotifications(ctx, agreement.ConsumerMSPID, "CountersignAgreement", agreementID)
+}
+
+// ReadAgreement returns the agreement stored in the world state with given id
+func (s *SmartContract) ReadAgreement(ctx contractapi.TransactionContextInterface, agreementID string) (*Agreement, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(agreementObjectType, []string{agreementID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var agreement Agreement
+	exists, err := getRecord(ctx, key, &agreement)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, newError(ErrNotFound, map[string]string{"agreementID": agreementID}, "the agreement %s does not exist", agreementID)
+	}
+
+	return &agreement, nil
+}
+
+func putAgreement(ctx contractapi.TransactionContextInterface, agreement *Agreement) error {
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	agreement.UpdatedAt = now
+
+	key, err := ctx.GetStub().CreateCompositeKey(agreementObjectType, []string{agreement.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, agreement)
+}
+
+// assertAgreement returns an error unless the submitting organization is the provider itself
+// or holds an active agreement with the provider covering listID
+func assertAgreement(ctx contractapi.TransactionContextInterface, providerMSPID string, listID string) error {
+	consumerMSPID, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if consumerMSPID == providerMSPID {
+		return nil
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(agreementConsumerObjectType, []string{consumerMSPID})
+	if err != nil {
+		return err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return fmt.Errorf("failed to split composite key: %v", err)
+		}
+		key, err := ctx.GetStub().CreateCompositeKey(agreementObjectType, attributes[1:])
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %v", err)
+		}
+
+		agreementJSON, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return fmt.Errorf("failed to read from world state: %v", err)
+		}
+		var agreement Agreement
+		err = json.Unmarshal(agreementJSON, &agreement)
+		if err != nil {
+			return err
+		}
+
+		if agreement.Status == AgreementActive && agreement.ProviderMSPID == providerMSPID && stringInSlice(listID, agreement.ListIDs) {
+			return nil
+		}
+	}
+
+	return newError(ErrUnauthorized, map[string]string{"id": listID}, "organization %s has no active agreement with %s covering %s", consumerMSPID, providerMSPID, listID)
+}
+
+func stringInSlice(value string, values []string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
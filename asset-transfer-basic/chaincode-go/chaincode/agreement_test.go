@@ -0,0 +1,103 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProposeAgreement(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+
+	_, err := assetTransfer.ProposeAgreement(ws.transactionContext, "Org1MSP", []string{"www.bbc.co.uk"}, "research use")
+	requireError(t, err, chaincode.ErrValidation, "an agreement requires a consumer organization other than Org1MSP")
+
+	_, err = assetTransfer.ProposeAgreement(ws.transactionContext, "Org2MSP", []string{"www.google.com"}, "research use")
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.google.com does not exist")
+
+	// only lists owned by the provider or the proposing client can be offered
+	ws.setClient("Org1MSP", "user2", false)
+	_, err = assetTransfer.ProposeAgreement(ws.transactionContext, "Org2MSP", []string{"www.bbc.co.uk"}, "research use")
+	requireError(t, err, chaincode.ErrUnauthorized, "the asset www.bbc.co.uk does not belong to Org1MSP and cannot be offered in an agreement")
+	ws.setClient("Org1MSP", "user1", false)
+
+	agreementID, err := assetTransfer.ProposeAgreement(ws.transactionContext, "Org2MSP", []string{"www.bbc.co.uk"}, "research use")
+	require.NoError(t, err)
+	require.Equal(t, "agreement-"+ws.txID()+"-1", agreementID)
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", false)
+	pending, err := assetTransfer.GetMyPendingActions(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	notifications := pending.Notifications
+	require.Len(t, notifications, 1)
+	require.Equal(t, "CountersignAgreement", notifications[0].Action)
+	require.Equal(t, agreementID, notifications[0].Subject)
+}
+
+func TestAgreementLifecycle(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+	agreementID, err := assetTransfer.ProposeAgreement(ws.transactionContext, "Org2MSP", []string{"www.bbc.co.uk"}, "research use")
+	require.NoError(t, err)
+	ws.commit()
+
+	err = assetTransfer.CountersignAgreement(ws.transactionContext, agreementID)
+	requireError(t, err, chaincode.ErrUnauthorized, "only Org2MSP may countersign the agreement "+agreementID)
+
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, assetTransfer.CountersignAgreement(ws.transactionContext, agreementID))
+	ws.commit()
+
+	// countersigning clears the request to countersign
+	pending, err := assetTransfer.GetMyPendingActions(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	require.Empty(t, pending.Notifications)
+
+	agreement, err := assetTransfer.ReadAgreement(ws.transactionContext, agreementID)
+	require.NoError(t, err)
+	require.Equal(t, chaincode.AgreementActive, agreement.Status)
+	require.Equal(t, "Org1MSP", agreement.ProviderMSPID)
+
+	ws.setClient("Org3MSP", "user3", false)
+	err = assetTransfer.TerminateAgreement(ws.transactionContext, agreementID)
+	requireError(t, err, chaincode.ErrUnauthorized, "only the parties to the agreement "+agreementID+" may terminate it")
+
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.TerminateAgreement(ws.transactionContext, agreementID))
+	ws.commit()
+
+	err = assetTransfer.TerminateAgreement(ws.transactionContext, agreementID)
+	requireError(t, err, chaincode.ErrValidation, "the agreement "+agreementID+" has already been terminated")
+
+	_, err = assetTransfer.ReadAgreement(ws.transactionContext, "agreement2")
+	requireError(t, err, chaincode.ErrNotFound, "the agreement agreement2 does not exist")
+}
+
+func TestTerminateAgreementClearsNotification(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+	agreementID, err := assetTransfer.ProposeAgreement(ws.transactionContext, "Org2MSP", []string{"www.bbc.co.uk"}, "research use")
+	require.NoError(t, err)
+	ws.commit()
+
+	// withdrawing a proposed agreement clears the consumer's request to countersign it
+	require.NoError(t, assetTransfer.TerminateAgreement(ws.transactionContext, agreementID))
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", false)
+	pending, err := assetTransfer.GetMyPendingActions(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	require.Empty(t, pending.Notifications)
+}
@@ -0,0 +1,223 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// aliasObjectType keys the alias records by alias, and assetAliasObjectType indexes the aliases of each asset
+const (
+	aliasObjectType      = "alias"
+	assetAliasObjectType = "alias~asset"
+)
+
+// AssetAlias maps a stable external identifier, such as a human-friendly name or the ID used by
+// another system, to the key of an asset. Aliases follow their asset through key migrations.
+type AssetAlias struct {
+	Alias     string    `json:"alias"`
+	AssetID   string    `json:"assetID"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	UpdatedBy string    `json:"updatedBy"`
+}
+
+// RegisterAlias registers alias as an alternative identifier of the asset with given id.
+// Only the owner of the asset or an administrator may register an alias for it.
+func (s *SmartContract) RegisterAlias(ctx contractapi.TransactionContextInterface, alias string, id string) error {
+	if alias == "" {
+		return newError(ErrValidation, map[string]string{"field": "alias"}, "an alias is required")
+	}
+	err := s.assertAssetOwnerOrAdmin(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	existing, err := readAlias(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return newError(ErrValidation, map[string]string{"alias": alias, "id": existing.AssetID}, "the alias %s is already registered to %s", alias, existing.AssetID)
+	}
+
+	return putAlias(ctx, alias, "", id)
+}
+
+// ResolveAlias returns the key of the asset the alias refers to
+func (s *SmartContract) ResolveAlias(ctx contractapi.TransactionContextInterface, alias string) (string, error) {
+	existing, err := readAlias(ctx, alias)
+	if err != nil {
+		return "", err
+	}
+	if existing == nil {
+		return "", newError(ErrNotFound, map[string]string{"alias": alias}, "the alias %s is not registered", alias)
+	}
+
+	return existing.AssetID, nil
+}
+
+// ReassignAlias points an existing alias at the asset with given id. The caller must own, or
+// administer, both the asset the alias currently refers to and the new one.
+func (s *SmartContract) ReassignAlias(ctx contractapi.TransactionContextInterface, alias string, id string) error {
+	existing, err := readAlias(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return newError(ErrNotFound, map[string]string{"alias": alias}, "the alias %s is not registered", alias)
+	}
+	if existing.AssetID == id {
+		return newError(ErrValidation, map[string]string{"alias": alias, "id": id}, "the alias %s already refers to %s", alias, id)
+	}
+
+	err = s.assertAssetOwnerOrAdmin(ctx, existing.AssetID)
+	if err != nil {
+		return err
+	}
+	err = s.assertAssetOwnerOrAdmin(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return putAlias(ctx, alias, existing.AssetID, id)
+}
+
+// assertAssetOwnerOrAdmin returns an error unless the asset exists and the caller owns it or is an administrator
+func (s *SmartContract) assertAssetOwnerOrAdmin(ctx contractapi.TransactionContextInterface, id string) error {
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return assertOwnerOrAdmin(ctx, asset.Owner)
+}
+
+func readAlias(ctx contractapi.TransactionContextInterface, alias string) (*AssetAlias, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(aliasObjectType, []string{alias})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var existing AssetAlias
+	exists, err := getRecord(ctx, key, &existing)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	return &existing, nil
+}
+
+// putAlias points alias at newID, moving its entry in the asset index away from oldID if set
+func putAlias(ctx contractapi.TransactionContextInterface, alias string, oldID string, newID string) error {
+	if oldID != "" {
+		oldIndexKey, err := ctx.GetStub().CreateCompositeKey(assetAliasObjectType, []string{oldID, alias})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %v", err)
+		}
+		err = ctx.GetStub().DelState(oldIndexKey)
+		if err != nil {
+			return fmt.Errorf("failed to delete from world state: %v", err)
+		}
+	}
+
+	updatedBy, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(aliasObjectType, []string{alias})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	err = putRecord(ctx, key, AssetAlias{Alias: alias, AssetID: newID, UpdatedAt: now, UpdatedBy: updatedBy})
+	if err != nil {
+		return err
+	}
+
+	indexKey, err := ctx.GetStub().CreateCompositeKey(assetAliasObjectType, []string{newID, alias})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	err = ctx.GetStub().PutState(indexKey, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put to world state: %v", err)
+	}
+
+	return nil
+}
+
+// assetAliases returns the aliases that refer to the asset with given id
+func assetAliases(ctx contractapi.TransactionContextInterface, id string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetAliasObjectType, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var aliases []string
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %v", err)
+		}
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("malformed alias index key %q", queryResponse.Key)
+		}
+		aliases = append(aliases, parts[1])
+	}
+
+	return aliases, nil
+}
+
+// deleteAssetAliases removes the aliases that refer to the asset with given id
+func deleteAssetAliases(ctx contractapi.TransactionContextInterface, id string) error {
+	aliases, err := assetAliases(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, alias := range aliases {
+		key, err := ctx.GetStub().CreateCompositeKey(aliasObjectType, []string{alias})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %v", err)
+		}
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return fmt.Errorf("failed to delete from world state: %v", err)
+		}
+	}
+
+	_, err = deleteByPartialCompositeKey(ctx, assetAliasObjectType, []string{id})
+
+	return err
+}
+
+// moveAssetAliases points the aliases of the asset stored under oldID at newID
+func moveAssetAliases(ctx contractapi.TransactionContextInterface, oldID string, newID string) error {
+	aliases, err := assetAliases(ctx, oldID)
+	if err != nil {
+		return err
+	}
+
+	for _, alias := range aliases {
+		err = putAlias(ctx, alias, oldID, newID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
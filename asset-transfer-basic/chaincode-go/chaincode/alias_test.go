@@ -0,0 +1,93 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAliases(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	for _, id := range []string{"www.bbc.co.uk", "www.bbc.com"} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "", 1, "Org1MSP", 100))
+	}
+	ws.commit()
+
+	err := assetTransfer.RegisterAlias(ws.transactionContext, "", "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrValidation, "an alias is required")
+
+	ws.setClient("Org2MSP", "user2", false)
+	err = assetTransfer.RegisterAlias(ws.transactionContext, "bbc", "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+
+	// the owner of the asset may alias it whatever its attribute1
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.com", "", 1, "Org3MSP", 100))
+	require.NoError(t, assetTransfer.RegisterAlias(ws.transactionContext, "bbc-com", "www.bbc.com"))
+	ws.commit()
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.RegisterAlias(ws.transactionContext, "bbc", "www.bbc.org")
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.bbc.org does not exist")
+
+	require.NoError(t, assetTransfer.RegisterAlias(ws.transactionContext, "bbc", "www.bbc.co.uk"))
+	require.NoError(t, assetTransfer.RegisterAlias(ws.transactionContext, "crm:4711", "www.bbc.co.uk"))
+	ws.commit()
+
+	err = assetTransfer.RegisterAlias(ws.transactionContext, "bbc", "www.bbc.com")
+	requireError(t, err, chaincode.ErrValidation, "the alias bbc is already registered to www.bbc.co.uk")
+
+	id, err := assetTransfer.ResolveAlias(ws.transactionContext, "bbc")
+	require.NoError(t, err)
+	require.Equal(t, "www.bbc.co.uk", id)
+
+	_, err = assetTransfer.ResolveAlias(ws.transactionContext, "itv")
+	requireError(t, err, chaincode.ErrNotFound, "the alias itv is not registered")
+
+	err = assetTransfer.ReassignAlias(ws.transactionContext, "bbc", "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrValidation, "the alias bbc already refers to www.bbc.co.uk")
+
+	require.NoError(t, assetTransfer.ReassignAlias(ws.transactionContext, "bbc", "www.bbc.com"))
+	ws.commit()
+
+	id, err = assetTransfer.ResolveAlias(ws.transactionContext, "bbc")
+	require.NoError(t, err)
+	require.Equal(t, "www.bbc.com", id)
+
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.bbc.co.uk"))
+	ws.commit()
+
+	_, err = assetTransfer.ResolveAlias(ws.transactionContext, "crm:4711")
+	requireError(t, err, chaincode.ErrNotFound, "the alias crm:4711 is not registered")
+	id, err = assetTransfer.ResolveAlias(ws.transactionContext, "bbc")
+	require.NoError(t, err)
+	require.Equal(t, "www.bbc.com", id)
+}
+
+func TestAliasesFollowKeyMigrations(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+	require.NoError(t, assetTransfer.RegisterAlias(ws.transactionContext, "bbc", "www.bbc.co.uk"))
+	ws.commit()
+
+	_, err := assetTransfer.MigrateKeys(ws.transactionContext, "www.", "web:www.", 10, "")
+	require.NoError(t, err)
+	ws.commit()
+
+	id, err := assetTransfer.ResolveAlias(ws.transactionContext, "bbc")
+	require.NoError(t, err)
+	require.Equal(t, "web:www.bbc.co.uk", id)
+
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "web:www.bbc.co.uk"))
+	ws.commit()
+	for key := range ws.state {
+		require.NotContains(t, key, "alias")
+	}
+}
@@ -0,0 +1,269 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	lastModificationObjectType = "audit~asset"
+	auditEntryObjectType       = "audit~tx"
+)
+
+// auditTimeFormat renders audit entry timestamps in fixed width UTC, so that entry keys sort
+// in time order
+const auditTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// maxAuditPageSize caps the number of entries returned by one page of GetAuditLog
+const maxAuditPageSize = 100
+
+// LastModification records the most recent change made to an asset and who made it
+type LastModification struct {
+	MSPID       string    `json:"mspID"`
+	Operation   string    `json:"operation"`
+	SubmitterID string    `json:"submitterID"`
+	Timestamp   time.Time `json:"timestamp"`
+	TxID        string    `json:"txID"`
+}
+
+// GetLastModification returns the most recent change recorded for the asset with given id
+func (s *SmartContract) GetLastModification(ctx contractapi.TransactionContextInterface, id string) (*LastModification, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(lastModificationObjectType, []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var modification LastModification
+	exists, err := getRecord(ctx, key, &modification)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, newError(ErrNotFound, map[string]string{"id": id}, "no modification of %s has been recorded", id)
+	}
+
+	return &modification, nil
+}
+
+// recordModification stores the submitting identity and operation of a change to the asset with given id
+func recordModification(ctx contractapi.TransactionContextInterface, id string, operation string) error {
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	submitterID, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(lastModificationObjectType, []string{id})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, LastModification{
+		MSPID:       mspID,
+		Operation:   operation,
+		SubmitterID: submitterID,
+		Timestamp:   now,
+		TxID:        ctx.GetStub().GetTxID(),
+	})
+}
+
+// AuditEntry records a mutating transaction: who invoked which function, a digest of its
+// arguments and when. Unlike the history of the world state it is kept for every caller and
+// function, including those that change no asset.
+type AuditEntry struct {
+	ClientID       string    `json:"clientID"`
+	Function       string    `json:"function"`
+	MSPID          string    `json:"mspID"`
+	ParametersHash string    `json:"parametersHash"`
+	Timestamp      time.Time `json:"timestamp"`
+	TxID           string    `json:"txID"`
+}
+
+// AuditLog is the response of GetAuditLog
+type AuditLog struct {
+	Bookmark string        `json:"bookmark"`
+	Entries  []*AuditEntry `json:"entries"`
+	Metadata QueryMetadata `json:"metadata"`
+}
+
+// GetAuditLog returns one page of the audit entries recorded from startTime up to but not
+// including endTime, both in RFC 3339 format, oldest first. Pass the returned bookmark to
+// fetch the next page; it is empty after the last page.
+func (s *SmartContract) GetAuditLog(ctx contractapi.TransactionContextInterface, startTime string, endTime string, pageSize int, bookmark string) (*AuditLog, error) {
+	start, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		return nil, newError(ErrValidation, map[string]string{"field": "startTime"}, "the time %s must be in RFC 3339 format", startTime)
+	}
+	end, err := time.Parse(time.RFC3339, endTime)
+	if err != nil {
+		return nil, newError(ErrValidation, map[string]string{"field": "endTime"}, "the time %s must be in RFC 3339 format", endTime)
+	}
+	if !start.Before(end) {
+		return nil, newError(ErrValidation, map[string]string{"field": "endTime"}, "the start time must be before the end time")
+	}
+	if pageSize < 1 || pageSize > maxAuditPageSize {
+		return nil, newError(ErrValidation, map[string]string{"field": "pageSize"}, "the page size must be between 1 and %d", maxAuditPageSize)
+	}
+
+	startAttributes := []string{start.UTC().Format(auditTimeFormat)}
+	if bookmark != "" {
+		startAttributes = strings.SplitN(bookmark, "/", 2)
+		if len(startAttributes) != 2 || startAttributes[0] < start.UTC().Format(auditTimeFormat) {
+			return nil, newError(ErrValidation, map[string]string{"field": "bookmark"}, "the bookmark %s does not belong to the time range", bookmark)
+		}
+	}
+	startKey, err := ctx.GetStub().CreateCompositeKey(auditEntryObjectType, startAttributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+	endKey, err := ctx.GetStub().CreateCompositeKey(auditEntryObjectType, []string{end.UTC().Format(auditTimeFormat)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(auditEntryObjectType, []string{}, int32(pageSize), startKey)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*AuditEntry{}
+	nextKey := responseMetadata.Bookmark
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if queryResponse.Key >= endKey {
+			// the entries are in time order, so the rest of the page lies after the range too
+			nextKey = ""
+			break
+		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.returned(len(queryResponse.Value))
+		if err != nil {
+			return nil, err
+		}
+
+		var entry AuditEntry
+		err = json.Unmarshal(queryResponse.Value, &entry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+
+	nextBookmark := ""
+	if nextKey != "" && nextKey < endKey {
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(nextKey)
+		if err != nil {
+			return nil, err
+		}
+		nextBookmark = strings.Join(attributes, "/")
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Cost = &meter.cost
+
+	return &AuditLog{Bookmark: nextBookmark, Entries: entries, Metadata: metadata}, nil
+}
+
+// recordAuditEntry stores the audit entry of the current transaction, which invokes function
+func recordAuditEntry(ctx contractapi.TransactionContextInterface, function string) error {
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	clientID, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, parameters := ctx.GetStub().GetFunctionAndParameters()
+	if parameters == nil {
+		parameters = []string{}
+	}
+	parametersJSON, err := json.Marshal(parameters)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(parametersJSON)
+
+	txID := ctx.GetStub().GetTxID()
+	key, err := ctx.GetStub().CreateCompositeKey(auditEntryObjectType, []string{now.UTC().Format(auditTimeFormat), txID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, AuditEntry{
+		ClientID:       clientID,
+		Function:       function,
+		MSPID:          mspID,
+		ParametersHash: hex.EncodeToString(digest[:]),
+		Timestamp:      now,
+		TxID:           txID,
+	})
+}
+
+// eraseAuditClient tombstones the client of every audit entry recorded for subjectRef
+func eraseAuditClient(ctx contractapi.TransactionContextInterface, subjectRef string) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(auditEntryObjectType, []string{})
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	count := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		var entry AuditEntry
+		err = json.Unmarshal(queryResponse.Value, &entry)
+		if err != nil {
+			return 0, err
+		}
+		if entry.ClientID != subjectRef {
+			continue
+		}
+
+		entry.ClientID = ErasedSubject
+		err = putRecord(ctx, queryResponse.Key, entry)
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,103 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLastModification(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	_, err := assetTransfer.GetLastModification(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrNotFound, "no modification of www.bbc.co.uk has been recorded")
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", true)
+	_, err = assetTransfer.TransferAsset(ws.transactionContext, "www.bbc.co.uk", "Org2MSP")
+	require.NoError(t, err)
+	ws.commit()
+
+	modification, err := assetTransfer.GetLastModification(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.LastModification{
+		MSPID:       "Org2MSP",
+		Operation:   "transfer",
+		SubmitterID: "user2",
+		Timestamp:   time.Date(2021, time.March, 1, 9, 1, 0, 0, time.UTC),
+		TxID:        "tx001",
+	}, modification)
+
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.bbc.co.uk"))
+	ws.commit()
+
+	modification, err = assetTransfer.GetLastModification(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "delete", modification.Operation)
+}
+
+func TestGetAuditLog(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+	ws.commit()
+	// evaluated functions are not audited
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "ReadAsset"))
+	ws.commit()
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "SmartContract:UpdateAsset"))
+	ws.commit()
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "DeleteAsset"))
+	ws.commit()
+
+	log, err := assetTransfer.GetAuditLog(ws.transactionContext, "2021-03-01T09:00:00Z", "2021-03-01T10:00:00Z", 2, "")
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.AuditEntry{
+		{
+			ClientID:       "admin",
+			Function:       "CreateAsset",
+			MSPID:          "Org1MSP",
+			ParametersHash: "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+			Timestamp:      time.Date(2021, time.March, 1, 9, 0, 0, 0, time.UTC),
+			TxID:           "tx000",
+		},
+		{
+			ClientID:       "user2",
+			Function:       "UpdateAsset",
+			MSPID:          "Org2MSP",
+			ParametersHash: "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+			Timestamp:      time.Date(2021, time.March, 1, 9, 2, 0, 0, time.UTC),
+			TxID:           "tx002",
+		},
+	}, log.Entries)
+	require.Equal(t, "2021-03-01T09:03:00.000000000Z/tx003", log.Bookmark)
+
+	log, err = assetTransfer.GetAuditLog(ws.transactionContext, "2021-03-01T09:00:00Z", "2021-03-01T10:00:00Z", 2, log.Bookmark)
+	require.NoError(t, err)
+	require.Len(t, log.Entries, 1)
+	require.Equal(t, "DeleteAsset", log.Entries[0].Function)
+	require.Empty(t, log.Bookmark)
+
+	// the end of the range is exclusive
+	log, err = assetTransfer.GetAuditLog(ws.transactionContext, "2021-03-01T09:01:00Z", "2021-03-01T09:03:00Z", 10, "")
+	require.NoError(t, err)
+	require.Len(t, log.Entries, 1)
+	require.Equal(t, "UpdateAsset", log.Entries[0].Function)
+	require.Empty(t, log.Bookmark)
+
+	_, err = assetTransfer.GetAuditLog(ws.transactionContext, "yesterday", "2021-03-01T10:00:00Z", 10, "")
+	requireError(t, err, chaincode.ErrValidation, "the time yesterday must be in RFC 3339 format")
+	_, err = assetTransfer.GetAuditLog(ws.transactionContext, "2021-03-01T10:00:00Z", "2021-03-01T09:00:00Z", 10, "")
+	requireError(t, err, chaincode.ErrValidation, "the start time must be before the end time")
+	_, err = assetTransfer.GetAuditLog(ws.transactionContext, "2021-03-01T09:00:00Z", "2021-03-01T10:00:00Z", 0, "")
+	requireError(t, err, chaincode.ErrValidation, "the page size must be between 1 and 100")
+	_, err = assetTransfer.GetAuditLog(ws.transactionContext, "2021-03-01T09:05:00Z", "2021-03-01T10:00:00Z", 10, "2021-03-01T09:03:00.000000000Z/tx003")
+	requireError(t, err, chaincode.ErrValidation, "the bookmark 2021-03-01T09:03:00.000000000Z/tx003 does not belong to the time range")
+}
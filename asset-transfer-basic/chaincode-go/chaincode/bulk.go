@@ -0,0 +1,386 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// maxBulkRecords bounds the number of assets a single bulk transaction may modify and is the
+// default limit on the entries of a batch
+const maxBulkRecords = 100
+
+// JobImportAssets issues the assets of the JSON array in its assets parameter, in the format
+// CreateAssets takes, one page per ContinueJob call, so that imports larger than the batch
+// limit need not be split by the client. The assets are owned by the client that started the
+// job. Assets that already exist, or repeat an earlier asset of the import, are skipped.
+const JobImportAssets = "importAssets"
+
+func init() {
+	jobKinds[JobImportAssets] = jobKind{
+		validate: func(params map[string]string) error {
+			assets, err := decodeAssetBatch(params["assets"])
+			if err != nil {
+				return err
+			}
+			if len(assets) == 0 {
+				return newError(ErrValidation, map[string]string{"field": "assets"}, "the import must contain at least one asset")
+			}
+			return nil
+		},
+		run: importAssetsPage,
+	}
+}
+
+// assetPatch holds the values a caller supplies to UpdateAssetsWhere. Fields left out
+// of the JSON document keep their current value. The asset ID and allowlist cannot be patched.
+type assetPatch struct {
+	Attribute1    *string `json:"attribute1"`
+	Attribute2    *int    `json:"attribute2"`
+	Blocklist     *string `json:"blocklist"`
+	Webfilterlist *int    `json:"webfilterlist"`
+}
+
+// CreateAssets issues the assets of the JSON array assetsJSON to the world state in a single
+// transaction and returns their IDs. An asset without an "id" is stored under its allowlist,
+// like one issued by CreateAsset. The batch is rejected as a whole if any of its assets
+// already exists or appears in it more than once.
+func (s *SmartContract) CreateAssets(ctx contractapi.TransactionContextInterface, assetsJSON string) ([]string, error) {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assets, err := decodeAssetBatch(assetsJSON)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := readPayloadLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(assets) < 1 {
+		return nil, newError(ErrValidation, map[string]string{"field": "assets"}, "the batch must contain between 1 and %d assets", limits.maxBatchEntries)
+	}
+	err = limits.assertBatchSize(len(assets))
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// no asset is written until the whole batch is validated,
+	// so duplicates within the batch are detected by hand
+	ids := make([]string, 0, len(assets))
+	for _, asset := range assets {
+		asset.Owner = owner
+		err = prepareNewAsset(ctx, asset)
+		if err != nil {
+			return nil, err
+		}
+		if stringInSlice(asset.ID, ids) {
+			return nil, newError(ErrValidation, map[string]string{"id": asset.ID}, "the asset %s appears more than once in the batch", asset.ID)
+		}
+		exists, err := s.AssetExists(ctx, asset.ID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, assetExistsError(asset.ID)
+		}
+		ids = append(ids, asset.ID)
+	}
+
+	err = chargeQuota(ctx, assets...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = meterOperation(ctx, meterCreate, len(assets))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, asset := range assets {
+		err = putAsset(ctx, asset)
+		if err != nil {
+			return nil, err
+		}
+
+		err = recordModification(ctx, asset.ID, operationCreate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = emitAssetEvent(ctx, assetCreatedEventName, assets...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// decodeAssetBatch decodes the JSON array of assets taken by CreateAssets and JobImportAssets
+func decodeAssetBatch(assetsJSON string) ([]*Asset, error) {
+	var assets []*Asset
+	decoder := json.NewDecoder(bytes.NewReader([]byte(assetsJSON)))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&assets)
+	if err != nil {
+		return nil, newError(ErrValidation, nil, "invalid asset batch: %v", err)
+	}
+	for _, asset := range assets {
+		if asset == nil {
+			return nil, newError(ErrValidation, nil, "invalid asset batch: null is not an asset")
+		}
+	}
+
+	return assets, nil
+}
+
+// importAssetsPage issues up to pageSize assets of an import job, starting at the index of the
+// asset held by the job's bookmark
+func importAssetsPage(ctx contractapi.TransactionContextInterface, job *Job, pageSize int) (int, string, error) {
+	assets, err := decodeAssetBatch(job.Params["assets"])
+	if err != nil {
+		return 0, "", err
+	}
+	start := 0
+	if job.Bookmark != "" {
+		start, err = strconv.Atoi(job.Bookmark)
+		if err != nil || start < 0 || start > len(assets) {
+			return 0, "", fmt.Errorf("the bookmark %s is not an asset of the import", job.Bookmark)
+		}
+	}
+	end := start + pageSize
+	if end > len(assets) {
+		end = len(assets)
+	}
+
+	s := &SmartContract{}
+	var ids []string
+	var page []*Asset
+	for _, asset := range assets[start:end] {
+		asset.Owner = job.StartedBy
+		err = prepareNewAsset(ctx, asset)
+		if err != nil {
+			return 0, "", err
+		}
+		if stringInSlice(asset.ID, ids) {
+			continue
+		}
+		exists, err := s.AssetExists(ctx, asset.ID)
+		if err != nil {
+			return 0, "", err
+		}
+		if exists {
+			continue
+		}
+		ids = append(ids, asset.ID)
+		page = append(page, asset)
+	}
+
+	if len(page) > 0 {
+		err = chargeQuota(ctx, page...)
+		if err != nil {
+			return 0, "", err
+		}
+		err = meterOperation(ctx, meterCreate, len(page))
+		if err != nil {
+			return 0, "", err
+		}
+		for _, asset := range page {
+			err = putAsset(ctx, asset)
+			if err != nil {
+				return 0, "", err
+			}
+			err = recordModification(ctx, asset.ID, operationCreate)
+			if err != nil {
+				return 0, "", err
+			}
+		}
+		err = emitAssetEvent(ctx, assetCreatedEventName, page...)
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
+	bookmark := ""
+	if end < len(assets) {
+		bookmark = strconv.Itoa(end)
+	}
+
+	return end - start, bookmark, nil
+}
+
+// UpdateAssetsWhere applies patchJSON to at most maxRecords assets matching the rich query
+// selector and returns the IDs of the assets it modified. Callers page through larger result
+// sets by repeating the transaction until it returns fewer than maxRecords IDs, so the selector
+// should exclude assets that have already been patched.
+func (s *SmartContract) UpdateAssetsWhere(ctx contractapi.TransactionContextInterface, selector string, patchJSON string, maxRecords int) ([]string, error) {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = assertBulkSelector(ctx, selector, maxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	var patch assetPatch
+	decoder := json.NewDecoder(bytes.NewReader([]byte(patchJSON)))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&patch)
+	if err != nil {
+		return nil, newError(ErrValidation, nil, "invalid asset patch: %v", err)
+	}
+	if patch == (assetPatch{}) {
+		return nil, newError(ErrValidation, nil, "the asset patch does not change any field")
+	}
+
+	matches, err := selectAssets(ctx, selector, maxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	modified := []string{}
+	updated := []*Asset{}
+	for _, asset := range matches {
+		patch.apply(asset)
+		asset, err = s.updateAsset(ctx, asset.ID, asset.Blocklist, asset.Attribute2, asset.Attribute1, asset.Webfilterlist)
+		if err != nil {
+			return nil, err
+		}
+		modified = append(modified, asset.ID)
+		updated = append(updated, asset)
+	}
+	if len(updated) == 0 {
+		return modified, nil
+	}
+
+	err = emitAssetEvent(ctx, assetUpdatedEventName, updated...)
+	if err != nil {
+		return nil, err
+	}
+
+	return modified, nil
+}
+
+// DeleteAssetsByQuery deletes at most maxRecords assets matching the rich query selector, like
+// DeleteAsset does one, and returns the IDs of the assets it deleted. Callers delete larger
+// result sets by repeating the transaction until it returns fewer than maxRecords IDs, which
+// keeps the read/write set of each transaction bounded.
+func (s *SmartContract) DeleteAssetsByQuery(ctx contractapi.TransactionContextInterface, selector string, maxRecords int) ([]string, error) {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = assertBulkSelector(ctx, selector, maxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := selectAssets(ctx, selector, maxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := []string{}
+	for _, asset := range matches {
+		err = deleteAsset(ctx, asset)
+		if err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, asset.ID)
+	}
+	if len(matches) == 0 {
+		return deleted, nil
+	}
+
+	err = releaseQuota(ctx, deleted...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = emitAssetEvent(ctx, assetDeletedEventName, matches...)
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// assertBulkSelector checks the selector and record limit of a bulk transaction
+func assertBulkSelector(ctx contractapi.TransactionContextInterface, selector string, maxRecords int) error {
+	limits, err := readPayloadLimits(ctx)
+	if err != nil {
+		return err
+	}
+	if maxRecords < 1 || maxRecords > limits.maxBatchEntries {
+		return newError(ErrValidation, map[string]string{"field": "maxRecords"}, "maxRecords must be between 1 and %d", limits.maxBatchEntries)
+	}
+
+	var selectorObject map[string]interface{}
+	err = json.Unmarshal([]byte(selector), &selectorObject)
+	if err != nil || selectorObject == nil {
+		return newError(ErrValidation, map[string]string{"field": "selector"}, "the selector must be a JSON object")
+	}
+
+	return nil
+}
+
+// selectAssets returns at most maxRecords assets matching the rich query selector
+func selectAssets(ctx contractapi.TransactionContextInterface, selector string, maxRecords int) ([]*Asset, error) {
+	// no limit is sent with the query because internal records matching the selector would count towards it
+	resultsIterator, err := queryState(ctx, fmt.Sprintf(`{"selector":%s}`, selector))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var matches []*Asset
+	for resultsIterator.HasNext() && len(matches) < maxRecords {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		// internal records are JSON documents too and may match a loose selector
+		id, ok, err := assetIDFromKey(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		asset, err := unmarshalAsset(id, queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, asset)
+	}
+
+	return matches, nil
+}
+
+func (p assetPatch) apply(asset *Asset) {
+	if p.Attribute1 != nil {
+		asset.Attribute1 = *p.Attribute1
+	}
+	if p.Attribute2 != nil {
+		asset.Attribute2 = *p.Attribute2
+	}
+	if p.Blocklist != nil {
+		asset.Blocklist = *p.Blocklist
+	}
+	if p.Webfilterlist != nil {
+		asset.Webfilterlist = *p.Webfilterlist
+	}
+}
@@ -0,0 +1,144 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateAssetsWhere(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	for _, id := range []string{"www.bbc.co.uk", "www.google.com", "www.napier.ac.uk"} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "", 1, "", 100))
+	}
+	ws.commit()
+
+	_, err := assetTransfer.UpdateAssetsWhere(ws.transactionContext, "{}", `{"attribute2":0}`, 10)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err = assetTransfer.UpdateAssetsWhere(ws.transactionContext, "{}", `{"attribute2":0}`, 0)
+	requireError(t, err, chaincode.ErrValidation, "maxRecords must be between 1 and 100")
+
+	_, err = assetTransfer.UpdateAssetsWhere(ws.transactionContext, "[]", `{"attribute2":0}`, 10)
+	requireError(t, err, chaincode.ErrValidation, "the selector must be a JSON object")
+
+	_, err = assetTransfer.UpdateAssetsWhere(ws.transactionContext, "{}", `{"allowlist":"www.xxx.com"}`, 10)
+	requireError(t, err, chaincode.ErrValidation, `invalid asset patch: json: unknown field "allowlist"`)
+
+	_, err = assetTransfer.UpdateAssetsWhere(ws.transactionContext, "{}", "{}", 10)
+	requireError(t, err, chaincode.ErrValidation, "the asset patch does not change any field")
+
+	modified, err := assetTransfer.UpdateAssetsWhere(ws.transactionContext, `{"attribute1":"nobody"}`, `{"attribute2":0}`, 10)
+	require.NoError(t, err)
+	require.Empty(t, modified)
+
+	modified, err = assetTransfer.UpdateAssetsWhere(ws.transactionContext, "{}", `{"attribute2":0}`, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.bbc.co.uk", "www.google.com"}, modified)
+	require.Contains(t, ws.writes, assetKey("www.bbc.co.uk"))
+	require.Contains(t, ws.writes, assetKey("www.google.com"))
+	require.NotContains(t, ws.writes, assetKey("www.napier.ac.uk"))
+}
+
+func TestDeleteAssetsByQuery(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	for _, id := range []string{"www.bbc.co.uk", "www.google.com", "www.napier.ac.uk"} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "www.xxx.com", 1, "", 100))
+		ws.commit()
+	}
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.yahoo.com", "", 2, "", 100))
+	ws.commit()
+
+	_, err := assetTransfer.DeleteAssetsByQuery(ws.transactionContext, `{"attribute2":1}`, 10)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err = assetTransfer.DeleteAssetsByQuery(ws.transactionContext, `{"attribute2":1}`, 101)
+	requireError(t, err, chaincode.ErrValidation, "maxRecords must be between 1 and 100")
+
+	_, err = assetTransfer.DeleteAssetsByQuery(ws.transactionContext, "null", 10)
+	requireError(t, err, chaincode.ErrValidation, "the selector must be a JSON object")
+
+	deleted, err := assetTransfer.DeleteAssetsByQuery(ws.transactionContext, `{"attribute2":3}`, 10)
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+
+	count := ws.stub.SetEventCallCount()
+	deleted, err = assetTransfer.DeleteAssetsByQuery(ws.transactionContext, `{"attribute2":1}`, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.bbc.co.uk", "www.google.com"}, deleted)
+	require.Equal(t, count+1, ws.stub.SetEventCallCount())
+	name, event := lastAssetEvent(t, ws)
+	require.Equal(t, "AssetDeleted", name)
+	require.Len(t, event.Assets, 2)
+	ws.commit()
+
+	assets, err := assetTransfer.GetAssetsByBlocklist(ws.transactionContext, "www.xxx.com")
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 1)
+	require.Equal(t, "www.napier.ac.uk", assets.Assets[0].ID)
+
+	// the quota of every deleted asset is released, not only that of the last one
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 2, usage.Assets)
+	require.Equal(t, 3, usage.Entries)
+}
+
+func TestCreateAssets(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+
+	_, err := assetTransfer.CreateAssets(ws.transactionContext, `[{"allowlist":"www.google.com"}]`)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err = assetTransfer.CreateAssets(ws.transactionContext, "[]")
+	requireError(t, err, chaincode.ErrValidation, "the batch must contain between 1 and 100 assets")
+
+	_, err = assetTransfer.CreateAssets(ws.transactionContext, `[{"allowlist":"www.google.com","colour":"red"}]`)
+	requireError(t, err, chaincode.ErrValidation, `invalid asset batch: json: unknown field "colour"`)
+
+	_, err = assetTransfer.CreateAssets(ws.transactionContext, `[{"allowlist":"www.google.com"},{"allowlist":"www.google.com"}]`)
+	requireError(t, err, chaincode.ErrValidation, "the asset www.google.com appears more than once in the batch")
+	ws.rollback()
+
+	_, err = assetTransfer.CreateAssets(ws.transactionContext, `[{"allowlist":"www.google.com"},{"allowlist":"www.bbc.co.uk"}]`)
+	requireError(t, err, chaincode.ErrAssetExists, "the asset www.bbc.co.uk already exists")
+	ws.rollback()
+
+	created := ws.now
+	ids, err := assetTransfer.CreateAssets(ws.transactionContext, `[
+		{"allowlist":"www.google.com","attribute2":1,"blocklist":"www.xxx.com","createdAt":"2000-01-01T00:00:00Z","webfilterlist":100},
+		{"allowlist":"www.napier.ac.uk","attribute2":2,"webfilterlist":200}
+	]`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.google.com", "www.napier.ac.uk"}, ids)
+	ws.commit()
+
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.google.com")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Asset{Allowlist: "www.google.com", Attribute2: 1, Blocklist: "www.xxx.com", CreatedAt: created, ID: "www.google.com", Owner: "admin", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: created, Webfilterlist: 100}, asset)
+
+	// the whole batch is charged against the quota, not only its last asset
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 3, usage.Assets)
+	require.Equal(t, 4, usage.Entries)
+
+	require.NoError(t, assetTransfer.SetQuota(ws.transactionContext, "Org1MSP", 4, 10))
+	ws.commit()
+
+	_, err = assetTransfer.CreateAssets(ws.transactionContext, `[{"allowlist":"www.yahoo.com"},{"allowlist":"www.bing.com"}]`)
+	requireError(t, err, chaincode.ErrQuotaExceeded, "quota exceeded: organization Org1MSP already owns 4 of 4 permitted assets")
+	ws.rollback()
+}
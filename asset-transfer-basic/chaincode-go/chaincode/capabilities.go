@@ -0,0 +1,107 @@
+package chaincode
+
+import (
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SchemaVersion identifies the layout of the records the contract stores and returns. It is
+// increased whenever a change would break clients written against the previous layout.
+const SchemaVersion = 4
+
+// Watch pattern matching modes
+const (
+	MatchExact  = "exact"
+	MatchPrefix = "prefix"
+)
+
+// Capabilities describes what this version of the contract supports, so that clients can adapt
+// to the deployed chaincode at runtime. Features maps each optional feature to whether it is
+// currently enabled; writes are disabled while the contract is in maintenance mode.
+type Capabilities struct {
+	Features      map[string]bool `json:"features"`
+	JobKinds      []string        `json:"jobKinds"`
+	Limits        map[string]int  `json:"limits"`
+	MatchingModes []string        `json:"matchingModes"`
+	SchemaVersion int             `json:"schemaVersion"`
+}
+
+// GetCapabilities returns the features, limits and schema version of the deployed contract
+func (s *SmartContract) GetCapabilities(ctx contractapi.TransactionContextInterface) (*Capabilities, error) {
+	config, err := readConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := readPayloadLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]string, 0, len(jobKinds))
+	for kind := range jobKinds {
+		jobs = append(jobs, kind)
+	}
+	sort.Strings(jobs)
+
+	return &Capabilities{
+		Features: map[string]bool{
+			"abpRules":             true,
+			"aliases":              true,
+			"assetDocuments":       true,
+			"assetIDs":             true,
+			"assetEvents":          true,
+			"assetOwners":          true,
+			"assetStats":           true,
+			"auditLog":             true,
+			"categories":           true,
+			"composedTransactions": true,
+			"delegation":           true,
+			"domainNormalization":  true,
+			"emergencyRollback":    true,
+			"endorsementPolicies":  true,
+			"errorCodes":           true,
+			"expiry":               true,
+			"filterListExport":     true,
+			"fieldProjection":      true,
+			"filterPolicies":       true,
+			"hostsFileImport":      true,
+			"idempotencyKeys":      true,
+			"jobs":                 true,
+			"legacyAssetMigration": true,
+			"privateBlocklists":    true,
+			"responseEnvelope":     true,
+			"roles":                true,
+			"schedules":            true,
+			"schemaMigrations":     true,
+			"timestamps":           true,
+			"transferProposals":    true,
+			"usageMetrics":         true,
+			"writes":               !config.MaintenanceMode,
+		},
+		JobKinds: jobs,
+		Limits: map[string]int{
+			"idempotencyTTLSeconds": int(idempotencyTTL.Seconds()),
+			"maxActivityPageSize":   maxActivityPageSize,
+			"maxArgumentBytes":      limits.maxArgumentBytes,
+			"maxAssetIDLength":      maxAssetIDLength,
+			"maxAssetPageSize":      maxAssetPageSize,
+			"maxAuditPageSize":      maxAuditPageSize,
+			"maxBulkRecords":        limits.maxBatchEntries,
+			"maxComposedSteps":      maxComposedSteps,
+			"maxJobPageSize":        maxJobPageSize,
+			"maxLinkDepth":          maxLinkDepth,
+			"maxQueryBytes":         meter.maxBytes,
+			"maxQueryKeys":          meter.maxKeys,
+			"maxSampleSize":         maxSampleSize,
+			"maxValueBytes":         limits.maxValueBytes,
+			"maxWebfilterlist":      maxWebfilterlist,
+		},
+		MatchingModes: []string{MatchExact, MatchPrefix},
+		SchemaVersion: SchemaVersion,
+	}, nil
+}
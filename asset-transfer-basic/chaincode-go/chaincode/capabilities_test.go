@@ -0,0 +1,34 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCapabilities(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	capabilities, err := assetTransfer.GetCapabilities(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, chaincode.SchemaVersion, capabilities.SchemaVersion)
+	require.True(t, capabilities.Features["writes"])
+	require.Equal(t, []string{chaincode.JobCompactUsageMetrics, chaincode.JobImportAssets, chaincode.JobMigrateKeys, chaincode.JobRecountAssetStats, chaincode.JobSweepIdempotencyKeys}, capabilities.JobKinds)
+	require.Equal(t, []string{chaincode.MatchExact, chaincode.MatchPrefix}, capabilities.MatchingModes)
+	require.Equal(t, 10000, capabilities.Limits["maxQueryKeys"])
+	require.Equal(t, 86400, capabilities.Limits["idempotencyTTLSeconds"])
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetQueryLimits(ws.transactionContext, 50, 1024))
+	ws.commit()
+	require.NoError(t, assetTransfer.SetMaintenanceMode(ws.transactionContext, true))
+	ws.commit()
+
+	capabilities, err = assetTransfer.GetCapabilities(ws.transactionContext)
+	require.NoError(t, err)
+	require.False(t, capabilities.Features["writes"])
+	require.Equal(t, 50, capabilities.Limits["maxQueryKeys"])
+	require.Equal(t, 1024, capabilities.Limits["maxQueryBytes"])
+}
@@ -0,0 +1,184 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// categoryObjectType keys the category registry by category ID, and categoryIndexObjectType
+// indexes assets by their category followed by their ID
+const (
+	categoryObjectType      = "category"
+	categoryIndexObjectType = "index~category"
+)
+
+// Category is an entry of the managed taxonomy that filter entries are classified by, such as
+// "social", "adult" or "education", so that policies can target a category rather than a domain
+type Category struct {
+	Description string `json:"description"`
+	ID          string `json:"id"`
+}
+
+// CreateCategory adds a new category to the registry
+func (s *SmartContract) CreateCategory(ctx contractapi.TransactionContextInterface, categoryID string, description string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if categoryID == "" {
+		return newError(ErrValidation, map[string]string{"field": "categoryID"}, "a category ID is required")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(categoryObjectType, []string{categoryID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	var existing Category
+	exists, err := getRecord(ctx, key, &existing)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return newError(ErrValidation, map[string]string{"categoryID": categoryID}, "the category %s already exists", categoryID)
+	}
+
+	return putRecord(ctx, key, Category{Description: description, ID: categoryID})
+}
+
+// ListCategories returns the registered categories ordered by ID
+func (s *SmartContract) ListCategories(ctx contractapi.TransactionContextInterface) ([]*Category, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(categoryObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	categories := []*Category{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var category Category
+		err = json.Unmarshal(queryResponse.Value, &category)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, &category)
+	}
+
+	return categories, nil
+}
+
+// SetAssetCategory classifies the asset with given id under a registered category, or removes
+// its classification when categoryID is empty. Only the owner of the asset or an administrator
+// may classify it.
+func (s *SmartContract) SetAssetCategory(ctx contractapi.TransactionContextInterface, id string, categoryID string) error {
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = assertOwnerOrAdmin(ctx, asset.Owner)
+	if err != nil {
+		return err
+	}
+	err = assertMutable(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = assertCategoryExists(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+
+	asset.Category = categoryID
+	err = putAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+
+	err = recordModification(ctx, id, operationUpdate)
+	if err != nil {
+		return err
+	}
+
+	return emitAssetEvent(ctx, assetUpdatedEventName, asset)
+}
+
+// GetAssetsByCategory returns the assets classified under the category categoryID. The assets
+// are looked up in the category index rather than by scanning every asset.
+func (s *SmartContract) GetAssetsByCategory(ctx contractapi.TransactionContextInterface, categoryID string) (*AssetList, error) {
+	if categoryID == "" {
+		return nil, newError(ErrValidation, map[string]string{"field": "categoryID"}, "a category ID is required")
+	}
+	err := assertCategoryExists(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(categoryIndexObjectType, []string{categoryID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := []*Asset{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %v", err)
+		}
+
+		asset, err := readIndexedAsset(ctx, meter, attributes[1])
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Cost = &meter.cost
+
+	return &AssetList{Assets: assets, Metadata: metadata}, nil
+}
+
+// assertCategoryExists returns an error unless categoryID is empty or a registered category
+func assertCategoryExists(ctx contractapi.TransactionContextInterface, categoryID string) error {
+	if categoryID == "" {
+		return nil
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(categoryObjectType, []string{categoryID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	var category Category
+	exists, err := getRecord(ctx, key, &category)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return newError(ErrNotFound, map[string]string{"categoryID": categoryID}, "the category %s does not exist", categoryID)
+	}
+
+	return nil
+}
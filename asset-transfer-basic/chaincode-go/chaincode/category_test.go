@@ -0,0 +1,85 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategories(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	err := assetTransfer.CreateCategory(ws.transactionContext, "social", "Social networks")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.CreateCategory(ws.transactionContext, "", "")
+	requireError(t, err, chaincode.ErrValidation, "a category ID is required")
+	require.NoError(t, assetTransfer.CreateCategory(ws.transactionContext, "social", "Social networks"))
+	require.NoError(t, assetTransfer.CreateCategory(ws.transactionContext, "education", "Schools and universities"))
+	ws.commit()
+	err = assetTransfer.CreateCategory(ws.transactionContext, "social", "")
+	requireError(t, err, chaincode.ErrValidation, "the category social already exists")
+
+	categories, err := assetTransfer.ListCategories(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.Category{
+		{Description: "Schools and universities", ID: "education"},
+		{Description: "Social networks", ID: "social"},
+	}, categories)
+
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.napier.ac.uk", "", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.ed.ac.uk", "", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+
+	err = assetTransfer.SetAssetCategory(ws.transactionContext, "www.napier.ac.uk", "adult")
+	requireError(t, err, chaincode.ErrNotFound, "the category adult does not exist")
+	require.NoError(t, assetTransfer.SetAssetCategory(ws.transactionContext, "www.napier.ac.uk", "education"))
+	require.NoError(t, assetTransfer.SetAssetCategory(ws.transactionContext, "www.ed.ac.uk", "education"))
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", false)
+	err = assetTransfer.SetAssetCategory(ws.transactionContext, "www.bbc.co.uk", "education")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+
+	ids := func(list *chaincode.AssetList) []string {
+		ids := []string{}
+		for _, asset := range list.Assets {
+			ids = append(ids, asset.ID)
+		}
+		return ids
+	}
+	assets, err := assetTransfer.GetAssetsByCategory(ws.transactionContext, "education")
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.ed.ac.uk", "www.napier.ac.uk"}, ids(assets))
+	require.Equal(t, 2, assets.Metadata.Cost.KeysScanned)
+	_, err = assetTransfer.GetAssetsByCategory(ws.transactionContext, "adult")
+	requireError(t, err, chaincode.ErrNotFound, "the category adult does not exist")
+
+	// updates keep the category, which is only changed or cleared explicitly
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.ed.ac.uk", "www.xxx.com", 2, "", 200))
+	require.NoError(t, assetTransfer.SetAssetCategory(ws.transactionContext, "www.napier.ac.uk", ""))
+	ws.commit()
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.ed.ac.uk")
+	require.NoError(t, err)
+	require.Equal(t, "education", asset.Category)
+	assets, err = assetTransfer.GetAssetsByCategory(ws.transactionContext, "education")
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.ed.ac.uk"}, ids(assets))
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err = assetTransfer.CreateAssets(ws.transactionContext, `[{"allowlist":"www.instagram.com","category":"adult"}]`)
+	requireError(t, err, chaincode.ErrNotFound, "the category adult does not exist")
+	ws.rollback()
+	_, err = assetTransfer.CreateAssets(ws.transactionContext, `[{"allowlist":"www.instagram.com","category":"social"}]`)
+	require.NoError(t, err)
+	ws.commit()
+	assets, err = assetTransfer.GetAssetsByCategory(ws.transactionContext, "social")
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.instagram.com"}, ids(assets))
+}
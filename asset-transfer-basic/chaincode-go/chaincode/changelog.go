@@ -0,0 +1,151 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// AssetChange describes how one transaction changed an asset compared to its previous version
+type AssetChange struct {
+	Changes   []*FieldChange `json:"changes"`
+	Deleted   bool           `json:"deleted"`
+	Timestamp time.Time      `json:"timestamp"`
+	TxID      string         `json:"txID"`
+}
+
+// FieldChange describes the change to a single field of an asset. Values are JSON encoded and
+// empty when the field was absent. For list fields, Added and Removed hold the entries that
+// differ between the two versions.
+type FieldChange struct {
+	Added    []string `json:"added,omitempty" metadata:"added,optional"`
+	Field    string   `json:"field"`
+	NewValue string   `json:"newValue"`
+	OldValue string   `json:"oldValue"`
+	Removed  []string `json:"removed,omitempty" metadata:"removed,optional"`
+}
+
+// AssetChangeLog is the response of GetAssetChangeLog
+type AssetChangeLog struct {
+	Changes  []*AssetChange `json:"changes"`
+	Metadata QueryMetadata  `json:"metadata"`
+}
+
+// GetAssetChangeLog returns the field level changes between consecutive versions of the asset, oldest first
+func (s *SmartContract) GetAssetChangeLog(ctx contractapi.TransactionContextInterface, id string) (*AssetChangeLog, error) {
+	resultsIterator, err := assetHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []*AssetChange{}
+	previous := map[string]json.RawMessage{}
+	for resultsIterator.HasNext() {
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.returned(len(modification.Value))
+		if err != nil {
+			return nil, err
+		}
+
+		timestamp, err := ptypes.Timestamp(modification.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		change := &AssetChange{
+			Changes:   []*FieldChange{},
+			Deleted:   modification.IsDelete,
+			Timestamp: timestamp,
+			TxID:      modification.TxId,
+		}
+
+		current := map[string]json.RawMessage{}
+		if !modification.IsDelete {
+			err = json.Unmarshal(modification.Value, &current)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal the version written by %s: %v", modification.TxId, err)
+			}
+			change.Changes = diffFields(previous, current)
+		}
+
+		changes = append(changes, change)
+		previous = current
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata.Cost = &meter.cost
+
+	return &AssetChangeLog{Changes: changes, Metadata: metadata}, nil
+}
+
+// diffFields compares two JSON objects field by field, in field name order
+func diffFields(previous map[string]json.RawMessage, current map[string]json.RawMessage) []*FieldChange {
+	fields := []string{}
+	for field := range previous {
+		fields = append(fields, field)
+	}
+	for field := range current {
+		if _, ok := previous[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := []*FieldChange{}
+	for _, field := range fields {
+		oldValue, newValue := string(previous[field]), string(current[field])
+		if oldValue == newValue {
+			continue
+		}
+
+		change := &FieldChange{Field: field, NewValue: newValue, OldValue: oldValue}
+		var oldEntries, newEntries []json.RawMessage
+		oldErr := json.Unmarshal(previous[field], &oldEntries)
+		newErr := json.Unmarshal(current[field], &newEntries)
+		if (oldErr == nil || oldValue == "") && (newErr == nil || newValue == "") {
+			change.Added = entriesMissingFrom(newEntries, oldEntries)
+			change.Removed = entriesMissingFrom(oldEntries, newEntries)
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// entriesMissingFrom returns the entries of values that do not appear in others
+func entriesMissingFrom(values []json.RawMessage, others []json.RawMessage) []string {
+	seen := map[string]bool{}
+	for _, other := range others {
+		seen[string(other)] = true
+	}
+
+	var missing []string
+	for _, value := range values {
+		if !seen[string(value)] {
+			missing = append(missing, string(value))
+		}
+	}
+
+	return missing
+}
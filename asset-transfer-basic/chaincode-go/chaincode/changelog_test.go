@@ -0,0 +1,54 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAssetChangeLog(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, ws.putState(assetKey("www.bbc.co.uk"), []byte(`{"attribute1":"Org1MSP","blocklist":["www.xxx.com"]}`)))
+	ws.commit()
+	require.NoError(t, ws.putState(assetKey("www.bbc.co.uk"), []byte(`{"attribute1":"Org2MSP","blocklist":["www.xxx.com","www.yyy.com"]}`)))
+	ws.commit()
+	require.NoError(t, ws.delState(assetKey("www.bbc.co.uk")))
+	ws.commit()
+
+	changeLog, err := assetTransfer.GetAssetChangeLog(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	changes := changeLog.Changes
+	require.Equal(t, []*chaincode.AssetChange{
+		{
+			Changes: []*chaincode.FieldChange{
+				{Field: "attribute1", NewValue: `"Org1MSP"`},
+				{Added: []string{`"www.xxx.com"`}, Field: "blocklist", NewValue: `["www.xxx.com"]`},
+			},
+			Timestamp: time.Date(2021, time.March, 1, 9, 0, 0, 0, time.UTC),
+			TxID:      "tx000",
+		},
+		{
+			Changes: []*chaincode.FieldChange{
+				{Field: "attribute1", NewValue: `"Org2MSP"`, OldValue: `"Org1MSP"`},
+				{Added: []string{`"www.yyy.com"`}, Field: "blocklist", NewValue: `["www.xxx.com","www.yyy.com"]`, OldValue: `["www.xxx.com"]`},
+			},
+			Timestamp: time.Date(2021, time.March, 1, 9, 1, 0, 0, time.UTC),
+			TxID:      "tx001",
+		},
+		{
+			Changes:   []*chaincode.FieldChange{},
+			Deleted:   true,
+			Timestamp: time.Date(2021, time.March, 1, 9, 2, 0, 0, time.UTC),
+			TxID:      "tx002",
+		},
+	}, changes)
+
+	changeLog, err = assetTransfer.GetAssetChangeLog(ws.transactionContext, "www.google.com")
+	require.NoError(t, err)
+	changes = changeLog.Changes
+	require.Empty(t, changes)
+}
@@ -0,0 +1,132 @@
+package chaincode
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// maxComposedSteps caps the number of steps of one ComposeTransaction
+const maxComposedSteps = 50
+
+// TransactionStep is one call of a contract function made by ComposeTransaction. Args are
+// passed as they would be as the arguments of a transaction with the name Function.
+type TransactionStep struct {
+	Args     []string `json:"args"`
+	Function string   `json:"function"`
+}
+
+var (
+	composer     shim.Chaincode
+	composerErr  error
+	composerOnce sync.Once
+)
+
+// ComposeTransaction calls the functions of the contract listed in steps one after another
+// within the current transaction and returns what each of them returned. Every step reads the
+// writes of the steps before it, and if any step fails none of them take effect. Each step is
+// authorized on its own, as the transaction it names would be. The transaction emits the event
+// of the last step that sets one.
+func (s *SmartContract) ComposeTransaction(ctx contractapi.TransactionContextInterface, steps []TransactionStep) ([]string, error) {
+	if len(steps) == 0 {
+		return nil, newError(ErrValidation, map[string]string{"field": "steps"}, "a composed transaction requires at least one step")
+	}
+	if len(steps) > maxComposedSteps {
+		return nil, newError(ErrValidation, map[string]string{"field": "steps"}, "a composed transaction may have at most %d steps", maxComposedSteps)
+	}
+
+	composerOnce.Do(func() {
+		composer, composerErr = contractapi.NewChaincode(&SmartContract{})
+	})
+	if composerErr != nil {
+		return nil, fmt.Errorf("failed to create chaincode for composed transaction: %v", composerErr)
+	}
+
+	stub := withWriteCache(ctx.GetStub())
+	parent, _ := ctx.(*TransactionContext)
+	results := []string{}
+	for i, step := range steps {
+		function := step.Function
+		if function == "" || strings.HasPrefix(function, systemContractPrefix) || functionName(function) == "ComposeTransaction" {
+			return nil, newError(ErrValidation, map[string]string{"function": step.Function}, "step %d: %s cannot be composed", i+1, step.Function)
+		}
+
+		args := [][]byte{[]byte(function)}
+		for _, arg := range step.Args {
+			args = append(args, []byte(arg))
+		}
+		response := composer.Invoke(&composedStub{
+			ChaincodeStubInterface: stub,
+			args:                   args,
+			identity:               ctx.GetClientIdentity(),
+			parent:                 parent,
+		})
+		if response.Status >= shim.ERRORTHRESHOLD {
+			if coded := parseError(response.Message); coded != nil {
+				coded.Message = fmt.Sprintf("step %d: %s failed: %s", i+1, function, coded.Message)
+				return nil, coded
+			}
+			return nil, fmt.Errorf("step %d: %s failed: %s", i+1, function, response.Message)
+		}
+		results = append(results, string(response.Payload))
+	}
+
+	return results, nil
+}
+
+// composedStub presents a step of ComposeTransaction as a transaction of its own, on the stub
+// and with the client identity of the transaction composing it
+type composedStub struct {
+	shim.ChaincodeStubInterface
+	args     [][]byte
+	identity cid.ClientIdentity
+	parent   *TransactionContext
+}
+
+// composedStep reports whether the current transaction is a step of ComposeTransaction
+func composedStep(ctx contractapi.TransactionContextInterface) bool {
+	_, ok := ctx.GetStub().(*composedStub)
+
+	return ok
+}
+
+func (s *composedStub) GetArgs() [][]byte {
+	return s.args
+}
+
+func (s *composedStub) GetStringArgs() []string {
+	args := make([]string, 0, len(s.args))
+	for _, arg := range s.args {
+		args = append(args, string(arg))
+	}
+
+	return args
+}
+
+func (s *composedStub) GetFunctionAndParameters() (string, []string) {
+	args := s.GetStringArgs()
+
+	return args[0], args[1:]
+}
+
+// GetTransient returns the transient data of the composing transaction without its
+// idempotency key, which belongs to the composing transaction rather than to its steps
+func (s *composedStub) GetTransient() (map[string][]byte, error) {
+	transient, err := s.ChaincodeStubInterface.GetTransient()
+	if err != nil {
+		return nil, err
+	}
+
+	stepTransient := map[string][]byte{}
+	for name, value := range transient {
+		if name != IdempotencyKeyTransient {
+			stepTransient[name] = value
+		}
+	}
+
+	return stepTransient, nil
+}
@@ -0,0 +1,75 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposeTransaction(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	results, err := assetTransfer.ComposeTransaction(ws.transactionContext, []chaincode.TransactionStep{
+		{Function: "CreateAsset", Args: []string{"www.bbc.co.uk", "www.xxx.com", "1", "Org1MSP", "100"}},
+		{Function: "ReadAsset", Args: []string{"www.bbc.co.uk"}},
+		{Function: "ProposeAgreement", Args: []string{"Org2MSP", `["www.bbc.co.uk"]`, "research use"}},
+		{Function: "ProposeAgreement", Args: []string{"Org3MSP", `["www.bbc.co.uk"]`, "research use"}},
+		{Function: "TransferAsset", Args: []string{"www.bbc.co.uk", "Org2MSP"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	require.Equal(t, "", results[0])
+	var asset chaincode.Asset
+	require.NoError(t, json.Unmarshal([]byte(results[1]), &asset))
+	require.Equal(t, "Org1MSP", asset.Attribute1)
+	require.Equal(t, "user1", asset.Owner)
+	require.NotEqual(t, results[2], results[3])
+	require.Equal(t, "Org1MSP", results[4])
+	ws.commit()
+
+	asset2, err := assetTransfer.ReadAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "Org2MSP", asset2.Attribute1)
+	agreement, err := assetTransfer.ReadAgreement(ws.transactionContext, results[3])
+	require.NoError(t, err)
+	require.Equal(t, "Org3MSP", agreement.ConsumerMSPID)
+}
+
+func TestComposeTransactionFailures(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	_, err := assetTransfer.ComposeTransaction(ws.transactionContext, []chaincode.TransactionStep{})
+	requireError(t, err, chaincode.ErrValidation, "a composed transaction requires at least one step")
+
+	_, err = assetTransfer.ComposeTransaction(ws.transactionContext, []chaincode.TransactionStep{
+		{Function: "ComposeTransaction", Args: []string{"[]"}},
+	})
+	requireError(t, err, chaincode.ErrValidation, "step 1: ComposeTransaction cannot be composed")
+	_, err = assetTransfer.ComposeTransaction(ws.transactionContext, []chaincode.TransactionStep{
+		{Function: "org.hyperledger.fabric:GetMetadata"},
+	})
+	requireError(t, err, chaincode.ErrValidation, "step 1: org.hyperledger.fabric:GetMetadata cannot be composed")
+
+	// every step is authorized as the transaction it names
+	_, err = assetTransfer.ComposeTransaction(ws.transactionContext, []chaincode.TransactionStep{
+		{Function: "CreateAsset", Args: []string{"www.bbc.co.uk", "", "1", "", "100"}},
+		{Function: "DeleteAsset", Args: []string{"www.bbc.co.uk"}},
+	})
+	requireError(t, err, chaincode.ErrUnauthorized, "step 2: DeleteAsset failed: the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	_, err = assetTransfer.ComposeTransaction(ws.transactionContext, []chaincode.TransactionStep{
+		{Function: "CreateAsset", Args: []string{"www.google.com", "", "1", "", "100"}},
+		{Function: "CreateAsset", Args: []string{"www.google.com", "", "1", "", "100"}},
+	})
+	requireError(t, err, chaincode.ErrAssetExists, "step 2: CreateAsset failed: the asset www.google.com already exists")
+
+	_, err = assetTransfer.ComposeTransaction(ws.transactionContext, []chaincode.TransactionStep{
+		{Function: "ReadAsset"},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "step 1: ReadAsset failed: Incorrect number of params")
+}
@@ -0,0 +1,98 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const configObjectType = "config"
+
+// Config holds the contract-wide settings managed by administrators. The Default quota limits
+// are applied to organizations joining through OnboardOrg, and OffboardAction decides what
+// OffboardOrg does with the assets of a departing organization. The MaxQuery limits cap the
+// cost of expensive queries and the MaxArgumentBytes, MaxBatchEntries and MaxValueBytes limits
+// the size of payloads, with defaults applying while they are zero. StateDatabase tells
+// rich queries whether the peers can answer them, as set by SetStateDatabase.
+type Config struct {
+	DefaultMaxAssets   int    `json:"defaultMaxAssets"`
+	DefaultMaxEntries  int    `json:"defaultMaxEntries"`
+	MaintenanceMode    bool   `json:"maintenanceMode"`
+	MaxArgumentBytes   int    `json:"maxArgumentBytes"`
+	MaxBatchEntries    int    `json:"maxBatchEntries"`
+	MaxQueryBytes      int    `json:"maxQueryBytes"`
+	MaxQueryKeys       int    `json:"maxQueryKeys"`
+	MaxValueBytes      int    `json:"maxValueBytes"`
+	OffboardAction     string `json:"offboardAction"`
+	OffboardReassignTo string `json:"offboardReassignTo"`
+	StateDatabase      string `json:"stateDatabase"`
+}
+
+// GetConfig returns the contract-wide settings
+func (s *SmartContract) GetConfig(ctx contractapi.TransactionContextInterface) (*Config, error) {
+	return readConfig(ctx)
+}
+
+// SetMaintenanceMode freezes or unfreezes writes. While maintenance mode is on every
+// transaction other than queries and SetMaintenanceMode itself is rejected.
+func (s *SmartContract) SetMaintenanceMode(ctx contractapi.TransactionContextInterface, on bool) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	config, err := readConfig(ctx)
+	if err != nil {
+		return err
+	}
+	config.MaintenanceMode = on
+
+	return putConfig(ctx, config)
+}
+
+func configKey(ctx contractapi.TransactionContextInterface) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(configObjectType, []string{"contract"})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readConfig returns the stored settings, or the defaults if none have been saved
+func readConfig(ctx contractapi.TransactionContextInterface) (*Config, error) {
+	key, err := configKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	_, err = getRecord(ctx, key, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func putConfig(ctx contractapi.TransactionContextInterface, config *Config) error {
+	key, err := configKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	return putRecord(ctx, key, config)
+}
+
+// assertWritable rejects the named transaction while maintenance mode is on
+func assertWritable(ctx contractapi.TransactionContextInterface, function string) error {
+	config, err := readConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config.MaintenanceMode {
+		return newError(ErrMaintenanceMode, map[string]string{"function": function}, "the contract is in maintenance mode and %s is not permitted", function)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func beforeTransaction(t *testing.T, assetTransfer *chaincode.SmartContract, ws *worldState, function string) error {
+	before, ok := assetTransfer.GetBeforeTransaction().(func(contractapi.TransactionContextInterface) error)
+	require.True(t, ok)
+
+	ws.stub.GetFunctionAndParametersReturns(function, nil)
+	return before(ws.transactionContext)
+}
+
+func TestSetMaintenanceMode(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	err := assetTransfer.SetMaintenanceMode(ws.transactionContext, true)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetMaintenanceMode(ws.transactionContext, true))
+	ws.commit()
+
+	config, err := assetTransfer.GetConfig(ws.transactionContext)
+	require.NoError(t, err)
+	require.True(t, config.MaintenanceMode)
+
+	err = beforeTransaction(t, assetTransfer, ws, "CreateAsset")
+	requireError(t, err, chaincode.ErrMaintenanceMode, "the contract is in maintenance mode and CreateAsset is not permitted")
+
+	err = beforeTransaction(t, assetTransfer, ws, "SmartContract:DeleteAsset")
+	requireError(t, err, chaincode.ErrMaintenanceMode, "the contract is in maintenance mode and DeleteAsset is not permitted")
+
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "ReadAsset"))
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "GetAllAssets"))
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "SetMaintenanceMode"))
+
+	require.NoError(t, assetTransfer.SetMaintenanceMode(ws.transactionContext, false))
+	ws.commit()
+
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+}
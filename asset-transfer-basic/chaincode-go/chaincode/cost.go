@@ -0,0 +1,83 @@
+package chaincode
+
+import (
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Cost caps applied while no limits have been configured
+const (
+	defaultMaxQueryKeys  = 10000
+	defaultMaxQueryBytes = 4 * 1024 * 1024
+)
+
+// QueryCost reports the work done to answer a query, so clients can tune their requests
+type QueryCost struct {
+	BytesReturned int `json:"bytesReturned"`
+	KeysScanned   int `json:"keysScanned"`
+}
+
+// costMeter accumulates the cost of a query and enforces the configured caps
+type costMeter struct {
+	cost     QueryCost
+	maxBytes int
+	maxKeys  int
+}
+
+// SetQueryLimits configures the maximum number of keys a single query may scan and bytes it may return
+func (s *SmartContract) SetQueryLimits(ctx contractapi.TransactionContextInterface, maxKeys int, maxBytes int) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if maxKeys < 1 || maxBytes < 1 {
+		return newError(ErrValidation, nil, "query limits must be positive")
+	}
+
+	config, err := readConfig(ctx)
+	if err != nil {
+		return err
+	}
+	config.MaxQueryBytes = maxBytes
+	config.MaxQueryKeys = maxKeys
+
+	return putConfig(ctx, config)
+}
+
+func newCostMeter(ctx contractapi.TransactionContextInterface) (*costMeter, error) {
+	config, err := readConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	meter := &costMeter{maxBytes: config.MaxQueryBytes, maxKeys: config.MaxQueryKeys}
+	if meter.maxBytes == 0 {
+		meter.maxBytes = defaultMaxQueryBytes
+	}
+	if meter.maxKeys == 0 {
+		meter.maxKeys = defaultMaxQueryKeys
+	}
+
+	return meter, nil
+}
+
+// scanned accounts for one key read from the world state or history
+func (m *costMeter) scanned() error {
+	m.cost.KeysScanned++
+	if m.cost.KeysScanned > m.maxKeys {
+		return newError(ErrQueryTooExpensive, map[string]string{"limit": strconv.Itoa(m.maxKeys)}, "the query scanned more than %d keys; narrow your query", m.maxKeys)
+	}
+
+	return nil
+}
+
+// returned accounts for a record of the given size included in the response
+func (m *costMeter) returned(size int) error {
+	m.cost.BytesReturned += size
+	if m.cost.BytesReturned > m.maxBytes {
+		return newError(ErrQueryTooExpensive, map[string]string{"limit": strconv.Itoa(m.maxBytes)}, "the query returned more than %d bytes; narrow your query", m.maxBytes)
+	}
+
+	return nil
+}
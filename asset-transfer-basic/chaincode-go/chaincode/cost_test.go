@@ -0,0 +1,49 @@
+package chaincode_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCostCaps(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, fmt.Sprintf("www.site%d.com", i), "", 1, "", 100))
+	}
+	ws.commit()
+
+	assets, err := assetTransfer.GetAllAssets(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.QueryCost{BytesReturned: 1275, KeysScanned: 5}, assets.Metadata.Cost)
+
+	err = assetTransfer.SetQueryLimits(ws.transactionContext, 4, 100)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.SetQueryLimits(ws.transactionContext, 0, 100)
+	requireError(t, err, chaincode.ErrValidation, "query limits must be positive")
+
+	require.NoError(t, assetTransfer.SetQueryLimits(ws.transactionContext, 4, 2000))
+	ws.commit()
+
+	_, err = assetTransfer.GetAllAssets(ws.transactionContext)
+	requireError(t, err, chaincode.ErrQueryTooExpensive, "the query scanned more than 4 keys; narrow your query")
+
+	_, err = assetTransfer.GetRandomAssets(ws.transactionContext, 2, "qa")
+	requireError(t, err, chaincode.ErrQueryTooExpensive, "the query scanned more than 4 keys; narrow your query")
+
+	require.NoError(t, assetTransfer.SetQueryLimits(ws.transactionContext, 10, 600))
+	ws.commit()
+
+	_, err = assetTransfer.GetAllAssets(ws.transactionContext)
+	requireError(t, err, chaincode.ErrQueryTooExpensive, "the query returned more than 600 bytes; narrow your query")
+
+	sample, err := assetTransfer.GetRandomAssets(ws.transactionContext, 2, "qa")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.QueryCost{BytesReturned: 510, KeysScanned: 5}, sample.Metadata.Cost)
+}
@@ -0,0 +1,181 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const delegationObjectType = "delegation"
+
+// Kinds of administrative delegation
+const (
+	DelegationOperatorApproval = "operator-approval"
+	DelegationLease            = "lease"
+	DelegationBreakGlass       = "break-glass"
+)
+
+// maxHeartbeatIntervals bounds how long each kind of delegation may go without a heartbeat
+var maxHeartbeatIntervals = map[string]time.Duration{
+	DelegationOperatorApproval: 7 * 24 * time.Hour,
+	DelegationLease:            30 * 24 * time.Hour,
+	DelegationBreakGlass:       time.Hour,
+}
+
+// Delegation grants a client the rights of an administrator for as long as an administrator
+// keeps renewing it with HeartbeatDelegation. A delegation that misses its heartbeat lapses:
+// it is no longer honoured, even though the record stays until it is revoked.
+type Delegation struct {
+	DelegateID        string    `json:"delegateID"`
+	GrantedAt         time.Time `json:"grantedAt"`
+	GrantedBy         string    `json:"grantedBy"`
+	HeartbeatInterval int       `json:"heartbeatInterval"`
+	Kind              string    `json:"kind"`
+	LastHeartbeat     time.Time `json:"lastHeartbeat"`
+}
+
+// lapsed reports whether the delegation missed its heartbeat by the given time
+func (d *Delegation) lapsed(now time.Time) bool {
+	return now.After(d.LastHeartbeat.Add(time.Duration(d.HeartbeatInterval) * time.Second))
+}
+
+// DelegateAdmin grants administrator rights to the client delegateID until the delegation goes
+// heartbeatInterval seconds without a heartbeat. Only administrators holding the certificate
+// attribute may delegate, so delegates cannot extend their rights to others.
+func (s *SmartContract) DelegateAdmin(ctx contractapi.TransactionContextInterface, delegateID string, kind string, heartbeatInterval int) error {
+	err := assertCertifiedAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if delegateID == "" {
+		return newError(ErrValidation, map[string]string{"field": "delegateID"}, "a delegate ID is required")
+	}
+	maxInterval, ok := maxHeartbeatIntervals[kind]
+	if !ok {
+		return newError(ErrValidation, map[string]string{"field": "kind"}, "the delegation kind must be %s, %s or %s", DelegationOperatorApproval, DelegationLease, DelegationBreakGlass)
+	}
+	if heartbeatInterval < 1 || time.Duration(heartbeatInterval)*time.Second > maxInterval {
+		return newError(ErrValidation, map[string]string{"field": "heartbeatInterval"}, "the heartbeat interval of a %s delegation must be between 1 and %d seconds", kind, int(maxInterval.Seconds()))
+	}
+
+	grantedBy, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	return putDelegation(ctx, &Delegation{
+		DelegateID:        delegateID,
+		GrantedAt:         now,
+		GrantedBy:         grantedBy,
+		HeartbeatInterval: heartbeatInterval,
+		Kind:              kind,
+		LastHeartbeat:     now,
+	})
+}
+
+// HeartbeatDelegation renews the delegation held by delegateID. A lapsed delegation cannot be
+// renewed and must be granted again.
+func (s *SmartContract) HeartbeatDelegation(ctx contractapi.TransactionContextInterface, delegateID string) error {
+	err := assertCertifiedAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	delegation, err := s.GetDelegation(ctx, delegateID)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	if delegation.lapsed(now) {
+		return newError(ErrValidation, map[string]string{"delegateID": delegateID}, "the delegation to %s has lapsed and must be granted again", delegateID)
+	}
+	delegation.LastHeartbeat = now
+
+	return putDelegation(ctx, delegation)
+}
+
+// RevokeDelegation removes the delegation held by delegateID
+func (s *SmartContract) RevokeDelegation(ctx contractapi.TransactionContextInterface, delegateID string) error {
+	err := assertCertifiedAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.GetDelegation(ctx, delegateID)
+	if err != nil {
+		return err
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(delegationObjectType, []string{delegateID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return ctx.GetStub().DelState(key)
+}
+
+// GetDelegation returns the delegation held by delegateID, whether or not it has lapsed
+func (s *SmartContract) GetDelegation(ctx contractapi.TransactionContextInterface, delegateID string) (*Delegation, error) {
+	delegation, err := readDelegation(ctx, delegateID)
+	if err != nil {
+		return nil, err
+	}
+	if delegation == nil {
+		return nil, newError(ErrNotFound, map[string]string{"delegateID": delegateID}, "no delegation to %s exists", delegateID)
+	}
+
+	return delegation, nil
+}
+
+// hasActiveDelegation reports whether the submitting client holds a delegation that has not lapsed
+func hasActiveDelegation(ctx contractapi.TransactionContextInterface) (bool, error) {
+	id, err := callerID(ctx)
+	if err != nil {
+		return false, err
+	}
+	delegation, err := readDelegation(ctx, id)
+	if err != nil || delegation == nil {
+		return false, err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return !delegation.lapsed(now), nil
+}
+
+func readDelegation(ctx contractapi.TransactionContextInterface, delegateID string) (*Delegation, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(delegationObjectType, []string{delegateID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var delegation Delegation
+	exists, err := getRecord(ctx, key, &delegation)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	return &delegation, nil
+}
+
+func putDelegation(ctx contractapi.TransactionContextInterface, delegation *Delegation) error {
+	key, err := ctx.GetStub().CreateCompositeKey(delegationObjectType, []string{delegation.DelegateID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, delegation)
+}
@@ -0,0 +1,72 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelegateAdmin(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	err := assetTransfer.DelegateAdmin(ws.transactionContext, "operator1", chaincode.DelegationLease, 3600)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.DelegateAdmin(ws.transactionContext, "operator1", "forever", 3600)
+	requireError(t, err, chaincode.ErrValidation, "the delegation kind must be operator-approval, lease or break-glass")
+	err = assetTransfer.DelegateAdmin(ws.transactionContext, "operator1", chaincode.DelegationBreakGlass, 7200)
+	requireError(t, err, chaincode.ErrValidation, "the heartbeat interval of a break-glass delegation must be between 1 and 3600 seconds")
+
+	granted := ws.now
+	require.NoError(t, assetTransfer.DelegateAdmin(ws.transactionContext, "operator1", chaincode.DelegationBreakGlass, 3600))
+	ws.commit()
+
+	ws.setClient("Org1MSP", "operator1", false)
+	require.NoError(t, assetTransfer.SetMaintenanceMode(ws.transactionContext, true))
+	ws.commit()
+
+	// a delegate cannot pass its rights on
+	err = assetTransfer.DelegateAdmin(ws.transactionContext, "operator2", chaincode.DelegationLease, 3600)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.now = ws.now.Add(50 * time.Minute)
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.HeartbeatDelegation(ws.transactionContext, "operator1"))
+	renewed := ws.now
+	ws.commit()
+
+	delegation, err := assetTransfer.GetDelegation(ws.transactionContext, "operator1")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Delegation{
+		DelegateID:        "operator1",
+		GrantedAt:         granted,
+		GrantedBy:         "admin",
+		HeartbeatInterval: 3600,
+		Kind:              chaincode.DelegationBreakGlass,
+		LastHeartbeat:     renewed,
+	}, delegation)
+
+	ws.now = ws.now.Add(50 * time.Minute)
+	ws.setClient("Org1MSP", "operator1", false)
+	require.NoError(t, assetTransfer.SetMaintenanceMode(ws.transactionContext, false))
+	ws.commit()
+
+	ws.now = ws.now.Add(61 * time.Minute)
+	err = assetTransfer.SetMaintenanceMode(ws.transactionContext, true)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+	ws.rollback()
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.HeartbeatDelegation(ws.transactionContext, "operator1")
+	requireError(t, err, chaincode.ErrValidation, "the delegation to operator1 has lapsed and must be granted again")
+	ws.rollback()
+
+	require.NoError(t, assetTransfer.RevokeDelegation(ws.transactionContext, "operator1"))
+	ws.commit()
+	_, err = assetTransfer.GetDelegation(ws.transactionContext, "operator1")
+	requireError(t, err, chaincode.ErrNotFound, "no delegation to operator1 exists")
+}
@@ -0,0 +1,208 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// maxWebfilterlist bounds the webfilterlist of an asset document
+const maxWebfilterlist = 1000
+
+// Kinds of value a field of an asset document holds
+const (
+	fieldInteger = "integer"
+	fieldString  = "string"
+)
+
+// documentField declares a field of an asset document. A ranged integer must lie between
+// min and max, and a string with a format must be accepted by it. Fields that are not
+// updatable may only be given when the asset is created.
+type documentField struct {
+	name      string
+	kind      string
+	required  bool
+	updatable bool
+	ranged    bool
+	min       int
+	max       int
+	format    func(string) error
+}
+
+// assetDocumentSchema declares the JSON document accepted by CreateAssetFromJSON and
+// UpdateAssetFromJSON, in the order its fields are validated
+var assetDocumentSchema = []documentField{
+	{name: "allowlist", kind: fieldString, required: true, format: validateDomainField},
+	{name: "attribute1", kind: fieldString, updatable: true},
+	{name: "attribute2", kind: fieldInteger, updatable: true},
+	{name: "blocklist", kind: fieldString, required: true, updatable: true, format: validateDomainListField},
+	{name: "id", kind: fieldString, format: validateAssetID},
+	{name: "ruleType", kind: fieldString, format: validateRuleTypeField},
+	{name: "webfilterlist", kind: fieldInteger, required: true, updatable: true, ranged: true, min: 0, max: maxWebfilterlist},
+}
+
+// CreateAssetFromJSON issues a new asset like CreateAsset from the JSON document assetJSON,
+// such as {"allowlist":"www.bbc.co.uk","blocklist":"","webfilterlist":100}. The document is
+// validated against assetDocumentSchema and every invalid field is reported in the error.
+// An asset without an "id" is stored under its allowlist.
+func (s *SmartContract) CreateAssetFromJSON(ctx contractapi.TransactionContextInterface, assetJSON string) error {
+	asset, err := parseAssetDocument(assetJSON, false, "")
+	if err != nil {
+		return err
+	}
+
+	return s.createAsset(ctx, *asset)
+}
+
+// UpdateAssetFromJSON overwrites the asset with given id like UpdateAsset with the values
+// of the JSON document assetJSON, which is validated like that of CreateAssetFromJSON. The
+// allowlist, ID and rule type of an asset cannot be updated and must be left out of the document.
+func (s *SmartContract) UpdateAssetFromJSON(ctx contractapi.TransactionContextInterface, id string, assetJSON string) error {
+	existing, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+	asset, err := parseAssetDocument(assetJSON, true, existing.RuleType)
+	if err != nil {
+		return err
+	}
+
+	return s.UpdateAsset(ctx, id, asset.Blocklist, asset.Attribute2, asset.Attribute1, asset.Webfilterlist)
+}
+
+// parseAssetDocument decodes an asset document and validates it against assetDocumentSchema,
+// returning a validation error naming every invalid field in its message and details. The
+// blocklist is validated in the syntax of ruleType for an update, and of the rule type of the
+// document otherwise.
+func parseAssetDocument(assetJSON string, update bool, ruleType string) (*Asset, error) {
+	var document map[string]json.RawMessage
+	err := json.Unmarshal([]byte(assetJSON), &document)
+	if err != nil || document == nil {
+		return nil, newError(ErrValidation, nil, "invalid asset document: the document must be a JSON object")
+	}
+	if !update {
+		// a rule type that is not a string is reported with the other fields
+		_ = json.Unmarshal(document["ruleType"], &ruleType)
+	}
+
+	var problems []string
+	details := map[string]string{}
+	report := func(field string, problem string) {
+		problems = append(problems, fmt.Sprintf("%s: %s", field, problem))
+		details[field] = problem
+	}
+	known := make([]string, 0, len(assetDocumentSchema))
+	values := map[string]interface{}{}
+	for _, field := range assetDocumentSchema {
+		known = append(known, field.name)
+		raw, present := document[field.name]
+		if update && !field.updatable {
+			if present {
+				report(field.name, "the field cannot be updated")
+			}
+			continue
+		}
+		if !present {
+			if field.required {
+				report(field.name, "the field is required")
+			}
+			continue
+		}
+
+		if field.name == "blocklist" && ruleType == RuleTypeABP {
+			field.format = validateABPRulesField
+		}
+		value, problem := field.validate(raw)
+		if problem != "" {
+			report(field.name, problem)
+			continue
+		}
+		values[field.name] = value
+	}
+	// unknown fields are reported last, sorted, as map iteration order is random
+	var unknown []string
+	for name := range document {
+		if !stringInSlice(name, known) {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		report(name, "the field is not part of the asset schema")
+	}
+	if len(problems) > 0 {
+		return nil, newError(ErrValidation, details, "invalid asset document: %s", strings.Join(problems, "; "))
+	}
+
+	asset := Asset{}
+	asset.Allowlist, _ = values["allowlist"].(string)
+	asset.Attribute1, _ = values["attribute1"].(string)
+	asset.Attribute2, _ = values["attribute2"].(int)
+	asset.Blocklist, _ = values["blocklist"].(string)
+	asset.ID, _ = values["id"].(string)
+	asset.RuleType, _ = values["ruleType"].(string)
+	asset.Webfilterlist, _ = values["webfilterlist"].(int)
+
+	return &asset, nil
+}
+
+// validate decodes the value of the field, returning a description of the problem if it
+// does not meet the declared rules
+func (f documentField) validate(raw json.RawMessage) (interface{}, string) {
+	switch f.kind {
+	case fieldInteger:
+		var value float64
+		err := json.Unmarshal(raw, &value)
+		if err != nil || value != math.Trunc(value) || math.Abs(value) > math.MaxInt32 {
+			return nil, "the value must be an integer"
+		}
+		if f.ranged && (value < float64(f.min) || value > float64(f.max)) {
+			return nil, fmt.Sprintf("the value must be between %d and %d", f.min, f.max)
+		}
+		return int(value), ""
+	default:
+		var value string
+		err := json.Unmarshal(raw, &value)
+		if err != nil {
+			return nil, "the value must be a string"
+		}
+		if f.format != nil {
+			err = f.format(value)
+			if err != nil {
+				return nil, errorMessage(err)
+			}
+		}
+		return value, ""
+	}
+}
+
+// validateDomainField accepts an empty allowlist or a URL or domain name
+func validateDomainField(value string) error {
+	if value == "" {
+		return nil
+	}
+	_, err := normalizeDomain(value)
+	return err
+}
+
+// validateDomainListField accepts a comma separated list of URLs or domain names
+func validateDomainListField(value string) error {
+	_, err := normalizeDomainList(value)
+	return err
+}
+
+// validateABPRulesField accepts a comma separated list of AdBlock Plus rules
+func validateABPRulesField(value string) error {
+	_, err := normalizeABPRules(value)
+	return err
+}
+
+// validateRuleTypeField accepts an empty rule type or a supported one
+func validateRuleTypeField(value string) error {
+	_, err := normalizeBlocklist(value, "")
+	return err
+}
@@ -0,0 +1,64 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAssetFromJSON(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	err := assetTransfer.CreateAssetFromJSON(ws.transactionContext, `["www.bbc.co.uk"]`)
+	requireError(t, err, chaincode.ErrValidation, "invalid asset document: the document must be a JSON object")
+
+	err = assetTransfer.CreateAssetFromJSON(ws.transactionContext, `{"blocklist":"ftp://www.xxx.com","webfilterlist":1001,"attribute2":"5","price":1,"colour":"red"}`)
+	requireError(t, err, chaincode.ErrValidation, `invalid asset document: allowlist: the field is required; attribute2: the value must be an integer; blocklist: invalid domain "ftp://www.xxx.com": the scheme ftp is not supported; webfilterlist: the value must be between 0 and 1000; colour: the field is not part of the asset schema; price: the field is not part of the asset schema`)
+
+	err = assetTransfer.CreateAssetFromJSON(ws.transactionContext, `{"allowlist":"www.bbc.co.uk","blocklist":"","webfilterlist":1.5}`)
+	requireError(t, err, chaincode.ErrValidation, "invalid asset document: webfilterlist: the value must be an integer")
+
+	created := ws.now
+	require.NoError(t, assetTransfer.CreateAssetFromJSON(ws.transactionContext, `{"allowlist":"HTTPS://WWW.BBC.CO.UK/news","attribute2":2,"blocklist":"www.xxx.com","webfilterlist":1000}`))
+	ws.commit()
+
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Asset{Allowlist: "www.bbc.co.uk", Attribute2: 2, Blocklist: "www.xxx.com", CreatedAt: created, ID: "www.bbc.co.uk", Owner: "user1", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: created, Webfilterlist: 1000}, asset)
+
+	err = assetTransfer.CreateAssetFromJSON(ws.transactionContext, `{"allowlist":"www.bbc.co.uk","blocklist":"","webfilterlist":0}`)
+	requireError(t, err, chaincode.ErrAssetExists, "the asset www.bbc.co.uk already exists")
+
+	require.NoError(t, assetTransfer.CreateAssetFromJSON(ws.transactionContext, `{"allowlist":"www.bbc.co.uk","blocklist":"","id":"bbc-staff","webfilterlist":0}`))
+	ws.commit()
+
+	exists, err := assetTransfer.AssetExists(ws.transactionContext, "bbc-staff")
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+func TestUpdateAssetFromJSON(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+
+	err := assetTransfer.UpdateAssetFromJSON(ws.transactionContext, "www.bbc.co.uk", `{"allowlist":"www.google.com","id":"google","webfilterlist":-1}`)
+	requireError(t, err, chaincode.ErrValidation, "invalid asset document: allowlist: the field cannot be updated; blocklist: the field is required; id: the field cannot be updated; webfilterlist: the value must be between 0 and 1000")
+
+	err = assetTransfer.UpdateAssetFromJSON(ws.transactionContext, "www.google.com", `{"blocklist":"","webfilterlist":200}`)
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.google.com does not exist")
+
+	require.NoError(t, assetTransfer.UpdateAssetFromJSON(ws.transactionContext, "www.bbc.co.uk", `{"attribute1":"Org1MSP","blocklist":"www.xxx.com","webfilterlist":200}`))
+	ws.commit()
+
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "Org1MSP", asset.Attribute1)
+	require.Equal(t, 0, asset.Attribute2)
+	require.Equal(t, "www.xxx.com", asset.Blocklist)
+	require.Equal(t, 200, asset.Webfilterlist)
+}
@@ -0,0 +1,286 @@
+package chaincode
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"golang.org/x/net/idna"
+)
+
+// Verdicts of MatchDomain
+const (
+	DomainAllowed  = "allowed"
+	DomainBlocked  = "blocked"
+	DomainUnlisted = "unlisted"
+)
+
+// maxDomainLength bounds the length in bytes of a normalized domain name
+const maxDomainLength = 253
+
+// domainListSeparator delimits the domains of a blocklist
+const domainListSeparator = ","
+
+// wildcardDomain matches every domain when used as a blocklist entry
+const wildcardDomain = "*"
+
+// normalizeDomain returns the canonical form of a list entry: the lower case host name of a
+// URL or domain, without scheme, port, path or trailing dot, with internationalized labels
+// encoded as punycode. A leading "*." matches the subdomains of the rest of the entry.
+func normalizeDomain(entry string) (string, error) {
+	host := strings.TrimSpace(entry)
+	if i := strings.Index(host, "://"); i >= 0 {
+		scheme := strings.ToLower(host[:i])
+		if scheme != "http" && scheme != "https" {
+			return "", newError(ErrValidation, map[string]string{"domain": entry}, "invalid domain %q: the scheme %s is not supported", entry, scheme)
+		}
+		host = host[i+len("://"):]
+	}
+	if i := strings.IndexAny(host, "/?#"); i >= 0 {
+		host = host[:i]
+	}
+	if strings.Contains(host, "@") {
+		return "", newError(ErrValidation, map[string]string{"domain": entry}, "invalid domain %q: user information is not allowed", entry)
+	}
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		port, err := strconv.Atoi(host[i+1:])
+		if err != nil || port < 1 || port > 65535 {
+			return "", newError(ErrValidation, map[string]string{"domain": entry}, "invalid domain %q: malformed port", entry)
+		}
+		host = host[:i]
+	}
+	host = strings.TrimSuffix(host, ".")
+
+	wildcard := strings.HasPrefix(host, wildcardDomain+".")
+	if wildcard {
+		host = strings.TrimPrefix(host, wildcardDomain+".")
+	}
+	if host == "" {
+		return "", newError(ErrValidation, map[string]string{"domain": entry}, "invalid domain %q: the host name is empty", entry)
+	}
+
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", newError(ErrValidation, map[string]string{"domain": entry}, "invalid domain %q: %v", entry, err)
+	}
+	if len(ascii) > maxDomainLength {
+		return "", newError(ErrValidation, map[string]string{"domain": entry}, "invalid domain %q: the host name exceeds %d bytes", entry, maxDomainLength)
+	}
+	for _, label := range strings.Split(ascii, ".") {
+		if label == "" || len(label) > 63 {
+			return "", newError(ErrValidation, map[string]string{"domain": entry}, "invalid domain %q: every label must have between 1 and 63 characters", entry)
+		}
+	}
+
+	if wildcard {
+		return wildcardDomain + "." + ascii, nil
+	}
+
+	return ascii, nil
+}
+
+// normalizeDomainList normalizes every domain of a comma separated blocklist, dropping
+// duplicates. The wildcard "*" blocks every domain.
+func normalizeDomainList(list string) (string, error) {
+	if strings.TrimSpace(list) == "" {
+		return "", nil
+	}
+
+	var domains []string
+	for _, entry := range strings.Split(list, domainListSeparator) {
+		domain := strings.TrimSpace(entry)
+		if domain != wildcardDomain {
+			var err error
+			domain, err = normalizeDomain(entry)
+			if err != nil {
+				return "", err
+			}
+		}
+		if !stringInSlice(domain, domains) {
+			domains = append(domains, domain)
+		}
+	}
+
+	return strings.Join(domains, domainListSeparator), nil
+}
+
+// normalizeAsset normalizes the allowlist and blocklist of an asset about to be stored, the
+// blocklist in the syntax of its rule type. An empty allowlist stays empty.
+func normalizeAsset(asset *Asset) error {
+	if asset.Allowlist != "" {
+		allowlist, err := normalizeDomain(asset.Allowlist)
+		if err != nil {
+			return err
+		}
+		asset.Allowlist = allowlist
+	}
+
+	blocklist, err := normalizeBlocklist(asset.RuleType, asset.Blocklist)
+	if err != nil {
+		return err
+	}
+	asset.Blocklist = blocklist
+
+	return nil
+}
+
+// DomainMatch is the response of MatchDomain. Entry is the most specific entry matching the
+// hostname and List the ID of the asset holding it, both empty when the hostname is unlisted.
+// Rule is the AdBlock Plus rule the entry was derived from, if any.
+type DomainMatch struct {
+	Entry    string        `json:"entry,omitempty" metadata:"entry,optional"`
+	Hostname string        `json:"hostname"`
+	List     string        `json:"list,omitempty" metadata:"list,optional"`
+	Metadata QueryMetadata `json:"metadata"`
+	Rule     string        `json:"rule,omitempty" metadata:"rule,optional"`
+	Verdict  string        `json:"verdict"`
+}
+
+// MatchDomain reports whether hostname is allowed or blocked by the stored lists. The entries
+// that could match the hostname are looked up in the allowlist and blocklist indexes, which
+// cover public blocklists only and assets stored in schema version 4 or later, and the longest
+// matching entry decides: an exact entry beats a wildcard "*." entry for the same domain, which
+// beats the wildcard of a parent domain, and "*" matches last. Between an allow and a block
+// entry of equal length the block entry wins. The rules of AdBlock Plus assets match like the
+// entries they translate to, except that, as in AdBlock Plus, a matching exception rule
+// overrides every matching blocking rule.
+func (s *SmartContract) MatchDomain(ctx contractapi.TransactionContextInterface, hostname string) (*DomainMatch, error) {
+	return matchDomain(ctx, hostname, nil)
+}
+
+// matchDomain matches hostname like MatchDomain, leaving out expired assets. Unless at is nil,
+// it only considers the assets whose schedule is active at that time.
+func matchDomain(ctx contractapi.TransactionContextInterface, hostname string, at *time.Time) (*DomainMatch, error) {
+	normalized, err := normalizeDomain(hostname)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(normalized, wildcardDomain+".") {
+		return nil, newError(ErrValidation, map[string]string{"domain": hostname}, "the hostname %s must not be a wildcard", hostname)
+	}
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	match := DomainMatch{Hostname: normalized, Verdict: DomainUnlisted}
+	best := -1
+	consider := func(entry string, list string, verdict string, rule string) {
+		specificity := matchSpecificity(entry, normalized)
+		if specificity < 0 || specificity < best {
+			return
+		}
+		if specificity == best && (verdict != DomainBlocked || match.Verdict == DomainBlocked) {
+			return
+		}
+		best = specificity
+		match.Entry = entry
+		match.List = list
+		match.Rule = rule
+		match.Verdict = verdict
+	}
+
+	// expiry is judged at the time schedules are evaluated at, or else at the transaction time
+	now := at
+	if now == nil {
+		t, err := txTime(ctx)
+		if err != nil {
+			return nil, err
+		}
+		now = &t
+	}
+	assets := map[string]*Asset{}
+	activeAsset := func(id string) (*Asset, error) {
+		asset, ok := assets[id]
+		if !ok {
+			var err error
+			asset, err = readIndexedAsset(ctx, meter, id)
+			if err != nil {
+				return nil, err
+			}
+			if asset.expiredAt(*now) || (at != nil && !asset.Schedule.activeAt(*at)) {
+				asset = nil
+			}
+			assets[id] = asset
+		}
+		return asset, nil
+	}
+
+	// AdBlock Plus rules are considered once every exception rule is known
+	type ruleMatch struct{ entry, list, verdict string }
+	var ruleMatches []ruleMatch
+	excepted := false
+	for _, entry := range matchCandidates(normalized) {
+		for _, list := range []struct{ objectType, verdict string }{
+			{allowlistIndexObjectType, DomainAllowed},
+			{blocklistIndexObjectType, DomainBlocked},
+		} {
+			ids, err := indexedAssets(ctx, meter, list.objectType, entry)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range ids {
+				asset, err := activeAsset(id)
+				if err != nil {
+					return nil, err
+				}
+				switch {
+				case asset == nil:
+				case asset.RuleType == RuleTypeABP && (list.verdict == DomainBlocked || entry != asset.Allowlist):
+					ruleMatches = append(ruleMatches, ruleMatch{entry, id, list.verdict})
+					excepted = excepted || list.verdict == DomainAllowed
+				default:
+					consider(entry, id, list.verdict, "")
+				}
+			}
+		}
+	}
+	for _, m := range ruleMatches {
+		if excepted && m.verdict == DomainBlocked {
+			continue
+		}
+		rule := abpRule{domain: strings.TrimPrefix(m.entry, wildcardDomain+"."), exception: m.verdict == DomainAllowed}
+		consider(m.entry, m.list, m.verdict, rule.String())
+	}
+
+	match.Metadata, err = queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	match.Metadata.Cost = &meter.cost
+
+	return &match, nil
+}
+
+// matchCandidates returns the entries that match the normalized hostname: the hostname itself,
+// the wildcard of each of its parent domains and the wildcard matching every hostname
+func matchCandidates(hostname string) []string {
+	candidates := []string{hostname}
+	labels := strings.Split(hostname, ".")
+	for i := 1; i < len(labels); i++ {
+		candidates = append(candidates, wildcardDomain+"."+strings.Join(labels[i:], "."))
+	}
+
+	return append(candidates, wildcardDomain)
+}
+
+// matchSpecificity returns how specifically entry matches the normalized hostname, or -1 if it
+// does not match at all. It is the length of the domain suffix the entry matches, and one more
+// for an exact entry, so that longer suffixes take precedence.
+func matchSpecificity(entry string, hostname string) int {
+	switch {
+	case entry == wildcardDomain:
+		return 0
+	case entry == hostname:
+		return len(hostname) + 1
+	case strings.HasPrefix(entry, wildcardDomain+"."):
+		suffix := strings.TrimPrefix(entry, wildcardDomain)
+		if strings.HasSuffix(hostname, suffix) {
+			return len(suffix)
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,114 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAssetNormalizesDomains(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "https://Scholar.Google.com/", "", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "bücher.de", "HTTP://www.XXX.com:8080/index.html, www.xxx.com.,*.ads.example", 1, "", 100))
+	ws.commit()
+
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "scholar.google.com")
+	require.NoError(t, err)
+	require.Equal(t, "scholar.google.com", asset.Allowlist)
+
+	asset, err = assetTransfer.ReadAsset(ws.transactionContext, "xn--bcher-kva.de")
+	require.NoError(t, err)
+	require.Equal(t, "www.xxx.com,*.ads.example", asset.Blocklist)
+
+	err = assetTransfer.CreateAsset(ws.transactionContext, "scholar.google.com.", "", 1, "", 100)
+	requireError(t, err, chaincode.ErrAssetExists, "the asset scholar.google.com already exists")
+
+	err = assetTransfer.UpdateAsset(ws.transactionContext, "xn--bcher-kva.de", "*,WWW.YYY.COM", 1, "", 100)
+	require.NoError(t, err)
+	ws.commit()
+	asset, err = assetTransfer.ReadAsset(ws.transactionContext, "xn--bcher-kva.de")
+	require.NoError(t, err)
+	require.Equal(t, "*,www.yyy.com", asset.Blocklist)
+}
+
+func TestCreateAssetRejectsMalformedDomains(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	for allowlist, message := range map[string]string{
+		"ftp://www.bbc.co.uk":        `invalid domain "ftp://www.bbc.co.uk": the scheme ftp is not supported`,
+		"https://user@www.bbc.co.uk": `invalid domain "https://user@www.bbc.co.uk": user information is not allowed`,
+		"www.bbc.co.uk:http":         `invalid domain "www.bbc.co.uk:http": malformed port`,
+		"https:///index.html":        `invalid domain "https:///index.html": the host name is empty`,
+		"www..bbc.co.uk":             `invalid domain "www..bbc.co.uk": every label must have between 1 and 63 characters`,
+		"www.bbc co.uk":              `invalid domain "www.bbc co.uk": idna: disallowed rune U+0020`,
+	} {
+		err := assetTransfer.CreateAsset(ws.transactionContext, allowlist, "", 1, "", 100)
+		requireError(t, err, chaincode.ErrValidation, message)
+	}
+
+	err := assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com,,www.yyy.com", 1, "", 100)
+	requireError(t, err, chaincode.ErrValidation, `invalid domain "": the host name is empty`)
+	require.Empty(t, ws.writes)
+}
+
+func TestMatchDomain(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.instagram.com", "", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "*.instagram.com,ads.bbc.co.uk", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "news.bbc.co.uk", "*.bbc.co.uk", 1, "", 100))
+	ws.commit()
+
+	for hostname, expected := range map[string][3]string{
+		"WWW.Instagram.com":    {chaincode.DomainAllowed, "www.instagram.com", "www.instagram.com"},
+		"cdn.instagram.com":    {chaincode.DomainBlocked, "*.instagram.com", "www.bbc.co.uk"},
+		"instagram.com":        {chaincode.DomainUnlisted, "", ""},
+		"ads.bbc.co.uk":        {chaincode.DomainBlocked, "ads.bbc.co.uk", "www.bbc.co.uk"},
+		"news.bbc.co.uk":       {chaincode.DomainAllowed, "news.bbc.co.uk", "news.bbc.co.uk"},
+		"sport.bbc.co.uk":      {chaincode.DomainBlocked, "*.bbc.co.uk", "news.bbc.co.uk"},
+		"https://www.bbc.com/": {chaincode.DomainUnlisted, "", ""},
+	} {
+		match, err := assetTransfer.MatchDomain(ws.transactionContext, hostname)
+		require.NoError(t, err, hostname)
+		require.Equal(t, expected, [3]string{match.Verdict, match.Entry, match.List}, hostname)
+	}
+
+	// only the index entries of ads.bbc.co.uk and *.bbc.co.uk are scanned, not every asset
+	match, err := assetTransfer.MatchDomain(ws.transactionContext, "ads.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, 2, match.Metadata.Cost.KeysScanned)
+
+	_, err = assetTransfer.MatchDomain(ws.transactionContext, "*.instagram.com")
+	requireError(t, err, chaincode.ErrValidation, "the hostname *.instagram.com must not be a wildcard")
+	_, err = assetTransfer.MatchDomain(ws.transactionContext, "ftp://www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrValidation, `invalid domain "ftp://www.bbc.co.uk": the scheme ftp is not supported`)
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.com", "*", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "sport.bbc.co.uk", "", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.yahoo.com", "", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "mail.yahoo.com", "www.yahoo.com", 1, "", 100))
+	ws.commit()
+
+	match, err = assetTransfer.MatchDomain(ws.transactionContext, "www.example.com")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.DomainBlocked, match.Verdict)
+	require.Equal(t, "*", match.Entry)
+
+	match, err = assetTransfer.MatchDomain(ws.transactionContext, "www.bbc.com")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.DomainAllowed, match.Verdict)
+
+	match, err = assetTransfer.MatchDomain(ws.transactionContext, "sport.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.DomainAllowed, match.Verdict)
+
+	match, err = assetTransfer.MatchDomain(ws.transactionContext, "www.yahoo.com")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.DomainBlocked, match.Verdict)
+	require.Equal(t, "mail.yahoo.com", match.List)
+}
@@ -0,0 +1,103 @@
+package chaincode
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/statebased"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SetAssetEndorsementPolicy requires a peer of every organization in orgs to endorse any later
+// change to the asset with given id, in place of the chaincode endorsement policy. The policy
+// is validated by the peers when the change is committed, so a change endorsed by too few
+// organizations is rejected as invalid. An empty orgs removes the policy again.
+func (s *SmartContract) SetAssetEndorsementPolicy(ctx contractapi.TransactionContextInterface, id string, orgs []string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := assetKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	exists, err := s.AssetExists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return assetNotFoundError(id)
+	}
+
+	var policy []byte
+	if len(orgs) > 0 {
+		for i, mspID := range orgs {
+			if mspID == "" {
+				return newError(ErrValidation, map[string]string{"field": "orgs"}, "an MSP ID is required")
+			}
+			if stringInSlice(mspID, orgs[:i]) {
+				return newError(ErrValidation, map[string]string{"mspID": mspID}, "the organization %s is listed more than once", mspID)
+			}
+			org, err := readOrganization(ctx, mspID)
+			if err != nil {
+				return err
+			}
+			if org != nil && org.Status == OrgOffboarded {
+				return newError(ErrValidation, map[string]string{"mspID": mspID}, "the organization %s has been offboarded", mspID)
+			}
+		}
+
+		endorsementPolicy, err := statebased.NewStateEP(nil)
+		if err != nil {
+			return err
+		}
+		err = endorsementPolicy.AddOrgs(statebased.RoleTypePeer, orgs...)
+		if err != nil {
+			return fmt.Errorf("failed to add organizations to endorsement policy: %v", err)
+		}
+		policy, err = endorsementPolicy.Policy()
+		if err != nil {
+			return fmt.Errorf("failed to create endorsement policy: %v", err)
+		}
+	}
+
+	err = ctx.GetStub().SetStateValidationParameter(key, policy)
+	if err != nil {
+		return fmt.Errorf("failed to set validation parameter on asset: %v", err)
+	}
+
+	return recordModification(ctx, id, operationUpdate)
+}
+
+// GetAssetEndorsementPolicy returns the organizations that must endorse changes to the asset
+// with given id, in order, or an empty list if the chaincode endorsement policy applies
+func (s *SmartContract) GetAssetEndorsementPolicy(ctx contractapi.TransactionContextInterface, id string) ([]string, error) {
+	key, err := assetKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := s.AssetExists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, assetNotFoundError(id)
+	}
+
+	policy, err := ctx.GetStub().GetStateValidationParameter(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validation parameter of asset: %v", err)
+	}
+	if len(policy) == 0 {
+		return []string{}, nil
+	}
+	endorsementPolicy, err := statebased.NewStateEP(policy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endorsement policy on asset %s: %v", id, err)
+	}
+
+	orgs := endorsementPolicy.ListOrgs()
+	sort.Strings(orgs)
+
+	return orgs, nil
+}
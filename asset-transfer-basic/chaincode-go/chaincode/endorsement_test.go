@@ -0,0 +1,54 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/statebased"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetAssetEndorsementPolicy(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 1, "", 100))
+	ws.commit()
+
+	err := assetTransfer.SetAssetEndorsementPolicy(ws.transactionContext, "www.bbc.co.uk", []string{"Org1MSP", "Org2MSP"})
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.SetAssetEndorsementPolicy(ws.transactionContext, "www.google.com", []string{"Org1MSP"})
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.google.com does not exist")
+	err = assetTransfer.SetAssetEndorsementPolicy(ws.transactionContext, "www.bbc.co.uk", []string{"Org1MSP", "Org1MSP"})
+	requireError(t, err, chaincode.ErrValidation, "the organization Org1MSP is listed more than once")
+	err = assetTransfer.SetAssetEndorsementPolicy(ws.transactionContext, "www.bbc.co.uk", []string{""})
+	requireError(t, err, chaincode.ErrValidation, "an MSP ID is required")
+	require.Equal(t, 0, ws.stub.SetStateValidationParameterCallCount())
+
+	require.NoError(t, assetTransfer.SetAssetEndorsementPolicy(ws.transactionContext, "www.bbc.co.uk", []string{"Org2MSP", "Org1MSP"}))
+	require.Equal(t, 1, ws.stub.SetStateValidationParameterCallCount())
+	key, policy := ws.stub.SetStateValidationParameterArgsForCall(0)
+	require.Equal(t, assetKey("www.bbc.co.uk"), key)
+	endorsementPolicy, err := statebased.NewStateEP(policy)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"Org1MSP", "Org2MSP"}, endorsementPolicy.ListOrgs())
+	ws.commit()
+
+	ws.stub.GetStateValidationParameterReturns(policy, nil)
+	orgs, err := assetTransfer.GetAssetEndorsementPolicy(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Org1MSP", "Org2MSP"}, orgs)
+
+	// an empty list restores the chaincode endorsement policy
+	require.NoError(t, assetTransfer.SetAssetEndorsementPolicy(ws.transactionContext, "www.bbc.co.uk", []string{}))
+	_, policy = ws.stub.SetStateValidationParameterArgsForCall(1)
+	require.Nil(t, policy)
+	ws.commit()
+
+	ws.stub.GetStateValidationParameterReturns(nil, nil)
+	orgs, err = assetTransfer.GetAssetEndorsementPolicy(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Empty(t, orgs)
+}
@@ -0,0 +1,118 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// systemContractPrefix namespaces the functions the contract API adds to every chaincode
+const systemContractPrefix = "org.hyperledger.fabric:"
+
+// WarnCodeNearQuota is the code of the warning raised once an organization has used 90% of a quota limit
+const WarnCodeNearQuota = "NEAR_QUOTA"
+
+// Warning describes a non-fatal condition met while processing a transaction
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Response is the envelope in which the result of every successful transaction is returned,
+// together with the warnings raised while processing it. Result is null for transactions
+// that return no value.
+type Response struct {
+	Result   json.RawMessage `json:"result"`
+	Warnings []Warning       `json:"warnings"`
+}
+
+// WithResponseEnvelope wraps the chaincode so that the payload of every successful transaction
+// of the contract is returned as a Response
+func WithResponseEnvelope(cc shim.Chaincode) shim.Chaincode {
+	return &envelopeChaincode{Chaincode: cc}
+}
+
+type envelopeChaincode struct {
+	shim.Chaincode
+}
+
+// warningStub collects the warnings raised by a transaction alongside the stub it runs on
+type warningStub struct {
+	shim.ChaincodeStubInterface
+	warnings []Warning
+}
+
+// Invoke runs the transaction and wraps its payload in a Response. A retry of a request
+// already processed under the same idempotency key succeeds with the result of the original
+// transaction and an IDEMPOTENT_REPLAY warning naming it.
+func (c *envelopeChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	function, _ := stub.GetFunctionAndParameters()
+	if strings.HasPrefix(function, systemContractPrefix) {
+		return c.Chaincode.Invoke(stub)
+	}
+
+	collector := &warningStub{ChaincodeStubInterface: stub, warnings: []Warning{}}
+	response := c.Chaincode.Invoke(collector)
+	if response.Status >= shim.ERRORTHRESHOLD {
+		replayed := parseError(response.Message)
+		if replayed == nil || replayed.Code != ErrIdempotentReplay.Code || !json.Valid([]byte(replayed.Details["result"])) {
+			return response
+		}
+		warning := Warning{Code: ErrIdempotentReplay.Code, Message: fmt.Sprintf("the request was already processed by transaction %s, whose result is returned", replayed.Details["txID"])}
+		response = shim.Success(nil)
+		return withEnvelope(response, Response{Result: json.RawMessage(replayed.Details["result"]), Warnings: append(collector.warnings, warning)})
+	}
+
+	return withEnvelope(response, Response{Result: resultJSON(functionName(function), response.Payload), Warnings: collector.warnings})
+}
+
+// withEnvelope replaces the payload of response with envelope
+func withEnvelope(response peer.Response, envelope Response) peer.Response {
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to marshal the response envelope: %v", err))
+	}
+	response.Payload = payload
+
+	return response
+}
+
+// resultJSON converts the payload produced by the contract API for function to JSON. Strings
+// are returned unquoted by the contract API and are encoded here, everything else already is JSON.
+func resultJSON(function string, payload []byte) json.RawMessage {
+	if len(payload) == 0 {
+		return json.RawMessage("null")
+	}
+
+	method, ok := reflect.TypeOf(&SmartContract{}).MethodByName(function)
+	if (ok && method.Type.NumOut() > 1 && method.Type.Out(0).Kind() == reflect.String) || !json.Valid(payload) {
+		encoded, _ := json.Marshal(string(payload))
+		return encoded
+	}
+
+	return payload
+}
+
+// addWarning reports a non-fatal condition to the client in the response envelope. It has no
+// effect when the chaincode is not wrapped by WithResponseEnvelope.
+func addWarning(ctx contractapi.TransactionContextInterface, code string, format string, args ...interface{}) {
+	stub := ctx.GetStub()
+	for {
+		switch wrapper := stub.(type) {
+		case *warningStub:
+			wrapper.warnings = append(wrapper.warnings, Warning{Code: code, Message: fmt.Sprintf(format, args...)})
+			return
+		case *writeCache:
+			stub = wrapper.ChaincodeStubInterface
+		case *composedStub:
+			stub = wrapper.ChaincodeStubInterface
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChaincode runs invoke against a transaction context built on the stub it is given,
+// as the contract API does
+type fakeChaincode struct {
+	ws     *worldState
+	invoke func(ctx *chaincode.TransactionContext) peer.Response
+}
+
+func (c *fakeChaincode) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	return shim.Success(nil)
+}
+
+func (c *fakeChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	ctx := &chaincode.TransactionContext{}
+	ctx.SetStub(stub)
+	ctx.SetClientIdentity(c.ws.clientIdentity)
+	return c.invoke(ctx)
+}
+
+func TestWithResponseEnvelope(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetQuota(ws.transactionContext, "Org1MSP", 2, 0))
+	ws.commit()
+
+	cc := chaincode.WithResponseEnvelope(&fakeChaincode{ws: ws, invoke: func(ctx *chaincode.TransactionContext) peer.Response {
+		err := assetTransfer.CreateAsset(ctx, "www.bbc.co.uk", "", 1, "", 100)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(nil)
+	}})
+	ws.stub.GetFunctionAndParametersReturns("CreateAsset", nil)
+	response := cc.Invoke(ws.stub)
+	require.Equal(t, int32(shim.OK), response.Status)
+	require.JSONEq(t, `{"result":null,"warnings":[]}`, string(response.Payload))
+	ws.commit()
+
+	cc = chaincode.WithResponseEnvelope(&fakeChaincode{ws: ws, invoke: func(ctx *chaincode.TransactionContext) peer.Response {
+		err := assetTransfer.CreateAsset(ctx, "www.napier.ac.uk", "", 1, "", 100)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(nil)
+	}})
+	response = cc.Invoke(ws.stub)
+	require.Equal(t, int32(shim.OK), response.Status)
+	require.JSONEq(t, `{"result":null,"warnings":[{"code":"NEAR_QUOTA","message":"organization Org1MSP owns 2 of 2 permitted assets"}]}`, string(response.Payload))
+	ws.commit()
+
+	response = cc.Invoke(ws.stub)
+	require.Equal(t, int32(shim.ERROR), response.Status)
+	require.Empty(t, response.Payload)
+	ws.rollback()
+}
+
+func TestWithResponseEnvelopeEncodesResults(t *testing.T) {
+	ws := newWorldState()
+
+	for _, tc := range []struct {
+		function string
+		payload  string
+		expected string
+	}{
+		{"ReadAsset", `{"allowlist":"www.bbc.co.uk"}`, `{"result":{"allowlist":"www.bbc.co.uk"},"warnings":[]}`},
+		{"AssetExists", `true`, `{"result":true,"warnings":[]}`},
+		{"EraseSubjectData", `true`, `{"result":"true","warnings":[]}`},
+		{"basic:EraseSubjectData", `erasure-tx000-1`, `{"result":"erasure-tx000-1","warnings":[]}`},
+		{"org.hyperledger.fabric:GetMetadata", `{"info":{}}`, `{"info":{}}`},
+	} {
+		payload := []byte(tc.payload)
+		cc := chaincode.WithResponseEnvelope(&fakeChaincode{ws: ws, invoke: func(ctx *chaincode.TransactionContext) peer.Response {
+			return shim.Success(payload)
+		}})
+		ws.stub.GetFunctionAndParametersReturns(tc.function, nil)
+		response := cc.Invoke(ws.stub)
+		require.JSONEq(t, tc.expected, string(response.Payload), tc.function)
+	}
+}
+
+func TestWithResponseEnvelopeReplaysIdempotentRequests(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+	before, ok := assetTransfer.GetBeforeTransaction().(func(contractapi.TransactionContextInterface) error)
+	require.True(t, ok)
+	after, ok := assetTransfer.GetAfterTransaction().(func(contractapi.TransactionContextInterface, interface{}) error)
+	require.True(t, ok)
+
+	ws.setClient("Org1MSP", "admin", true)
+	cc := chaincode.WithResponseEnvelope(&fakeChaincode{ws: ws, invoke: func(ctx *chaincode.TransactionContext) peer.Response {
+		err := before(ctx)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		certificateID, err := assetTransfer.EraseSubjectData(ctx, "user9")
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		err = after(ctx, certificateID)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success([]byte(certificateID))
+	}})
+	ws.stub.GetFunctionAndParametersReturns("EraseSubjectData", []string{"user9"})
+	ws.stub.GetTransientReturns(map[string][]byte{chaincode.IdempotencyKeyTransient: []byte("request1")}, nil)
+	response := cc.Invoke(ws.stub)
+	require.Equal(t, int32(shim.OK), response.Status)
+	require.JSONEq(t, `{"result":"erasure-tx000-1","warnings":[]}`, string(response.Payload))
+	ws.commit()
+
+	// the retry receives the result of the original transaction without erasing again
+	response = cc.Invoke(ws.stub)
+	require.Equal(t, int32(shim.OK), response.Status)
+	require.JSONEq(t, `{"result":"erasure-tx000-1","warnings":[{"code":"IDEMPOTENT_REPLAY","message":"the request was already processed by transaction tx000, whose result is returned"}]}`, string(response.Payload))
+	for key := range ws.writes {
+		require.NotContains(t, key, "erasure")
+	}
+	ws.rollback()
+}
@@ -0,0 +1,247 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const erasureObjectType = "erasure"
+
+// ErasedSubject replaces references to a data subject whose data has been erased
+const ErasedSubject = "ERASED"
+
+const operationErase = "erase"
+
+// ErasureCertificate records that the data held about a subject was erased. It stores a hash
+// of the subject reference rather than the reference itself, so auditors holding the reference
+// can confirm the erasure without the certificate identifying the subject.
+type ErasureCertificate struct {
+	ErasedAt    time.Time `json:"erasedAt"`
+	ID          string    `json:"id"`
+	References  int       `json:"references"`
+	RequestedBy string    `json:"requestedBy"`
+	SubjectHash string    `json:"subjectHash"`
+}
+
+// EraseSubjectData removes the data held about subjectRef, a client ID or asset owner, from the
+// world state. Assets owned by the subject are reassigned to ErasedSubject and their private
+// blocklists deleted, modification, transfer and audit records naming the subject are
+// tombstoned, and the subject's roles, notifications, watches and idempotency records are
+// deleted. Immutable assets are tombstoned as well, since erasure is a legal obligation. Earlier
+// versions remain in the ledger's history, which cannot be rewritten. The ID of the erasure
+// certificate is returned.
+func (s *SmartContract) EraseSubjectData(ctx contractapi.TransactionContextInterface, subjectRef string) (string, error) {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return "", err
+	}
+	if subjectRef == "" || subjectRef == ErasedSubject {
+		return "", newError(ErrValidation, map[string]string{"field": "subjectRef"}, "a subject reference is required")
+	}
+
+	references, err := s.eraseAssetOwner(ctx, subjectRef)
+	if err != nil {
+		return "", err
+	}
+
+	count, err := eraseModificationSubmitter(ctx, subjectRef)
+	if err != nil {
+		return "", err
+	}
+	references += count
+
+	count, err = eraseTransferParty(ctx, subjectRef)
+	if err != nil {
+		return "", err
+	}
+	references += count
+
+	count, err = eraseAuditClient(ctx, subjectRef)
+	if err != nil {
+		return "", err
+	}
+	references += count
+
+	count, err = eraseRoleAssignments(ctx, subjectRef)
+	if err != nil {
+		return "", err
+	}
+	references += count
+
+	count, err = eraseIdempotencyRecords(ctx, subjectRef)
+	if err != nil {
+		return "", err
+	}
+	references += count
+
+	for _, objectType := range []string{inboxObjectType, watchObjectType} {
+		count, err = deleteByPartialCompositeKey(ctx, objectType, []string{subjectRef})
+		if err != nil {
+			return "", err
+		}
+		references += count
+	}
+
+	requestedBy, err := callerID(ctx)
+	if err != nil {
+		return "", err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	subjectHash := sha256.Sum256([]byte(subjectRef))
+	certificate := ErasureCertificate{
+		ErasedAt:    now,
+		ID:          newRecordID(ctx, "erasure"),
+		References:  references,
+		RequestedBy: requestedBy,
+		SubjectHash: hex.EncodeToString(subjectHash[:]),
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(erasureObjectType, []string{certificate.ID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+	err = putRecord(ctx, key, certificate)
+	if err != nil {
+		return "", err
+	}
+
+	return certificate.ID, nil
+}
+
+// ReadErasureCertificate returns the erasure certificate stored in the world state with given id
+func (s *SmartContract) ReadErasureCertificate(ctx contractapi.TransactionContextInterface, certificateID string) (*ErasureCertificate, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(erasureObjectType, []string{certificateID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var certificate ErasureCertificate
+	exists, err := getRecord(ctx, key, &certificate)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, newError(ErrNotFound, map[string]string{"certificateID": certificateID}, "the erasure certificate %s does not exist", certificateID)
+	}
+
+	return &certificate, nil
+}
+
+// eraseAssetOwner reassigns every asset whose owner or attribute1 is subjectRef to
+// ErasedSubject, deleting the private blocklists of the assets the subject owns
+func (s *SmartContract) eraseAssetOwner(ctx contractapi.TransactionContextInterface, subjectRef string) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetObjectType, []string{})
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	count := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		asset, err := unmarshalStoredAsset(ctx, queryResponse.Key, queryResponse.Value)
+		if err != nil {
+			return 0, err
+		}
+		if asset.Attribute1 != subjectRef && asset.Owner != subjectRef {
+			continue
+		}
+
+		if asset.Attribute1 == subjectRef {
+			asset.Attribute1 = ErasedSubject
+		}
+		if asset.Owner == subjectRef {
+			err = deletePrivateBlocklist(ctx, asset)
+			if err != nil {
+				return 0, err
+			}
+			asset.BlocklistCollection = ""
+			asset.BlocklistHash = ""
+			asset.Owner = ErasedSubject
+		}
+		err = putAsset(ctx, asset)
+		if err != nil {
+			return 0, err
+		}
+		err = recordModification(ctx, asset.ID, operationErase)
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// eraseModificationSubmitter tombstones the submitter of every modification record made by subjectRef
+func eraseModificationSubmitter(ctx contractapi.TransactionContextInterface, subjectRef string) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(lastModificationObjectType, []string{})
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	count := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		var modification LastModification
+		err = json.Unmarshal(queryResponse.Value, &modification)
+		if err != nil {
+			return 0, err
+		}
+		if modification.SubmitterID != subjectRef {
+			continue
+		}
+
+		modification.SubmitterID = ErasedSubject
+		err = putRecord(ctx, queryResponse.Key, modification)
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// deleteByPartialCompositeKey deletes every record of objectType whose key starts with attributes
+// and returns the number of records deleted
+func deleteByPartialCompositeKey(ctx contractapi.TransactionContextInterface, objectType string, attributes []string) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	count := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		err = ctx.GetStub().DelState(queryResponse.Key)
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete from world state: %v", err)
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,98 @@
+package chaincode_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEraseSubjectData(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.google.com", "", 1, "", 100))
+	ws.commit()
+	_, err := assetTransfer.TransferAsset(ws.transactionContext, "www.google.com", "Org1MSP")
+	require.NoError(t, err)
+	require.NoError(t, assetTransfer.GrantRole(ws.transactionContext, "Org1MSP", "user9", chaincode.EditorRole))
+	ws.commit()
+
+	ws.setClient("Org1MSP", "user9", false)
+	require.NoError(t, beforeTransaction(t, &assetTransfer, ws, "CreateAsset"))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.stub.GetTransientReturns(map[string][]byte{chaincode.BlocklistTransient: []byte("www.xxx.com")}, nil)
+	require.NoError(t, assetTransfer.CreatePrivateAsset(ws.transactionContext, "www.bbc.com", 1, "", 100))
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.google.com", "", 2, "", 100))
+	require.NoError(t, assetTransfer.RegisterWatch(ws.transactionContext, "www.*"))
+	ws.commit()
+	putNotification(t, ws, chaincode.Notification{ID: "n1", Recipient: "user9", Action: "AcceptTransfer", Subject: "www.bbc.co.uk", CreatedAt: ws.now})
+	putNotification(t, ws, chaincode.Notification{ID: "n2", Recipient: "user1", Action: "AcceptTransfer", Subject: "www.bbc.co.uk", CreatedAt: ws.now})
+
+	_, err = assetTransfer.EraseSubjectData(ws.transactionContext, "user9")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err = assetTransfer.EraseSubjectData(ws.transactionContext, "")
+	requireError(t, err, chaincode.ErrValidation, "a subject reference is required")
+
+	certificateID, err := assetTransfer.EraseSubjectData(ws.transactionContext, "user9")
+	require.NoError(t, err)
+	ws.commit()
+
+	modification, err := assetTransfer.GetLastModification(ws.transactionContext, "www.google.com")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.ErasedSubject, modification.SubmitterID)
+
+	// the assets of the subject are reassigned and their private blocklists deleted
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.ErasedSubject, asset.Owner)
+	asset, err = assetTransfer.ReadAsset(ws.transactionContext, "www.bbc.com")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.ErasedSubject, asset.Owner)
+	require.Empty(t, asset.BlocklistCollection)
+	require.NotContains(t, ws.private["_implicit_org_Org1MSP"], assetKey("www.bbc.com"))
+
+	// the audit log and role registry no longer name the subject
+	auditLog, err := assetTransfer.GetAuditLog(ws.transactionContext, "2021-03-01T09:00:00Z", "2021-03-01T10:00:00Z", 10, "")
+	require.NoError(t, err)
+	require.Len(t, auditLog.Entries, 1)
+	require.Equal(t, chaincode.ErasedSubject, auditLog.Entries[0].ClientID)
+	_, err = assetTransfer.GetRole(ws.transactionContext, "Org1MSP", "user9")
+	requireError(t, err, chaincode.ErrNotFound, "no role is assigned to user9 of Org1MSP")
+
+	ws.setClient("Org1MSP", "user9", false)
+	watchList, err := assetTransfer.GetMyWatches(ws.transactionContext)
+	require.NoError(t, err)
+	watches := watchList.Watches
+	require.Empty(t, watches)
+	pending, err := assetTransfer.GetMyPendingActions(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	notifications := pending.Notifications
+	require.Empty(t, notifications)
+
+	ws.setClient("Org1MSP", "user1", false)
+	pending, err = assetTransfer.GetMyPendingActions(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	notifications = pending.Notifications
+	require.Len(t, notifications, 1)
+
+	certificate, err := assetTransfer.ReadErasureCertificate(ws.transactionContext, certificateID)
+	require.NoError(t, err)
+	subjectHash := sha256.Sum256([]byte("user9"))
+	require.Equal(t, &chaincode.ErasureCertificate{
+		ErasedAt:    ws.now.Add(-1 * time.Minute),
+		ID:          certificateID,
+		References:  7,
+		RequestedBy: "admin",
+		SubjectHash: hex.EncodeToString(subjectHash[:]),
+	}, certificate)
+
+	_, err = assetTransfer.ReadErasureCertificate(ws.transactionContext, "erasure2")
+	requireError(t, err, chaincode.ErrNotFound, "the erasure certificate erasure2 does not exist")
+}
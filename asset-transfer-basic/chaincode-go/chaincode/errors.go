@@ -0,0 +1,82 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Error is an error clients can act on without parsing its message. It reaches clients as a
+// JSON object holding its code, its message and, where they help, details such as the ID of
+// the asset concerned. Errors of other kinds keep a plain text message.
+type Error struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// The kinds of Error returned by the contract. errors.Is matches an Error against its kind.
+var (
+	ErrAlreadyExists     = &Error{Code: "ALREADY_EXISTS"}
+	ErrAssetExists       = &Error{Code: "ASSET_EXISTS"}
+	ErrAssetNotFound     = &Error{Code: "ASSET_NOT_FOUND"}
+	ErrIdempotentReplay  = &Error{Code: "IDEMPOTENT_REPLAY"}
+	ErrMaintenanceMode   = &Error{Code: "MAINTENANCE_MODE"}
+	ErrNotFound          = &Error{Code: "NOT_FOUND"}
+	ErrPayloadTooLarge   = &Error{Code: "PAYLOAD_TOO_LARGE"}
+	ErrQueryTooExpensive = &Error{Code: "QUERY_TOO_EXPENSIVE"}
+	ErrQuotaExceeded     = &Error{Code: "QUOTA_EXCEEDED"}
+	ErrUnauthorized      = &Error{Code: "UNAUTHORIZED"}
+	ErrValidation        = &Error{Code: "VALIDATION"}
+)
+
+// Error returns the JSON form of the error
+func (e *Error) Error() string {
+	errorJSON, err := json.Marshal(e)
+	if err != nil {
+		return e.Message
+	}
+
+	return string(errorJSON)
+}
+
+// Is reports whether target is an Error with the same code
+func (e *Error) Is(target error) bool {
+	kind, ok := target.(*Error)
+
+	return ok && kind.Code == e.Code
+}
+
+// newError returns an Error of the given kind with a formatted message
+func newError(kind *Error, details map[string]string, format string, args ...interface{}) error {
+	return &Error{Code: kind.Code, Message: fmt.Sprintf(format, args...), Details: details}
+}
+
+func assetNotFoundError(id string) error {
+	return newError(ErrAssetNotFound, map[string]string{"id": id}, "the asset %s does not exist", id)
+}
+
+func assetExistsError(id string) error {
+	return newError(ErrAssetExists, map[string]string{"id": id}, "the asset %s already exists", id)
+}
+
+// errorMessage returns the message of err, without the code and details of an Error
+func errorMessage(err error) string {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Message
+	}
+
+	return err.Error()
+}
+
+// parseError returns the Error encoded in message, or nil if message is plain text
+func parseError(message string) *Error {
+	var coded Error
+	err := json.Unmarshal([]byte(message), &coded)
+	if err != nil || coded.Code == "" {
+		return nil
+	}
+
+	return &coded
+}
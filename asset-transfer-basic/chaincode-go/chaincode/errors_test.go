@@ -0,0 +1,44 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+// requireError asserts that err is an Error of the given kind with the given message
+func requireError(t *testing.T, err error, kind *chaincode.Error, message string) {
+	t.Helper()
+	var coded *chaincode.Error
+	require.True(t, errors.As(err, &coded), "expected an error with code %s, got %v", kind.Code, err)
+	require.True(t, errors.Is(err, kind), "expected an error with code %s, got %s", kind.Code, coded.Code)
+	require.Equal(t, message, coded.Message)
+}
+
+func TestErrorJSON(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	_, err := assetTransfer.ReadAsset(ws.transactionContext, "www.google.com")
+	require.EqualError(t, err, `{"code":"ASSET_NOT_FOUND","message":"the asset www.google.com does not exist","details":{"id":"www.google.com"}}`)
+	require.True(t, errors.Is(err, chaincode.ErrAssetNotFound))
+	require.False(t, errors.Is(err, chaincode.ErrAssetExists))
+
+	err = assetTransfer.CreateAssetFromJSON(ws.transactionContext, `{"allowlist":"ftp://www.bbc.co.uk","colour":"red"}`)
+	var coded chaincode.Error
+	require.NoError(t, json.Unmarshal([]byte(err.Error()), &coded))
+	require.Equal(t, chaincode.ErrValidation.Code, coded.Code)
+	require.Equal(t, map[string]string{
+		"allowlist":     `invalid domain "ftp://www.bbc.co.uk": the scheme ftp is not supported`,
+		"blocklist":     "the field is required",
+		"colour":        "the field is not part of the asset schema",
+		"webfilterlist": "the field is required",
+	}, coded.Details)
+
+	ws.setClient("Org1MSP", "user2", false)
+	err = assetTransfer.DeleteAsset(ws.transactionContext, "www.google.com")
+	require.EqualError(t, err, `{"code":"UNAUTHORIZED","message":"the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match"}`)
+}
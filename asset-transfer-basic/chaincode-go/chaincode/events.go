@@ -0,0 +1,50 @@
+package chaincode
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Chaincode events emitted when assets change, so that gateway clients can keep local copies
+// of the filter lists in sync
+const (
+	assetCreatedEventName     = "AssetCreated"
+	assetUpdatedEventName     = "AssetUpdated"
+	assetDeletedEventName     = "AssetDeleted"
+	assetTransferredEventName = "AssetTransferred"
+)
+
+// AssetEvent is the payload of the AssetCreated, AssetUpdated, AssetDeleted and
+// AssetTransferred events. Assets holds every asset the transaction changed as it was written,
+// or as it was before deletion. Watchers lists the client IDs whose watches matched one of the
+// assets, so an event listener can notify only those subscribers.
+type AssetEvent struct {
+	Assets   []*Asset `json:"assets"`
+	TxID     string   `json:"txID"`
+	Watchers []string `json:"watchers"`
+}
+
+// emitAssetEvent emits the asset event name for assets. As a transaction carries a single
+// event, a transaction changing several assets emits one event covering all of them.
+func emitAssetEvent(ctx contractapi.TransactionContextInterface, name string, assets ...*Asset) error {
+	ids := make([]string, 0, len(assets))
+	for _, asset := range assets {
+		ids = append(ids, asset.ID)
+	}
+	watchers, err := assetWatchers(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	eventJSON, err := json.Marshal(AssetEvent{
+		Assets:   assets,
+		TxID:     ctx.GetStub().GetTxID(),
+		Watchers: watchers,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().SetEvent(name, eventJSON)
+}
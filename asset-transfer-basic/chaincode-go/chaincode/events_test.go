@@ -0,0 +1,88 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func lastAssetEvent(t *testing.T, ws *worldState) (string, chaincode.AssetEvent) {
+	name, payload := ws.stub.SetEventArgsForCall(ws.stub.SetEventCallCount() - 1)
+	var event chaincode.AssetEvent
+	require.NoError(t, json.Unmarshal(payload, &event))
+	return name, event
+}
+
+func TestAssetLifecycleEvents(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	created := ws.now
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 1, "Org1MSP", 100))
+	name, event := lastAssetEvent(t, ws)
+	require.Equal(t, "AssetCreated", name)
+	require.Equal(t, chaincode.AssetEvent{
+		Assets:   []*chaincode.Asset{{Allowlist: "www.bbc.co.uk", Attribute1: "Org1MSP", Attribute2: 1, Blocklist: "www.xxx.com", CreatedAt: created, ID: "www.bbc.co.uk", Owner: "user1", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: created, Webfilterlist: 100}},
+		TxID:     ws.txID(),
+		Watchers: []string{},
+	}, event)
+	ws.commit()
+
+	updated := ws.now
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "www.yyy.com", 2, "Org1MSP", 200))
+	name, event = lastAssetEvent(t, ws)
+	require.Equal(t, "AssetUpdated", name)
+	require.Equal(t, []*chaincode.Asset{{Allowlist: "www.bbc.co.uk", Attribute1: "Org1MSP", Attribute2: 2, Blocklist: "www.yyy.com", CreatedAt: created, ID: "www.bbc.co.uk", Owner: "user1", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: updated, Webfilterlist: 200}}, event.Assets)
+	ws.commit()
+
+	transferred := ws.now
+	_, err := assetTransfer.TransferAsset(ws.transactionContext, "www.bbc.co.uk", "Org2MSP")
+	require.NoError(t, err)
+	name, event = lastAssetEvent(t, ws)
+	require.Equal(t, "AssetTransferred", name)
+	require.Equal(t, []*chaincode.Asset{{Allowlist: "www.bbc.co.uk", Attribute1: "Org2MSP", Attribute2: 2, Blocklist: "www.yyy.com", CreatedAt: created, ID: "www.bbc.co.uk", Owner: "Org2MSP", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: transferred, Webfilterlist: 200}}, event.Assets)
+	ws.commit()
+
+	// the deleted asset is reported as it was before the deletion
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.bbc.co.uk"))
+	name, event = lastAssetEvent(t, ws)
+	require.Equal(t, "AssetDeleted", name)
+	require.Equal(t, []*chaincode.Asset{{Allowlist: "www.bbc.co.uk", Attribute1: "Org2MSP", Attribute2: 2, Blocklist: "www.yyy.com", CreatedAt: created, ID: "www.bbc.co.uk", Owner: "Org2MSP", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: transferred, Webfilterlist: 200}}, event.Assets)
+	ws.commit()
+
+	count := ws.stub.SetEventCallCount()
+	err = assetTransfer.DeleteAsset(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.bbc.co.uk does not exist")
+	require.Equal(t, count, ws.stub.SetEventCallCount())
+}
+
+func TestBatchAssetEvents(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	created := ws.now
+	_, err := assetTransfer.CreateAssets(ws.transactionContext, `[{"allowlist":"www.bbc.co.uk"},{"allowlist":"www.google.com"}]`)
+	require.NoError(t, err)
+	require.Equal(t, 1, ws.stub.SetEventCallCount())
+	name, event := lastAssetEvent(t, ws)
+	require.Equal(t, "AssetCreated", name)
+	require.Equal(t, []*chaincode.Asset{{Allowlist: "www.bbc.co.uk", CreatedAt: created, ID: "www.bbc.co.uk", Owner: "admin", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: created}, {Allowlist: "www.google.com", CreatedAt: created, ID: "www.google.com", Owner: "admin", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: created}}, event.Assets)
+	ws.commit()
+
+	updated := ws.now
+	_, err = assetTransfer.UpdateAssetsWhere(ws.transactionContext, "{}", `{"attribute2":3}`, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, ws.stub.SetEventCallCount())
+	name, event = lastAssetEvent(t, ws)
+	require.Equal(t, "AssetUpdated", name)
+	require.Equal(t, []*chaincode.Asset{{Allowlist: "www.bbc.co.uk", Attribute2: 3, CreatedAt: created, ID: "www.bbc.co.uk", Owner: "admin", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: updated}, {Allowlist: "www.google.com", Attribute2: 3, CreatedAt: created, ID: "www.google.com", Owner: "admin", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: updated}}, event.Assets)
+	ws.commit()
+
+	_, err = assetTransfer.UpdateAssetsWhere(ws.transactionContext, `{"attribute1":"nobody"}`, `{"attribute2":4}`, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, ws.stub.SetEventCallCount())
+}
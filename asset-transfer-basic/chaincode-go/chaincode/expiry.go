@@ -0,0 +1,183 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// expiryIndexObjectType indexes the assets that expire by their expiry followed by their ID
+const expiryIndexObjectType = "index~expiry"
+
+// expiryIndexLayout formats expiries in UTC with a fixed width, so that index keys sort by time
+const expiryIndexLayout = "2006-01-02T15:04:05.000000000Z"
+
+// SetAssetExpiry makes the asset with given id expire at the RFC 3339 time expiresAt, or
+// removes its expiry when expiresAt is empty. An expired asset is treated as absent by
+// ReadAsset and MatchDomain until PruneExpiredAssets removes it. Only the owner of the asset
+// or an administrator may set its expiry.
+func (s *SmartContract) SetAssetExpiry(ctx contractapi.TransactionContextInterface, id string, expiresAt string) error {
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = assertOwnerOrAdmin(ctx, asset.Owner)
+	if err != nil {
+		return err
+	}
+	err = assertMutable(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	asset.ExpiresAt, err = parseExpiry(ctx, expiresAt)
+	if err != nil {
+		return err
+	}
+	err = putAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+
+	err = recordModification(ctx, id, operationUpdate)
+	if err != nil {
+		return err
+	}
+
+	return emitAssetEvent(ctx, assetUpdatedEventName, asset)
+}
+
+// parseExpiry parses the RFC 3339 expiry expiresAt, which must be after the transaction time,
+// returning the zero time when expiresAt is empty
+func parseExpiry(ctx contractapi.TransactionContextInterface, expiresAt string) (time.Time, error) {
+	if expiresAt == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return time.Time{}, newError(ErrValidation, map[string]string{"field": "expiresAt"}, "the expiry %s must be in RFC 3339 format", expiresAt)
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !t.After(now) {
+		return time.Time{}, newError(ErrValidation, map[string]string{"field": "expiresAt"}, "the expiry %s must be after the transaction time", expiresAt)
+	}
+
+	return t, nil
+}
+
+// PruneExpiredAssets deletes the assets that expired at or before the RFC 3339 time asOf, like
+// DeleteAsset does one, and returns their IDs. At most as many assets as a batch may hold are
+// pruned per transaction, so callers repeat it until it returns fewer. Immutable assets are
+// left in place. The expiry index is scanned in order of expiry, so assets that have not
+// expired are not read.
+func (s *SmartContract) PruneExpiredAssets(ctx contractapi.TransactionContextInterface, asOf string) ([]string, error) {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cutoff, err := time.Parse(time.RFC3339, asOf)
+	if err != nil {
+		return nil, newError(ErrValidation, map[string]string{"field": "asOf"}, "the time %s must be in RFC 3339 format", asOf)
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cutoff.After(now) {
+		return nil, newError(ErrValidation, map[string]string{"field": "asOf"}, "the time %s must not be after the transaction time", asOf)
+	}
+	limits, err := readPayloadLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	expired, err := expiredAssets(ctx, cutoff, limits.maxBatchEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := []string{}
+	for _, asset := range expired {
+		err = deleteAsset(ctx, asset)
+		if err != nil {
+			return nil, err
+		}
+		pruned = append(pruned, asset.ID)
+	}
+	if len(expired) == 0 {
+		return pruned, nil
+	}
+
+	err = releaseQuota(ctx, pruned...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = emitAssetEvent(ctx, assetDeletedEventName, expired...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pruned, nil
+}
+
+// expiredAssets returns at most limit mutable assets that expired at or before cutoff, in
+// order of expiry
+func expiredAssets(ctx contractapi.TransactionContextInterface, cutoff time.Time, limit int) ([]*Asset, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(expiryIndexObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	bound := cutoff.UTC().Format(expiryIndexLayout)
+	var expired []*Asset
+	for resultsIterator.HasNext() && len(expired) < limit {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %v", err)
+		}
+		if attributes[0] > bound {
+			break
+		}
+
+		immutability, err := readImmutability(ctx, attributes[1])
+		if err != nil {
+			return nil, err
+		}
+		if immutability != nil {
+			continue
+		}
+		asset, err := readStoredAsset(ctx, attributes[1])
+		if err != nil {
+			return nil, err
+		}
+		expired = append(expired, asset)
+	}
+
+	return expired, nil
+}
+
+// expiryIndexKey returns the key indexing an asset that expires
+func expiryIndexKey(ctx contractapi.TransactionContextInterface, asset *Asset) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(expiryIndexObjectType, []string{asset.ExpiresAt.UTC().Format(expiryIndexLayout), asset.ID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return key, nil
+}
+
+// expiredAt reports whether the asset has an expiry at or before t
+func (a *Asset) expiredAt(t time.Time) bool {
+	return !a.ExpiresAt.IsZero() && !t.Before(a.ExpiresAt)
+}
@@ -0,0 +1,117 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetAssetExpiry(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.napier.ac.uk", "www.instagram.com", 1, "", 100))
+	ws.commit()
+
+	err := assetTransfer.SetAssetExpiry(ws.transactionContext, "www.napier.ac.uk", "tomorrow")
+	requireError(t, err, chaincode.ErrValidation, "the expiry tomorrow must be in RFC 3339 format")
+	err = assetTransfer.SetAssetExpiry(ws.transactionContext, "www.napier.ac.uk", "2021-03-01T09:00:00Z")
+	requireError(t, err, chaincode.ErrValidation, "the expiry 2021-03-01T09:00:00Z must be after the transaction time")
+
+	ws.setClient("Org1MSP", "user2", false)
+	err = assetTransfer.SetAssetExpiry(ws.transactionContext, "www.napier.ac.uk", "2021-03-01T12:00:00Z")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.SetAssetExpiry(ws.transactionContext, "www.napier.ac.uk", "2021-03-01T12:00:00+00:00"))
+	ws.commit()
+
+	// updates keep the expiry
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.napier.ac.uk", "www.instagram.com", 2, "", 100))
+	ws.commit()
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.napier.ac.uk")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC), asset.ExpiresAt.UTC())
+	match, err := assetTransfer.MatchDomain(ws.transactionContext, "www.instagram.com")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.DomainBlocked, match.Verdict)
+
+	// once expired the asset is treated as absent, though it is still stored
+	ws.now = time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	_, err = assetTransfer.ReadAsset(ws.transactionContext, "www.napier.ac.uk")
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.napier.ac.uk does not exist")
+	match, err = assetTransfer.MatchDomain(ws.transactionContext, "www.instagram.com")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.DomainUnlisted, match.Verdict)
+	require.Contains(t, ws.state, assetKey("www.napier.ac.uk"))
+
+	// IsBlockedAt judges the expiry at the time it is given
+	blocked, err := assetTransfer.IsBlockedAt(ws.transactionContext, "www.instagram.com", "2021-03-01T11:59:00Z")
+	require.NoError(t, err)
+	require.True(t, blocked)
+	blocked, err = assetTransfer.IsBlockedAt(ws.transactionContext, "www.instagram.com", "2021-03-01T12:00:00Z")
+	require.NoError(t, err)
+	require.False(t, blocked)
+}
+
+func TestPruneExpiredAssets(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	for id, expiresAt := range map[string]string{
+		"www.bbc.co.uk":    "2021-03-01T10:00:00Z",
+		"www.google.com":   "2021-03-01T10:30:00+01:00",
+		"www.napier.ac.uk": "2021-03-02T10:00:00Z",
+		"www.yahoo.com":    "",
+	} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "www.xxx.com", 1, "", 100))
+		ws.commit()
+		if expiresAt != "" {
+			require.NoError(t, assetTransfer.SetAssetExpiry(ws.transactionContext, id, expiresAt))
+			ws.commit()
+		}
+	}
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.instagram.com", "", 1, "", 100))
+	ws.commit()
+	require.NoError(t, assetTransfer.SetAssetExpiry(ws.transactionContext, "www.instagram.com", "2021-03-01T09:30:00Z"))
+	ws.commit()
+	require.NoError(t, assetTransfer.MarkAssetImmutable(ws.transactionContext, "www.instagram.com"))
+	ws.now = time.Date(2021, time.March, 1, 13, 0, 0, 0, time.UTC)
+	ws.commit()
+
+	ws.setClient("Org1MSP", "user1", false)
+	_, err := assetTransfer.PruneExpiredAssets(ws.transactionContext, "2021-03-01T12:00:00Z")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err = assetTransfer.PruneExpiredAssets(ws.transactionContext, "2021-03-02T12:00:00Z")
+	requireError(t, err, chaincode.ErrValidation, "the time 2021-03-02T12:00:00Z must not be after the transaction time")
+	_, err = assetTransfer.PruneExpiredAssets(ws.transactionContext, "noon")
+	requireError(t, err, chaincode.ErrValidation, "the time noon must be in RFC 3339 format")
+
+	pruned, err := assetTransfer.PruneExpiredAssets(ws.transactionContext, "2021-03-01T09:29:59Z")
+	require.NoError(t, err)
+	require.Empty(t, pruned)
+
+	// assets are pruned in order of expiry, and the immutable asset is left in place
+	pruned, err = assetTransfer.PruneExpiredAssets(ws.transactionContext, "2021-03-01T12:00:00Z")
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.google.com", "www.bbc.co.uk"}, pruned)
+	name, event := lastAssetEvent(t, ws)
+	require.Equal(t, "AssetDeleted", name)
+	require.Len(t, event.Assets, 2)
+	ws.commit()
+
+	require.NotContains(t, ws.state, assetKey("www.bbc.co.uk"))
+	require.Contains(t, ws.state, assetKey("www.instagram.com"))
+	assets, err := assetTransfer.GetAssetsByBlocklist(ws.transactionContext, "www.xxx.com")
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 2)
+
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 3, usage.Assets)
+}
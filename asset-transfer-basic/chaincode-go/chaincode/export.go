@@ -0,0 +1,203 @@
+package chaincode
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// The formats ExportFilterList renders a filter policy in
+const (
+	ExportFormatDnsmasq = "dnsmasq"
+	ExportFormatHosts   = "hosts"
+	ExportFormatRPZ     = "rpz"
+	ExportFormatSquid   = "squid"
+)
+
+// rpzTTL is the time to live in seconds of the records of an exported RPZ zone
+const rpzTTL = 300
+
+// ExportFilterList returns the merged lists of the filter policy policyID, as ResolvePolicy
+// resolves them, as text an edge resolver or proxy can load directly:
+//   - "hosts" maps every blocked domain to 0.0.0.0 in the /etc/hosts format. A hosts file
+//     cannot express wildcards or exceptions, so wildcard entries are listed in a comment only.
+//   - "dnsmasq" is a dnsmasq configuration answering 0.0.0.0 for blocked domains and forwarding
+//     allowed ones. dnsmasq matches a domain together with its subdomains, so an exact entry
+//     covers the subdomains too, and "*.example.com" covers example.com itself.
+//   - "rpz" is a DNS response policy zone, rewriting blocked names to NXDOMAIN and passing
+//     allowed names through.
+//   - "squid" is a set of Squid ACLs and http_access rules to include in squid.conf ahead of
+//     its own rules. Each entry gets a rule, ordered as MatchDomain ranks the entries, so the
+//     first rule matching a request is that of the entry MatchDomain would pick. Requests no
+//     entry matches fall through to the rules that follow.
+//
+// As in MatchDomain, an entry both allowed and blocked is blocked.
+func (s *SmartContract) ExportFilterList(ctx contractapi.TransactionContextInterface, policyID string, format string) (string, error) {
+	err := validateExportFormat(format)
+	if err != nil {
+		return "", err
+	}
+
+	policy, err := existingFilterPolicy(ctx, policyID)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := resolveFilterPolicy(ctx, policy)
+	if err != nil {
+		return "", err
+	}
+
+	return renderFilterList(ctx, fmt.Sprintf("filter policy %s (%s)", policy.ID, policy.Name), resolved, format)
+}
+
+// ExportDeviceFilterList returns the lists the device deviceID enforces, as
+// GetEffectivePolicyForDevice resolves them, in a format of ExportFilterList
+func (s *SmartContract) ExportDeviceFilterList(ctx contractapi.TransactionContextInterface, deviceID string, format string) (string, error) {
+	err := validateExportFormat(format)
+	if err != nil {
+		return "", err
+	}
+
+	resolved, err := s.GetEffectivePolicyForDevice(ctx, deviceID)
+	if err != nil {
+		return "", err
+	}
+	subject := fmt.Sprintf("device %s without a filter policy", deviceID)
+	if resolved.ID != "" {
+		subject = fmt.Sprintf("device %s, filter policy %s (%s)", deviceID, resolved.ID, resolved.Name)
+	}
+
+	return renderFilterList(ctx, subject, resolved, format)
+}
+
+func validateExportFormat(format string) error {
+	switch format {
+	case ExportFormatDnsmasq, ExportFormatHosts, ExportFormatRPZ, ExportFormatSquid:
+		return nil
+	}
+
+	return newError(ErrValidation, map[string]string{"field": "format"}, "the format must be %s, %s, %s or %s", ExportFormatDnsmasq, ExportFormatHosts, ExportFormatRPZ, ExportFormatSquid)
+}
+
+// renderFilterList renders the resolved lists in format, under a comment naming subject
+func renderFilterList(ctx contractapi.TransactionContextInterface, subject string, resolved *ResolvedFilterPolicy, format string) (string, error) {
+	var allowlist []string
+	for _, entry := range resolved.Allowlist {
+		if !stringInSlice(entry, resolved.Blocklist) {
+			allowlist = append(allowlist, entry)
+		}
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	comment := "#"
+	if format == ExportFormatRPZ {
+		comment = ";"
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s %s exported at %s\n", comment, subject, now.Format(time.RFC3339))
+
+	switch format {
+	case ExportFormatHosts:
+		var skipped []string
+		for _, entry := range resolved.Blocklist {
+			if strings.HasPrefix(entry, wildcardDomain) {
+				skipped = append(skipped, entry)
+				continue
+			}
+			fmt.Fprintf(&text, "0.0.0.0 %s\n", entry)
+		}
+		if len(skipped) > 0 {
+			fmt.Fprintf(&text, "# not expressible in the hosts format: %s\n", strings.Join(skipped, " "))
+		}
+
+	case ExportFormatDnsmasq:
+		var blocked []string
+		for _, entry := range resolved.Blocklist {
+			domain := dnsmasqDomain(entry)
+			if !stringInSlice(domain, blocked) {
+				blocked = append(blocked, domain)
+				fmt.Fprintf(&text, "address=/%s/0.0.0.0\n", domain)
+			}
+		}
+		for _, entry := range allowlist {
+			domain := dnsmasqDomain(entry)
+			if !stringInSlice(domain, blocked) {
+				fmt.Fprintf(&text, "server=/%s/#\n", domain)
+			}
+		}
+
+	case ExportFormatRPZ:
+		fmt.Fprintf(&text, "$TTL %d\n", rpzTTL)
+		fmt.Fprintf(&text, "@ IN SOA localhost. root.localhost. %d 3600 600 86400 %d\n", now.Unix(), rpzTTL)
+		fmt.Fprintf(&text, "@ IN NS localhost.\n")
+		for _, entry := range resolved.Blocklist {
+			fmt.Fprintf(&text, "%s CNAME .\n", entry)
+		}
+		for _, entry := range allowlist {
+			fmt.Fprintf(&text, "%s CNAME rpz-passthru.\n", entry)
+		}
+
+	case ExportFormatSquid:
+		type rule struct {
+			entry  string
+			access string
+		}
+		var rules []rule
+		for _, entry := range resolved.Blocklist {
+			rules = append(rules, rule{entry: entry, access: "deny"})
+		}
+		for _, entry := range allowlist {
+			rules = append(rules, rule{entry: entry, access: "allow"})
+		}
+		// the more specific entry comes first, and a block entry before an allow entry as specific
+		sort.SliceStable(rules, func(i, j int) bool {
+			si, sj := entrySpecificity(rules[i].entry), entrySpecificity(rules[j].entry)
+			if si != sj {
+				return si > sj
+			}
+			return rules[i].access == "deny" && rules[j].access != "deny"
+		})
+		for i, r := range rules {
+			switch {
+			case r.entry == wildcardDomain:
+				fmt.Fprintf(&text, "http_access %s all\n", r.access)
+				continue
+			case strings.HasPrefix(r.entry, wildcardDomain+"."):
+				suffix := strings.TrimPrefix(r.entry, wildcardDomain)
+				fmt.Fprintf(&text, "acl filter_%d dstdom_regex -i %s$\n", i+1, regexp.QuoteMeta(suffix))
+			default:
+				fmt.Fprintf(&text, "acl filter_%d dstdomain %s\n", i+1, r.entry)
+			}
+			fmt.Fprintf(&text, "http_access %s filter_%d\n", r.access, i+1)
+		}
+	}
+
+	return text.String(), nil
+}
+
+// entrySpecificity returns the specificity matchSpecificity gives entry for any hostname it
+// matches, which does not depend on the hostname
+func entrySpecificity(entry string) int {
+	if strings.HasPrefix(entry, wildcardDomain) {
+		return len(strings.TrimPrefix(entry, wildcardDomain))
+	}
+
+	return len(entry) + 1
+}
+
+// dnsmasqDomain returns the dnsmasq domain pattern matching entry, "#" standing for every domain
+func dnsmasqDomain(entry string) string {
+	if entry == wildcardDomain {
+		return "#"
+	}
+
+	return strings.TrimPrefix(entry, wildcardDomain+".")
+}
@@ -0,0 +1,127 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportFilterList(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "*.doubleclick.net,www.xxx.com", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.xxx.com", "", 1, "", 100))
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.CreatePolicy(ws.transactionContext, "year7", "Year 7 policy"))
+	ws.commit()
+	require.NoError(t, assetTransfer.AddAssetToPolicy(ws.transactionContext, "year7", "www.bbc.co.uk"))
+	ws.commit()
+	require.NoError(t, assetTransfer.AddAssetToPolicy(ws.transactionContext, "year7", "www.xxx.com"))
+	ws.commit()
+
+	ws.setClient("Org1MSP", "user1", false)
+	_, err := assetTransfer.ExportFilterList(ws.transactionContext, "year7", "pac")
+	requireError(t, err, chaincode.ErrValidation, "the format must be dnsmasq, hosts, rpz or squid")
+	_, err = assetTransfer.ExportFilterList(ws.transactionContext, "staff", chaincode.ExportFormatHosts)
+	requireError(t, err, chaincode.ErrNotFound, "the policy staff does not exist")
+
+	text, err := assetTransfer.ExportFilterList(ws.transactionContext, "year7", chaincode.ExportFormatHosts)
+	require.NoError(t, err)
+	require.Equal(t, `# filter policy year7 (Year 7 policy) exported at 2021-03-01T09:03:00Z
+0.0.0.0 www.xxx.com
+# not expressible in the hosts format: *.doubleclick.net
+`, text)
+
+	text, err = assetTransfer.ExportFilterList(ws.transactionContext, "year7", chaincode.ExportFormatDnsmasq)
+	require.NoError(t, err)
+	require.Equal(t, `# filter policy year7 (Year 7 policy) exported at 2021-03-01T09:03:00Z
+address=/doubleclick.net/0.0.0.0
+address=/www.xxx.com/0.0.0.0
+server=/www.bbc.co.uk/#
+`, text)
+
+	text, err = assetTransfer.ExportFilterList(ws.transactionContext, "year7", chaincode.ExportFormatRPZ)
+	require.NoError(t, err)
+	require.Equal(t, `; filter policy year7 (Year 7 policy) exported at 2021-03-01T09:03:00Z
+$TTL 300
+@ IN SOA localhost. root.localhost. 1614589380 3600 600 86400 300
+@ IN NS localhost.
+*.doubleclick.net CNAME .
+www.xxx.com CNAME .
+www.bbc.co.uk CNAME rpz-passthru.
+`, text)
+}
+
+func TestExportFilterListForSquid(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "*", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "news.bbc.co.uk", "*.bbc.co.uk,www.xxx.com", 1, "", 100))
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.CreatePolicy(ws.transactionContext, "year7", "Year 7 policy"))
+	ws.commit()
+	require.NoError(t, assetTransfer.AddAssetToPolicy(ws.transactionContext, "year7", "www.bbc.co.uk"))
+	ws.commit()
+	require.NoError(t, assetTransfer.AddAssetToPolicy(ws.transactionContext, "year7", "news.bbc.co.uk"))
+	ws.commit()
+
+	// the exact allowlist entries beat the wildcard blocking the rest of bbc.co.uk, which beats "*"
+	text, err := assetTransfer.ExportFilterList(ws.transactionContext, "year7", chaincode.ExportFormatSquid)
+	require.NoError(t, err)
+	require.Equal(t, `# filter policy year7 (Year 7 policy) exported at 2021-03-01T09:03:00Z
+acl filter_1 dstdomain news.bbc.co.uk
+http_access allow filter_1
+acl filter_2 dstdomain www.bbc.co.uk
+http_access allow filter_2
+acl filter_3 dstdomain www.xxx.com
+http_access deny filter_3
+acl filter_4 dstdom_regex -i \.bbc\.co\.uk$
+http_access deny filter_4
+http_access deny all
+`, text)
+}
+
+func TestExportDeviceFilterList(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 1, "", 100))
+	require.NoError(t, assetTransfer.RegisterDevice(ws.transactionContext, "laptop1", "staff"))
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.CreatePolicy(ws.transactionContext, "staff", "Staff policy"))
+	ws.commit()
+
+	_, err := assetTransfer.ExportDeviceFilterList(ws.transactionContext, "laptop1", "pac")
+	requireError(t, err, chaincode.ErrValidation, "the format must be dnsmasq, hosts, rpz or squid")
+	_, err = assetTransfer.ExportDeviceFilterList(ws.transactionContext, "laptop9", chaincode.ExportFormatSquid)
+	requireError(t, err, chaincode.ErrNotFound, "the device laptop9 does not exist")
+
+	text, err := assetTransfer.ExportDeviceFilterList(ws.transactionContext, "laptop1", chaincode.ExportFormatDnsmasq)
+	require.NoError(t, err)
+	require.Equal(t, "# device laptop1 without a filter policy exported at 2021-03-01T09:01:00Z\n", text)
+
+	require.NoError(t, assetTransfer.AddAssetToPolicy(ws.transactionContext, "staff", "www.bbc.co.uk"))
+	ws.commit()
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.AssignPolicyToDevice(ws.transactionContext, "laptop1", "staff"))
+	ws.commit()
+
+	text, err = assetTransfer.ExportDeviceFilterList(ws.transactionContext, "laptop1", chaincode.ExportFormatDnsmasq)
+	require.NoError(t, err)
+	require.Equal(t, `# device laptop1, filter policy staff (Staff policy) exported at 2021-03-01T09:03:00Z
+address=/www.xxx.com/0.0.0.0
+server=/www.bbc.co.uk/#
+`, text)
+
+	text, err = assetTransfer.ExportDeviceFilterList(ws.transactionContext, "laptop1", chaincode.ExportFormatSquid)
+	require.NoError(t, err)
+	require.Equal(t, `# device laptop1, filter policy staff (Staff policy) exported at 2021-03-01T09:03:00Z
+acl filter_1 dstdomain www.bbc.co.uk
+http_access allow filter_1
+acl filter_2 dstdomain www.xxx.com
+http_access deny filter_2
+`, text)
+}
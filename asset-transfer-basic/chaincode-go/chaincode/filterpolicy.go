@@ -0,0 +1,206 @@
+package chaincode
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const filterPolicyObjectType = "filterpolicy"
+
+// FilterPolicy bundles assets into a named policy, such as "Year 7 policy" or "Staff policy",
+// that can be assigned as a whole. AssetIDs is kept sorted.
+type FilterPolicy struct {
+	AssetIDs []string `json:"assetIDs"`
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+}
+
+// ResolvedFilterPolicy is the response of ResolvePolicy. Allowlist and Blocklist merge the
+// entries of the assets of the policy, sorted and without duplicates, with the AdBlock Plus
+// rules of an asset translated into the entries they match. Missing lists the assets
+// of the policy that no longer exist, whose entries are left out.
+type ResolvedFilterPolicy struct {
+	Allowlist []string `json:"allowlist"`
+	AssetIDs  []string `json:"assetIDs"`
+	Blocklist []string `json:"blocklist"`
+	ID        string   `json:"id"`
+	Missing   []string `json:"missing"`
+	Name      string   `json:"name"`
+}
+
+// CreatePolicy stores a new filter policy without any assets
+func (s *SmartContract) CreatePolicy(ctx contractapi.TransactionContextInterface, policyID string, name string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if policyID == "" {
+		return newError(ErrValidation, map[string]string{"field": "policyID"}, "a policy ID is required")
+	}
+
+	existing, err := readFilterPolicy(ctx, policyID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return newError(ErrValidation, map[string]string{"policyID": policyID}, "the policy %s already exists", policyID)
+	}
+
+	return putFilterPolicy(ctx, &FilterPolicy{AssetIDs: []string{}, ID: policyID, Name: name})
+}
+
+// AddAssetToPolicy adds the asset with given id to the filter policy policyID
+func (s *SmartContract) AddAssetToPolicy(ctx contractapi.TransactionContextInterface, policyID string, id string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	policy, err := existingFilterPolicy(ctx, policyID)
+	if err != nil {
+		return err
+	}
+	exists, err := s.AssetExists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return assetNotFoundError(id)
+	}
+	if stringInSlice(id, policy.AssetIDs) {
+		return newError(ErrValidation, map[string]string{"id": id, "policyID": policyID}, "the asset %s is already part of the policy %s", id, policyID)
+	}
+
+	policy.AssetIDs = append(policy.AssetIDs, id)
+	sort.Strings(policy.AssetIDs)
+
+	return putFilterPolicy(ctx, policy)
+}
+
+// RemoveAssetFromPolicy removes the asset with given id from the filter policy policyID. The
+// asset need not exist any more, so that policies can be cleaned up after a deletion.
+func (s *SmartContract) RemoveAssetFromPolicy(ctx contractapi.TransactionContextInterface, policyID string, id string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	policy, err := existingFilterPolicy(ctx, policyID)
+	if err != nil {
+		return err
+	}
+
+	remaining := make([]string, 0, len(policy.AssetIDs))
+	for _, assetID := range policy.AssetIDs {
+		if assetID != id {
+			remaining = append(remaining, assetID)
+		}
+	}
+	if len(remaining) == len(policy.AssetIDs) {
+		return newError(ErrNotFound, map[string]string{"id": id, "policyID": policyID}, "the asset %s is not part of the policy %s", id, policyID)
+	}
+	policy.AssetIDs = remaining
+
+	return putFilterPolicy(ctx, policy)
+}
+
+// ResolvePolicy returns the merged allowlist and blocklist of the assets of the filter policy
+// policyID. Private blocklists are not part of the public assets and are left out.
+func (s *SmartContract) ResolvePolicy(ctx contractapi.TransactionContextInterface, policyID string) (*ResolvedFilterPolicy, error) {
+	policy, err := existingFilterPolicy(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveFilterPolicy(ctx, policy)
+}
+
+// resolveFilterPolicy merges the lists of the assets of policy
+func resolveFilterPolicy(ctx contractapi.TransactionContextInterface, policy *FilterPolicy) (*ResolvedFilterPolicy, error) {
+	resolved := ResolvedFilterPolicy{
+		Allowlist: []string{},
+		AssetIDs:  policy.AssetIDs,
+		Blocklist: []string{},
+		ID:        policy.ID,
+		Missing:   []string{},
+		Name:      policy.Name,
+	}
+	for _, id := range policy.AssetIDs {
+		key, err := assetKey(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		assetJSON, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if assetJSON == nil {
+			resolved.Missing = append(resolved.Missing, id)
+			continue
+		}
+		asset, err := unmarshalAsset(id, assetJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		allowed, blocked, err := listEntries(asset)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range allowed {
+			if !stringInSlice(entry, resolved.Allowlist) {
+				resolved.Allowlist = append(resolved.Allowlist, entry)
+			}
+		}
+		for _, entry := range blocked {
+			if !stringInSlice(entry, resolved.Blocklist) {
+				resolved.Blocklist = append(resolved.Blocklist, entry)
+			}
+		}
+	}
+	sort.Strings(resolved.Allowlist)
+	sort.Strings(resolved.Blocklist)
+
+	return &resolved, nil
+}
+
+// existingFilterPolicy returns the filter policy policyID, or an error if it does not exist
+func existingFilterPolicy(ctx contractapi.TransactionContextInterface, policyID string) (*FilterPolicy, error) {
+	policy, err := readFilterPolicy(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, newError(ErrNotFound, map[string]string{"policyID": policyID}, "the policy %s does not exist", policyID)
+	}
+
+	return policy, nil
+}
+
+// readFilterPolicy returns the filter policy policyID, or nil if it does not exist
+func readFilterPolicy(ctx contractapi.TransactionContextInterface, policyID string) (*FilterPolicy, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(filterPolicyObjectType, []string{policyID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var policy FilterPolicy
+	exists, err := getRecord(ctx, key, &policy)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	return &policy, nil
+}
+
+func putFilterPolicy(ctx contractapi.TransactionContextInterface, policy *FilterPolicy) error {
+	key, err := ctx.GetStub().CreateCompositeKey(filterPolicyObjectType, []string{policy.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, policy)
+}
@@ -0,0 +1,75 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterPolicies(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "www.instagram.com,www.xxx.com", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.napier.ac.uk", "www.xxx.com", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "scholar.google.com", "", 1, "", 100))
+	ws.commit()
+
+	err := assetTransfer.CreatePolicy(ws.transactionContext, "year7", "Year 7 policy")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.CreatePolicy(ws.transactionContext, "", "Year 7 policy")
+	requireError(t, err, chaincode.ErrValidation, "a policy ID is required")
+	require.NoError(t, assetTransfer.CreatePolicy(ws.transactionContext, "year7", "Year 7 policy"))
+	ws.commit()
+	err = assetTransfer.CreatePolicy(ws.transactionContext, "year7", "")
+	requireError(t, err, chaincode.ErrValidation, "the policy year7 already exists")
+
+	err = assetTransfer.AddAssetToPolicy(ws.transactionContext, "staff", "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrNotFound, "the policy staff does not exist")
+	err = assetTransfer.AddAssetToPolicy(ws.transactionContext, "year7", "www.unknown.com")
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.unknown.com does not exist")
+	require.NoError(t, assetTransfer.AddAssetToPolicy(ws.transactionContext, "year7", "www.napier.ac.uk"))
+	ws.commit()
+	require.NoError(t, assetTransfer.AddAssetToPolicy(ws.transactionContext, "year7", "www.bbc.co.uk"))
+	ws.commit()
+	require.NoError(t, assetTransfer.AddAssetToPolicy(ws.transactionContext, "year7", "scholar.google.com"))
+	ws.commit()
+	err = assetTransfer.AddAssetToPolicy(ws.transactionContext, "year7", "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrValidation, "the asset www.bbc.co.uk is already part of the policy year7")
+
+	ws.setClient("Org1MSP", "user1", false)
+	resolved, err := assetTransfer.ResolvePolicy(ws.transactionContext, "year7")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.ResolvedFilterPolicy{
+		Allowlist: []string{"scholar.google.com", "www.bbc.co.uk", "www.napier.ac.uk"},
+		AssetIDs:  []string{"scholar.google.com", "www.bbc.co.uk", "www.napier.ac.uk"},
+		Blocklist: []string{"www.instagram.com", "www.xxx.com"},
+		ID:        "year7",
+		Missing:   []string{},
+		Name:      "Year 7 policy",
+	}, resolved)
+
+	// a deleted asset no longer contributes its entries until it is removed from the policy
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.bbc.co.uk"))
+	ws.commit()
+	resolved, err = assetTransfer.ResolvePolicy(ws.transactionContext, "year7")
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.xxx.com"}, resolved.Blocklist)
+	require.Equal(t, []string{"www.bbc.co.uk"}, resolved.Missing)
+
+	require.NoError(t, assetTransfer.RemoveAssetFromPolicy(ws.transactionContext, "year7", "www.bbc.co.uk"))
+	ws.commit()
+	err = assetTransfer.RemoveAssetFromPolicy(ws.transactionContext, "year7", "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrNotFound, "the asset www.bbc.co.uk is not part of the policy year7")
+	resolved, err = assetTransfer.ResolvePolicy(ws.transactionContext, "year7")
+	require.NoError(t, err)
+	require.Equal(t, []string{"scholar.google.com", "www.napier.ac.uk"}, resolved.AssetIDs)
+	require.Empty(t, resolved.Missing)
+
+	_, err = assetTransfer.ResolvePolicy(ws.transactionContext, "staff")
+	requireError(t, err, chaincode.ErrNotFound, "the policy staff does not exist")
+}
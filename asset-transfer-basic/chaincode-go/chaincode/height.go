@@ -0,0 +1,80 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const ledgerHeightObjectType = "ledgerheight"
+
+// ledgerHeightShards spreads the ledger height counter over several keys. Every submitted
+// transaction increments one shard, chosen by its transaction ID, so concurrent transactions
+// in the same block rarely conflict on the counter.
+const ledgerHeightShards = 64
+
+// QueryMetadata describes the ledger state a multi-record query was answered from.
+// LedgerHeight counts the submitted transactions committed so far. It never decreases, so
+// clients can use it to correlate pages and cached results and to detect stale data.
+// Queries that scan many keys also report their Cost.
+type QueryMetadata struct {
+	Cost         *QueryCost `json:"cost,omitempty" metadata:"cost,optional"`
+	LedgerHeight uint64     `json:"ledgerHeight"`
+}
+
+type ledgerHeightShard struct {
+	Count uint64 `json:"count"`
+}
+
+// advanceLedgerHeight counts the current transaction towards the ledger height
+func advanceLedgerHeight(ctx contractapi.TransactionContextInterface) error {
+	key, err := ctx.GetStub().CreateCompositeKey(ledgerHeightObjectType, []string{txShard(ctx, ledgerHeightShards)})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var counter ledgerHeightShard
+	_, err = getRecord(ctx, key, &counter)
+	if err != nil {
+		return err
+	}
+	counter.Count++
+
+	return putRecord(ctx, key, counter)
+}
+
+// txShard picks one of shards counter shards for the current transaction by hashing its ID
+func txShard(ctx contractapi.TransactionContextInterface, shards uint32) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(ctx.GetStub().GetTxID()))
+
+	return fmt.Sprintf("%02d", hash.Sum32()%shards)
+}
+
+// queryMetadata returns the metadata included in multi-record query responses
+func queryMetadata(ctx contractapi.TransactionContextInterface) (QueryMetadata, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(ledgerHeightObjectType, []string{})
+	if err != nil {
+		return QueryMetadata{}, err
+	}
+	defer resultsIterator.Close()
+
+	var metadata QueryMetadata
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return QueryMetadata{}, err
+		}
+
+		var counter ledgerHeightShard
+		err = json.Unmarshal(queryResponse.Value, &counter)
+		if err != nil {
+			return QueryMetadata{}, err
+		}
+		metadata.LedgerHeight += counter.Count
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,35 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerHeightInQueryResponses(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	assets, err := assetTransfer.GetAllAssets(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), assets.Metadata.LedgerHeight)
+
+	for _, id := range []string{"www.bbc.co.uk", "www.google.com", "www.napier.ac.uk"} {
+		require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "", 1, "", 100))
+		ws.commit()
+	}
+
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "GetAllAssets"))
+	require.Empty(t, ws.writes)
+
+	assets, err = assetTransfer.GetAllAssets(ws.transactionContext)
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 3)
+	require.Equal(t, uint64(3), assets.Metadata.LedgerHeight)
+
+	watches, err := assetTransfer.GetMyWatches(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), watches.Metadata.LedgerHeight)
+}
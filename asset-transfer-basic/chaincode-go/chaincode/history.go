@@ -0,0 +1,147 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// AssetModification is one version of an asset as written by a transaction. Value is null
+// when the transaction deleted the asset.
+type AssetModification struct {
+	IsDelete  bool      `json:"isDelete"`
+	Timestamp time.Time `json:"timestamp"`
+	TxID      string    `json:"txID"`
+	Value     *Asset    `json:"value" metadata:"value,optional"`
+}
+
+// AssetHistory is the response of GetAssetHistory
+type AssetHistory struct {
+	History  []*AssetModification `json:"history"`
+	Metadata QueryMetadata        `json:"metadata"`
+}
+
+// GetAssetHistory returns every modification of the asset stored under id, in the order
+// the peer's history database returns them
+func (s *SmartContract) GetAssetHistory(ctx contractapi.TransactionContextInterface, id string) (*AssetHistory, error) {
+	resultsIterator, err := assetHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	history := []*AssetModification{}
+	for resultsIterator.HasNext() {
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.returned(len(modification.Value))
+		if err != nil {
+			return nil, err
+		}
+
+		timestamp, err := ptypes.Timestamp(modification.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &AssetModification{
+			IsDelete:  modification.IsDelete,
+			Timestamp: timestamp,
+			TxID:      modification.TxId,
+		}
+		if !modification.IsDelete {
+			entry.Value, err = unmarshalAsset(id, modification.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal the version written by %s: %v", modification.TxId, err)
+			}
+		}
+		history = append(history, entry)
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Cost = &meter.cost
+
+	return &AssetHistory{History: history, Metadata: metadata}, nil
+}
+
+// assetHistory returns the modifications of the asset with the given ID. Assets stored before
+// they were keyed by composite key keep their earlier versions in the history of the flat
+// key, which are returned ahead of those of the composite key.
+func assetHistory(ctx contractapi.TransactionContextInterface, id string) (shim.HistoryQueryIteratorInterface, error) {
+	key, err := assetKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// the peer never stored an asset under an empty flat key
+	keys := []string{key}
+	if id != "" {
+		keys = []string{id, key}
+	}
+
+	chained := &chainedHistoryIterator{}
+	for _, key := range keys {
+		resultsIterator, err := ctx.GetStub().GetHistoryForKey(key)
+		if err != nil {
+			chained.Close()
+			return nil, fmt.Errorf("failed to read history: %v", err)
+		}
+		chained.iterators = append(chained.iterators, resultsIterator)
+	}
+
+	return chained, nil
+}
+
+// chainedHistoryIterator iterates over the results of several history queries in turn
+type chainedHistoryIterator struct {
+	iterators []shim.HistoryQueryIteratorInterface
+	current   int
+}
+
+func (it *chainedHistoryIterator) HasNext() bool {
+	for ; it.current < len(it.iterators); it.current++ {
+		if it.iterators[it.current].HasNext() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (it *chainedHistoryIterator) Next() (*queryresult.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+
+	return it.iterators[it.current].Next()
+}
+
+func (it *chainedHistoryIterator) Close() error {
+	var closeErr error
+	for _, resultsIterator := range it.iterators {
+		err := resultsIterator.Close()
+		if err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+
+	return closeErr
+}
@@ -0,0 +1,40 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAssetHistory(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 1, "", 100))
+	ws.commit()
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.bbc.co.uk"))
+	ws.commit()
+
+	history, err := assetTransfer.GetAssetHistory(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Len(t, history.History, 3)
+	require.Equal(t, "tx000", history.History[0].TxID)
+	require.Equal(t, ws.now.Add(-3*time.Minute), history.History[0].Timestamp)
+	require.NotNil(t, history.History[1].Value)
+	require.False(t, history.History[1].IsDelete)
+	require.Equal(t, &chaincode.AssetModification{
+		IsDelete:  true,
+		Timestamp: ws.now.Add(-time.Minute),
+		TxID:      "tx002",
+	}, history.History[2])
+	require.Equal(t, 3, history.Metadata.Cost.KeysScanned)
+
+	history, err = assetTransfer.GetAssetHistory(ws.transactionContext, "www.google.com")
+	require.NoError(t, err)
+	require.Empty(t, history.History)
+}
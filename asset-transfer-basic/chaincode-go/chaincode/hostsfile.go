@@ -0,0 +1,182 @@
+package chaincode
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// The list types ImportHostsFile adds the domains of a hosts file to
+const (
+	ListTypeAllowlist = "allowlist"
+	ListTypeBlocklist = "blocklist"
+)
+
+// hostsFileReservedNames are the host names a hosts file maps to the local machine rather
+// than lists, which are never imported
+var hostsFileReservedNames = []string{
+	"0.0.0.0",
+	"broadcasthost",
+	"ip6-allhosts",
+	"ip6-allnodes",
+	"ip6-allrouters",
+	"ip6-localhost",
+	"ip6-localnet",
+	"ip6-loopback",
+	"ip6-mcastprefix",
+	"local",
+	"localhost",
+	"localhost.localdomain",
+}
+
+// ImportHostsFile creates an asset for every domain of content not yet on the list of the
+// given type, and returns the IDs of the new assets. content is in the /etc/hosts format, where
+// each line maps an address such as 0.0.0.0 to one or more host names, or in the Pi-hole format
+// of one domain per line; "#" starts a comment. An allowlist asset is stored under its domain,
+// like one issued by CreateAsset. Domains already on the list or repeated in content are
+// skipped, but the import is rejected as a whole if any line holds an invalid domain.
+func (s *SmartContract) ImportHostsFile(ctx contractapi.TransactionContextInterface, content string, listType string) ([]string, error) {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexObjectType string
+	switch listType {
+	case ListTypeAllowlist:
+		indexObjectType = allowlistIndexObjectType
+	case ListTypeBlocklist:
+		indexObjectType = blocklistIndexObjectType
+	default:
+		return nil, newError(ErrValidation, map[string]string{"field": "listType"}, "the list type must be %s or %s", ListTypeAllowlist, ListTypeBlocklist)
+	}
+
+	domains, err := parseHostsFile(content)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []*Asset
+	for _, domain := range domains {
+		listed, err := domainListed(ctx, indexObjectType, domain)
+		if err != nil {
+			return nil, err
+		}
+		if listed {
+			continue
+		}
+
+		asset := &Asset{Owner: owner}
+		if listType == ListTypeAllowlist {
+			exists, err := s.AssetExists(ctx, domain)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				continue
+			}
+			asset.Allowlist = domain
+		} else {
+			asset.Blocklist = domain
+		}
+		err = prepareNewAsset(ctx, asset)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+
+	ids := make([]string, 0, len(assets))
+	if len(assets) == 0 {
+		return ids, nil
+	}
+	limits, err := readPayloadLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = limits.assertBatchSize(len(assets))
+	if err != nil {
+		return nil, err
+	}
+
+	err = chargeQuota(ctx, assets...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = meterOperation(ctx, meterCreate, len(assets))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, asset := range assets {
+		err = putAsset(ctx, asset)
+		if err != nil {
+			return nil, err
+		}
+
+		err = recordModification(ctx, asset.ID, operationCreate)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, asset.ID)
+	}
+
+	err = emitAssetEvent(ctx, assetCreatedEventName, assets...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// parseHostsFile returns the normalized domains of a hosts file in the order they first appear
+func parseHostsFile(content string) ([]string, error) {
+	var domains []string
+	for i, line := range strings.Split(content, "\n") {
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if net.ParseIP(fields[0]) != nil {
+			fields = fields[1:]
+		}
+
+		for _, host := range fields {
+			if stringInSlice(strings.ToLower(host), hostsFileReservedNames) {
+				continue
+			}
+			domain, err := normalizeDomain(host)
+			if err != nil {
+				return nil, newError(ErrValidation, map[string]string{"domain": host, "line": strconv.Itoa(i + 1)}, "line %d: %s", i+1, errorMessage(err))
+			}
+			if !stringInSlice(domain, domains) {
+				domains = append(domains, domain)
+			}
+		}
+	}
+
+	return domains, nil
+}
+
+// domainListed reports whether the index of objectType holds exactly entry
+func domainListed(ctx contractapi.TransactionContextInterface, objectType string, entry string) (bool, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, append(domainLabels(entry), ""))
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	return resultsIterator.HasNext(), nil
+}
@@ -0,0 +1,61 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+const hostsFile = `# Pi-hole blocklist
+127.0.0.1 localhost
+::1 localhost ip6-localhost ip6-loopback
+0.0.0.0 0.0.0.0
+0.0.0.0 Ads.Example.com tracker.example.com # trackers
+0.0.0.0 ads.example.com
+www.xxx.com
+`
+
+func TestImportHostsFile(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	_, err := assetTransfer.ImportHostsFile(ws.transactionContext, hostsFile, chaincode.ListTypeBlocklist)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err = assetTransfer.ImportHostsFile(ws.transactionContext, hostsFile, "denylist")
+	requireError(t, err, chaincode.ErrValidation, "the list type must be allowlist or blocklist")
+	_, err = assetTransfer.ImportHostsFile(ws.transactionContext, "0.0.0.0 ads.example.com\n0.0.0.0 ads..example.com\n", chaincode.ListTypeBlocklist)
+	requireError(t, err, chaincode.ErrValidation, `line 2: invalid domain "ads..example.com": every label must have between 1 and 63 characters`)
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "", "www.xxx.com", 1, "", 100))
+	ws.commit()
+
+	ids, err := assetTransfer.ImportHostsFile(ws.transactionContext, hostsFile, chaincode.ListTypeBlocklist)
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+	ws.commit()
+
+	assets, err := assetTransfer.GetAssetsByBlocklist(ws.transactionContext, "ads.example.com")
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 1)
+	require.Equal(t, ids[0], assets.Assets[0].ID)
+	assets, err = assetTransfer.GetAssetsByBlocklist(ws.transactionContext, "www.xxx.com")
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 1)
+
+	ids, err = assetTransfer.ImportHostsFile(ws.transactionContext, hostsFile, chaincode.ListTypeBlocklist)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	ids, err = assetTransfer.ImportHostsFile(ws.transactionContext, hostsFile, chaincode.ListTypeAllowlist)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ads.example.com", "tracker.example.com", "www.xxx.com"}, ids)
+	ws.commit()
+
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "tracker.example.com")
+	require.NoError(t, err)
+	require.Equal(t, "tracker.example.com", asset.Allowlist)
+	require.Equal(t, "admin", asset.Owner)
+}
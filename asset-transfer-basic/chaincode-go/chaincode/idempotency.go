@@ -0,0 +1,260 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	idempotencyObjectType = "idempotency"
+	// idempotencyExpiryObjectType indexes the idempotency records by their expiry, keyed by
+	// the expiry in auditTimeFormat, the client ID and the idempotency key
+	idempotencyExpiryObjectType = "idempotency~expiry"
+)
+
+// IdempotencyKeyTransient is the transient data field in which clients may pass an idempotency
+// key with a submitted transaction
+const IdempotencyKeyTransient = "idempotencyKey"
+
+// idempotencyTTL is how long a processed idempotency key is remembered. Expired records are
+// removed by the JobSweepIdempotencyKeys job.
+const idempotencyTTL = 24 * time.Hour
+
+// processedRequest records the outcome of a transaction submitted with an idempotency key.
+// Keys are scoped to the submitting client, so clients cannot collide with each other. Result
+// holds the JSON result the transaction returned in its response envelope.
+type processedRequest struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+	Function  string    `json:"function"`
+	Result    string    `json:"result"`
+	TxID      string    `json:"txID"`
+}
+
+// GetAfterTransaction returns the function called after every successful transaction of the contract
+func (s *SmartContract) GetAfterTransaction() interface{} {
+	return afterTransaction
+}
+
+// afterTransaction remembers the result of a submitted transaction that carried an idempotency key
+func afterTransaction(ctx contractapi.TransactionContextInterface, result interface{}) error {
+	function := transactionName(ctx)
+	if stringInSlice(function, evaluateTransactions) {
+		return nil
+	}
+
+	key, err := idempotencyKey(ctx)
+	if err != nil || key == "" {
+		return err
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	processed := processedRequest{
+		ExpiresAt: now.Add(idempotencyTTL),
+		Function:  function,
+		Result:    string(resultJSON),
+		TxID:      ctx.GetStub().GetTxID(),
+	}
+	err = putRecord(ctx, key, processed)
+	if err != nil {
+		return err
+	}
+
+	expiryKey, err := idempotencyExpiryKey(ctx, key, processed.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(expiryKey, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put to world state: %v", err)
+	}
+
+	return nil
+}
+
+// assertNotReplayed rejects a transaction whose idempotency key was already processed within
+// the last idempotencyTTL. A retry of the same function fails with ErrIdempotentReplay, whose
+// details carry the original transaction ID and result, so WithResponseEnvelope can return
+// that outcome to the client.
+func assertNotReplayed(ctx contractapi.TransactionContextInterface, function string) error {
+	key, err := idempotencyKey(ctx)
+	if err != nil || key == "" {
+		return err
+	}
+
+	var processed processedRequest
+	exists, err := getRecord(ctx, key, &processed)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	if !now.Before(processed.ExpiresAt) {
+		return nil
+	}
+	if processed.Function != function {
+		return newError(ErrValidation, map[string]string{"function": processed.Function}, "the idempotency key was already used for %s", processed.Function)
+	}
+
+	return newError(ErrIdempotentReplay, map[string]string{"result": processed.Result, "txID": processed.TxID}, "the request was already processed by transaction %s with result %s", processed.TxID, processed.Result)
+}
+
+// idempotencyKey returns the world state key recording the idempotency key passed in the
+// transient data, or an empty string if the client passed none
+func idempotencyKey(ctx contractapi.TransactionContextInterface) (string, error) {
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", fmt.Errorf("failed to read transient data: %v", err)
+	}
+	requestKey := string(transient[IdempotencyKeyTransient])
+	if requestKey == "" {
+		return "", nil
+	}
+
+	clientID, err := callerID(ctx)
+	if err != nil {
+		return "", err
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(idempotencyObjectType, []string{clientID, requestKey})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return key, nil
+}
+
+// idempotencyExpiryKey returns the key indexing the idempotency record stored under key by
+// its expiry
+func idempotencyExpiryKey(ctx contractapi.TransactionContextInterface, key string, expiresAt time.Time) (string, error) {
+	_, attributes, err := ctx.GetStub().SplitCompositeKey(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to split composite key: %v", err)
+	}
+	expiryKey, err := ctx.GetStub().CreateCompositeKey(idempotencyExpiryObjectType, append([]string{expiresAt.UTC().Format(auditTimeFormat)}, attributes...))
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return expiryKey, nil
+}
+
+// sweepIdempotencyKeysPage deletes up to pageSize expired idempotency records in expiry order.
+// Partial composite key queries cannot start at a bookmark in a transaction that writes, so
+// the sweep takes the records from the front of the expiry index and deletes each entry it
+// takes. The next page therefore starts at the front again without reading any record twice,
+// and the sweep is complete once the front entry has not expired. The bookmark returned is
+// the entry the next page starts at.
+func sweepIdempotencyKeysPage(ctx contractapi.TransactionContextInterface, job *Job, pageSize int) (int, string, error) {
+	now, err := txTime(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(idempotencyExpiryObjectType, []string{})
+	if err != nil {
+		return 0, "", err
+	}
+	defer resultsIterator.Close()
+
+	deleted := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, "", err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to split composite key: %v", err)
+		}
+		expiresAt, err := time.Parse(auditTimeFormat, attributes[0])
+		if err != nil {
+			return 0, "", err
+		}
+		if now.Before(expiresAt) {
+			return deleted, "", nil
+		}
+		if deleted == pageSize {
+			return deleted, queryResponse.Key, nil
+		}
+
+		err = ctx.GetStub().DelState(queryResponse.Key)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to delete from world state: %v", err)
+		}
+		deleted++
+
+		// a key reused after expiry is indexed again under its new expiry, so the record is
+		// only deleted if it has not been replaced since
+		key, err := ctx.GetStub().CreateCompositeKey(idempotencyObjectType, attributes[1:])
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to create composite key: %v", err)
+		}
+		var processed processedRequest
+		exists, err := getRecord(ctx, key, &processed)
+		if err != nil {
+			return 0, "", err
+		}
+		if !exists || now.Before(processed.ExpiresAt) {
+			continue
+		}
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to delete from world state: %v", err)
+		}
+	}
+
+	return deleted, "", nil
+}
+
+// eraseIdempotencyRecords deletes the idempotency records of the client subjectRef together
+// with their entries in the expiry index
+func eraseIdempotencyRecords(ctx contractapi.TransactionContextInterface, subjectRef string) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(idempotencyObjectType, []string{subjectRef})
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	count := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		var processed processedRequest
+		err = json.Unmarshal(queryResponse.Value, &processed)
+		if err != nil {
+			return 0, err
+		}
+		expiryKey, err := idempotencyExpiryKey(ctx, queryResponse.Key, processed.ExpiresAt)
+		if err != nil {
+			return 0, err
+		}
+		for _, key := range []string{expiryKey, queryResponse.Key} {
+			err = ctx.GetStub().DelState(key)
+			if err != nil {
+				return 0, fmt.Errorf("failed to delete from world state: %v", err)
+			}
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,52 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func afterTransaction(t *testing.T, assetTransfer *chaincode.SmartContract, ws *worldState, result interface{}) error {
+	after, ok := assetTransfer.GetAfterTransaction().(func(contractapi.TransactionContextInterface, interface{}) error)
+	require.True(t, ok)
+
+	return after(ws.transactionContext, result)
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	ws.stub.GetTransientReturns(map[string][]byte{chaincode.IdempotencyKeyTransient: []byte("request1")}, nil)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	require.NoError(t, afterTransaction(t, assetTransfer, ws, []string{"www.bbc.co.uk"}))
+	ws.commit()
+
+	err := beforeTransaction(t, assetTransfer, ws, "CreateAsset")
+	requireError(t, err, chaincode.ErrIdempotentReplay, `the request was already processed by transaction tx000 with result ["www.bbc.co.uk"]`)
+	ws.rollback()
+
+	err = beforeTransaction(t, assetTransfer, ws, "DeleteAsset")
+	requireError(t, err, chaincode.ErrValidation, "the idempotency key was already used for CreateAsset")
+	ws.rollback()
+
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+	ws.rollback()
+
+	ws.setClient("Org1MSP", "user1", false)
+	ws.now = ws.now.Add(25 * time.Hour)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+	ws.rollback()
+
+	ws.stub.GetTransientReturns(map[string][]byte{}, nil)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+	require.NoError(t, afterTransaction(t, assetTransfer, ws, nil))
+	for key := range ws.writes {
+		require.NotContains(t, key, "idempotency")
+	}
+}
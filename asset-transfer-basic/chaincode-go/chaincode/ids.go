@@ -0,0 +1,37 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TransactionContext is the transaction context used by the contract. Besides the stub and
+// client identity it carries state that lives for the duration of a single transaction. The
+// context of a step of ComposeTransaction refers to the context of the composing transaction.
+type TransactionContext struct {
+	contractapi.TransactionContext
+	idSequence int
+	parent     *TransactionContext
+}
+
+// GetTransactionContextHandler returns the context type created for every transaction
+func (s *SmartContract) GetTransactionContextHandler() contractapi.SettableTransactionContextInterface {
+	return new(TransactionContext)
+}
+
+// newRecordID derives the ID of an internal record of the given kind from the transaction ID
+// and a counter that increases with every ID derived during the transaction. All endorsers
+// derive the same IDs, and IDs from different transactions cannot collide.
+func newRecordID(ctx contractapi.TransactionContextInterface, kind string) string {
+	sequence := 0
+	if tc, ok := ctx.(*TransactionContext); ok {
+		for tc.parent != nil {
+			tc = tc.parent
+		}
+		tc.idSequence++
+		sequence = tc.idSequence
+	}
+
+	return fmt.Sprintf("%s-%s-%d", kind, ctx.GetStub().GetTxID(), sequence)
+}
@@ -0,0 +1,43 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDerivedRecordIDs(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+
+	// the agreement and its inbox notification each take the next ID of the transaction
+	first, err := assetTransfer.ProposeAgreement(ws.transactionContext, "Org2MSP", []string{"www.bbc.co.uk"}, "research use")
+	require.NoError(t, err)
+	second, err := assetTransfer.ProposeAgreement(ws.transactionContext, "Org3MSP", []string{"www.bbc.co.uk"}, "research use")
+	require.NoError(t, err)
+	require.Equal(t, "agreement-tx001-1", first)
+	require.Equal(t, "agreement-tx001-3", second)
+	ws.commit()
+
+	third, err := assetTransfer.ProposeAgreement(ws.transactionContext, "Org2MSP", []string{"www.bbc.co.uk"}, "research use")
+	require.NoError(t, err)
+	require.Equal(t, "agreement-tx002-1", third)
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", false)
+	pending, err := assetTransfer.GetMyPendingActions(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	notifications := pending.Notifications
+	require.Len(t, notifications, 2)
+	require.Equal(t, "notification-tx001-2", notifications[0].ID)
+	require.Equal(t, "notification-tx002-2", notifications[1].ID)
+}
+
+func TestGetTransactionContextHandler(t *testing.T) {
+	assetTransfer := chaincode.SmartContract{}
+	require.IsType(t, &chaincode.TransactionContext{}, assetTransfer.GetTransactionContextHandler())
+}
@@ -0,0 +1,104 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const immutabilityObjectType = "immutable"
+
+// Immutability records that an asset was permanently frozen, and by whom
+type Immutability struct {
+	ID       string    `json:"id"`
+	MarkedAt time.Time `json:"markedAt"`
+	MarkedBy string    `json:"markedBy"`
+}
+
+// MarkAssetImmutable permanently freezes an asset. Afterwards the asset can still be read and
+// its history queried, but every transaction that would modify or delete it is rejected.
+// Only the owner of the asset or an administrator may mark it.
+func (s *SmartContract) MarkAssetImmutable(ctx contractapi.TransactionContextInterface, id string) error {
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = assertOwnerOrAdmin(ctx, asset.Owner)
+	if err != nil {
+		return err
+	}
+
+	immutability, err := readImmutability(ctx, id)
+	if err != nil {
+		return err
+	}
+	if immutability != nil {
+		return newError(ErrValidation, map[string]string{"id": id}, "the asset %s is already immutable", id)
+	}
+
+	return freezeAsset(ctx, id)
+}
+
+// GetAssetImmutability returns the immutability record of the asset, or nil if the asset may still change
+func (s *SmartContract) GetAssetImmutability(ctx contractapi.TransactionContextInterface, id string) (*Immutability, error) {
+	return readImmutability(ctx, id)
+}
+
+func readImmutability(ctx contractapi.TransactionContextInterface, id string) (*Immutability, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(immutabilityObjectType, []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var immutability Immutability
+	exists, err := getRecord(ctx, key, &immutability)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	return &immutability, nil
+}
+
+// assertMutable returns an error if the asset with given id has been marked immutable
+func assertMutable(ctx contractapi.TransactionContextInterface, id string) error {
+	immutability, err := readImmutability(ctx, id)
+	if err != nil {
+		return err
+	}
+	if immutability != nil {
+		return newError(ErrValidation, map[string]string{"id": id}, "the asset %s is immutable and cannot be modified", id)
+	}
+
+	return nil
+}
+
+// freezeAsset marks the asset immutable unless it already is
+func freezeAsset(ctx contractapi.TransactionContextInterface, id string) error {
+	immutability, err := readImmutability(ctx, id)
+	if err != nil {
+		return err
+	}
+	if immutability != nil {
+		return nil
+	}
+
+	markedBy, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(immutabilityObjectType, []string{id})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, Immutability{ID: id, MarkedAt: now, MarkedBy: markedBy})
+}
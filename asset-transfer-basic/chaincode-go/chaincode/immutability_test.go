@@ -0,0 +1,48 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkAssetImmutable(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+
+	immutability, err := assetTransfer.GetAssetImmutability(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Nil(t, immutability)
+
+	ws.setClient("Org2MSP", "user2", false)
+	err = assetTransfer.MarkAssetImmutable(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.MarkAssetImmutable(ws.transactionContext, "www.google.com")
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.google.com does not exist")
+
+	require.NoError(t, assetTransfer.MarkAssetImmutable(ws.transactionContext, "www.bbc.co.uk"))
+	ws.commit()
+
+	immutability, err = assetTransfer.GetAssetImmutability(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Immutability{ID: "www.bbc.co.uk", MarkedAt: ws.now.Add(-time.Minute), MarkedBy: "admin"}, immutability)
+
+	err = assetTransfer.MarkAssetImmutable(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrValidation, "the asset www.bbc.co.uk is already immutable")
+
+	err = assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 1, "", 100)
+	requireError(t, err, chaincode.ErrValidation, "the asset www.bbc.co.uk is immutable and cannot be modified")
+
+	_, err = assetTransfer.TransferAsset(ws.transactionContext, "www.bbc.co.uk", "Org2MSP")
+	requireError(t, err, chaincode.ErrValidation, "the asset www.bbc.co.uk is immutable and cannot be modified")
+
+	err = assetTransfer.DeleteAsset(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrValidation, "the asset www.bbc.co.uk is immutable and cannot be modified")
+}
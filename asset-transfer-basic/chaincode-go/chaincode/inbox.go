@@ -0,0 +1,223 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const inboxObjectType = "inbox"
+
+// maxNotificationPageSize is the largest page of notifications GetMyPendingActions returns
+const maxNotificationPageSize = 100
+
+// Notification tells a client identity, or every member of an organization, that something
+// is waiting for their action. Recipient holds either a client ID or an MSP ID.
+type Notification struct {
+	Acknowledged bool      `json:"acknowledged"`
+	Action       string    `json:"action"`
+	CreatedAt    time.Time `json:"createdAt"`
+	ID           string    `json:"id"`
+	Recipient    string    `json:"recipient"`
+	Subject      string    `json:"subject"`
+}
+
+// NotificationList is the response of GetMyPendingActions
+type NotificationList struct {
+	Bookmark      string          `json:"bookmark"`
+	Metadata      QueryMetadata   `json:"metadata"`
+	Notifications []*Notification `json:"notifications"`
+}
+
+// GetMyPendingActions returns the unacknowledged notifications among the next pageSize
+// notifications addressed to the submitting client and then to its organization. Pass the
+// returned bookmark to fetch the next page; it is empty after the last page.
+func (s *SmartContract) GetMyPendingActions(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string) (*NotificationList, error) {
+	if pageSize < 1 || pageSize > maxNotificationPageSize {
+		return nil, newError(ErrValidation, map[string]string{"field": "pageSize"}, "the page size must be between 1 and %d", maxNotificationPageSize)
+	}
+
+	id, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recipients := []string{id, mspID}
+
+	// the bookmark is the key of the next notification, which names the inbox it continues
+	first := 0
+	if bookmark != "" {
+		objectType, attributes, err := ctx.GetStub().SplitCompositeKey(bookmark)
+		if err != nil || objectType != inboxObjectType || len(attributes) == 0 {
+			return nil, newError(ErrValidation, map[string]string{"field": "bookmark"}, "the bookmark %s does not belong to the inbox of the client", bookmark)
+		}
+		for first < len(recipients) && recipients[first] != attributes[0] {
+			first++
+		}
+		if first == len(recipients) {
+			return nil, newError(ErrValidation, map[string]string{"field": "bookmark"}, "the bookmark %s does not belong to the inbox of the client", bookmark)
+		}
+	}
+
+	notifications := []*Notification{}
+	remaining := int32(pageSize)
+	next := ""
+	for i := first; i < len(recipients) && next == ""; i++ {
+		startKey := ""
+		if i == first {
+			startKey = bookmark
+		}
+		if remaining == 0 {
+			// the page is full, so the next one starts at the beginning of this inbox
+			next, err = ctx.GetStub().CreateCompositeKey(inboxObjectType, []string{recipients[i]})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create composite key: %v", err)
+			}
+			break
+		}
+
+		pending, fetched, nextKey, err := pendingNotifications(ctx, recipients[i], remaining, startKey)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, pending...)
+		remaining -= fetched
+		next = nextKey
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NotificationList{Bookmark: next, Metadata: metadata, Notifications: notifications}, nil
+}
+
+// AcknowledgeNotification marks a notification addressed to the submitting client, or to its
+// organization, as handled so that it no longer appears in GetMyPendingActions
+func (s *SmartContract) AcknowledgeNotification(ctx contractapi.TransactionContextInterface, notificationID string) error {
+	id, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, recipient := range []string{id, mspID} {
+		key, err := ctx.GetStub().CreateCompositeKey(inboxObjectType, []string{recipient, notificationID})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %v", err)
+		}
+
+		var notification Notification
+		exists, err := getRecord(ctx, key, &notification)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		if notification.Acknowledged {
+			return newError(ErrValidation, map[string]string{"notificationID": notificationID}, "the notification %s has already been acknowledged", notificationID)
+		}
+
+		notification.Acknowledged = true
+		return putRecord(ctx, key, notification)
+	}
+
+	return newError(ErrNotFound, map[string]string{"notificationID": notificationID}, "the notification %s does not exist", notificationID)
+}
+
+// notify adds an action request to the inbox of recipient, which may be a client ID or an MSP ID
+func notify(ctx contractapi.TransactionContextInterface, recipient string, action string, subject string) error {
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	notification := Notification{
+		Action:    action,
+		CreatedAt: now,
+		ID:        newRecordID(ctx, "notification"),
+		Recipient: recipient,
+		Subject:   subject,
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(inboxObjectType, []string{recipient, notification.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, notification)
+}
+
+// resolveNotifications deletes the notifications asking recipient to take action on subject,
+// once the action has been taken or can no longer be taken
+func resolveNotifications(ctx contractapi.TransactionContextInterface, recipient string, action string, subject string) error {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(inboxObjectType, []string{recipient})
+	if err != nil {
+		return err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+
+		var notification Notification
+		err = json.Unmarshal(queryResponse.Value, &notification)
+		if err != nil {
+			return err
+		}
+		if notification.Action != action || notification.Subject != subject {
+			continue
+		}
+		err = ctx.GetStub().DelState(queryResponse.Key)
+		if err != nil {
+			return fmt.Errorf("failed to delete from world state: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// pendingNotifications returns the unacknowledged notifications among the next pageSize
+// notifications in the inbox of recipient from startKey on, how many notifications it read and
+// the key the next page of the inbox starts at, which is empty after its last page
+func pendingNotifications(ctx contractapi.TransactionContextInterface, recipient string, pageSize int32, startKey string) ([]*Notification, int32, string, error) {
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(inboxObjectType, []string{recipient}, pageSize, startKey)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	defer resultsIterator.Close()
+
+	var notifications []*Notification
+	var fetched int32
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, 0, "", err
+		}
+		fetched++
+
+		var notification Notification
+		err = json.Unmarshal(queryResponse.Value, &notification)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		if !notification.Acknowledged {
+			notifications = append(notifications, &notification)
+		}
+	}
+
+	return notifications, fetched, responseMetadata.Bookmark, nil
+}
@@ -0,0 +1,92 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func putNotification(t *testing.T, ws *worldState, notification chaincode.Notification) {
+	key, err := createCompositeKey("inbox", []string{notification.Recipient, notification.ID})
+	require.NoError(t, err)
+	notificationJSON, err := json.Marshal(notification)
+	require.NoError(t, err)
+	ws.state[key] = notificationJSON
+}
+
+func TestGetMyPendingActions(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	putNotification(t, ws, chaincode.Notification{ID: "n1", Recipient: "user1", Action: "AcceptTransfer", Subject: "www.bbc.co.uk", CreatedAt: ws.now})
+	putNotification(t, ws, chaincode.Notification{ID: "n2", Recipient: "Org1MSP", Action: "AcceptTransfer", Subject: "www.google.com", CreatedAt: ws.now})
+	putNotification(t, ws, chaincode.Notification{ID: "n3", Recipient: "user2", Action: "AcceptTransfer", Subject: "www.xxx.com", CreatedAt: ws.now})
+
+	pending, err := assetTransfer.GetMyPendingActions(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	notifications := pending.Notifications
+	require.Len(t, notifications, 2)
+	require.Equal(t, "n1", notifications[0].ID)
+	require.Equal(t, "n2", notifications[1].ID)
+}
+
+func TestAcknowledgeNotification(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	putNotification(t, ws, chaincode.Notification{ID: "n1", Recipient: "Org1MSP", Action: "AcceptTransfer", Subject: "www.bbc.co.uk", CreatedAt: ws.now})
+	putNotification(t, ws, chaincode.Notification{ID: "n2", Recipient: "user2", Action: "AcceptTransfer", Subject: "www.xxx.com", CreatedAt: ws.now})
+
+	err := assetTransfer.AcknowledgeNotification(ws.transactionContext, "n2")
+	requireError(t, err, chaincode.ErrNotFound, "the notification n2 does not exist")
+
+	err = assetTransfer.AcknowledgeNotification(ws.transactionContext, "n1")
+	require.NoError(t, err)
+	ws.commit()
+
+	pending, err := assetTransfer.GetMyPendingActions(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	notifications := pending.Notifications
+	require.Empty(t, notifications)
+
+	err = assetTransfer.AcknowledgeNotification(ws.transactionContext, "n1")
+	requireError(t, err, chaincode.ErrValidation, "the notification n1 has already been acknowledged")
+}
+
+func TestGetMyPendingActionsPagination(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	putNotification(t, ws, chaincode.Notification{ID: "n1", Recipient: "user1", Action: "AcceptTransfer", Subject: "www.bbc.co.uk", CreatedAt: ws.now})
+	putNotification(t, ws, chaincode.Notification{ID: "n2", Recipient: "user1", Action: "AcceptTransfer", Subject: "www.google.com", CreatedAt: ws.now, Acknowledged: true})
+	putNotification(t, ws, chaincode.Notification{ID: "n3", Recipient: "Org1MSP", Action: "AcceptTransfer", Subject: "www.napier.ac.uk", CreatedAt: ws.now})
+	putNotification(t, ws, chaincode.Notification{ID: "n4", Recipient: "Org1MSP", Action: "AcceptTransfer", Subject: "www.xxx.com", CreatedAt: ws.now})
+
+	_, err := assetTransfer.GetMyPendingActions(ws.transactionContext, 0, "")
+	requireError(t, err, chaincode.ErrValidation, "the page size must be between 1 and 100")
+
+	// the client's inbox is paged first, and acknowledged notifications take up room on the page
+	pending, err := assetTransfer.GetMyPendingActions(ws.transactionContext, 2, "")
+	require.NoError(t, err)
+	require.Len(t, pending.Notifications, 1)
+	require.Equal(t, "n1", pending.Notifications[0].ID)
+	require.NotEmpty(t, pending.Bookmark)
+
+	pending, err = assetTransfer.GetMyPendingActions(ws.transactionContext, 1, pending.Bookmark)
+	require.NoError(t, err)
+	require.Len(t, pending.Notifications, 1)
+	require.Equal(t, "n3", pending.Notifications[0].ID)
+
+	pending, err = assetTransfer.GetMyPendingActions(ws.transactionContext, 2, pending.Bookmark)
+	require.NoError(t, err)
+	require.Len(t, pending.Notifications, 1)
+	require.Equal(t, "n4", pending.Notifications[0].ID)
+	require.Empty(t, pending.Bookmark)
+
+	bookmark, err := createCompositeKey("inbox", []string{"user2", "n5"})
+	require.NoError(t, err)
+	_, err = assetTransfer.GetMyPendingActions(ws.transactionContext, 2, bookmark)
+	requireError(t, err, chaincode.ErrValidation, "the bookmark "+bookmark+" does not belong to the inbox of the client")
+}
@@ -0,0 +1,321 @@
+package chaincode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// allowlistIndexObjectType and blocklistIndexObjectType index the allowlist and the blocklist
+// entries of assets by the labels of the domain in reverse order, followed by an empty attribute
+// and the asset ID, so that the assets listing a domain or any of its subdomains share a partial
+// composite key. Labels are never empty, so the empty attribute marks the end of the domain.
+const (
+	allowlistIndexObjectType = "index~allowlist"
+	blocklistIndexObjectType = "index~blocklist"
+)
+
+// domainIndexObjectType indexed assets by the labels of their ID in schema version 3, before
+// asset IDs were separate from allowlists. Its entries are removed when such an asset is rewritten.
+const domainIndexObjectType = "index~domain"
+
+// GetAssetsByPrefix returns one page of at most pageSize assets whose allowlist lies within the
+// domain prefix, such as "google.com" for google.com itself and all its subdomains, or
+// "*.google.com" for the subdomains only, in which case a page may hold one asset fewer than
+// pageSize. Like the bookmark, the returned bookmark is an asset ID. Assets stored before schema
+// version 4 are only listed once MigrateAll has rewritten them.
+func (s *SmartContract) GetAssetsByPrefix(ctx contractapi.TransactionContextInterface, prefix string, pageSize int32, bookmark string) (*PaginatedAssetList, error) {
+	if pageSize < 1 || pageSize > maxAssetPageSize {
+		return nil, newError(ErrValidation, map[string]string{"field": "pageSize"}, "the page size must be between 1 and %d", maxAssetPageSize)
+	}
+	domain, err := normalizeDomain(prefix)
+	if err != nil {
+		return nil, err
+	}
+	subdomainsOnly := strings.HasPrefix(domain, wildcardDomain+".")
+	domain = strings.TrimPrefix(domain, wildcardDomain+".")
+	labels := domainLabels(domain)
+
+	startKey := ""
+	if bookmark != "" {
+		next, err := s.ReadAsset(ctx, bookmark)
+		if err != nil {
+			return nil, err
+		}
+		if next.Allowlist != domain && !strings.HasSuffix(next.Allowlist, "."+domain) {
+			return nil, newError(ErrValidation, map[string]string{"field": "bookmark"}, "the bookmark %s does not belong to the prefix %s", bookmark, prefix)
+		}
+		startKey, err = entryIndexKey(ctx, allowlistIndexObjectType, next.Allowlist, next.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(allowlistIndexObjectType, labels, pageSize, startKey)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := []*Asset{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, err
+		}
+		entry, id, err := splitEntryIndexKey(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		if subdomainsOnly && entry == domain {
+			continue
+		}
+
+		asset, err := readIndexedAsset(ctx, meter, id)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+
+	nextBookmark := ""
+	if responseMetadata.Bookmark != "" {
+		_, nextBookmark, err = splitEntryIndexKey(ctx, responseMetadata.Bookmark)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Cost = &meter.cost
+
+	return &PaginatedAssetList{
+		Assets:              assets,
+		Bookmark:            nextBookmark,
+		FetchedRecordsCount: responseMetadata.FetchedRecordsCount,
+		Metadata:            metadata,
+	}, nil
+}
+
+// GetAssetsByBlocklist returns the assets whose public blocklist holds the entry domain, which
+// may be a wildcard entry such as "*.instagram.com". The assets are looked up in the blocklist
+// index, so assets stored before schema version 4 are only found once MigrateAll has rewritten them.
+func (s *SmartContract) GetAssetsByBlocklist(ctx contractapi.TransactionContextInterface, domain string) (*AssetList, error) {
+	entry, err := normalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := indexedAssets(ctx, meter, blocklistIndexObjectType, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := []*Asset{}
+	for _, id := range ids {
+		asset, err := readIndexedAsset(ctx, meter, id)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Cost = &meter.cost
+
+	return &AssetList{Assets: assets, Metadata: metadata}, nil
+}
+
+// indexedAssets returns the IDs of the assets whose index of objectType holds exactly entry
+func indexedAssets(ctx contractapi.TransactionContextInterface, meter *costMeter, objectType string, entry string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, append(domainLabels(entry), ""))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var ids []string
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, err
+		}
+		_, id, err := splitEntryIndexKey(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// readIndexedAsset reads the asset with the given ID found in an index, counting its size
+// against the cost of the query
+func readIndexedAsset(ctx contractapi.TransactionContextInterface, meter *costMeter, id string) (*Asset, error) {
+	key, err := assetKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	assetJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if assetJSON == nil {
+		return nil, fmt.Errorf("the index refers to the missing asset %s", id)
+	}
+	err = meter.returned(len(assetJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalAsset(id, assetJSON)
+}
+
+// domainLabels returns the labels of a domain from the top level down
+func domainLabels(domain string) []string {
+	return reverseLabels(strings.Split(domain, "."))
+}
+
+// reverseLabels reverses the order of labels in place and returns them
+func reverseLabels(labels []string) []string {
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+
+	return labels
+}
+
+// entryIndexKey returns the key under which the index of objectType records that the asset
+// with the given ID lists entry
+func entryIndexKey(ctx contractapi.TransactionContextInterface, objectType string, entry string, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(objectType, append(domainLabels(entry), "", id))
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return key, nil
+}
+
+// splitEntryIndexKey returns the entry and the asset ID recorded by an index key
+func splitEntryIndexKey(ctx contractapi.TransactionContextInterface, key string) (string, string, error) {
+	_, attributes, err := ctx.GetStub().SplitCompositeKey(key)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to split composite key: %v", err)
+	}
+	if len(attributes) < 3 || attributes[len(attributes)-2] != "" {
+		return "", "", fmt.Errorf("the key %q is not an index entry", key)
+	}
+
+	labels := attributes[:len(attributes)-2]
+	return strings.Join(reverseLabels(labels), "."), attributes[len(attributes)-1], nil
+}
+
+// indexKeys returns the index keys of the allowlist and blocklist entries and of the category
+// of asset. The rules of an AdBlock Plus asset are indexed by the entries they match.
+func indexKeys(ctx contractapi.TransactionContextInterface, asset *Asset) ([]string, error) {
+	var keys []string
+	if asset.Category != "" {
+		key, err := ctx.GetStub().CreateCompositeKey(categoryIndexObjectType, []string{asset.Category, asset.ID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create composite key: %v", err)
+		}
+		keys = append(keys, key)
+	}
+	if !asset.ExpiresAt.IsZero() {
+		key, err := expiryIndexKey(ctx, asset)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	allowed, blocked, err := listEntries(asset)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range allowed {
+		key, err := entryIndexKey(ctx, allowlistIndexObjectType, entry, asset.ID)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	for _, entry := range blocked {
+		key, err := entryIndexKey(ctx, blocklistIndexObjectType, entry, asset.ID)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// reindexAsset moves the index entries and the statistics of an asset from its previous version
+// to its current one. Either may be nil, for an asset being created or deleted.
+func reindexAsset(ctx contractapi.TransactionContextInterface, previous *Asset, current *Asset) error {
+	var currentKeys []string
+	if current != nil {
+		var err error
+		currentKeys, err = indexKeys(ctx, current)
+		if err != nil {
+			return err
+		}
+	}
+
+	if previous != nil {
+		previousKeys, err := indexKeys(ctx, previous)
+		if err != nil {
+			return err
+		}
+		if previous.SchemaVersion == 3 {
+			legacyKey, err := ctx.GetStub().CreateCompositeKey(domainIndexObjectType, domainLabels(previous.ID))
+			if err != nil {
+				return fmt.Errorf("failed to create composite key: %v", err)
+			}
+			previousKeys = append(previousKeys, legacyKey)
+		}
+		for _, key := range previousKeys {
+			if stringInSlice(key, currentKeys) {
+				continue
+			}
+			err = ctx.GetStub().DelState(key)
+			if err != nil {
+				return fmt.Errorf("failed to delete from world state: %v", err)
+			}
+		}
+	}
+
+	for _, key := range currentKeys {
+		err := ctx.GetStub().PutState(key, []byte{0x00})
+		if err != nil {
+			return fmt.Errorf("failed to put to world state: %v", err)
+		}
+	}
+
+	return countAsset(ctx, previous, current)
+}
@@ -0,0 +1,186 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAssetsByPrefix(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	for _, id := range []string{"www.google.com", "scholar.google.com", "google.com", "www.google.co.uk", "www.bbc.co.uk"} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "", 1, "", 100))
+	}
+	ws.commit()
+
+	ids := func(list *chaincode.PaginatedAssetList) []string {
+		ids := []string{}
+		for _, asset := range list.Assets {
+			ids = append(ids, asset.Allowlist)
+		}
+		return ids
+	}
+
+	page, err := assetTransfer.GetAssetsByPrefix(ws.transactionContext, "Google.com", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"google.com", "scholar.google.com", "www.google.com"}, ids(page))
+	require.Equal(t, "", page.Bookmark)
+	require.Equal(t, 3, page.Metadata.Cost.KeysScanned)
+
+	page, err = assetTransfer.GetAssetsByPrefix(ws.transactionContext, "*.google.com", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"scholar.google.com", "www.google.com"}, ids(page))
+
+	page, err = assetTransfer.GetAssetsByPrefix(ws.transactionContext, "co.uk", 1, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.bbc.co.uk"}, ids(page))
+	require.Equal(t, "www.google.co.uk", page.Bookmark)
+	page, err = assetTransfer.GetAssetsByPrefix(ws.transactionContext, "co.uk", 1, page.Bookmark)
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.google.co.uk"}, ids(page))
+	require.Equal(t, "", page.Bookmark)
+
+	_, err = assetTransfer.GetAssetsByPrefix(ws.transactionContext, "co.uk", 1, "www.google.com")
+	requireError(t, err, chaincode.ErrValidation, "the bookmark www.google.com does not belong to the prefix co.uk")
+	_, err = assetTransfer.GetAssetsByPrefix(ws.transactionContext, "co.uk", 0, "")
+	requireError(t, err, chaincode.ErrValidation, "the page size must be between 1 and 100")
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.google.com"))
+	ws.commit()
+	page, err = assetTransfer.GetAssetsByPrefix(ws.transactionContext, "*.google.com", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"scholar.google.com"}, ids(page))
+}
+
+func TestMigrateAllIndexesAssets(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	// written before assets were indexed by domain, and while they were indexed by their ID
+	require.NoError(t, ws.putState(assetKey("www.google.com"), []byte(`{"allowlist":"www.google.com","blocklist":"","schemaVersion":2}`)))
+	require.NoError(t, ws.putState(assetKey("www.bbc.co.uk"), []byte(`{"allowlist":"www.bbc.co.uk","blocklist":"www.xxx.com","schemaVersion":3}`)))
+	legacyIndexKey, err := createCompositeKey("index~domain", []string{"uk", "co", "bbc", "www"})
+	require.NoError(t, err)
+	require.NoError(t, ws.putState(legacyIndexKey, []byte{0x00}))
+	ws.commit()
+
+	page, err := assetTransfer.GetAssetsByPrefix(ws.transactionContext, "google.com", 10, "")
+	require.NoError(t, err)
+	require.Empty(t, page.Assets)
+	match, err := assetTransfer.MatchDomain(ws.transactionContext, "www.xxx.com")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.DomainUnlisted, match.Verdict)
+
+	ws.setClient("Org1MSP", "admin", true)
+	progress, err := assetTransfer.MigrateAll(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), progress.Migrated)
+	ws.commit()
+
+	page, err = assetTransfer.GetAssetsByPrefix(ws.transactionContext, "google.com", 10, "")
+	require.NoError(t, err)
+	require.Len(t, page.Assets, 1)
+	require.Equal(t, chaincode.SchemaVersion, page.Assets[0].SchemaVersion)
+	match, err = assetTransfer.MatchDomain(ws.transactionContext, "www.xxx.com")
+	require.NoError(t, err)
+	require.Equal(t, [2]string{chaincode.DomainBlocked, "www.bbc.co.uk"}, [2]string{match.Verdict, match.List})
+	require.NotContains(t, ws.state, legacyIndexKey)
+}
+
+func TestAssetsKeyedByID(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	// both blocklist-only assets of the base set have an empty allowlist and no longer share a key
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.InitLedger(ws.transactionContext))
+	ws.commit()
+	assets, err := assetTransfer.GetAllAssets(ws.transactionContext)
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 6)
+	match, err := assetTransfer.MatchDomain(ws.transactionContext, "www.instagram.com")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.DomainBlocked, match.Verdict)
+	blocklistOnly, err := assetTransfer.ReadAsset(ws.transactionContext, match.List)
+	require.NoError(t, err)
+	require.Equal(t, "", blocklistOnly.Allowlist)
+	require.Equal(t, "www.instagram.com", blocklistOnly.Blocklist)
+
+	err = assetTransfer.CreateAssetWithID(ws.transactionContext, "", "www.google.com", "", 1, "", 100)
+	requireError(t, err, chaincode.ErrValidation, "the asset ID must not be empty")
+	err = assetTransfer.CreateAssetWithID(ws.transactionContext, "asset~1", "www.google.com", "", 1, "", 100)
+	requireError(t, err, chaincode.ErrValidation, "the asset ID asset~1 uses the reserved prefix asset~")
+	err = assetTransfer.CreateAssetWithID(ws.transactionContext, "www.google.com", "www.yahoo.com", "", 1, "", 100)
+	requireError(t, err, chaincode.ErrAssetExists, "the asset www.google.com already exists")
+
+	// an asset may list a domain another asset already allows
+	require.NoError(t, assetTransfer.CreateAssetWithID(ws.transactionContext, "google-mirror", "WWW.Google.com", "", 1, "", 100))
+	ws.commit()
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "google-mirror")
+	require.NoError(t, err)
+	require.Equal(t, "www.google.com", asset.Allowlist)
+	page, err := assetTransfer.GetAssetsByPrefix(ws.transactionContext, "www.google.com", 10, "")
+	require.NoError(t, err)
+	require.Len(t, page.Assets, 2)
+
+	// updating the blocklist of an asset moves its index entries
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, match.List, "www.tiktok.com", 15, "", 700))
+	ws.commit()
+	match, err = assetTransfer.MatchDomain(ws.transactionContext, "www.instagram.com")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.DomainUnlisted, match.Verdict)
+	match, err = assetTransfer.MatchDomain(ws.transactionContext, "www.tiktok.com")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.DomainBlocked, match.Verdict)
+}
+
+func TestGetAssetsByBlocklist(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com,*.instagram.com", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.google.com", "www.xxx.com", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.napier.ac.uk", "mail.xxx.com", 1, "", 100))
+	ws.commit()
+
+	ids := func(list *chaincode.AssetList) []string {
+		ids := []string{}
+		for _, asset := range list.Assets {
+			ids = append(ids, asset.ID)
+		}
+		return ids
+	}
+
+	assets, err := assetTransfer.GetAssetsByBlocklist(ws.transactionContext, "https://WWW.xxx.com/")
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.bbc.co.uk", "www.google.com"}, ids(assets))
+	require.Equal(t, 2, assets.Metadata.Cost.KeysScanned)
+
+	assets, err = assetTransfer.GetAssetsByBlocklist(ws.transactionContext, "*.instagram.com")
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.bbc.co.uk"}, ids(assets))
+
+	assets, err = assetTransfer.GetAssetsByBlocklist(ws.transactionContext, "xxx.com")
+	require.NoError(t, err)
+	require.Empty(t, assets.Assets)
+
+	_, err = assetTransfer.GetAssetsByBlocklist(ws.transactionContext, "bad..entry")
+	require.Error(t, err)
+
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.google.com", "", 1, "", 100))
+	ws.commit()
+	assets, err = assetTransfer.GetAssetsByBlocklist(ws.transactionContext, "www.xxx.com")
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.bbc.co.uk"}, ids(assets))
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.bbc.co.uk"))
+	ws.commit()
+	assets, err = assetTransfer.GetAssetsByBlocklist(ws.transactionContext, "www.xxx.com")
+	require.NoError(t, err)
+	require.Empty(t, assets.Assets)
+}
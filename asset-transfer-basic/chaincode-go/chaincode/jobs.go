@@ -0,0 +1,182 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const jobObjectType = "job"
+
+// maxJobPageSize caps the number of records one ContinueJob call may process
+const maxJobPageSize = 500
+
+// Kinds of long-running jobs
+const (
+	// JobMigrateKeys moves assets between key prefixes, like MigrateKeys. It takes the
+	// parameters fromPrefix and toPrefix.
+	JobMigrateKeys = "migrateKeys"
+	// JobSweepIdempotencyKeys deletes idempotency records whose TTL has passed. It takes no parameters.
+	JobSweepIdempotencyKeys = "sweepIdempotencyKeys"
+)
+
+// Job tracks a long-running operation that is processed one bounded page per ContinueJob
+// transaction, so that no single transaction exceeds the peer's timeout or size limits
+type Job struct {
+	Bookmark  string            `json:"bookmark"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Done      bool              `json:"done"`
+	ID        string            `json:"id"`
+	Kind      string            `json:"kind"`
+	Params    map[string]string `json:"params"`
+	Processed int               `json:"processed"`
+	StartedBy string            `json:"startedBy"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// jobRunner processes one page of a job of a given kind, returning the number of records it
+// processed and the bookmark of the next page, which is empty once the job is complete
+type jobRunner func(ctx contractapi.TransactionContextInterface, job *Job, pageSize int) (int, string, error)
+
+// jobKind validates the parameters of a kind of job and runs its pages
+type jobKind struct {
+	validate func(params map[string]string) error
+	run      jobRunner
+}
+
+var jobKinds = map[string]jobKind{
+	JobMigrateKeys: {
+		validate: func(params map[string]string) error {
+			return validateMigration(params["fromPrefix"], params["toPrefix"])
+		},
+		run: func(ctx contractapi.TransactionContextInterface, job *Job, pageSize int) (int, string, error) {
+			migrated, bookmark, err := migrateKeysPage(ctx, job.Params["fromPrefix"], job.Params["toPrefix"], int32(pageSize), job.Bookmark)
+			return int(migrated), bookmark, err
+		},
+	},
+	JobSweepIdempotencyKeys: {
+		validate: func(params map[string]string) error {
+			return nil
+		},
+		run: sweepIdempotencyKeysPage,
+	},
+}
+
+// StartJob records a new job of the given kind, with paramsJSON holding its parameters as a
+// JSON object of strings, and returns the job ID to pass to ContinueJob
+func (s *SmartContract) StartJob(ctx contractapi.TransactionContextInterface, kind string, paramsJSON string) (string, error) {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	handler, ok := jobKinds[kind]
+	if !ok {
+		return "", newError(ErrValidation, map[string]string{"field": "kind"}, "unknown job kind %s", kind)
+	}
+	params := map[string]string{}
+	if paramsJSON != "" {
+		err = json.Unmarshal([]byte(paramsJSON), &params)
+		if err != nil {
+			return "", newError(ErrValidation, map[string]string{"field": "paramsJSON"}, "invalid job parameters: %v", err)
+		}
+	}
+	err = handler.validate(params)
+	if err != nil {
+		return "", err
+	}
+
+	startedBy, err := callerID(ctx)
+	if err != nil {
+		return "", err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	job := &Job{
+		CreatedAt: now,
+		ID:        newRecordID(ctx, jobObjectType),
+		Kind:      kind,
+		Params:    params,
+		StartedBy: startedBy,
+		UpdatedAt: now,
+	}
+	err = putJob(ctx, job)
+	if err != nil {
+		return "", err
+	}
+
+	return job.ID, nil
+}
+
+// ContinueJob processes the next page of up to pageSize records of a job and returns its progress
+func (s *SmartContract) ContinueJob(ctx contractapi.TransactionContextInterface, jobID string, pageSize int) (*Job, error) {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize < 1 || pageSize > maxJobPageSize {
+		return nil, newError(ErrValidation, map[string]string{"field": "pageSize"}, "the page size must be between 1 and %d", maxJobPageSize)
+	}
+
+	job, err := s.GetJobStatus(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Done {
+		return nil, newError(ErrValidation, map[string]string{"jobID": jobID}, "the job %s is already complete", jobID)
+	}
+
+	processed, bookmark, err := jobKinds[job.Kind].run(ctx, job, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("the job %s failed: %v", jobID, err)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	job.Bookmark = bookmark
+	job.Done = bookmark == ""
+	job.Processed += processed
+	job.UpdatedAt = now
+
+	err = putJob(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetJobStatus returns the job stored in the world state with given id
+func (s *SmartContract) GetJobStatus(ctx contractapi.TransactionContextInterface, jobID string) (*Job, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(jobObjectType, []string{jobID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var job Job
+	exists, err := getRecord(ctx, key, &job)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, newError(ErrNotFound, map[string]string{"jobID": jobID}, "the job %s does not exist", jobID)
+	}
+
+	return &job, nil
+}
+
+func putJob(ctx contractapi.TransactionContextInterface, job *Job) error {
+	key, err := ctx.GetStub().CreateCompositeKey(jobObjectType, []string{job.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, job)
+}
@@ -0,0 +1,152 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateKeysJob(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	for _, id := range []string{"www.bbc.co.uk", "www.google.com", "www.napier.ac.uk"} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "", 1, "", 100))
+	}
+	ws.commit()
+
+	_, err := assetTransfer.StartJob(ws.transactionContext, chaincode.JobMigrateKeys, `{"fromPrefix":"www.","toPrefix":"web:www."}`)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err = assetTransfer.StartJob(ws.transactionContext, "reindex", "")
+	requireError(t, err, chaincode.ErrValidation, "unknown job kind reindex")
+	_, err = assetTransfer.StartJob(ws.transactionContext, chaincode.JobMigrateKeys, `{"fromPrefix":"www."}`)
+	requireError(t, err, chaincode.ErrValidation, "the key prefixes www. and  overlap")
+
+	created := ws.now
+	jobID, err := assetTransfer.StartJob(ws.transactionContext, chaincode.JobMigrateKeys, `{"fromPrefix":"www.","toPrefix":"web:www."}`)
+	require.NoError(t, err)
+	ws.commit()
+
+	_, err = assetTransfer.ContinueJob(ws.transactionContext, jobID, 501)
+	requireError(t, err, chaincode.ErrValidation, "the page size must be between 1 and 500")
+
+	job, err := assetTransfer.ContinueJob(ws.transactionContext, jobID, 2)
+	require.NoError(t, err)
+	require.Equal(t, "www.napier.ac.uk", job.Bookmark)
+	require.Equal(t, 2, job.Processed)
+	require.False(t, job.Done)
+	ws.commit()
+
+	_, err = assetTransfer.ContinueJob(ws.transactionContext, jobID, 2)
+	require.NoError(t, err)
+	ws.commit()
+
+	job, err = assetTransfer.GetJobStatus(ws.transactionContext, jobID)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Job{
+		CreatedAt: created,
+		Done:      true,
+		ID:        jobID,
+		Kind:      chaincode.JobMigrateKeys,
+		Params:    map[string]string{"fromPrefix": "www.", "toPrefix": "web:www."},
+		Processed: 3,
+		StartedBy: "admin",
+		UpdatedAt: ws.now.Add(-time.Minute),
+	}, job)
+
+	exists, err := assetTransfer.AssetExists(ws.transactionContext, "web:www.napier.ac.uk")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	_, err = assetTransfer.ContinueJob(ws.transactionContext, jobID, 2)
+	requireError(t, err, chaincode.ErrValidation, "the job "+jobID+" is already complete")
+
+	_, err = assetTransfer.GetJobStatus(ws.transactionContext, "job-tx999-1")
+	requireError(t, err, chaincode.ErrNotFound, "the job job-tx999-1 does not exist")
+}
+
+func TestImportAssetsJob(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.google.com", "", 1, "", 100))
+	ws.commit()
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err := assetTransfer.StartJob(ws.transactionContext, chaincode.JobImportAssets, `{"assets":"[]"}`)
+	requireError(t, err, chaincode.ErrValidation, "the import must contain at least one asset")
+
+	assets := `[{"allowlist":"www.bbc.co.uk","blocklist":""},{"allowlist":"www.google.com","blocklist":""},{"allowlist":"www.napier.ac.uk","blocklist":""}]`
+	params, err := json.Marshal(map[string]string{"assets": assets})
+	require.NoError(t, err)
+	jobID, err := assetTransfer.StartJob(ws.transactionContext, chaincode.JobImportAssets, string(params))
+	require.NoError(t, err)
+	ws.commit()
+
+	job, err := assetTransfer.ContinueJob(ws.transactionContext, jobID, 2)
+	require.NoError(t, err)
+	require.Equal(t, "2", job.Bookmark)
+	require.False(t, job.Done)
+	ws.commit()
+	job, err = assetTransfer.ContinueJob(ws.transactionContext, jobID, 2)
+	require.NoError(t, err)
+	require.True(t, job.Done)
+	require.Equal(t, 3, job.Processed)
+	ws.commit()
+
+	imported, err := assetTransfer.ReadAsset(ws.transactionContext, "www.napier.ac.uk")
+	require.NoError(t, err)
+	require.Equal(t, "admin", imported.Owner)
+
+	// the existing asset is skipped rather than taken over
+	existing, err := assetTransfer.ReadAsset(ws.transactionContext, "www.google.com")
+	require.NoError(t, err)
+	require.Equal(t, "user1", existing.Owner)
+}
+
+func TestSweepIdempotencyKeysJob(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	// the records of user1 and user2 expire before the sweep, those of user3 and user4 after it
+	for _, client := range []string{"user1", "user2", "user3", "user4"} {
+		ws.setClient("Org1MSP", client, false)
+		ws.stub.GetTransientReturns(map[string][]byte{chaincode.IdempotencyKeyTransient: []byte("request1")}, nil)
+		require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+		require.NoError(t, afterTransaction(t, assetTransfer, ws, nil))
+		ws.commit()
+		ws.now = ws.now.Add(10 * time.Hour)
+	}
+	ws.stub.GetTransientReturns(map[string][]byte{}, nil)
+
+	ws.setClient("Org1MSP", "admin", true)
+	jobID, err := assetTransfer.StartJob(ws.transactionContext, chaincode.JobSweepIdempotencyKeys, "")
+	require.NoError(t, err)
+	ws.commit()
+
+	job, err := assetTransfer.ContinueJob(ws.transactionContext, jobID, 1)
+	require.NoError(t, err)
+	require.False(t, job.Done)
+	ws.commit()
+	job, err = assetTransfer.ContinueJob(ws.transactionContext, jobID, 1)
+	require.NoError(t, err)
+	require.True(t, job.Done)
+	require.Equal(t, 2, job.Processed)
+	ws.commit()
+
+	remaining := map[string]int{}
+	for key := range ws.state {
+		for _, objectType := range []string{"idempotency", "idempotency~expiry"} {
+			if strings.HasPrefix(key, compositeKeyNamespace+objectType+compositeKeyNamespace) {
+				remaining[objectType]++
+			}
+		}
+	}
+	require.Equal(t, map[string]int{"idempotency": 2, "idempotency~expiry": 2}, remaining)
+}
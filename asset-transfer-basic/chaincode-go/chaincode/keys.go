@@ -0,0 +1,210 @@
+package chaincode
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// maxAssetIDLength bounds the length in bytes of a client supplied asset ID
+const maxAssetIDLength = 256
+
+// compositeKeySeparator delimits the parts of a composite key and starts every composite key
+const compositeKeySeparator = "\x00"
+
+// assetObjectType is the composite key object type assets are stored under, keyed by their ID,
+// so that they cannot collide with any other kind of record stored by the chaincode
+const assetObjectType = "asset"
+
+// KeyNamespace describes a namespace of internal records, the subsystem that owns it and the
+// composite key object types the subsystem stores in it. An object type is either the
+// namespace itself or the namespace followed by "~" and a qualifier.
+type KeyNamespace struct {
+	Namespace   string   `json:"namespace"`
+	ObjectTypes []string `json:"objectTypes"`
+	Subsystem   string   `json:"subsystem"`
+}
+
+// keyNamespaces registers every key namespace used for internal records. A subsystem storing
+// a new kind of record must claim its namespace here, and CheckKeyNamespaces fails at startup
+// if two subsystems claim the same namespace or object type.
+var keyNamespaces = []KeyNamespace{
+	{Namespace: "agreement", ObjectTypes: []string{agreementObjectType, agreementConsumerObjectType}, Subsystem: "data sharing agreements"},
+	{Namespace: "alias", ObjectTypes: []string{aliasObjectType, assetAliasObjectType}, Subsystem: "aliases"},
+	{Namespace: "archive", ObjectTypes: []string{}, Subsystem: "archive (reserved)"},
+	{Namespace: "asset", ObjectTypes: []string{assetObjectType}, Subsystem: "assets"},
+	{Namespace: "audit", ObjectTypes: []string{auditEntryObjectType, lastModificationObjectType}, Subsystem: "audit trail"},
+	{Namespace: "category", ObjectTypes: []string{categoryObjectType}, Subsystem: "categories"},
+	{Namespace: "config", ObjectTypes: []string{configObjectType}, Subsystem: "contract configuration"},
+	{Namespace: "delegation", ObjectTypes: []string{delegationObjectType}, Subsystem: "admin delegation"},
+	{Namespace: "device", ObjectTypes: []string{deviceObjectType}, Subsystem: "policy scopes"},
+	{Namespace: "erasure", ObjectTypes: []string{erasureObjectType}, Subsystem: "data erasure"},
+	{Namespace: "filterpolicy", ObjectTypes: []string{filterPolicyObjectType}, Subsystem: "filter policies"},
+	{Namespace: "fnmetric", ObjectTypes: []string{invocationMetricObjectType}, Subsystem: "usage metrics"},
+	{Namespace: "hits", ObjectTypes: []string{hitObjectType}, Subsystem: "list activity"},
+	{Namespace: "idempotency", ObjectTypes: []string{idempotencyObjectType, idempotencyExpiryObjectType}, Subsystem: "idempotency keys"},
+	{Namespace: "immutable", ObjectTypes: []string{immutabilityObjectType}, Subsystem: "immutability"},
+	{Namespace: "inbox", ObjectTypes: []string{inboxObjectType}, Subsystem: "notifications"},
+	{Namespace: "index", ObjectTypes: []string{allowlistIndexObjectType, blocklistIndexObjectType, categoryIndexObjectType, domainIndexObjectType, expiryIndexObjectType}, Subsystem: "indexes"},
+	{Namespace: "job", ObjectTypes: []string{jobObjectType}, Subsystem: "jobs"},
+	{Namespace: "lastknowngood", ObjectTypes: []string{lastKnownGoodObjectType}, Subsystem: "emergency rollback"},
+	{Namespace: "ledgerheight", ObjectTypes: []string{ledgerHeightObjectType}, Subsystem: "ledger height"},
+	{Namespace: "link", ObjectTypes: []string{linkObjectType, reverseLinkObjectType}, Subsystem: "asset links"},
+	{Namespace: "meter", ObjectTypes: []string{meteringObjectType}, Subsystem: "metering"},
+	{Namespace: "org", ObjectTypes: []string{orgObjectType}, Subsystem: "organization lifecycle"},
+	{Namespace: "permission", ObjectTypes: []string{permissionObjectType}, Subsystem: "permissions"},
+	{Namespace: "provenance", ObjectTypes: []string{provenanceObjectType}, Subsystem: "transfer provenance"},
+	{Namespace: "quota", ObjectTypes: []string{quotaObjectType}, Subsystem: "quotas"},
+	{Namespace: "quotacharge", ObjectTypes: []string{quotaChargeObjectType}, Subsystem: "quotas"},
+	{Namespace: "quotausage", ObjectTypes: []string{quotaUsageObjectType}, Subsystem: "quotas"},
+	{Namespace: "role", ObjectTypes: []string{roleObjectType}, Subsystem: "role registry"},
+	{Namespace: "scopepolicy", ObjectTypes: []string{scopePolicyObjectType}, Subsystem: "policy scopes"},
+	{Namespace: "stats", ObjectTypes: []string{assetStatsObjectType}, Subsystem: "asset statistics"},
+	{Namespace: "template", ObjectTypes: []string{templateObjectType}, Subsystem: "templates"},
+	{Namespace: "transfer", ObjectTypes: []string{transferObjectType, transferRecipientObjectType}, Subsystem: "transfer proposals"},
+	{Namespace: "transferpolicy", ObjectTypes: []string{transferPolicyObjectType}, Subsystem: "transfer policy"},
+	{Namespace: "watch", ObjectTypes: []string{watchObjectType}, Subsystem: "watches"},
+}
+
+// reservedNamespaces are the key namespaces used for internal records. An asset ID may not equal
+// one of them or start with one of them followed by "~".
+var reservedNamespaces = namespaceNames(keyNamespaces)
+
+// CheckKeyNamespaces verifies that no two subsystems share a key namespace or object type and
+// that every object type lies within its subsystem's namespace. It is run when the chaincode starts.
+func CheckKeyNamespaces() error {
+	return checkKeyNamespaces(keyNamespaces)
+}
+
+// ListKeyNamespaces returns the registered key namespaces and the subsystems owning them
+func (s *SmartContract) ListKeyNamespaces(ctx contractapi.TransactionContextInterface) ([]*KeyNamespace, error) {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]*KeyNamespace, 0, len(keyNamespaces))
+	for i := range keyNamespaces {
+		namespace := keyNamespaces[i]
+		namespaces = append(namespaces, &namespace)
+	}
+
+	return namespaces, nil
+}
+
+// ListReservedPrefixes returns the key prefixes that asset IDs may not use
+func (s *SmartContract) ListReservedPrefixes(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	prefixes := []string{compositeKeySeparator}
+	for _, namespace := range reservedNamespaces {
+		prefixes = append(prefixes, namespace+"~")
+	}
+
+	return prefixes, nil
+}
+
+func namespaceNames(namespaces []KeyNamespace) []string {
+	names := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		names = append(names, namespace.Namespace)
+	}
+
+	return names
+}
+
+func checkKeyNamespaces(namespaces []KeyNamespace) error {
+	owners := map[string]string{}
+	for _, namespace := range namespaces {
+		if namespace.Namespace == "" || strings.ContainsAny(namespace.Namespace, "~"+compositeKeySeparator) {
+			return fmt.Errorf("the key namespace %q of %s must be non-empty and contain neither ~ nor U+0000", namespace.Namespace, namespace.Subsystem)
+		}
+		if owner, ok := owners[namespace.Namespace]; ok {
+			return fmt.Errorf("the key namespace %s is claimed by both %s and %s", namespace.Namespace, owner, namespace.Subsystem)
+		}
+		owners[namespace.Namespace] = namespace.Subsystem
+	}
+
+	objectTypeOwners := map[string]string{}
+	for _, namespace := range namespaces {
+		for _, objectType := range namespace.ObjectTypes {
+			if objectType != namespace.Namespace && !strings.HasPrefix(objectType, namespace.Namespace+"~") {
+				return fmt.Errorf("the object type %s of %s lies outside its namespace %s", objectType, namespace.Subsystem, namespace.Namespace)
+			}
+			if owner, ok := objectTypeOwners[objectType]; ok {
+				return fmt.Errorf("the object type %s is claimed by both %s and %s", objectType, owner, namespace.Subsystem)
+			}
+			objectTypeOwners[objectType] = namespace.Subsystem
+		}
+	}
+
+	return nil
+}
+
+// validateAssetID rejects asset IDs that could shadow internal records or that the peer would refuse
+func validateAssetID(id string) error {
+	if len(id) > maxAssetIDLength {
+		return newError(ErrValidation, map[string]string{"field": "id"}, "the asset ID %.32s... exceeds the maximum length of %d bytes", id, maxAssetIDLength)
+	}
+	if !utf8.ValidString(id) {
+		return newError(ErrValidation, map[string]string{"field": "id"}, "the asset ID %q is not valid UTF-8", id)
+	}
+	if strings.Contains(id, compositeKeySeparator) {
+		return newError(ErrValidation, map[string]string{"field": "id"}, "the asset ID %q must not contain the composite key separator U+0000", id)
+	}
+
+	for _, namespace := range reservedNamespaces {
+		if id == namespace || strings.HasPrefix(id, namespace+"~") {
+			return newError(ErrValidation, map[string]string{"field": "id"}, "the asset ID %s uses the reserved prefix %s~", id, namespace)
+		}
+	}
+
+	return nil
+}
+
+// defaultAssetID returns the ID of a new asset that was not given one: its normalized
+// allowlist, or an ID derived from the transaction for an asset listing no allowlist
+func defaultAssetID(ctx contractapi.TransactionContextInterface, allowlist string) string {
+	if allowlist != "" {
+		return allowlist
+	}
+
+	return newRecordID(ctx, assetObjectType)
+}
+
+// assetKey returns the composite key the asset with the given ID is stored under
+func assetKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(assetObjectType, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return key, nil
+}
+
+// assetIDFromKey returns the ID of the asset stored under key, or false if key holds another
+// kind of record
+func assetIDFromKey(ctx contractapi.TransactionContextInterface, key string) (string, bool, error) {
+	prefix, err := ctx.GetStub().CreateCompositeKey(assetObjectType, []string{})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create composite key: %v", err)
+	}
+	if !strings.HasPrefix(key, prefix) {
+		return "", false, nil
+	}
+
+	_, attributes, err := ctx.GetStub().SplitCompositeKey(key)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to split composite key: %v", err)
+	}
+	if len(attributes) != 1 {
+		return "", false, fmt.Errorf("malformed asset key %q", key)
+	}
+
+	return attributes[0], true, nil
+}
+
+// prefixRangeEnd returns the exclusive end key of a range query covering every key starting with prefix
+func prefixRangeEnd(prefix string) string {
+	return prefix + string(utf8.MaxRune)
+}
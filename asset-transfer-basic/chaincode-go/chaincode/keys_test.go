@@ -0,0 +1,63 @@
+package chaincode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAssetRejectsReservedKeys(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	err := assetTransfer.CreateAsset(ws.transactionContext, "config", "", 1, "", 100)
+	requireError(t, err, chaincode.ErrValidation, "the asset ID config uses the reserved prefix config~")
+
+	err = assetTransfer.CreateAsset(ws.transactionContext, "quota~Org1MSP", "", 1, "", 100)
+	requireError(t, err, chaincode.ErrValidation, "the asset ID quota~Org1MSP uses the reserved prefix quota~")
+
+	err = assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk\x00x", "", 1, "", 100)
+	requireError(t, err, chaincode.ErrValidation, `the asset ID "www.bbc.co.uk\x00x" must not contain the composite key separator U+0000`)
+
+	err = assetTransfer.CreateAsset(ws.transactionContext, "\xff", "", 1, "", 100)
+	requireError(t, err, chaincode.ErrValidation, `the asset ID "\xff" is not valid UTF-8`)
+
+	err = assetTransfer.CreateAsset(ws.transactionContext, strings.Repeat("a", 257), "", 1, "", 100)
+	requireError(t, err, chaincode.ErrValidation, "the asset ID aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa... exceeds the maximum length of 256 bytes")
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "config.example.com", "", 1, "", 100))
+	require.NotNil(t, ws.writes[assetKey("config.example.com")])
+}
+
+func TestListReservedPrefixes(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	prefixes, err := assetTransfer.ListReservedPrefixes(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, "\x00", prefixes[0])
+	require.Contains(t, prefixes, "config~")
+	require.Contains(t, prefixes, "audit~")
+	require.Contains(t, prefixes, "watch~")
+}
+
+func TestListKeyNamespaces(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, chaincode.CheckKeyNamespaces())
+
+	_, err := assetTransfer.ListKeyNamespaces(ws.transactionContext)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	namespaces, err := assetTransfer.ListKeyNamespaces(ws.transactionContext)
+	require.NoError(t, err)
+	require.Contains(t, namespaces, &chaincode.KeyNamespace{Namespace: "link", ObjectTypes: []string{"link", "link~reverse"}, Subsystem: "asset links"})
+
+	prefixes, err := assetTransfer.ListReservedPrefixes(ws.transactionContext)
+	require.NoError(t, err)
+	require.Len(t, prefixes, len(namespaces)+1)
+}
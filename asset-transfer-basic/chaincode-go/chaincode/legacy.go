@@ -0,0 +1,170 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// legacyMigrationEventName is the chaincode event emitted after each page of a legacy asset migration
+const legacyMigrationEventName = "LegacyAssetMigrationProgress"
+
+// LegacyAssetMigrationProgress reports how far a legacy asset migration has advanced. It is
+// returned by MigrateLegacyAssets and emitted as the payload of a LegacyAssetMigrationProgress
+// event. Pass Bookmark to the next MigrateLegacyAssets call until Done is true.
+type LegacyAssetMigrationProgress struct {
+	Bookmark string `json:"bookmark"`
+	Done     bool   `json:"done"`
+	Migrated int32  `json:"migrated"`
+	Scanned  int32  `json:"scanned"`
+}
+
+// MigrateLegacyAssets moves up to pageSize assets still stored under flat keys, starting at
+// bookmark, to the composite keys assets are now stored under, rewriting those stored in the
+// legacy layout into the current one on the way. The migration is resumable: each call
+// continues from the bookmark returned by the previous one.
+func (s *SmartContract) MigrateLegacyAssets(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*LegacyAssetMigrationProgress, error) {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize <= 0 {
+		return nil, newError(ErrValidation, map[string]string{"field": "pageSize"}, "the page size must be positive")
+	}
+
+	// range queries only cover flat keys, which no record but a legacy asset is stored under.
+	// paginated range queries are only available to read-only transactions, so the page is
+	// bounded by hand
+	resultsIterator, err := ctx.GetStub().GetStateByRange(bookmark, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	progress := LegacyAssetMigrationProgress{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if progress.Scanned == pageSize {
+			progress.Bookmark = queryResponse.Key
+			break
+		}
+		progress.Scanned++
+
+		err = migrateLegacyAsset(ctx, queryResponse.Key, queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+		progress.Migrated++
+	}
+	progress.Done = progress.Bookmark == ""
+
+	progressJSON, err := json.Marshal(progress)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().SetEvent(legacyMigrationEventName, progressJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	return &progress, nil
+}
+
+// migrateLegacyAsset moves the asset stored under the flat key id to its composite key. Moving
+// an asset leaves its value unchanged, so immutable assets are migrated as well.
+func migrateLegacyAsset(ctx contractapi.TransactionContextInterface, id string, assetJSON []byte) error {
+	key, err := assetKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return newError(ErrValidation, map[string]string{"id": id}, "cannot migrate %s: the asset already exists under its composite key", id)
+	}
+
+	asset, err := unmarshalAsset(id, assetJSON)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal the asset %s: %v", id, err)
+	}
+	err = putAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete from world state: %v", err)
+	}
+
+	return nil
+}
+
+// unmarshalAsset decodes the stored JSON of the asset with the given ID and upgrades it to the
+// current schema version
+func unmarshalAsset(id string, assetJSON []byte) (*Asset, error) {
+	asset, err := decodeAsset(id, assetJSON)
+	if err != nil {
+		return nil, err
+	}
+	upgradeAsset(asset)
+
+	return asset, nil
+}
+
+// decodeAsset decodes the stored JSON of the asset with the given ID as it was written. Assets
+// written while the fields of Asset were unexported are stored as an empty JSON object: the key
+// is the only part of them that survived, so their allowlist is recovered from it and their
+// other fields read as zero values. Assets written before schema version 4 lack an ID, which
+// was their allowlist and is recovered from the key as well.
+func decodeAsset(id string, assetJSON []byte) (*Asset, error) {
+	var asset Asset
+	err := json.Unmarshal(assetJSON, &asset)
+	if err != nil {
+		return nil, err
+	}
+
+	legacy, err := isLegacyAsset(assetJSON)
+	if err != nil {
+		return nil, err
+	}
+	if legacy {
+		asset.Allowlist = id
+	}
+	if asset.ID == "" {
+		asset.ID = id
+	}
+
+	return &asset, nil
+}
+
+// unmarshalStoredAsset decodes the asset stored under the composite key key
+func unmarshalStoredAsset(ctx contractapi.TransactionContextInterface, key string, assetJSON []byte) (*Asset, error) {
+	id, ok, err := assetIDFromKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("the key %q does not hold an asset", key)
+	}
+
+	return unmarshalAsset(id, assetJSON)
+}
+
+// isLegacyAsset reports whether the stored JSON of an asset lacks the allowlist that every
+// asset written in the current layout carries
+func isLegacyAsset(assetJSON []byte) (bool, error) {
+	var document map[string]json.RawMessage
+	err := json.Unmarshal(assetJSON, &document)
+	if err != nil {
+		return false, err
+	}
+	_, ok := document["allowlist"]
+
+	return !ok, nil
+}
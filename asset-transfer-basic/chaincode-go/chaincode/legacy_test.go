@@ -0,0 +1,79 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateLegacyAssets(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	// assets stored under flat keys, the first two written while the fields of Asset were unexported
+	require.NoError(t, ws.putState("www.bbc.co.uk", []byte("{}")))
+	require.NoError(t, ws.putState("www.google.com", []byte("{}")))
+	require.NoError(t, ws.putState("www.xxx.com", []byte(`{"allowlist":"www.xxx.com","attribute1":"Org1MSP","attribute2":2,"blocklist":"","webfilterlist":200}`)))
+	created := ws.now
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.napier.ac.uk", "www.xxx.com", 1, "Org1MSP", 100))
+	ws.commit()
+
+	assets, err := assetTransfer.GetAllAssets(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.Asset{
+		{Allowlist: "www.napier.ac.uk", Attribute1: "Org1MSP", Attribute2: 1, Blocklist: "www.xxx.com", CreatedAt: created, ID: "www.napier.ac.uk", Owner: "user1", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: created, Webfilterlist: 100},
+	}, assets.Assets)
+
+	_, err = assetTransfer.MigrateLegacyAssets(ws.transactionContext, 2, "")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err = assetTransfer.MigrateLegacyAssets(ws.transactionContext, 0, "")
+	requireError(t, err, chaincode.ErrValidation, "the page size must be positive")
+
+	migrated := ws.now
+	progress, err := assetTransfer.MigrateLegacyAssets(ws.transactionContext, 1, "")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.LegacyAssetMigrationProgress{Bookmark: "www.google.com", Migrated: 1, Scanned: 1}, progress)
+
+	name, payload := ws.stub.SetEventArgsForCall(ws.stub.SetEventCallCount() - 1)
+	require.Equal(t, "LegacyAssetMigrationProgress", name)
+	var event chaincode.LegacyAssetMigrationProgress
+	require.NoError(t, json.Unmarshal(payload, &event))
+	require.Equal(t, *progress, event)
+	ws.commit()
+	require.NotContains(t, ws.state, "www.bbc.co.uk")
+	require.JSONEq(t, `{"allowlist":"www.bbc.co.uk","attribute1":"","attribute2":0,"blocklist":"","createdAt":"2021-03-01T09:01:00Z","expiresAt":"0001-01-01T00:00:00Z","id":"www.bbc.co.uk","schemaVersion":4,"updatedAt":"2021-03-01T09:01:00Z","webfilterlist":0}`, string(ws.state[assetKey("www.bbc.co.uk")]))
+
+	// the versions written under the flat key remain part of the history
+	history, err := assetTransfer.GetAssetHistory(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Len(t, history.History, 3)
+	require.Equal(t, "tx000", history.History[0].TxID)
+	require.True(t, history.History[1].IsDelete)
+	require.Equal(t, &chaincode.Asset{Allowlist: "www.bbc.co.uk", CreatedAt: migrated, ID: "www.bbc.co.uk", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: migrated}, history.History[2].Value)
+
+	stored := ws.state[assetKey("www.napier.ac.uk")]
+	migrated = ws.now
+	progress, err = assetTransfer.MigrateLegacyAssets(ws.transactionContext, 5, progress.Bookmark)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.LegacyAssetMigrationProgress{Done: true, Migrated: 2, Scanned: 2}, progress)
+	require.NotContains(t, ws.writes, assetKey("www.napier.ac.uk"))
+	ws.commit()
+	require.Equal(t, stored, ws.state[assetKey("www.napier.ac.uk")])
+
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.xxx.com")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Asset{Allowlist: "www.xxx.com", Attribute1: "Org1MSP", Attribute2: 2, CreatedAt: migrated, ID: "www.xxx.com", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: migrated, Webfilterlist: 200}, asset)
+
+	progress, err = assetTransfer.MigrateLegacyAssets(ws.transactionContext, 5, "")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.LegacyAssetMigrationProgress{Done: true}, progress)
+
+	require.NoError(t, ws.putState("www.napier.ac.uk", []byte("{}")))
+	ws.commit()
+	_, err = assetTransfer.MigrateLegacyAssets(ws.transactionContext, 5, "")
+	requireError(t, err, chaincode.ErrValidation, "cannot migrate www.napier.ac.uk: the asset already exists under its composite key")
+}
@@ -0,0 +1,219 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	linkObjectType        = "link"
+	reverseLinkObjectType = "link~reverse"
+)
+
+// maxLinkDepth bounds the traversal performed by GetRelatedAssets
+const maxLinkDepth = 10
+
+// AssetLink is a typed relationship from a parent asset to a child asset, such as a policy
+// that includes a list. Depth is set by GetRelatedAssets to the number of links between the
+// asset the traversal started from and Child.
+type AssetLink struct {
+	Child    string `json:"child"`
+	Depth    int    `json:"depth,omitempty" metadata:"depth,optional"`
+	Parent   string `json:"parent"`
+	Relation string `json:"relation"`
+}
+
+// LinkAssets records a typed relationship from parentID to childID. The link is indexed in
+// both directions so it can be followed from either asset. Links decide what moves with a
+// transfer, so the client must own both assets or be an administrator.
+func (s *SmartContract) LinkAssets(ctx contractapi.TransactionContextInterface, parentID string, childID string, relation string) error {
+	if relation == "" {
+		return newError(ErrValidation, map[string]string{"field": "relation"}, "a relation is required")
+	}
+	if parentID == childID {
+		return newError(ErrValidation, map[string]string{"id": parentID}, "the asset %s cannot be linked to itself", parentID)
+	}
+
+	for _, id := range []string{parentID, childID} {
+		asset, err := s.ReadAsset(ctx, id)
+		if err != nil {
+			return err
+		}
+		err = assertOwnerOrAdmin(ctx, asset.Owner)
+		if err != nil {
+			return err
+		}
+	}
+
+	link := AssetLink{Child: childID, Parent: parentID, Relation: relation}
+	key, err := ctx.GetStub().CreateCompositeKey(linkObjectType, []string{parentID, relation, childID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	reverseKey, err := ctx.GetStub().CreateCompositeKey(reverseLinkObjectType, []string{childID, relation, parentID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	for _, k := range []string{key, reverseKey} {
+		err = putRecord(ctx, k, link)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnlinkAssets removes a relationship previously recorded by LinkAssets. It needs the same
+// rights as LinkAssets, though either asset may have expired since.
+func (s *SmartContract) UnlinkAssets(ctx contractapi.TransactionContextInterface, parentID string, childID string, relation string) error {
+	key, err := ctx.GetStub().CreateCompositeKey(linkObjectType, []string{parentID, relation, childID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	value, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if value == nil {
+		return newError(ErrNotFound, map[string]string{"childID": childID, "parentID": parentID, "relation": relation}, "the asset %s is not linked to %s by %s", parentID, childID, relation)
+	}
+
+	for _, id := range []string{parentID, childID} {
+		asset, err := readStoredAsset(ctx, id)
+		if err != nil {
+			return err
+		}
+		err = assertOwnerOrAdmin(ctx, asset.Owner)
+		if err != nil {
+			return err
+		}
+	}
+
+	return deleteLink(ctx, parentID, relation, childID)
+}
+
+// AssetLinkList is the response of GetRelatedAssets
+type AssetLinkList struct {
+	Links    []*AssetLink  `json:"links"`
+	Metadata QueryMetadata `json:"metadata"`
+}
+
+// GetRelatedAssets follows the links leaving id, up to depth links away, and returns every link
+// visited. An empty relation follows links of any relation.
+func (s *SmartContract) GetRelatedAssets(ctx contractapi.TransactionContextInterface, id string, relation string, depth int) (*AssetLinkList, error) {
+	if depth < 1 || depth > maxLinkDepth {
+		return nil, newError(ErrValidation, map[string]string{"field": "depth"}, "the traversal depth must be between 1 and %d", maxLinkDepth)
+	}
+
+	links := []*AssetLink{}
+	visited := map[string]bool{id: true}
+	frontier := []string{id}
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		var next []string
+		for _, parentID := range frontier {
+			children, err := queryLinks(ctx, linkObjectType, parentID, relation)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, link := range children {
+				link.Depth = level
+				links = append(links, link)
+				if !visited[link.Child] {
+					visited[link.Child] = true
+					next = append(next, link.Child)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AssetLinkList{Links: links, Metadata: metadata}, nil
+}
+
+// queryLinks returns the links stored under the given index for id. For the forward index the links
+// leave id; for the reverse index they arrive at it.
+func queryLinks(ctx contractapi.TransactionContextInterface, objectType string, id string, relation string) ([]*AssetLink, error) {
+	attributes := []string{id}
+	if relation != "" {
+		attributes = append(attributes, relation)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var links []*AssetLink
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %v", err)
+		}
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("malformed link key %q", queryResponse.Key)
+		}
+
+		link := &AssetLink{Parent: parts[0], Relation: parts[1], Child: parts[2]}
+		if objectType == reverseLinkObjectType {
+			link.Parent, link.Child = parts[2], parts[0]
+		}
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+func deleteLink(ctx contractapi.TransactionContextInterface, parentID string, relation string, childID string) error {
+	key, err := ctx.GetStub().CreateCompositeKey(linkObjectType, []string{parentID, relation, childID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	reverseKey, err := ctx.GetStub().CreateCompositeKey(reverseLinkObjectType, []string{childID, relation, parentID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	for _, k := range []string{key, reverseKey} {
+		err = ctx.GetStub().DelState(k)
+		if err != nil {
+			return fmt.Errorf("failed to delete from world state: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// deleteAssetLinks removes every link to or from id, so that deleting an asset leaves no dangling relationships
+func deleteAssetLinks(ctx contractapi.TransactionContextInterface, id string) error {
+	for _, objectType := range []string{linkObjectType, reverseLinkObjectType} {
+		links, err := queryLinks(ctx, objectType, id, "")
+		if err != nil {
+			return err
+		}
+
+		for _, link := range links {
+			err = deleteLink(ctx, link.Parent, link.Relation, link.Child)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
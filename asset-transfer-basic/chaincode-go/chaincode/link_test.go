@@ -0,0 +1,131 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkAssets(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	for _, id := range []string{"policy1", "list1", "feed1"} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "", 1, "", 100))
+	}
+	ws.commit()
+
+	err := assetTransfer.LinkAssets(ws.transactionContext, "policy1", "list1", "")
+	requireError(t, err, chaincode.ErrValidation, "a relation is required")
+
+	err = assetTransfer.LinkAssets(ws.transactionContext, "policy1", "policy1", "includes")
+	requireError(t, err, chaincode.ErrValidation, "the asset policy1 cannot be linked to itself")
+
+	err = assetTransfer.LinkAssets(ws.transactionContext, "policy1", "list2", "includes")
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset list2 does not exist")
+
+	require.NoError(t, assetTransfer.LinkAssets(ws.transactionContext, "policy1", "list1", "includes"))
+	require.NoError(t, assetTransfer.LinkAssets(ws.transactionContext, "list1", "feed1", "sources"))
+	ws.commit()
+
+	related, err := assetTransfer.GetRelatedAssets(ws.transactionContext, "policy1", "", 1)
+	require.NoError(t, err)
+	links := related.Links
+	require.Equal(t, []*chaincode.AssetLink{
+		{Child: "list1", Depth: 1, Parent: "policy1", Relation: "includes"},
+	}, links)
+
+	related, err = assetTransfer.GetRelatedAssets(ws.transactionContext, "policy1", "", 2)
+	require.NoError(t, err)
+	links = related.Links
+	require.Equal(t, []*chaincode.AssetLink{
+		{Child: "list1", Depth: 1, Parent: "policy1", Relation: "includes"},
+		{Child: "feed1", Depth: 2, Parent: "list1", Relation: "sources"},
+	}, links)
+
+	related, err = assetTransfer.GetRelatedAssets(ws.transactionContext, "policy1", "sources", 2)
+	require.NoError(t, err)
+	links = related.Links
+	require.Empty(t, links)
+
+	_, err = assetTransfer.GetRelatedAssets(ws.transactionContext, "policy1", "", 11)
+	requireError(t, err, chaincode.ErrValidation, "the traversal depth must be between 1 and 10")
+}
+
+func TestGetRelatedAssetsStopsAtCycles(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "list1", "", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "list2", "", 1, "", 100))
+	ws.commit()
+	require.NoError(t, assetTransfer.LinkAssets(ws.transactionContext, "list1", "list2", "extends"))
+	require.NoError(t, assetTransfer.LinkAssets(ws.transactionContext, "list2", "list1", "extends"))
+	ws.commit()
+
+	related, err := assetTransfer.GetRelatedAssets(ws.transactionContext, "list1", "extends", 10)
+	require.NoError(t, err)
+	links := related.Links
+	require.Len(t, links, 2)
+}
+
+func TestUnlinkAssets(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	for _, id := range []string{"policy1", "list1", "list2"} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "", 1, "", 100))
+	}
+	ws.commit()
+	require.NoError(t, assetTransfer.LinkAssets(ws.transactionContext, "policy1", "list1", "includes"))
+	require.NoError(t, assetTransfer.LinkAssets(ws.transactionContext, "policy1", "list2", "includes"))
+	ws.commit()
+
+	err := assetTransfer.UnlinkAssets(ws.transactionContext, "policy1", "list1", "sources")
+	requireError(t, err, chaincode.ErrNotFound, "the asset policy1 is not linked to list1 by sources")
+
+	require.NoError(t, assetTransfer.UnlinkAssets(ws.transactionContext, "policy1", "list1", "includes"))
+	ws.commit()
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "list2"))
+	ws.commit()
+
+	related, err := assetTransfer.GetRelatedAssets(ws.transactionContext, "policy1", "", 1)
+	require.NoError(t, err)
+	links := related.Links
+	require.Empty(t, links)
+	for key := range ws.state {
+		require.NotContains(t, key, "link")
+	}
+}
+
+func TestLinkAssetsRequiresOwnership(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "policy1", "", 1, "", 100))
+	ws.commit()
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "list1", "", 1, "", 100))
+	ws.commit()
+
+	err := assetTransfer.LinkAssets(ws.transactionContext, "policy1", "list1", "includes")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+
+	ws.setClient("Org1MSP", "user1", false)
+	err = assetTransfer.LinkAssets(ws.transactionContext, "policy1", "list1", "includes")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.LinkAssets(ws.transactionContext, "policy1", "list1", "includes"))
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", false)
+	err = assetTransfer.UnlinkAssets(ws.transactionContext, "policy1", "list1", "includes")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.UnlinkAssets(ws.transactionContext, "policy1", "list1", "includes"))
+}
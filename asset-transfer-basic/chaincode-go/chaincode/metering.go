@@ -0,0 +1,135 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const meteringObjectType = "meter"
+
+// meteringShards spreads the record of each organization and period over several keys. Every
+// metered transaction updates one shard, chosen by its transaction ID, so concurrent
+// transactions of one organization rarely conflict on its record.
+const meteringShards = 16
+
+// meteringPeriodLayout buckets billable operations by calendar month (UTC)
+const meteringPeriodLayout = "2006-01"
+
+// Billable operations recorded by the metering subsystem
+const (
+	meterCreate = "create"
+	// meterPublish counts the new versions of list contents made live, by updates that change
+	// a blocklist and by emergency rollbacks
+	meterPublish = "publish"
+	meterUpdate  = "update"
+)
+
+// MeteringRecord counts the billable operations performed by one organization during one period
+type MeteringRecord struct {
+	MSPID      string         `json:"mspID"`
+	Operations map[string]int `json:"operations"`
+	Period     string         `json:"period"`
+}
+
+// UsageReport is the response of GetUsageReport
+type UsageReport struct {
+	Metadata QueryMetadata     `json:"metadata"`
+	Records  []*MeteringRecord `json:"records"`
+}
+
+// GetUsageReport returns the metering records for a period (YYYY-MM). When mspID is empty the
+// records of every organization active in that period are returned.
+func (s *SmartContract) GetUsageReport(ctx contractapi.TransactionContextInterface, period string, mspID string) (*UsageReport, error) {
+	if period == "" {
+		return nil, newError(ErrValidation, map[string]string{"field": "period"}, "a metering period is required")
+	}
+
+	attributes := []string{period}
+	if mspID != "" {
+		attributes = append(attributes, mspID)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(meteringObjectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the shards of an organization are adjacent, so each run of them is merged into one record
+	records := []*MeteringRecord{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.returned(len(queryResponse.Value))
+		if err != nil {
+			return nil, err
+		}
+
+		var shard MeteringRecord
+		err = json.Unmarshal(queryResponse.Value, &shard)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 || records[len(records)-1].MSPID != shard.MSPID {
+			records = append(records, &MeteringRecord{MSPID: shard.MSPID, Operations: map[string]int{}, Period: shard.Period})
+		}
+		record := records[len(records)-1]
+		for operation, count := range shard.Operations {
+			record.Operations[operation] += count
+		}
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata.Cost = &meter.cost
+
+	return &UsageReport{Metadata: metadata, Records: records}, nil
+}
+
+// meterOperation adds count billable operations to a shard of the submitting organization's
+// record for the current period
+func meterOperation(ctx contractapi.TransactionContextInterface, operation string, count int) error {
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	period := now.UTC().Format(meteringPeriodLayout)
+
+	key, err := ctx.GetStub().CreateCompositeKey(meteringObjectType, []string{period, mspID, txShard(ctx, meteringShards)})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	record := MeteringRecord{MSPID: mspID, Period: period}
+	_, err = getRecord(ctx, key, &record)
+	if err != nil {
+		return err
+	}
+	if record.Operations == nil {
+		record.Operations = map[string]int{}
+	}
+	record.Operations[operation] += count
+
+	return putRecord(ctx, key, record)
+}
@@ -0,0 +1,51 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUsageReport(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	_, err := assetTransfer.GetUsageReport(ws.transactionContext, "", "")
+	requireError(t, err, chaincode.ErrValidation, "a metering period is required")
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.napier.ac.uk", "", 1, "", 100))
+	ws.commit()
+
+	// an update publishes a new version of the list only if it changes the blocklist
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 2, "", 100))
+	ws.commit()
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 3, "", 100))
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.google.com", "", 1, "", 100))
+	ws.commit()
+
+	ws.now = time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "scholar.google.com", "", 1, "", 100))
+	ws.commit()
+
+	report, err := assetTransfer.GetUsageReport(ws.transactionContext, "2021-03", "")
+	require.NoError(t, err)
+	records := report.Records
+	require.Equal(t, []*chaincode.MeteringRecord{
+		{MSPID: "Org1MSP", Operations: map[string]int{"create": 2, "publish": 1, "update": 2}, Period: "2021-03"},
+		{MSPID: "Org2MSP", Operations: map[string]int{"create": 1}, Period: "2021-03"},
+	}, records)
+
+	report, err = assetTransfer.GetUsageReport(ws.transactionContext, "2021-04", "Org2MSP")
+	require.NoError(t, err)
+	records = report.Records
+	require.Equal(t, []*chaincode.MeteringRecord{
+		{MSPID: "Org2MSP", Operations: map[string]int{"create": 1}, Period: "2021-04"},
+	}, records)
+}
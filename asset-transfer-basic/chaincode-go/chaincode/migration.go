@@ -0,0 +1,205 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// migrationEventName is the chaincode event emitted after each page of a key migration
+const migrationEventName = "KeyMigrationProgress"
+
+// KeyMigrationProgress reports how far a key migration has advanced. It is returned by
+// MigrateKeys and emitted as the payload of a KeyMigrationProgress event. Pass Bookmark
+// to the next MigrateKeys call until Done is true.
+type KeyMigrationProgress struct {
+	Bookmark   string `json:"bookmark"`
+	Done       bool   `json:"done"`
+	FromPrefix string `json:"fromPrefix"`
+	Migrated   int32  `json:"migrated"`
+	ToPrefix   string `json:"toPrefix"`
+}
+
+// MigrateKeys moves up to pageSize assets whose IDs start with fromPrefix so that they are
+// stored under toPrefix instead, together with the internal records indexed by the asset key.
+// The migration is resumable: each call continues from the bookmark returned by the previous one.
+func (s *SmartContract) MigrateKeys(ctx contractapi.TransactionContextInterface, fromPrefix string, toPrefix string, pageSize int32, bookmark string) (*KeyMigrationProgress, error) {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = validateMigration(fromPrefix, toPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize <= 0 {
+		return nil, newError(ErrValidation, map[string]string{"field": "pageSize"}, "the page size must be positive")
+	}
+
+	progress := KeyMigrationProgress{FromPrefix: fromPrefix, ToPrefix: toPrefix}
+	progress.Migrated, progress.Bookmark, err = migrateKeysPage(ctx, fromPrefix, toPrefix, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	progress.Done = progress.Bookmark == ""
+
+	progressJSON, err := json.Marshal(progress)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().SetEvent(migrationEventName, progressJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	return &progress, nil
+}
+
+// validateMigration rejects a migration between prefixes that are empty, equal or overlapping
+func validateMigration(fromPrefix string, toPrefix string) error {
+	if fromPrefix == "" || fromPrefix == toPrefix {
+		return newError(ErrValidation, nil, "a migration requires two distinct key prefixes")
+	}
+	if strings.HasPrefix(toPrefix, fromPrefix) || strings.HasPrefix(fromPrefix, toPrefix) {
+		return newError(ErrValidation, nil, "the key prefixes %s and %s overlap", fromPrefix, toPrefix)
+	}
+
+	return nil
+}
+
+// migrateKeysPage moves up to pageSize assets from fromPrefix to toPrefix, starting at bookmark.
+// It returns the number of assets moved and the ID the next page starts from, which is empty
+// once no assets remain under fromPrefix.
+func migrateKeysPage(ctx contractapi.TransactionContextInterface, fromPrefix string, toPrefix string, pageSize int32, bookmark string) (int32, string, error) {
+	startID := fromPrefix
+	if bookmark != "" {
+		if !strings.HasPrefix(bookmark, fromPrefix) {
+			return 0, "", newError(ErrValidation, map[string]string{"field": "bookmark"}, "the bookmark %s does not belong to the prefix %s", bookmark, fromPrefix)
+		}
+		startID = bookmark
+	}
+
+	// partial composite key queries only match whole attributes and paginated queries are only
+	// available to read-only transactions, so both the prefix and the page are bounded by hand
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetObjectType, []string{})
+	if err != nil {
+		return 0, "", err
+	}
+	defer resultsIterator.Close()
+
+	var migrated int32
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, "", err
+		}
+		id, _, err := assetIDFromKey(ctx, queryResponse.Key)
+		if err != nil {
+			return 0, "", err
+		}
+		if id < startID {
+			continue
+		}
+		if !strings.HasPrefix(id, fromPrefix) {
+			break
+		}
+		if migrated == pageSize {
+			return migrated, id, nil
+		}
+
+		newID := toPrefix + strings.TrimPrefix(id, fromPrefix)
+		err = validateAssetID(newID)
+		if err != nil {
+			return 0, "", err
+		}
+		err = moveAssetKey(ctx, id, newID, queryResponse.Value)
+		if err != nil {
+			return 0, "", err
+		}
+		migrated++
+	}
+
+	return migrated, "", nil
+}
+
+// moveAssetKey rewrites the asset with ID oldID to newID, along with its aliases, transfer records
+// and quota charge
+func moveAssetKey(ctx contractapi.TransactionContextInterface, oldID string, newID string, assetJSON []byte) error {
+	err := assertMutable(ctx, oldID)
+	if err != nil {
+		return err
+	}
+
+	newKey, err := assetKey(ctx, newID)
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(newKey)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return newError(ErrValidation, map[string]string{"id": newID}, "cannot migrate %s: the asset %s already exists", oldID, newID)
+	}
+
+	// the allowlist of an asset is part of its data and stays as it is
+	previous, err := decodeAsset(oldID, assetJSON)
+	if err != nil {
+		return err
+	}
+	asset, err := unmarshalAsset(oldID, assetJSON)
+	if err != nil {
+		return err
+	}
+	asset.ID = newID
+	err = putAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+	oldKey, err := assetKey(ctx, oldID)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(oldKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete from world state: %v", err)
+	}
+	err = reindexAsset(ctx, previous, nil)
+	if err != nil {
+		return err
+	}
+	err = moveAssetAliases(ctx, oldID, newID)
+	if err != nil {
+		return err
+	}
+	err = moveTransfers(ctx, oldID, newID)
+	if err != nil {
+		return err
+	}
+
+	oldChargeKey, err := ctx.GetStub().CreateCompositeKey(quotaChargeObjectType, []string{oldID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	var charge quotaCharge
+	charged, err := getRecord(ctx, oldChargeKey, &charge)
+	if err != nil {
+		return err
+	}
+	if !charged {
+		return nil
+	}
+
+	newChargeKey, err := ctx.GetStub().CreateCompositeKey(quotaChargeObjectType, []string{newID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	err = putRecord(ctx, newChargeKey, charge)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().DelState(oldChargeKey)
+}
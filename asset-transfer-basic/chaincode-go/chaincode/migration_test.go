@@ -0,0 +1,62 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateKeys(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	for _, id := range []string{"www.bbc.co.uk", "www.google.com", "www.napier.ac.uk", "mail.xxx.com"} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "", 1, "", 100))
+		ws.commit()
+	}
+
+	_, err := assetTransfer.MigrateKeys(ws.transactionContext, "www.", "web:www.", 2, "")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err = assetTransfer.MigrateKeys(ws.transactionContext, "www.", "www.b", 2, "")
+	requireError(t, err, chaincode.ErrValidation, "the key prefixes www. and www.b overlap")
+
+	progress, err := assetTransfer.MigrateKeys(ws.transactionContext, "www.", "web:www.", 2, "")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.KeyMigrationProgress{Bookmark: "www.napier.ac.uk", FromPrefix: "www.", Migrated: 2, ToPrefix: "web:www."}, progress)
+
+	name, payload := ws.stub.SetEventArgsForCall(ws.stub.SetEventCallCount() - 1)
+	require.Equal(t, "KeyMigrationProgress", name)
+	var event chaincode.KeyMigrationProgress
+	require.NoError(t, json.Unmarshal(payload, &event))
+	require.Equal(t, *progress, event)
+	ws.commit()
+
+	progress, err = assetTransfer.MigrateKeys(ws.transactionContext, "www.", "web:www.", 2, progress.Bookmark)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.KeyMigrationProgress{Done: true, FromPrefix: "www.", Migrated: 1, ToPrefix: "web:www."}, progress)
+	ws.commit()
+
+	for _, id := range []string{"web:www.bbc.co.uk", "web:www.google.com", "web:www.napier.ac.uk", "mail.xxx.com"} {
+		exists, err := assetTransfer.AssetExists(ws.transactionContext, id)
+		require.NoError(t, err)
+		require.True(t, exists, id)
+	}
+	exists, err := assetTransfer.AssetExists(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.False(t, exists)
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "web:www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "web:www.bbc.co.uk", asset.ID)
+	require.Equal(t, "www.bbc.co.uk", asset.Allowlist)
+
+	// the quota charge moved with the asset, so deleting it under the new key releases the quota
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "web:www.bbc.co.uk"))
+	ws.commit()
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 3, usage.Assets)
+}
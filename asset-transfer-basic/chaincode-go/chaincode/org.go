@@ -0,0 +1,381 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const orgObjectType = "org"
+
+// Organization lifecycle states
+const (
+	OrgActive     = "ACTIVE"
+	OrgOffboarded = "OFFBOARDED"
+)
+
+// Actions OffboardOrg may take on the assets created by a departing organization
+const (
+	OffboardFreeze   = "freeze"
+	OffboardReassign = "reassign"
+)
+
+// Organization records a consortium member onboarded through OnboardOrg
+type Organization struct {
+	MSPID     string    `json:"mspID"`
+	Roles     []string  `json:"roles"`
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SetOrgLifecyclePolicy configures the default quota given to onboarded organizations and what
+// happens to the assets of offboarded ones. Assets are either frozen, or reassigned to the
+// organization reassignTo.
+func (s *SmartContract) SetOrgLifecyclePolicy(ctx contractapi.TransactionContextInterface, defaultMaxAssets int, defaultMaxEntries int, offboardAction string, reassignTo string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if defaultMaxAssets < 0 || defaultMaxEntries < 0 {
+		return newError(ErrValidation, nil, "quota limits must not be negative")
+	}
+	switch offboardAction {
+	case OffboardFreeze:
+		if reassignTo != "" {
+			return newError(ErrValidation, map[string]string{"field": "reassignTo"}, "assets are only reassigned by the %s action", OffboardReassign)
+		}
+	case OffboardReassign:
+		if reassignTo == "" {
+			return newError(ErrValidation, map[string]string{"field": "reassignTo"}, "the %s action requires an organization to reassign assets to", OffboardReassign)
+		}
+	default:
+		return newError(ErrValidation, map[string]string{"field": "offboardAction"}, "the offboard action must be %s or %s", OffboardFreeze, OffboardReassign)
+	}
+
+	config, err := readConfig(ctx)
+	if err != nil {
+		return err
+	}
+	config.DefaultMaxAssets = defaultMaxAssets
+	config.DefaultMaxEntries = defaultMaxEntries
+	config.OffboardAction = offboardAction
+	config.OffboardReassignTo = reassignTo
+
+	return putConfig(ctx, config)
+}
+
+// OnboardOrg admits an organization to the consortium. It receives the default quota, and
+// rolesJSON, a JSON array of role names, grants it every restricted function permitted to one
+// of those roles.
+func (s *SmartContract) OnboardOrg(ctx contractapi.TransactionContextInterface, mspID string, rolesJSON string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if mspID == "" {
+		return newError(ErrValidation, map[string]string{"field": "mspID"}, "an MSP ID is required")
+	}
+
+	roles := []string{}
+	if rolesJSON != "" {
+		err = json.Unmarshal([]byte(rolesJSON), &roles)
+		if err != nil {
+			return fmt.Errorf("the roles must be a JSON array of strings: %v", err)
+		}
+	}
+
+	org, err := readOrganization(ctx, mspID)
+	if err != nil {
+		return err
+	}
+	if org != nil && org.Status == OrgActive {
+		return newError(ErrValidation, map[string]string{"mspID": mspID}, "the organization %s is already onboarded", mspID)
+	}
+
+	config, err := readConfig(ctx)
+	if err != nil {
+		return err
+	}
+	err = s.SetQuota(ctx, mspID, config.DefaultMaxAssets, config.DefaultMaxEntries)
+	if err != nil {
+		return err
+	}
+
+	err = updatePermissions(ctx, func(permission *FunctionPermission) bool {
+		for _, role := range roles {
+			if stringInSlice(role, permission.Roles) && !stringInSlice(mspID, permission.MSPIDs) {
+				permission.MSPIDs = append(permission.MSPIDs, mspID)
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
+
+	return putOrganization(ctx, &Organization{MSPID: mspID, Roles: roles, Status: OrgActive})
+}
+
+// OffboardOrg removes an organization from the consortium. Its function permissions are revoked
+// and the assets it created are frozen or reassigned according to the lifecycle policy.
+// A restricted function that only the organization could invoke stays closed.
+func (s *SmartContract) OffboardOrg(ctx contractapi.TransactionContextInterface, mspID string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	org, err := readOrganization(ctx, mspID)
+	if err != nil {
+		return err
+	}
+	if org == nil || org.Status != OrgActive {
+		return newError(ErrNotFound, map[string]string{"mspID": mspID}, "the organization %s is not onboarded", mspID)
+	}
+
+	config, err := readConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config.OffboardAction == "" {
+		return newError(ErrValidation, nil, "no offboard action has been configured")
+	}
+	if config.OffboardReassignTo == mspID {
+		return newError(ErrValidation, map[string]string{"mspID": mspID}, "the assets of %s cannot be reassigned to itself", mspID)
+	}
+
+	err = updatePermissions(ctx, func(permission *FunctionPermission) bool {
+		remaining := []string{}
+		for _, permitted := range permission.MSPIDs {
+			if permitted != mspID {
+				remaining = append(remaining, permitted)
+			}
+		}
+		changed := len(remaining) != len(permission.MSPIDs)
+		permission.MSPIDs = remaining
+		return changed
+	})
+	if err != nil {
+		return err
+	}
+
+	assetIDs, err := chargedAssets(ctx, mspID)
+	if err != nil {
+		return err
+	}
+	reassigned := &quotaCharge{MSPID: config.OffboardReassignTo}
+	reassignedAssets := 0
+	for _, id := range assetIDs {
+		if config.OffboardAction == OffboardFreeze {
+			err = freezeAsset(ctx, id)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		// immutable assets keep their owner
+		immutability, err := readImmutability(ctx, id)
+		if err != nil {
+			return err
+		}
+		if immutability != nil {
+			continue
+		}
+		err = s.reassignAsset(ctx, id, reassigned)
+		if err != nil {
+			return err
+		}
+		reassignedAssets++
+	}
+	if reassignedAssets > 0 {
+		err = moveQuotaUsage(ctx, mspID, config.OffboardReassignTo, reassignedAssets, reassigned.Entries)
+		if err != nil {
+			return err
+		}
+	}
+
+	org.Status = OrgOffboarded
+	return putOrganization(ctx, org)
+}
+
+// ReadOrganization returns the onboarding record of the organization with given MSP ID
+func (s *SmartContract) ReadOrganization(ctx contractapi.TransactionContextInterface, mspID string) (*Organization, error) {
+	org, err := readOrganization(ctx, mspID)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, newError(ErrNotFound, map[string]string{"mspID": mspID}, "the organization %s has not been onboarded", mspID)
+	}
+
+	return org, nil
+}
+
+func readOrganization(ctx contractapi.TransactionContextInterface, mspID string) (*Organization, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(orgObjectType, []string{mspID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var org Organization
+	exists, err := getRecord(ctx, key, &org)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	return &org, nil
+}
+
+func putOrganization(ctx contractapi.TransactionContextInterface, org *Organization) error {
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	org.UpdatedAt = now
+
+	key, err := ctx.GetStub().CreateCompositeKey(orgObjectType, []string{org.MSPID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, org)
+}
+
+// updatePermissions applies update to every function permission and stores those it reports as changed
+func updatePermissions(ctx contractapi.TransactionContextInterface, update func(*FunctionPermission) bool) error {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(permissionObjectType, []string{})
+	if err != nil {
+		return err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+
+		var permission FunctionPermission
+		err = json.Unmarshal(queryResponse.Value, &permission)
+		if err != nil {
+			return err
+		}
+		if !update(&permission) {
+			continue
+		}
+
+		err = putRecord(ctx, queryResponse.Key, permission)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// chargedAssets returns the IDs of the assets charged to the quota of mspID
+func chargedAssets(ctx contractapi.TransactionContextInterface, mspID string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(quotaChargeObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var ids []string
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var charge quotaCharge
+		err = json.Unmarshal(queryResponse.Value, &charge)
+		if err != nil {
+			return nil, err
+		}
+		if charge.MSPID != mspID {
+			continue
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %v", err)
+		}
+		ids = append(ids, attributes[0])
+	}
+
+	return ids, nil
+}
+
+// reassignAsset transfers an asset to the organization named by charge, moving its quota charge
+// along with it, and adds the entries charged for the asset to charge
+func (s *SmartContract) reassignAsset(ctx contractapi.TransactionContextInterface, id string, charge *quotaCharge) error {
+	// expired assets that have not been pruned yet are reassigned too
+	asset, err := readStoredAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+	asset.Attribute1 = charge.MSPID
+	asset.Owner = charge.MSPID
+	err = putAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+	err = recordModification(ctx, id, operationTransfer)
+	if err != nil {
+		return err
+	}
+
+	chargeKey, err := ctx.GetStub().CreateCompositeKey(quotaChargeObjectType, []string{id})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	var assetCharge quotaCharge
+	_, err = getRecord(ctx, chargeKey, &assetCharge)
+	if err != nil {
+		return err
+	}
+	charge.Entries += assetCharge.Entries
+
+	assetCharge.MSPID = charge.MSPID
+	return putRecord(ctx, chargeKey, assetCharge)
+}
+
+// moveQuotaUsage moves the given number of assets and entries from one organization's usage to
+// another's. The receiving organization's quota is not enforced, so that offboarding cannot fail
+// part way.
+func moveQuotaUsage(ctx contractapi.TransactionContextInterface, fromMSPID string, toMSPID string, assets int, entries int) error {
+	from, err := readQuotaUsage(ctx, fromMSPID)
+	if err != nil {
+		return err
+	}
+	to, err := readQuotaUsage(ctx, toMSPID)
+	if err != nil {
+		return err
+	}
+
+	from.Assets -= assets
+	from.Entries -= entries
+	if from.Assets < 0 {
+		from.Assets = 0
+	}
+	if from.Entries < 0 {
+		from.Entries = 0
+	}
+	to.Assets += assets
+	to.Entries += entries
+
+	err = putQuotaUsage(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	return putQuotaUsage(ctx, to)
+}
@@ -0,0 +1,169 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetOrgLifecyclePolicy(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	err := assetTransfer.SetOrgLifecyclePolicy(ws.transactionContext, 10, 20, chaincode.OffboardFreeze, "")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.SetOrgLifecyclePolicy(ws.transactionContext, 10, 20, "delete", "")
+	requireError(t, err, chaincode.ErrValidation, "the offboard action must be freeze or reassign")
+
+	err = assetTransfer.SetOrgLifecyclePolicy(ws.transactionContext, 10, 20, chaincode.OffboardReassign, "")
+	requireError(t, err, chaincode.ErrValidation, "the reassign action requires an organization to reassign assets to")
+
+	require.NoError(t, assetTransfer.SetOrgLifecyclePolicy(ws.transactionContext, 10, 20, chaincode.OffboardReassign, "Org1MSP"))
+	ws.commit()
+
+	config, err := assetTransfer.GetConfig(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Config{DefaultMaxAssets: 10, DefaultMaxEntries: 20, OffboardAction: "reassign", OffboardReassignTo: "Org1MSP"}, config)
+}
+
+func TestOnboardOrg(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetOrgLifecyclePolicy(ws.transactionContext, 10, 20, chaincode.OffboardFreeze, ""))
+	require.NoError(t, assetTransfer.SetFunctionPermission(ws.transactionContext, "DeleteAsset", []string{"Org1MSP"}, []string{"curator"}))
+	ws.commit()
+
+	err := assetTransfer.OnboardOrg(ws.transactionContext, "Org2MSP", `"curator"`)
+	require.EqualError(t, err, "the roles must be a JSON array of strings: json: cannot unmarshal string into Go value of type []string")
+
+	require.NoError(t, assetTransfer.OnboardOrg(ws.transactionContext, "Org2MSP", `["curator"]`))
+	ws.commit()
+
+	err = assetTransfer.OnboardOrg(ws.transactionContext, "Org2MSP", `["curator"]`)
+	requireError(t, err, chaincode.ErrValidation, "the organization Org2MSP is already onboarded")
+
+	org, err := assetTransfer.ReadOrganization(ws.transactionContext, "Org2MSP")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.OrgActive, org.Status)
+	require.Equal(t, []string{"curator"}, org.Roles)
+
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org2MSP")
+	require.NoError(t, err)
+	require.Equal(t, 10, usage.MaxAssets)
+	require.Equal(t, 20, usage.MaxEntries)
+
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, beforeTransaction(t, &assetTransfer, ws, "DeleteAsset"))
+
+	_, err = assetTransfer.ReadOrganization(ws.transactionContext, "Org3MSP")
+	requireError(t, err, chaincode.ErrNotFound, "the organization Org3MSP has not been onboarded")
+}
+
+func TestOffboardOrgFreezesAssets(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetFunctionPermission(ws.transactionContext, "DeleteAsset", []string{"Org1MSP"}, []string{"curator"}))
+	ws.commit()
+	require.NoError(t, assetTransfer.OnboardOrg(ws.transactionContext, "Org2MSP", `["curator"]`))
+	ws.commit()
+
+	err := assetTransfer.OffboardOrg(ws.transactionContext, "Org2MSP")
+	requireError(t, err, chaincode.ErrValidation, "no offboard action has been configured")
+
+	require.NoError(t, assetTransfer.SetOrgLifecyclePolicy(ws.transactionContext, 0, 0, chaincode.OffboardFreeze, ""))
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.OffboardOrg(ws.transactionContext, "Org2MSP"))
+	ws.commit()
+
+	err = assetTransfer.OffboardOrg(ws.transactionContext, "Org2MSP")
+	requireError(t, err, chaincode.ErrNotFound, "the organization Org2MSP is not onboarded")
+
+	immutability, err := assetTransfer.GetAssetImmutability(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.NotNil(t, immutability)
+
+	ws.setClient("Org2MSP", "user2", false)
+	err = beforeTransaction(t, &assetTransfer, ws, "DeleteAsset")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to invoke DeleteAsset")
+}
+
+func TestOffboardOrgReassignsAssets(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetOrgLifecyclePolicy(ws.transactionContext, 0, 0, chaincode.OffboardReassign, "Org1MSP"))
+	ws.commit()
+	require.NoError(t, assetTransfer.OnboardOrg(ws.transactionContext, "Org2MSP", ""))
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.google.com", "", 1, "", 100))
+	ws.commit()
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.OffboardOrg(ws.transactionContext, "Org2MSP"))
+	ws.commit()
+
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org2MSP")
+	require.NoError(t, err)
+	require.Equal(t, 0, usage.Assets)
+	require.Equal(t, 0, usage.Entries)
+
+	usage, err = assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 2, usage.Assets)
+	require.Equal(t, 3, usage.Entries)
+
+	modification, err := assetTransfer.GetLastModification(ws.transactionContext, "www.google.com")
+	require.NoError(t, err)
+	require.Equal(t, "transfer", modification.Operation)
+	require.Equal(t, "admin", modification.SubmitterID)
+}
+
+func TestOffboardOrgReassignsExpiredAssets(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetOrgLifecyclePolicy(ws.transactionContext, 0, 0, chaincode.OffboardReassign, "Org1MSP"))
+	ws.commit()
+	require.NoError(t, assetTransfer.OnboardOrg(ws.transactionContext, "Org2MSP", ""))
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 1, "Org2MSP", 100))
+	require.NoError(t, assetTransfer.SetAssetExpiry(ws.transactionContext, "www.bbc.co.uk", "2021-03-01T10:00:00Z"))
+	ws.commit()
+
+	// the asset has expired but has not been pruned
+	ws.now = time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.OffboardOrg(ws.transactionContext, "Org2MSP"))
+	ws.commit()
+
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 1, usage.Assets)
+
+	var asset chaincode.Asset
+	require.NoError(t, json.Unmarshal(ws.state[assetKey("www.bbc.co.uk")], &asset))
+	require.Equal(t, "Org1MSP", asset.Attribute1)
+	require.Equal(t, "Org1MSP", asset.Owner)
+}
@@ -0,0 +1,111 @@
+package chaincode
+
+import (
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Size limits applied while no limits have been configured. They stay well below the default
+// gRPC message size of the peer, so oversized requests fail with a descriptive error instead.
+const (
+	defaultMaxArgumentBytes = 256 * 1024
+	defaultMaxValueBytes    = 1024 * 1024
+)
+
+// payloadLimits holds the size limits in force for a transaction
+type payloadLimits struct {
+	maxArgumentBytes int
+	maxBatchEntries  int
+	maxValueBytes    int
+}
+
+// SetPayloadLimits configures the maximum size in bytes of a transaction argument, the maximum
+// number of entries in a batch and the maximum size in bytes of a value stored in the world state
+func (s *SmartContract) SetPayloadLimits(ctx contractapi.TransactionContextInterface, maxArgumentBytes int, maxBatchEntries int, maxValueBytes int) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if maxArgumentBytes < 1 || maxBatchEntries < 1 || maxValueBytes < 1 {
+		return newError(ErrValidation, nil, "payload limits must be positive")
+	}
+	if maxBatchEntries > maxBulkRecords {
+		return newError(ErrValidation, map[string]string{"field": "maxBatchEntries"}, "the batch entry limit must not exceed %d", maxBulkRecords)
+	}
+
+	config, err := readConfig(ctx)
+	if err != nil {
+		return err
+	}
+	config.MaxArgumentBytes = maxArgumentBytes
+	config.MaxBatchEntries = maxBatchEntries
+	config.MaxValueBytes = maxValueBytes
+
+	return putConfig(ctx, config)
+}
+
+func readPayloadLimits(ctx contractapi.TransactionContextInterface) (*payloadLimits, error) {
+	config, err := readConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := &payloadLimits{
+		maxArgumentBytes: config.MaxArgumentBytes,
+		maxBatchEntries:  config.MaxBatchEntries,
+		maxValueBytes:    config.MaxValueBytes,
+	}
+	if limits.maxArgumentBytes == 0 {
+		limits.maxArgumentBytes = defaultMaxArgumentBytes
+	}
+	if limits.maxBatchEntries == 0 {
+		limits.maxBatchEntries = maxBulkRecords
+	}
+	if limits.maxValueBytes == 0 {
+		limits.maxValueBytes = defaultMaxValueBytes
+	}
+
+	return limits, nil
+}
+
+// assertArgumentSizes rejects the named transaction if any of its arguments exceeds the size limit
+func assertArgumentSizes(ctx contractapi.TransactionContextInterface, function string) error {
+	limits, err := readPayloadLimits(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, params := ctx.GetStub().GetFunctionAndParameters()
+	for i, param := range params {
+		if len(param) > limits.maxArgumentBytes {
+			return payloadTooLargeError(limits.maxArgumentBytes, len(param), "argument %d of %s is %d bytes, exceeding the limit of %d bytes", i+1, function, len(param), limits.maxArgumentBytes)
+		}
+	}
+
+	return nil
+}
+
+// assertBatchSize rejects a batch of more entries than the configured limit
+func (l *payloadLimits) assertBatchSize(entries int) error {
+	if entries > l.maxBatchEntries {
+		return payloadTooLargeError(l.maxBatchEntries, entries, "the batch of %d entries exceeds the limit of %d entries", entries, l.maxBatchEntries)
+	}
+
+	return nil
+}
+
+// assertValueSize rejects a value too large to be stored in the world state
+func (l *payloadLimits) assertValueSize(size int) error {
+	if size > l.maxValueBytes {
+		return payloadTooLargeError(l.maxValueBytes, size, "the value of %d bytes exceeds the limit of %d bytes on stored values", size, l.maxValueBytes)
+	}
+
+	return nil
+}
+
+// payloadTooLargeError returns an ErrPayloadTooLarge error whose details hold the limit and
+// the size that exceeded it
+func payloadTooLargeError(limit int, size int, format string, args ...interface{}) error {
+	return newError(ErrPayloadTooLarge, map[string]string{"limit": strconv.Itoa(limit), "size": strconv.Itoa(size)}, format, args...)
+}
@@ -0,0 +1,79 @@
+package chaincode_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPayloadLimits(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	capabilities, err := assetTransfer.GetCapabilities(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, 256*1024, capabilities.Limits["maxArgumentBytes"])
+	require.Equal(t, 100, capabilities.Limits["maxBulkRecords"])
+	require.Equal(t, 1024*1024, capabilities.Limits["maxValueBytes"])
+
+	err = assetTransfer.SetPayloadLimits(ws.transactionContext, 1024, 2, 200)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.SetPayloadLimits(ws.transactionContext, 1024, 0, 200)
+	requireError(t, err, chaincode.ErrValidation, "payload limits must be positive")
+
+	err = assetTransfer.SetPayloadLimits(ws.transactionContext, 1024, 101, 200)
+	requireError(t, err, chaincode.ErrValidation, "the batch entry limit must not exceed 100")
+
+	require.NoError(t, assetTransfer.SetPayloadLimits(ws.transactionContext, 1024, 2, 200))
+	ws.commit()
+
+	capabilities, err = assetTransfer.GetCapabilities(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, 1024, capabilities.Limits["maxArgumentBytes"])
+	require.Equal(t, 2, capabilities.Limits["maxBulkRecords"])
+	require.Equal(t, 200, capabilities.Limits["maxValueBytes"])
+}
+
+func TestPayloadLimits(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetPayloadLimits(ws.transactionContext, 1024, 2, 300))
+	ws.commit()
+
+	before, ok := assetTransfer.GetBeforeTransaction().(func(contractapi.TransactionContextInterface) error)
+	require.True(t, ok)
+	ws.stub.GetFunctionAndParametersReturns("CreateAsset", []string{"www.bbc.co.uk", strings.Repeat("x", 1025)})
+	err := before(ws.transactionContext)
+	requireError(t, err, chaincode.ErrPayloadTooLarge, "argument 2 of CreateAsset is 1025 bytes, exceeding the limit of 1024 bytes")
+	var coded *chaincode.Error
+	require.True(t, errors.As(err, &coded))
+	require.Equal(t, map[string]string{"limit": "1024", "size": "1025"}, coded.Details)
+
+	ws.stub.GetFunctionAndParametersReturns("ReadAsset", []string{strings.Repeat("x", 1025)})
+	err = before(ws.transactionContext)
+	requireError(t, err, chaincode.ErrPayloadTooLarge, "argument 1 of ReadAsset is 1025 bytes, exceeding the limit of 1024 bytes")
+
+	_, err = assetTransfer.CreateAssets(ws.transactionContext, `[{"allowlist":"www.bbc.co.uk"},{"allowlist":"www.google.com"},{"allowlist":"www.yahoo.com"}]`)
+	requireError(t, err, chaincode.ErrPayloadTooLarge, "the batch of 3 entries exceeds the limit of 2 entries")
+	require.True(t, errors.As(err, &coded))
+	require.Equal(t, map[string]string{"limit": "2", "size": "3"}, coded.Details)
+
+	_, err = assetTransfer.UpdateAssetsWhere(ws.transactionContext, "{}", `{"attribute2":0}`, 3)
+	requireError(t, err, chaincode.ErrValidation, "maxRecords must be between 1 and 2")
+
+	err = assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", strings.Repeat(strings.Repeat("x", 63)+".", 3)+"com", 1, "", 100)
+	requireError(t, err, chaincode.ErrPayloadTooLarge, "the value of 450 bytes exceeds the limit of 300 bytes on stored values")
+	require.True(t, errors.As(err, &coded))
+	require.Equal(t, map[string]string{"limit": "300", "size": "450"}, coded.Details)
+	ws.rollback()
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 1, "", 100))
+}
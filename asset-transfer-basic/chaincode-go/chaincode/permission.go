@@ -0,0 +1,217 @@
+package chaincode
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const permissionObjectType = "permission"
+
+// roleAttribute is the certificate attribute holding a comma separated list of the client's roles
+const roleAttribute = "webfilter.role"
+
+// AdminRole is held by every client carrying the admin attribute
+const AdminRole = "admin"
+
+// unrestrictedFunctions manage the permission matrix itself. They are always guarded by the
+// admin check and cannot be restricted, so administrators cannot lock themselves out.
+var unrestrictedFunctions = []string{"RemoveFunctionPermission", "SetFunctionPermission"}
+
+// FunctionPermission restricts who may invoke a contract function. A client may invoke it if
+// its organization is listed in MSPIDs or it holds one of the listed Roles. Functions without
+// a permission are open to every client, subject to the checks made by the function itself.
+type FunctionPermission struct {
+	Function string   `json:"function"`
+	MSPIDs   []string `json:"mspIDs"`
+	Roles    []string `json:"roles"`
+}
+
+// SetFunctionPermission restricts the given function to the listed organizations and roles
+func (s *SmartContract) SetFunctionPermission(ctx contractapi.TransactionContextInterface, function string, mspIDs []string, roles []string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if !stringInSlice(function, contractFunctions()) {
+		return newError(ErrValidation, map[string]string{"field": "function"}, "the contract has no function %s", function)
+	}
+	if stringInSlice(function, unrestrictedFunctions) {
+		return newError(ErrValidation, map[string]string{"field": "function"}, "the permissions of %s cannot be restricted", function)
+	}
+	if len(mspIDs) == 0 && len(roles) == 0 {
+		return newError(ErrValidation, nil, "a permission must allow at least one organization or role")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(permissionObjectType, []string{function})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, FunctionPermission{Function: function, MSPIDs: mspIDs, Roles: roles})
+}
+
+// RemoveFunctionPermission opens the given function to every client again
+func (s *SmartContract) RemoveFunctionPermission(ctx contractapi.TransactionContextInterface, function string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(permissionObjectType, []string{function})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var permission FunctionPermission
+	exists, err := getRecord(ctx, key, &permission)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return newError(ErrNotFound, map[string]string{"function": function}, "the function %s has no permission", function)
+	}
+
+	err = ctx.GetStub().DelState(key)
+	if err != nil {
+		return fmt.Errorf("failed to delete from world state: %v", err)
+	}
+
+	return nil
+}
+
+// GetEffectivePermissions returns the contract functions the submitting client may invoke, as
+// both the permission matrix and its registry role allow them
+func (s *SmartContract) GetEffectivePermissions(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	role, err := effectiveRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permitted := []string{}
+	for _, function := range contractFunctions() {
+		allowed, err := isPermitted(ctx, function)
+		if err != nil {
+			return nil, err
+		}
+		if allowed && roleAllows(role, function) {
+			permitted = append(permitted, function)
+		}
+	}
+
+	return permitted, nil
+}
+
+// assertPermitted returns an error unless the permission matrix lets the submitting client invoke function
+func assertPermitted(ctx contractapi.TransactionContextInterface, function string) error {
+	allowed, err := isPermitted(ctx, function)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return newError(ErrUnauthorized, map[string]string{"function": function}, "the client is not authorized to invoke %s", function)
+	}
+
+	return nil
+}
+
+func isPermitted(ctx contractapi.TransactionContextInterface, function string) (bool, error) {
+	if stringInSlice(function, unrestrictedFunctions) {
+		return true, nil
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(permissionObjectType, []string{function})
+	if err != nil {
+		return false, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var permission FunctionPermission
+	exists, err := getRecord(ctx, key, &permission)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return true, nil
+	}
+
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return false, err
+	}
+	if stringInSlice(mspID, permission.MSPIDs) {
+		return true, nil
+	}
+
+	roles, err := callerRoles(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if stringInSlice(role, permission.Roles) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// callerRoles returns the roles held by the submitting client, from its certificate and the
+// role registry
+func callerRoles(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	value, found, err := ctx.GetClientIdentity().GetAttributeValue(roleAttribute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client attribute %s: %v", roleAttribute, err)
+	}
+
+	var roles []string
+	if found {
+		for _, role := range strings.Split(value, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				roles = append(roles, role)
+			}
+		}
+	}
+	role, err := registeredRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if role != "" {
+		roles = append(roles, role)
+	}
+	if role != AdminRole && assertAdmin(ctx) == nil {
+		roles = append(roles, AdminRole)
+	}
+
+	return roles, nil
+}
+
+// contractFunctions returns the names of the transactions exposed by SmartContract, sorted.
+// Like the contract API, it leaves out the methods of the contract interfaces.
+func contractFunctions() []string {
+	interfaceTypes := []reflect.Type{
+		reflect.TypeOf((*contractapi.ContractInterface)(nil)).Elem(),
+		reflect.TypeOf((*contractapi.IgnoreContractInterface)(nil)).Elem(),
+		reflect.TypeOf((*contractapi.EvaluationContractInterface)(nil)).Elem(),
+	}
+
+	contractType := reflect.TypeOf(&SmartContract{})
+	functions := []string{}
+	for i := 0; i < contractType.NumMethod(); i++ {
+		name := contractType.Method(i).Name
+		excluded := false
+		for _, interfaceType := range interfaceTypes {
+			if _, ok := interfaceType.MethodByName(name); ok {
+				excluded = true
+			}
+		}
+		if !excluded {
+			functions = append(functions, name)
+		}
+	}
+	sort.Strings(functions)
+
+	return functions
+}
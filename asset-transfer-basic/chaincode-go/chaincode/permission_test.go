@@ -0,0 +1,76 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetFunctionPermission(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	err := assetTransfer.SetFunctionPermission(ws.transactionContext, "DeleteAsset", []string{"Org1MSP"}, nil)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.SetFunctionPermission(ws.transactionContext, "GetBeforeTransaction", []string{"Org1MSP"}, nil)
+	requireError(t, err, chaincode.ErrValidation, "the contract has no function GetBeforeTransaction")
+
+	err = assetTransfer.SetFunctionPermission(ws.transactionContext, "SetFunctionPermission", []string{"Org1MSP"}, nil)
+	requireError(t, err, chaincode.ErrValidation, "the permissions of SetFunctionPermission cannot be restricted")
+
+	err = assetTransfer.SetFunctionPermission(ws.transactionContext, "DeleteAsset", nil, nil)
+	requireError(t, err, chaincode.ErrValidation, "a permission must allow at least one organization or role")
+
+	require.NoError(t, assetTransfer.SetFunctionPermission(ws.transactionContext, "DeleteAsset", []string{"Org1MSP"}, []string{"auditor"}))
+	ws.commit()
+
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "DeleteAsset"))
+
+	ws.setClient("Org2MSP", "user2", false)
+	err = beforeTransaction(t, assetTransfer, ws, "SmartContract:DeleteAsset")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to invoke DeleteAsset")
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+
+	ws.clientIdentity.GetAttributeValueReturns("viewer, auditor", true, nil)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "DeleteAsset"))
+	ws.clientIdentity.GetAttributeValueReturns("", false, nil)
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.RemoveFunctionPermission(ws.transactionContext, "DeleteAsset"))
+	ws.commit()
+
+	err = assetTransfer.RemoveFunctionPermission(ws.transactionContext, "DeleteAsset")
+	requireError(t, err, chaincode.ErrNotFound, "the function DeleteAsset has no permission")
+
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "DeleteAsset"))
+}
+
+func TestGetEffectivePermissions(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	permitted, err := assetTransfer.GetEffectivePermissions(ws.transactionContext)
+	require.NoError(t, err)
+	require.Contains(t, permitted, "CreateAsset")
+	require.Contains(t, permitted, "SetFunctionPermission")
+	require.NotContains(t, permitted, "GetEvaluateTransactions")
+	require.NotContains(t, permitted, "GetTransactionContextHandler")
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetFunctionPermission(ws.transactionContext, "CreateAsset", nil, []string{chaincode.AdminRole}))
+	ws.commit()
+
+	permitted, err = assetTransfer.GetEffectivePermissions(ws.transactionContext)
+	require.NoError(t, err)
+	require.Contains(t, permitted, "CreateAsset")
+
+	ws.setClient("Org1MSP", "user1", false)
+	permitted, err = assetTransfer.GetEffectivePermissions(ws.transactionContext)
+	require.NoError(t, err)
+	require.NotContains(t, permitted, "CreateAsset")
+	require.Contains(t, permitted, "ReadAsset")
+}
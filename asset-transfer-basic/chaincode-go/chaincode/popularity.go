@@ -0,0 +1,211 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const hitObjectType = "hits"
+
+// hitCounterShards spreads the hit counter of each list over several keys, so that filters
+// reporting hits for a popular list concurrently rarely conflict
+const hitCounterShards = 16
+
+// maxActivityPageSize caps the number of lists returned by one page of GetListActivityReport
+const maxActivityPageSize = 100
+
+type hitCounter struct {
+	Count     uint64    `json:"count"`
+	LastHitAt time.Time `json:"lastHitAt"`
+}
+
+// ListActivity combines the hit count, last modification and watchers of a list. A list is
+// Unused when it was never hit and nobody watches it, and Stale when it was neither hit nor
+// modified within the report's window.
+type ListActivity struct {
+	Hits           uint64    `json:"hits"`
+	ID             string    `json:"id"`
+	LastHitAt      time.Time `json:"lastHitAt"`
+	LastModifiedAt time.Time `json:"lastModifiedAt"`
+	Stale          bool      `json:"stale"`
+	Unused         bool      `json:"unused"`
+	Watchers       int       `json:"watchers"`
+}
+
+// ListActivityReport is the response of GetListActivityReport. Bookmark is empty on the last page.
+type ListActivityReport struct {
+	Bookmark string          `json:"bookmark"`
+	Lists    []*ListActivity `json:"lists"`
+	Metadata QueryMetadata   `json:"metadata"`
+}
+
+// RecordListHits adds the hits reported by a web filter to the counters of the lists named in
+// hitsJSON, a JSON object mapping asset IDs to hit counts such as {"www.bbc.co.uk":12}
+func (s *SmartContract) RecordListHits(ctx contractapi.TransactionContextInterface, hitsJSON string) error {
+	hits := map[string]uint64{}
+	err := json.Unmarshal([]byte(hitsJSON), &hits)
+	if err != nil {
+		return fmt.Errorf("invalid hit counts: %v", err)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	shard := txShard(ctx, hitCounterShards)
+
+	ids := make([]string, 0, len(hits))
+	for id, count := range hits {
+		if count > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		exists, err := s.AssetExists(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return assetNotFoundError(id)
+		}
+
+		key, err := ctx.GetStub().CreateCompositeKey(hitObjectType, []string{id, shard})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %v", err)
+		}
+		var counter hitCounter
+		_, err = getRecord(ctx, key, &counter)
+		if err != nil {
+			return err
+		}
+		counter.Count += hits[id]
+		counter.LastHitAt = now
+
+		err = putRecord(ctx, key, counter)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetListActivityReport returns one page of lists, in key order, with their hit counts, last
+// modification and number of watchers, flagging lists that had no hits and no edits in the
+// last staleDays days. Pass the returned bookmark to fetch the next page.
+func (s *SmartContract) GetListActivityReport(ctx contractapi.TransactionContextInterface, staleDays int, pageSize int, bookmark string) (*ListActivityReport, error) {
+	if staleDays < 1 {
+		return nil, newError(ErrValidation, map[string]string{"field": "staleDays"}, "the staleness window must be at least one day")
+	}
+	if pageSize < 1 || pageSize > maxActivityPageSize {
+		return nil, newError(ErrValidation, map[string]string{"field": "pageSize"}, "the page size must be between 1 and %d", maxActivityPageSize)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := now.AddDate(0, 0, -staleDays)
+
+	watches, err := queryWatches(ctx, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, responseMetadata, err := getAssetsWithPagination(ctx, int32(pageSize), bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := []*ListActivity{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, err
+		}
+
+		id, _, err := assetIDFromKey(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		activity, err := listActivity(ctx, id, watches)
+		if err != nil {
+			return nil, err
+		}
+		activity.Unused = activity.Hits == 0 && activity.Watchers == 0
+		activity.Stale = activity.LastHitAt.Before(cutoff) && activity.LastModifiedAt.Before(cutoff)
+		lists = append(lists, activity)
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata.Cost = &meter.cost
+
+	return &ListActivityReport{Bookmark: responseMetadata.Bookmark, Lists: lists, Metadata: metadata}, nil
+}
+
+// listActivity gathers the hit counter shards, last modification and watchers of the list with given id
+func listActivity(ctx contractapi.TransactionContextInterface, id string, watches []*Watch) (*ListActivity, error) {
+	activity := &ListActivity{ID: id}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(hitObjectType, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var counter hitCounter
+		err = json.Unmarshal(queryResponse.Value, &counter)
+		if err != nil {
+			return nil, err
+		}
+		activity.Hits += counter.Count
+		if counter.LastHitAt.After(activity.LastHitAt) {
+			activity.LastHitAt = counter.LastHitAt
+		}
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(lastModificationObjectType, []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+	var modification LastModification
+	_, err = getRecord(ctx, key, &modification)
+	if err != nil {
+		return nil, err
+	}
+	activity.LastModifiedAt = modification.Timestamp
+
+	for _, watch := range watches {
+		if watch.matches(id) {
+			activity.Watchers++
+		}
+	}
+
+	return activity, nil
+}
@@ -0,0 +1,65 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetListActivityReport(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	created := ws.now
+	for _, id := range []string{"www.aaa.com", "www.bbb.com", "www.ccc.com", "www.ddd.com"} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "", 1, "", 100))
+	}
+	require.NoError(t, assetTransfer.RegisterWatch(ws.transactionContext, "www.ccc.*"))
+	ws.commit()
+
+	firstHit := ws.now
+	require.NoError(t, assetTransfer.RecordListHits(ws.transactionContext, `{"www.aaa.com":3,"www.bbb.com":0}`))
+	ws.commit()
+	require.NoError(t, assetTransfer.RecordListHits(ws.transactionContext, `{"www.aaa.com":2}`))
+	ws.commit()
+
+	err := assetTransfer.RecordListHits(ws.transactionContext, `{"www.eee.com":1}`)
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.eee.com does not exist")
+	ws.rollback()
+
+	ws.now = ws.now.Add(40 * 24 * time.Hour)
+	secondHit := ws.now
+	require.NoError(t, assetTransfer.RecordListHits(ws.transactionContext, `{"www.bbb.com":1}`))
+	ws.commit()
+
+	_, err = assetTransfer.GetListActivityReport(ws.transactionContext, 0, 10, "")
+	requireError(t, err, chaincode.ErrValidation, "the staleness window must be at least one day")
+	_, err = assetTransfer.GetListActivityReport(ws.transactionContext, 30, 101, "")
+	requireError(t, err, chaincode.ErrValidation, "the page size must be between 1 and 100")
+
+	report, err := assetTransfer.GetListActivityReport(ws.transactionContext, 30, 3, "")
+	require.NoError(t, err)
+	require.Equal(t, "www.ddd.com", report.Bookmark)
+	require.Equal(t, []*chaincode.ListActivity{
+		{Hits: 5, ID: "www.aaa.com", LastHitAt: firstHit.Add(time.Minute), LastModifiedAt: created, Stale: true},
+		{Hits: 1, ID: "www.bbb.com", LastHitAt: secondHit, LastModifiedAt: created},
+		{ID: "www.ccc.com", LastModifiedAt: created, Stale: true, Watchers: 1},
+	}, report.Lists)
+	require.Equal(t, 3, report.Metadata.Cost.KeysScanned)
+
+	report, err = assetTransfer.GetListActivityReport(ws.transactionContext, 30, 3, report.Bookmark)
+	require.NoError(t, err)
+	require.Empty(t, report.Bookmark)
+	require.Equal(t, []*chaincode.ListActivity{
+		{ID: "www.ddd.com", LastModifiedAt: created, Stale: true, Unused: true},
+	}, report.Lists)
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.aaa.com"))
+	ws.commit()
+	for key := range ws.state {
+		require.NotContains(t, key, "hits\x00www.aaa.com")
+	}
+}
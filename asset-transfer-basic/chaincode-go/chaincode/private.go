@@ -0,0 +1,183 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// BlocklistTransient is the transient data field in which CreatePrivateAsset receives the
+// blocklist, so that it never appears in the transaction proposal or the block
+const BlocklistTransient = "blocklist"
+
+// implicitCollectionPrefix prefixes the MSP ID of an organization in the name of its implicit
+// private data collection
+const implicitCollectionPrefix = "_implicit_org_"
+
+// CreatePrivateAsset issues a new asset whose blocklist, passed in the blocklist transient field,
+// is normalized and stored in the implicit private data collection of the caller's organization.
+// The public asset only records the collection and the SHA-256 digest of the blocklist, which
+// matches the private data hash the peers keep on the ledger.
+func (s *SmartContract) CreatePrivateAsset(ctx contractapi.TransactionContextInterface, allowlist string, attribute2 int, attribute1 string, webfilterlist int) error {
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to read transient data: %v", err)
+	}
+	transientBlocklist, ok := transient[BlocklistTransient]
+	if !ok {
+		return newError(ErrValidation, map[string]string{"field": BlocklistTransient}, "the blocklist must be passed in the %s transient field", BlocklistTransient)
+	}
+
+	normalized := Asset{Allowlist: allowlist, Blocklist: string(transientBlocklist)}
+	err = prepareNewAsset(ctx, &normalized)
+	if err != nil {
+		return err
+	}
+	blocklist := []byte(normalized.Blocklist)
+
+	exists, err := s.AssetExists(ctx, normalized.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return assetExistsError(normalized.ID)
+	}
+	collection, err := orgCollection(ctx)
+	if err != nil {
+		return err
+	}
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(blocklist)
+	asset := Asset{
+		Allowlist:           normalized.Allowlist,
+		Attribute1:          attribute1,
+		Attribute2:          attribute2,
+		BlocklistCollection: collection,
+		BlocklistHash:       hex.EncodeToString(digest[:]),
+		ID:                  normalized.ID,
+		Owner:               owner,
+		Webfilterlist:       webfilterlist,
+	}
+
+	// the organization is charged for the entries of the blocklist it keeps private
+	err = chargeQuota(ctx, &normalized)
+	if err != nil {
+		return err
+	}
+
+	err = meterOperation(ctx, meterCreate, 1)
+	if err != nil {
+		return err
+	}
+
+	err = putAsset(ctx, &asset)
+	if err != nil {
+		return err
+	}
+	key, err := assetKey(ctx, asset.ID)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutPrivateData(collection, key, blocklist)
+	if err != nil {
+		return fmt.Errorf("failed to put private data: %v", err)
+	}
+
+	err = recordModification(ctx, asset.ID, operationCreate)
+	if err != nil {
+		return err
+	}
+
+	return emitAssetEvent(ctx, assetCreatedEventName, &asset)
+}
+
+// ReadPrivateAsset returns the asset stored with given ID together with its private
+// blocklist. Members of the organization holding the blocklist can read it, as can members of
+// organizations with an active agreement covering the asset. Either must evaluate the
+// transaction on a peer of the holding organization, as only its peers have the data.
+func (s *SmartContract) ReadPrivateAsset(ctx contractapi.TransactionContextInterface, id string) (*Asset, error) {
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if asset.BlocklistCollection == "" {
+		return nil, newError(ErrNotFound, map[string]string{"id": id}, "the asset %s has no private blocklist", id)
+	}
+	collection := asset.BlocklistCollection
+	providerMSPID := strings.TrimPrefix(collection, implicitCollectionPrefix)
+	err = assertAgreement(ctx, providerMSPID, id)
+	if err != nil {
+		return nil, err
+	}
+	peerMSPID, err := shim.GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get peer MSP ID: %v", err)
+	}
+	if peerMSPID != providerMSPID {
+		return nil, newError(ErrValidation, map[string]string{"id": id, "mspID": providerMSPID}, "the blocklist of %s is private to the collection %s and must be read from a peer of %s, not of %s", id, collection, providerMSPID, peerMSPID)
+	}
+
+	key, err := assetKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	blocklist, err := ctx.GetStub().GetPrivateData(collection, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private data: %v", err)
+	}
+	if blocklist == nil {
+		return nil, fmt.Errorf("the private blocklist of %s is not available on this peer", id)
+	}
+	digest := sha256.Sum256(blocklist)
+	if hex.EncodeToString(digest[:]) != asset.BlocklistHash {
+		return nil, fmt.Errorf("the private blocklist of %s does not match its public hash", id)
+	}
+
+	asset.Blocklist = string(blocklist)
+
+	return asset, nil
+}
+
+// orgCollection returns the implicit private data collection of the caller's organization. The
+// transaction must be endorsed by a peer of that organization, as only its peers hold the data.
+func orgCollection(ctx contractapi.TransactionContextInterface) (string, error) {
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return "", err
+	}
+	peerMSPID, err := shim.GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get peer MSP ID: %v", err)
+	}
+	if mspID != peerMSPID {
+		return "", newError(ErrValidation, map[string]string{"mspID": mspID}, "the client of organization %s must use a peer of its own organization, not of %s", mspID, peerMSPID)
+	}
+
+	return implicitCollectionPrefix + mspID, nil
+}
+
+// deletePrivateBlocklist removes the private blocklist of asset, if it has one
+func deletePrivateBlocklist(ctx contractapi.TransactionContextInterface, asset *Asset) error {
+	if asset.BlocklistCollection == "" {
+		return nil
+	}
+
+	key, err := assetKey(ctx, asset.ID)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelPrivateData(asset.BlocklistCollection, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete private data: %v", err)
+	}
+
+	return nil
+}
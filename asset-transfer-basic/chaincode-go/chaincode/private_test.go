@@ -0,0 +1,103 @@
+package chaincode_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePrivateAsset(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	err := assetTransfer.CreatePrivateAsset(ws.transactionContext, "www.bbc.co.uk", 1, "Org1MSP", 100)
+	requireError(t, err, chaincode.ErrValidation, "the blocklist must be passed in the blocklist transient field")
+
+	ws.stub.GetTransientReturns(map[string][]byte{chaincode.BlocklistTransient: []byte("www.xxx.com,www.yyy.com")}, nil)
+	created := ws.now
+	require.NoError(t, assetTransfer.CreatePrivateAsset(ws.transactionContext, "www.bbc.co.uk", 1, "Org1MSP", 100))
+	require.Equal(t, []byte("www.xxx.com,www.yyy.com"), ws.privateWrites["_implicit_org_Org1MSP"][assetKey("www.bbc.co.uk")])
+	ws.commit()
+
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Asset{
+		Allowlist:           "www.bbc.co.uk",
+		Attribute1:          "Org1MSP",
+		Attribute2:          1,
+		BlocklistCollection: "_implicit_org_Org1MSP",
+		BlocklistHash:       "f070276ca1df05c64ca977b2fb4d251b7d29ea1ab34e4ceab55c4814c8b337e7",
+		CreatedAt:           created,
+		ID:                  "www.bbc.co.uk",
+		Owner:               "user1",
+		SchemaVersion:       chaincode.SchemaVersion,
+		UpdatedAt:           created,
+		Webfilterlist:       100,
+	}, asset)
+
+	// the allowlist domain and both private blocklist domains are charged
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 3, usage.Entries)
+
+	err = assetTransfer.CreatePrivateAsset(ws.transactionContext, "www.bbc.co.uk", 1, "Org1MSP", 100)
+	requireError(t, err, chaincode.ErrAssetExists, "the asset www.bbc.co.uk already exists")
+
+	err = assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "Org1MSP", 100)
+	requireError(t, err, chaincode.ErrValidation, "the blocklist of www.bbc.co.uk is private and cannot be updated")
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.bbc.co.uk"))
+	require.Contains(t, ws.privateWrites["_implicit_org_Org1MSP"], assetKey("www.bbc.co.uk"))
+	require.Nil(t, ws.privateWrites["_implicit_org_Org1MSP"][assetKey("www.bbc.co.uk")])
+}
+
+func TestReadPrivateAsset(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.google.com", "www.xxx.com", 1, "Org1MSP", 100))
+	ws.stub.GetTransientReturns(map[string][]byte{chaincode.BlocklistTransient: []byte("www.xxx.com")}, nil)
+	require.NoError(t, assetTransfer.CreatePrivateAsset(ws.transactionContext, "www.bbc.co.uk", 1, "Org1MSP", 100))
+	ws.commit()
+
+	asset, err := assetTransfer.ReadPrivateAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "www.xxx.com", asset.Blocklist)
+
+	_, err = assetTransfer.ReadPrivateAsset(ws.transactionContext, "www.google.com")
+	requireError(t, err, chaincode.ErrNotFound, "the asset www.google.com has no private blocklist")
+
+	ws.setClient("Org2MSP", "user2", false)
+	_, err = assetTransfer.ReadPrivateAsset(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrUnauthorized, "organization Org2MSP has no active agreement with Org1MSP covering www.bbc.co.uk")
+
+	// an active agreement lets the consumer read the blocklist from a peer of the provider
+	ws.setClient("Org1MSP", "user1", false)
+	agreementID, err := assetTransfer.ProposeAgreement(ws.transactionContext, "Org2MSP", []string{"www.bbc.co.uk"}, "research use")
+	require.NoError(t, err)
+	ws.commit()
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, assetTransfer.CountersignAgreement(ws.transactionContext, agreementID))
+	ws.commit()
+
+	asset, err = assetTransfer.ReadPrivateAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "www.xxx.com", asset.Blocklist)
+
+	os.Setenv("CORE_PEER_LOCALMSPID", "Org2MSP")
+	_, err = assetTransfer.ReadPrivateAsset(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrValidation, "the blocklist of www.bbc.co.uk is private to the collection _implicit_org_Org1MSP and must be read from a peer of Org1MSP, not of Org2MSP")
+	os.Setenv("CORE_PEER_LOCALMSPID", "Org1MSP")
+
+	ws.setClient("Org1MSP", "user1", false)
+	ws.private["_implicit_org_Org1MSP"][assetKey("www.bbc.co.uk")] = []byte("www.yyy.com")
+	_, err = assetTransfer.ReadPrivateAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.EqualError(t, err, "the private blocklist of www.bbc.co.uk does not match its public hash")
+
+	delete(ws.private["_implicit_org_Org1MSP"], assetKey("www.bbc.co.uk"))
+	_, err = assetTransfer.ReadPrivateAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.EqualError(t, err, "the private blocklist of www.bbc.co.uk is not available on this peer")
+}
@@ -0,0 +1,149 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ProjectedAsset holds the requested fields of an asset, JSON encoded. Fields absent from the
+// stored asset are left out.
+type ProjectedAsset struct {
+	Fields map[string]string `json:"fields"`
+	Key    string            `json:"key"`
+}
+
+// ProjectedAssetList is the response of GetAllAssetFields
+type ProjectedAssetList struct {
+	Assets   []*ProjectedAsset `json:"assets"`
+	Metadata QueryMetadata     `json:"metadata"`
+}
+
+// ReadAssetFields returns only the given fields of the asset stored in the world state with given id
+func (s *SmartContract) ReadAssetFields(ctx contractapi.TransactionContextInterface, id string, fields []string) (*ProjectedAsset, error) {
+	err := validateProjection(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := assetKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	assetJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if assetJSON == nil {
+		return nil, assetNotFoundError(id)
+	}
+
+	return projectAsset(id, assetJSON, fields)
+}
+
+// GetAllAssetFields returns only the given fields of every asset found in world state
+func (s *SmartContract) GetAllAssetFields(ctx contractapi.TransactionContextInterface, fields []string) (*ProjectedAssetList, error) {
+	err := validateProjection(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := []*ProjectedAsset{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, err
+		}
+
+		id, _, err := assetIDFromKey(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		asset, err := projectAsset(id, queryResponse.Value, fields)
+		if err != nil {
+			return nil, err
+		}
+		size := len(asset.Key)
+		for field, value := range asset.Fields {
+			size += len(field) + len(value)
+		}
+		err = meter.returned(size)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Cost = &meter.cost
+
+	return &ProjectedAssetList{Assets: assets, Metadata: metadata}, nil
+}
+
+// assetFields returns the JSON names of the fields of an Asset
+func assetFields() []string {
+	assetType := reflect.TypeOf(Asset{})
+	fields := make([]string, 0, assetType.NumField())
+	for i := 0; i < assetType.NumField(); i++ {
+		name := strings.Split(assetType.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+
+	return fields
+}
+
+func validateProjection(fields []string) error {
+	if len(fields) == 0 {
+		return newError(ErrValidation, map[string]string{"field": "fields"}, "at least one field is required")
+	}
+
+	known := assetFields()
+	for _, field := range fields {
+		if !stringInSlice(field, known) {
+			return newError(ErrValidation, map[string]string{"field": "fields"}, "unknown asset field %s, expected one of %s", field, strings.Join(known, ", "))
+		}
+	}
+
+	return nil
+}
+
+// projectAsset extracts the given fields from the stored JSON of an asset
+func projectAsset(key string, assetJSON []byte, fields []string) (*ProjectedAsset, error) {
+	var document map[string]json.RawMessage
+	err := json.Unmarshal(assetJSON, &document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the asset %s: %v", key, err)
+	}
+
+	asset := &ProjectedAsset{Fields: map[string]string{}, Key: key}
+	for _, field := range fields {
+		if value, ok := document[field]; ok {
+			asset.Fields[field] = string(value)
+		}
+	}
+
+	return asset, nil
+}
@@ -0,0 +1,50 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAssetFields(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, ws.putState(assetKey("www.bbc.co.uk"), []byte(`{"allowlist":"www.bbc.co.uk","attribute1":"Org1MSP","attribute2":5,"blocklist":"","webfilterlist":300}`)))
+	ws.commit()
+
+	asset, err := assetTransfer.ReadAssetFields(ws.transactionContext, "www.bbc.co.uk", []string{"attribute1", "webfilterlist"})
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.ProjectedAsset{
+		Fields: map[string]string{"attribute1": `"Org1MSP"`, "webfilterlist": "300"},
+		Key:    "www.bbc.co.uk",
+	}, asset)
+
+	_, err = assetTransfer.ReadAssetFields(ws.transactionContext, "www.bbc.co.uk", []string{})
+	requireError(t, err, chaincode.ErrValidation, "at least one field is required")
+
+	_, err = assetTransfer.ReadAssetFields(ws.transactionContext, "www.bbc.co.uk", []string{"price"})
+	requireError(t, err, chaincode.ErrValidation, "unknown asset field price, expected one of allowlist, attribute1, attribute2, blocklist, blocklistCollection, blocklistHash, category, createdAt, expiresAt, id, owner, ruleType, schedule, schemaVersion, updatedAt, webfilterlist")
+
+	_, err = assetTransfer.ReadAssetFields(ws.transactionContext, "www.google.com", []string{"attribute1"})
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.google.com does not exist")
+}
+
+func TestGetAllAssetFields(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, ws.putState(assetKey("www.bbc.co.uk"), []byte(`{"allowlist":"www.bbc.co.uk","attribute1":"Org1MSP","blocklist":"","webfilterlist":300}`)))
+	require.NoError(t, ws.putState(assetKey("www.xxx.com"), []byte(`{"allowlist":"","blocklist":"www.xxx.com","webfilterlist":400}`)))
+	ws.commit()
+
+	assets, err := assetTransfer.GetAllAssetFields(ws.transactionContext, []string{"attribute1"})
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.ProjectedAsset{
+		{Fields: map[string]string{"attribute1": `"Org1MSP"`}, Key: "www.bbc.co.uk"},
+		{Fields: map[string]string{}, Key: "www.xxx.com"},
+	}, assets.Assets)
+	require.Equal(t, 2, assets.Metadata.Cost.KeysScanned)
+	require.Equal(t, len("www.bbc.co.uk")+len("attribute1")+len(`"Org1MSP"`)+len("www.xxx.com"), assets.Metadata.Cost.BytesReturned)
+}
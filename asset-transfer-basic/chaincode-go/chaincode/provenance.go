@@ -0,0 +1,177 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// provenanceObjectType keys the transfer records of each asset by asset ID
+const provenanceObjectType = "provenance"
+
+// TransferRecord records one transfer of an asset from its previous to its new owner
+type TransferRecord struct {
+	NewOwner      string    `json:"newOwner"`
+	PreviousOwner string    `json:"previousOwner"`
+	Timestamp     time.Time `json:"timestamp"`
+	TxID          string    `json:"txID"`
+}
+
+// TransferHistory is the response of GetTransferHistory. Transfers are listed oldest first.
+type TransferHistory struct {
+	ID        string            `json:"id"`
+	Transfers []*TransferRecord `json:"transfers"`
+}
+
+// GetTransferHistory returns the transfers recorded for the asset with given id. The records
+// outlive the asset, so the history of a deleted asset can still be read.
+func (s *SmartContract) GetTransferHistory(ctx contractapi.TransactionContextInterface, id string) (*TransferHistory, error) {
+	transfers, err := readTransfers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(transfers) == 0 {
+		exists, err := s.AssetExists(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, assetNotFoundError(id)
+		}
+	}
+
+	return &TransferHistory{ID: id, Transfers: transfers}, nil
+}
+
+// recordTransfer appends the transfer of the asset with given id in this transaction to its provenance
+func recordTransfer(ctx contractapi.TransactionContextInterface, id string, previousOwner string, newOwner string) error {
+	transfers, err := readTransfers(ctx, id)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	transfers = append(transfers, &TransferRecord{
+		NewOwner:      newOwner,
+		PreviousOwner: previousOwner,
+		Timestamp:     now,
+		TxID:          ctx.GetStub().GetTxID(),
+	})
+
+	key, err := provenanceKey(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return putRecord(ctx, key, transfers)
+}
+
+// readTransfers returns the transfer records of the asset with given id, oldest first
+func readTransfers(ctx contractapi.TransactionContextInterface, id string) ([]*TransferRecord, error) {
+	key, err := provenanceKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := []*TransferRecord{}
+	_, err = getRecord(ctx, key, &transfers)
+	if err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+// moveTransfers moves the transfer records of the asset with ID oldID to newID
+func moveTransfers(ctx contractapi.TransactionContextInterface, oldID string, newID string) error {
+	oldKey, err := provenanceKey(ctx, oldID)
+	if err != nil {
+		return err
+	}
+	transfersJSON, err := ctx.GetStub().GetState(oldKey)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if transfersJSON == nil {
+		return nil
+	}
+
+	newKey, err := provenanceKey(ctx, newID)
+	if err != nil {
+		return err
+	}
+	err = putState(ctx, newKey, transfersJSON)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(oldKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete from world state: %v", err)
+	}
+
+	return nil
+}
+
+// eraseTransferParty replaces subjectRef as previous or new owner in every transfer record and
+// returns the number of records changed
+func eraseTransferParty(ctx contractapi.TransactionContextInterface, subjectRef string) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(provenanceObjectType, []string{})
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	count := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		var transfers []*TransferRecord
+		err = json.Unmarshal(queryResponse.Value, &transfers)
+		if err != nil {
+			return 0, err
+		}
+		changed := false
+		for _, transfer := range transfers {
+			named := transfer.PreviousOwner == subjectRef || transfer.NewOwner == subjectRef
+			if !named {
+				continue
+			}
+			if transfer.PreviousOwner == subjectRef {
+				transfer.PreviousOwner = ErasedSubject
+			}
+			if transfer.NewOwner == subjectRef {
+				transfer.NewOwner = ErasedSubject
+			}
+			changed = true
+			count++
+		}
+		if !changed {
+			continue
+		}
+
+		err = putRecord(ctx, queryResponse.Key, transfers)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+// provenanceKey returns the key the transfer records of the asset with given id are stored under
+func provenanceKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(provenanceObjectType, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,77 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTransferHistory(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "Org1MSP", 100))
+	ws.commit()
+
+	history, err := assetTransfer.GetTransferHistory(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TransferHistory{ID: "www.bbc.co.uk", Transfers: []*chaincode.TransferRecord{}}, history)
+
+	first := ws.now
+	_, err = assetTransfer.TransferAsset(ws.transactionContext, "www.bbc.co.uk", "Org2MSP")
+	require.NoError(t, err)
+	ws.commit()
+	ws.setClient("Org2MSP", "user2", false)
+	_, err = assetTransfer.TransferAsset(ws.transactionContext, "www.bbc.co.uk", "Org3MSP")
+	require.NoError(t, err)
+	ws.commit()
+
+	history, err = assetTransfer.GetTransferHistory(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.TransferRecord{
+		{NewOwner: "Org2MSP", PreviousOwner: "user1", Timestamp: first, TxID: "tx001"},
+		{NewOwner: "Org3MSP", PreviousOwner: "Org2MSP", Timestamp: first.Add(time.Minute), TxID: "tx002"},
+	}, history.Transfers)
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err = assetTransfer.EraseSubjectData(ws.transactionContext, "Org2MSP")
+	require.NoError(t, err)
+	ws.commit()
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.bbc.co.uk"))
+	ws.commit()
+
+	history, err = assetTransfer.GetTransferHistory(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.TransferRecord{
+		{NewOwner: chaincode.ErasedSubject, PreviousOwner: "user1", Timestamp: first, TxID: "tx001"},
+		{NewOwner: "Org3MSP", PreviousOwner: chaincode.ErasedSubject, Timestamp: first.Add(time.Minute), TxID: "tx002"},
+	}, history.Transfers)
+
+	_, err = assetTransfer.GetTransferHistory(ws.transactionContext, "www.google.com")
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.google.com does not exist")
+}
+
+func TestTransferHistoryFollowsKeyMigrations(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "Org1MSP", 100))
+	ws.commit()
+	_, err := assetTransfer.TransferAsset(ws.transactionContext, "www.bbc.co.uk", "Org2MSP")
+	require.NoError(t, err)
+	ws.commit()
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err = assetTransfer.MigrateKeys(ws.transactionContext, "www.", "web:www.", 10, "")
+	require.NoError(t, err)
+	ws.commit()
+
+	history, err := assetTransfer.GetTransferHistory(ws.transactionContext, "web:www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Len(t, history.Transfers, 1)
+
+	_, err = assetTransfer.GetTransferHistory(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.bbc.co.uk does not exist")
+}
@@ -0,0 +1,98 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// QueryAssets returns the assets matching queryString, a CouchDB rich query such as
+// {"selector":{"blocklist":"www.xxx.com"}}. On goleveldb the contract answers the query itself,
+// supporting selectors on top level fields, limit and skip.
+func (s *SmartContract) QueryAssets(ctx contractapi.TransactionContextInterface, queryString string) (*AssetList, error) {
+	resultsIterator, err := queryState(ctx, queryString)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	assets, metadata, err := queriedAssets(ctx, resultsIterator)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AssetList{Assets: assets, Metadata: metadata}, nil
+}
+
+// QueryAssetsWithPagination returns one page of at most pageSize assets matching queryString,
+// starting at bookmark, together with the bookmark of the next page. FetchedRecordsCount counts
+// every document the state database returned, internal records included, so a page may hold
+// fewer assets.
+func (s *SmartContract) QueryAssetsWithPagination(ctx contractapi.TransactionContextInterface, queryString string, pageSize int32, bookmark string) (*PaginatedAssetList, error) {
+	if pageSize < 1 || pageSize > maxAssetPageSize {
+		return nil, newError(ErrValidation, map[string]string{"field": "pageSize"}, "the page size must be between 1 and %d", maxAssetPageSize)
+	}
+
+	resultsIterator, responseMetadata, err := queryStateWithPagination(ctx, queryString, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	assets, metadata, err := queriedAssets(ctx, resultsIterator)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaginatedAssetList{
+		Assets:              assets,
+		Bookmark:            responseMetadata.Bookmark,
+		FetchedRecordsCount: responseMetadata.FetchedRecordsCount,
+		Metadata:            metadata,
+	}, nil
+}
+
+// queriedAssets reads the assets returned by a rich query, metering its cost
+func queriedAssets(ctx contractapi.TransactionContextInterface, resultsIterator shim.StateQueryIteratorInterface) ([]*Asset, QueryMetadata, error) {
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, QueryMetadata{}, err
+	}
+
+	assets := []*Asset{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, QueryMetadata{}, err
+		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, QueryMetadata{}, err
+		}
+		// internal records are JSON documents too and may match a loose selector
+		id, ok, err := assetIDFromKey(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, QueryMetadata{}, err
+		}
+		if !ok {
+			continue
+		}
+		err = meter.returned(len(queryResponse.Value))
+		if err != nil {
+			return nil, QueryMetadata{}, err
+		}
+
+		asset, err := unmarshalAsset(id, queryResponse.Value)
+		if err != nil {
+			return nil, QueryMetadata{}, err
+		}
+		assets = append(assets, asset)
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, QueryMetadata{}, err
+	}
+	metadata.Cost = &meter.cost
+
+	return assets, metadata, nil
+}
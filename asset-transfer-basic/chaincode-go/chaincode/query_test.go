@@ -0,0 +1,52 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryAssets(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	require.NoError(t, ws.putState(assetKey("www.bbc.co.uk"), []byte(`{"blocklist":"www.xxx.com"}`)))
+	require.NoError(t, ws.putState(assetKey("www.google.com"), []byte(`{"blocklist":"www.yyy.com"}`)))
+	require.NoError(t, ws.putState(assetKey("www.napier.ac.uk"), []byte(`{"blocklist":"www.xxx.com"}`)))
+	require.NoError(t, ws.putState(compositeKeyNamespace+"config"+compositeKeyNamespace, []byte(`{"blocklist":"www.xxx.com"}`)))
+	ws.commit()
+
+	assets, err := assetTransfer.QueryAssets(ws.transactionContext, `{"selector":{"blocklist":"www.xxx.com"}}`)
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 2)
+	require.Equal(t, 3, assets.Metadata.Cost.KeysScanned)
+
+	_, err = assetTransfer.QueryAssets(ws.transactionContext, `selector`)
+	require.EqualError(t, err, "invalid query: invalid character 's' looking for beginning of value")
+}
+
+func TestQueryAssetsWithPagination(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	for _, id := range []string{"www.aaa.com", "www.bbb.com", "www.ccc.com"} {
+		require.NoError(t, ws.putState(assetKey(id), []byte(`{"blocklist":"www.xxx.com"}`)))
+	}
+	require.NoError(t, ws.putState(assetKey("www.ddd.com"), []byte(`{"blocklist":"www.yyy.com"}`)))
+	ws.commit()
+
+	_, err := assetTransfer.QueryAssetsWithPagination(ws.transactionContext, `{"selector":{}}`, 101, "")
+	requireError(t, err, chaincode.ErrValidation, "the page size must be between 1 and 100")
+
+	page, err := assetTransfer.QueryAssetsWithPagination(ws.transactionContext, `{"selector":{"blocklist":"www.xxx.com"}}`, 2, "")
+	require.NoError(t, err)
+	require.Len(t, page.Assets, 2)
+	require.Equal(t, int32(2), page.FetchedRecordsCount)
+	require.Equal(t, assetKey("www.ccc.com"), page.Bookmark)
+
+	page, err = assetTransfer.QueryAssetsWithPagination(ws.transactionContext, `{"selector":{"blocklist":"www.xxx.com"}}`, 2, page.Bookmark)
+	require.NoError(t, err)
+	require.Len(t, page.Assets, 1)
+	require.Empty(t, page.Bookmark)
+}
@@ -0,0 +1,405 @@
+package chaincode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	quotaObjectType       = "quota"
+	quotaUsageObjectType  = "quotausage"
+	quotaChargeObjectType = "quotacharge"
+)
+
+// Quota limits how much of the shared namespace a single organization may consume.
+// A limit of zero means unlimited.
+type Quota struct {
+	MaxAssets  int    `json:"maxAssets"`
+	MaxEntries int    `json:"maxEntries"`
+	MSPID      string `json:"mspID"`
+}
+
+// QuotaUsage reports an organization's consumption against its quota
+type QuotaUsage struct {
+	Assets     int    `json:"assets"`
+	Entries    int    `json:"entries"`
+	MaxAssets  int    `json:"maxAssets"`
+	MaxEntries int    `json:"maxEntries"`
+	MSPID      string `json:"mspID"`
+}
+
+// quotaCharge remembers which organization an asset was charged to, so the same
+// amount can be released when the asset is deleted
+type quotaCharge struct {
+	Entries int    `json:"entries"`
+	MSPID   string `json:"mspID"`
+}
+
+// SetQuota configures the maximum number of assets and allowlist/blocklist entries an organization may create
+func (s *SmartContract) SetQuota(ctx contractapi.TransactionContextInterface, mspID string, maxAssets int, maxEntries int) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if mspID == "" {
+		return newError(ErrValidation, map[string]string{"field": "mspID"}, "an MSP ID is required")
+	}
+	if maxAssets < 0 || maxEntries < 0 {
+		return newError(ErrValidation, nil, "quota limits must not be negative")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(quotaObjectType, []string{mspID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	quota := Quota{
+		MaxAssets:  maxAssets,
+		MaxEntries: maxEntries,
+		MSPID:      mspID,
+	}
+	return putRecord(ctx, key, quota)
+}
+
+// GetQuotaUsage returns the quota configured for an organization together with its current usage
+func (s *SmartContract) GetQuotaUsage(ctx contractapi.TransactionContextInterface, mspID string) (*QuotaUsage, error) {
+	quota, err := readQuota(ctx, mspID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := readQuotaUsage(ctx, mspID)
+	if err != nil {
+		return nil, err
+	}
+	usage.MaxAssets = quota.MaxAssets
+	usage.MaxEntries = quota.MaxEntries
+
+	return usage, nil
+}
+
+// readQuota returns the quota configured for mspID, or an unlimited quota if none is set
+func readQuota(ctx contractapi.TransactionContextInterface, mspID string) (*Quota, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(quotaObjectType, []string{mspID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	quota := Quota{MSPID: mspID}
+	_, err = getRecord(ctx, key, &quota)
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+func readQuotaUsage(ctx contractapi.TransactionContextInterface, mspID string) (*QuotaUsage, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(quotaUsageObjectType, []string{mspID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	usage := QuotaUsage{MSPID: mspID}
+	_, err = getRecord(ctx, key, &usage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
+func putQuotaUsage(ctx contractapi.TransactionContextInterface, usage *QuotaUsage) error {
+	key, err := ctx.GetStub().CreateCompositeKey(quotaUsageObjectType, []string{usage.MSPID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	// limits are always read from the quota record, never from the usage record
+	return putRecord(ctx, key, QuotaUsage{Assets: usage.Assets, Entries: usage.Entries, MSPID: usage.MSPID})
+}
+
+// chargeQuota accounts newly created assets against the submitting organization's quota,
+// failing if the organization would exceed either of its limits
+func chargeQuota(ctx contractapi.TransactionContextInterface, assets ...*Asset) error {
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	quota, err := readQuota(ctx, mspID)
+	if err != nil {
+		return err
+	}
+
+	usage, err := readQuotaUsage(ctx, mspID)
+	if err != nil {
+		return err
+	}
+
+	// the charges of a batch are accumulated and the usage is written once
+	for _, asset := range assets {
+		entries := assetEntryCount(asset)
+		err = assertQuotaRoom(quota, usage, 1, entries)
+		if err != nil {
+			return err
+		}
+		usage.Assets++
+		usage.Entries += entries
+
+		chargeKey, err := ctx.GetStub().CreateCompositeKey(quotaChargeObjectType, []string{asset.ID})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %v", err)
+		}
+		err = putRecord(ctx, chargeKey, quotaCharge{Entries: entries, MSPID: mspID})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = putQuotaUsage(ctx, usage)
+	if err != nil {
+		return err
+	}
+	warnNearQuota(ctx, quota, usage)
+
+	return nil
+}
+
+// assertQuotaRoom returns an error unless an organization with the given quota and usage may
+// take on the given number of assets and entries more
+func assertQuotaRoom(quota *Quota, usage *QuotaUsage, assets int, entries int) error {
+	details := map[string]string{"mspID": quota.MSPID}
+	if assets > 0 && quota.MaxAssets > 0 && usage.Assets+assets > quota.MaxAssets {
+		return newError(ErrQuotaExceeded, details, "quota exceeded: organization %s already owns %d of %d permitted assets", quota.MSPID, usage.Assets, quota.MaxAssets)
+	}
+	if entries > 0 && quota.MaxEntries > 0 && usage.Entries+entries > quota.MaxEntries {
+		return newError(ErrQuotaExceeded, details, "quota exceeded: organization %s has %d of %d permitted entries and cannot add %d more", quota.MSPID, usage.Entries, quota.MaxEntries, entries)
+	}
+
+	return nil
+}
+
+// warnNearQuota warns the client when an organization has reached 90% of one of its limits
+func warnNearQuota(ctx contractapi.TransactionContextInterface, quota *Quota, usage *QuotaUsage) {
+	if nearLimit(usage.Assets, quota.MaxAssets) {
+		addWarning(ctx, WarnCodeNearQuota, "organization %s owns %d of %d permitted assets", quota.MSPID, usage.Assets, quota.MaxAssets)
+	}
+	if nearLimit(usage.Entries, quota.MaxEntries) {
+		addWarning(ctx, WarnCodeNearQuota, "organization %s has %d of %d permitted entries", quota.MSPID, usage.Entries, quota.MaxEntries)
+	}
+}
+
+// moveQuotaCharge charges the asset with given ID to mspID, the organization that now owns it,
+// instead of the organization it was charged to, failing if mspID would exceed one of its
+// limits. Assets that were never charged, such as those written by InitLedger, are ignored.
+func moveQuotaCharge(ctx contractapi.TransactionContextInterface, id string, mspID string) error {
+	chargeKey, err := ctx.GetStub().CreateCompositeKey(quotaChargeObjectType, []string{id})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	var charge quotaCharge
+	charged, err := getRecord(ctx, chargeKey, &charge)
+	if err != nil {
+		return err
+	}
+	if !charged || charge.MSPID == mspID {
+		return nil
+	}
+
+	quota, err := readQuota(ctx, mspID)
+	if err != nil {
+		return err
+	}
+	usage, err := readQuotaUsage(ctx, mspID)
+	if err != nil {
+		return err
+	}
+	err = assertQuotaRoom(quota, usage, 1, charge.Entries)
+	if err != nil {
+		return err
+	}
+
+	err = moveQuotaUsage(ctx, charge.MSPID, mspID, 1, charge.Entries)
+	if err != nil {
+		return err
+	}
+	charge.MSPID = mspID
+	err = putRecord(ctx, chargeKey, charge)
+	if err != nil {
+		return err
+	}
+	usage.Assets++
+	usage.Entries += charge.Entries
+	warnNearQuota(ctx, quota, usage)
+
+	return nil
+}
+
+// ownerOrganization returns owner if it is the MSP ID of an organization: the submitting
+// client's, a registered one or one given a quota. It returns "" for owners that are client
+// IDs, whose assets stay charged to the organization that was charged for them.
+func ownerOrganization(ctx contractapi.TransactionContextInterface, owner string) (string, error) {
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return "", err
+	}
+	if owner == mspID {
+		return owner, nil
+	}
+
+	org, err := readOrganization(ctx, owner)
+	if err != nil {
+		return "", err
+	}
+	if org != nil {
+		return owner, nil
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(quotaObjectType, []string{owner})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+	quotaJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if quotaJSON != nil {
+		return owner, nil
+	}
+
+	return "", nil
+}
+
+// rechargeQuota brings the entries charged for an updated asset in line with the entries it
+// now holds, failing if the organization charged for it would exceed its entry limit. Assets
+// that were never charged, such as those written by InitLedger, are ignored.
+func rechargeQuota(ctx contractapi.TransactionContextInterface, asset *Asset) error {
+	chargeKey, err := ctx.GetStub().CreateCompositeKey(quotaChargeObjectType, []string{asset.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	var charge quotaCharge
+	charged, err := getRecord(ctx, chargeKey, &charge)
+	if err != nil {
+		return err
+	}
+	added := assetEntryCount(asset) - charge.Entries
+	if !charged || added == 0 {
+		return nil
+	}
+
+	quota, err := readQuota(ctx, charge.MSPID)
+	if err != nil {
+		return err
+	}
+	usage, err := readQuotaUsage(ctx, charge.MSPID)
+	if err != nil {
+		return err
+	}
+	err = assertQuotaRoom(quota, usage, 0, added)
+	if err != nil {
+		return err
+	}
+	usage.Entries += added
+	if usage.Entries < 0 {
+		usage.Entries = 0
+	}
+
+	charge.Entries += added
+	err = putRecord(ctx, chargeKey, charge)
+	if err != nil {
+		return err
+	}
+	err = putQuotaUsage(ctx, usage)
+	if err != nil {
+		return err
+	}
+
+	if added > 0 && nearLimit(usage.Entries, quota.MaxEntries) {
+		addWarning(ctx, WarnCodeNearQuota, "organization %s has %d of %d permitted entries", charge.MSPID, usage.Entries, quota.MaxEntries)
+	}
+
+	return nil
+}
+
+// nearLimit reports whether used has reached 90% of a limit, where zero means unlimited
+func nearLimit(used int, limit int) bool {
+	return limit > 0 && used*10 >= limit*9
+}
+
+// releaseQuota returns the allowance charged for the assets with the given IDs to the
+// organizations that created them. Assets that were never charged, such as those written by
+// InitLedger, are ignored.
+func releaseQuota(ctx contractapi.TransactionContextInterface, ids ...string) error {
+	// the releases of a batch are accumulated per organization and each usage is written once
+	usages := map[string]*QuotaUsage{}
+	var mspIDs []string
+	for _, id := range ids {
+		chargeKey, err := ctx.GetStub().CreateCompositeKey(quotaChargeObjectType, []string{id})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %v", err)
+		}
+
+		var charge quotaCharge
+		charged, err := getRecord(ctx, chargeKey, &charge)
+		if err != nil {
+			return err
+		}
+		if !charged {
+			continue
+		}
+
+		usage, ok := usages[charge.MSPID]
+		if !ok {
+			usage, err = readQuotaUsage(ctx, charge.MSPID)
+			if err != nil {
+				return err
+			}
+			usages[charge.MSPID] = usage
+			mspIDs = append(mspIDs, charge.MSPID)
+		}
+		usage.Assets--
+		usage.Entries -= charge.Entries
+
+		err = ctx.GetStub().DelState(chargeKey)
+		if err != nil {
+			return fmt.Errorf("failed to delete quota charge from world state: %v", err)
+		}
+	}
+
+	for _, mspID := range mspIDs {
+		usage := usages[mspID]
+		if usage.Assets < 0 {
+			usage.Assets = 0
+		}
+		if usage.Entries < 0 {
+			usage.Entries = 0
+		}
+
+		err := putQuotaUsage(ctx, usage)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// assetEntryCount returns the number of allowlist and blocklist entries held by an asset: its
+// allowlist domain, and every domain or rule of its comma separated blocklist
+func assetEntryCount(asset *Asset) int {
+	count := 0
+	if asset.Allowlist != "" {
+		count++
+	}
+	for _, entry := range strings.Split(asset.Blocklist, domainListSeparator) {
+		if strings.TrimSpace(entry) != "" {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,169 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetQuota(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	err := assetTransfer.SetQuota(ws.transactionContext, "Org1MSP", 2, 3)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.SetQuota(ws.transactionContext, "Org1MSP", -1, 3)
+	requireError(t, err, chaincode.ErrValidation, "quota limits must not be negative")
+
+	err = assetTransfer.SetQuota(ws.transactionContext, "Org1MSP", 2, 3)
+	require.NoError(t, err)
+	ws.commit()
+
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.QuotaUsage{MaxAssets: 2, MaxEntries: 3, MSPID: "Org1MSP"}, usage)
+}
+
+func TestCreateAssetEnforcesQuota(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetQuota(ws.transactionContext, "Org1MSP", 2, 3))
+	ws.commit()
+
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 1, "", 100))
+	ws.commit()
+
+	err := assetTransfer.CreateAsset(ws.transactionContext, "www.napier.ac.uk", "www.instagram.com", 1, "", 100)
+	requireError(t, err, chaincode.ErrQuotaExceeded, "quota exceeded: organization Org1MSP has 2 of 3 permitted entries and cannot add 2 more")
+	ws.rollback()
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.napier.ac.uk", "", 1, "", 100))
+	ws.commit()
+
+	err = assetTransfer.CreateAsset(ws.transactionContext, "www.google.com", "", 1, "", 100)
+	requireError(t, err, chaincode.ErrQuotaExceeded, "quota exceeded: organization Org1MSP already owns 2 of 2 permitted assets")
+	ws.rollback()
+
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 2, usage.Assets)
+	require.Equal(t, 3, usage.Entries)
+
+	ws.setClient("Org2MSP", "user2", true)
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.bbc.co.uk"))
+	ws.commit()
+
+	usage, err = assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 1, usage.Assets)
+	require.Equal(t, 1, usage.Entries)
+}
+
+func TestUpdateAssetEnforcesQuota(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetQuota(ws.transactionContext, "Org1MSP", 0, 3))
+	ws.commit()
+
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.napier.ac.uk", "", 1, "", 100))
+	ws.commit()
+
+	// adding a blocklist charges its entries
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 1, "", 100))
+	ws.commit()
+	err := assetTransfer.UpdateAsset(ws.transactionContext, "www.napier.ac.uk", "www.instagram.com", 1, "", 100)
+	requireError(t, err, chaincode.ErrQuotaExceeded, "quota exceeded: organization Org1MSP has 3 of 3 permitted entries and cannot add 1 more")
+	ws.rollback()
+
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 3, usage.Entries)
+
+	// clearing a blocklist releases its entries
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.napier.ac.uk", "www.instagram.com", 1, "", 100))
+	ws.commit()
+
+	usage, err = assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 3, usage.Entries)
+}
+
+func TestQuotaCountsBlocklistDomains(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetQuota(ws.transactionContext, "Org1MSP", 0, 4))
+	ws.commit()
+
+	// the allowlist domain and each of the three blocklist domains count as one entry
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com,www.yyy.com,www.zzz.com", 1, "", 100))
+	ws.commit()
+
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 4, usage.Entries)
+
+	err = assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com,www.yyy.com,www.zzz.com,www.instagram.com", 1, "", 100)
+	requireError(t, err, chaincode.ErrQuotaExceeded, "quota exceeded: organization Org1MSP has 4 of 4 permitted entries and cannot add 1 more")
+	ws.rollback()
+}
+
+func TestTransferMovesQuotaCharge(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetQuota(ws.transactionContext, "Org2MSP", 1, 0))
+	ws.commit()
+
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 1, "Org1MSP", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.napier.ac.uk", "", 1, "Org1MSP", 100))
+	ws.commit()
+
+	// an accepted transfer charges the asset to the recipient
+	require.NoError(t, assetTransfer.ProposeTransfer(ws.transactionContext, "www.bbc.co.uk", "Org2MSP"))
+	ws.commit()
+	ws.setClient("Org2MSP", "user2", false)
+	_, err := assetTransfer.AcceptTransfer(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	ws.commit()
+
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 1, usage.Assets)
+	require.Equal(t, 1, usage.Entries)
+	usage, err = assetTransfer.GetQuotaUsage(ws.transactionContext, "Org2MSP")
+	require.NoError(t, err)
+	require.Equal(t, 1, usage.Assets)
+	require.Equal(t, 2, usage.Entries)
+
+	// the recipient's quota is enforced
+	ws.setClient("Org1MSP", "user1", false)
+	_, err = assetTransfer.TransferAsset(ws.transactionContext, "www.napier.ac.uk", "Org2MSP")
+	requireError(t, err, chaincode.ErrQuotaExceeded, "quota exceeded: organization Org2MSP already owns 1 of 1 permitted assets")
+	ws.rollback()
+
+	// transfers to a client ID leave the charge with the organization
+	_, err = assetTransfer.TransferAsset(ws.transactionContext, "www.napier.ac.uk", "user3")
+	require.NoError(t, err)
+	ws.commit()
+
+	usage, err = assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 1, usage.Assets)
+}
@@ -0,0 +1,314 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const roleObjectType = "role"
+
+// Roles of the on-chain role registry. Editors may call every function open to them; viewers
+// may only call the read functions listed in evaluateTransactions.
+const (
+	EditorRole = "editor"
+	ViewerRole = "viewer"
+)
+
+// unregisteredRole stands for the clients missing from the registry of an organization that
+// has granted roles. Like viewers, they may only call read functions.
+const unregisteredRole = "unregistered"
+
+// registryRoles are the roles GrantRole may assign
+var registryRoles = []string{AdminRole, EditorRole, ViewerRole}
+
+// RoleAssignment records the role granted to a client of the organization MSPID in the role
+// registry. Once an organization has granted a role, its clients need the editor or admin role
+// to write, and clients absent from the registry may only read, as viewers do. A client with
+// the admin role may also act as the owner of the assets owned by clients in the registry of
+// its organization, but holds no administrator rights over the rest of the contract. Clients
+// of organizations that have granted no role, and administrators carrying the admin attribute
+// or holding a delegation, keep the rights their certificate gives them.
+type RoleAssignment struct {
+	ClientID  string    `json:"clientID"`
+	GrantedAt time.Time `json:"grantedAt"`
+	GrantedBy string    `json:"grantedBy"`
+	MSPID     string    `json:"mspID"`
+	Role      string    `json:"role"`
+}
+
+// GrantRole assigns the admin, editor or viewer role to the client clientID of the organization
+// mspID, replacing any role it held. Only administrators of that organization holding the
+// certificate attribute may grant roles, so clients granted the admin role cannot extend their
+// rights to others and no organization can assign roles to the clients of another.
+func (s *SmartContract) GrantRole(ctx contractapi.TransactionContextInterface, mspID string, clientID string, role string) error {
+	err := assertOrgAdmin(ctx, mspID)
+	if err != nil {
+		return err
+	}
+	if clientID == "" {
+		return newError(ErrValidation, nil, "a client ID is required")
+	}
+	if !stringInSlice(role, registryRoles) {
+		return newError(ErrValidation, map[string]string{"role": role}, "the role must be %s, %s or %s", AdminRole, EditorRole, ViewerRole)
+	}
+
+	grantedBy, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := roleKey(ctx, mspID, clientID)
+	if err != nil {
+		return err
+	}
+
+	return putRecord(ctx, key, RoleAssignment{
+		ClientID:  clientID,
+		GrantedAt: now,
+		GrantedBy: grantedBy,
+		MSPID:     mspID,
+		Role:      role,
+	})
+}
+
+// RevokeRole removes the client clientID of the organization mspID from the role registry.
+// Only administrators of that organization holding the certificate attribute may revoke roles.
+func (s *SmartContract) RevokeRole(ctx contractapi.TransactionContextInterface, mspID string, clientID string) error {
+	err := assertOrgAdmin(ctx, mspID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.GetRole(ctx, mspID, clientID)
+	if err != nil {
+		return err
+	}
+	key, err := roleKey(ctx, mspID, clientID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().DelState(key)
+}
+
+// GetRole returns the role the registry assigns to the client clientID of the organization mspID
+func (s *SmartContract) GetRole(ctx contractapi.TransactionContextInterface, mspID string, clientID string) (*RoleAssignment, error) {
+	assignment, err := readRoleAssignment(ctx, mspID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment == nil {
+		return nil, newError(ErrNotFound, map[string]string{"clientID": clientID, "mspID": mspID}, "no role is assigned to %s of %s", clientID, mspID)
+	}
+
+	return assignment, nil
+}
+
+// assertRoleAllows returns an error if the registry role of the submitting client does not
+// allow it to invoke function, that is if a viewer or an unregistered client of an
+// organization keeping a registry invokes a function that writes
+func assertRoleAllows(ctx contractapi.TransactionContextInterface, function string) error {
+	if stringInSlice(function, evaluateTransactions) {
+		return nil
+	}
+
+	role, err := effectiveRole(ctx)
+	if err != nil {
+		return err
+	}
+	if roleAllows(role, function) {
+		return nil
+	}
+	if role == unregisteredRole {
+		mspID, err := callerMSPID(ctx)
+		if err != nil {
+			return err
+		}
+		return newError(ErrUnauthorized, map[string]string{"function": function, "role": role}, "the client is not authorized to invoke %s: clients without a role in the registry of %s may only call read functions", function, mspID)
+	}
+
+	return newError(ErrUnauthorized, map[string]string{"function": function, "role": role}, "the client is not authorized to invoke %s: the %s role may only call read functions", function, role)
+}
+
+// roleAllows reports whether a client with the effective registry role role, or "" for none,
+// may invoke function
+func roleAllows(role string, function string) bool {
+	return (role != ViewerRole && role != unregisteredRole) || stringInSlice(function, evaluateTransactions)
+}
+
+// effectiveRole returns the registry role of the submitting client, unregisteredRole if its
+// organization has granted roles to others only, or "" if the registry does not restrict it
+func effectiveRole(ctx contractapi.TransactionContextInterface) (string, error) {
+	role, err := registeredRole(ctx)
+	if err != nil || role != "" {
+		return role, err
+	}
+
+	// administrators by certificate or delegation grant the roles, so they are never locked out
+	if assertCertifiedAdmin(ctx) == nil {
+		return "", nil
+	}
+	delegated, err := hasActiveDelegation(ctx)
+	if err != nil || delegated {
+		return "", err
+	}
+
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return "", err
+	}
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(roleObjectType, []string{mspID})
+	if err != nil {
+		return "", err
+	}
+	defer resultsIterator.Close()
+	if resultsIterator.HasNext() {
+		return unregisteredRole, nil
+	}
+
+	return "", nil
+}
+
+// assertRegistryAdminOf returns an error unless the submitting client holds the admin role in
+// the registry of its organization and owner is a client in that registry
+func assertRegistryAdminOf(ctx contractapi.TransactionContextInterface, owner string) error {
+	unauthorized := newError(ErrUnauthorized, nil, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+	if owner == "" {
+		return unauthorized
+	}
+	role, err := registeredRole(ctx)
+	if err != nil {
+		return err
+	}
+	if role != AdminRole {
+		return unauthorized
+	}
+
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	assignment, err := readRoleAssignment(ctx, mspID, owner)
+	if err != nil {
+		return err
+	}
+	if assignment == nil {
+		return unauthorized
+	}
+
+	return nil
+}
+
+// assertOrgAdmin returns an error unless the submitting client is an administrator holding the
+// certificate attribute and belongs to the organization mspID
+func assertOrgAdmin(ctx contractapi.TransactionContextInterface, mspID string) error {
+	err := assertCertifiedAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	callerMSP, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if callerMSP != mspID {
+		return newError(ErrUnauthorized, map[string]string{"mspID": mspID}, "the client is not authorized to perform this operation: only administrators of %s may manage its roles", mspID)
+	}
+
+	return nil
+}
+
+// registeredRole returns the registry role of the submitting client, or "" if it has none
+func registeredRole(ctx contractapi.TransactionContextInterface) (string, error) {
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return "", err
+	}
+	id, err := callerID(ctx)
+	if err != nil {
+		return "", err
+	}
+	assignment, err := readRoleAssignment(ctx, mspID, id)
+	if err != nil || assignment == nil {
+		return "", err
+	}
+
+	return assignment.Role, nil
+}
+
+func readRoleAssignment(ctx contractapi.TransactionContextInterface, mspID string, clientID string) (*RoleAssignment, error) {
+	key, err := roleKey(ctx, mspID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	var assignment RoleAssignment
+	exists, err := getRecord(ctx, key, &assignment)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	return &assignment, nil
+}
+
+func roleKey(ctx contractapi.TransactionContextInterface, mspID string, clientID string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(roleObjectType, []string{mspID, clientID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return key, nil
+}
+
+// eraseRoleAssignments deletes the roles granted to subjectRef and tombstones the grantor of
+// the roles it granted
+func eraseRoleAssignments(ctx contractapi.TransactionContextInterface, subjectRef string) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(roleObjectType, []string{})
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	count := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		var assignment RoleAssignment
+		err = json.Unmarshal(queryResponse.Value, &assignment)
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case assignment.ClientID == subjectRef:
+			// the client ID is part of the key, so the assignment cannot be kept
+			err = ctx.GetStub().DelState(queryResponse.Key)
+			if err != nil {
+				return 0, fmt.Errorf("failed to delete from world state: %v", err)
+			}
+		case assignment.GrantedBy == subjectRef:
+			assignment.GrantedBy = ErasedSubject
+			err = putRecord(ctx, queryResponse.Key, assignment)
+			if err != nil {
+				return 0, err
+			}
+		default:
+			continue
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,130 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleRegistry(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	err := assetTransfer.GrantRole(ws.transactionContext, "Org1MSP", "auditor1", chaincode.ViewerRole)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.GrantRole(ws.transactionContext, "Org1MSP", "auditor1", "owner")
+	requireError(t, err, chaincode.ErrValidation, "the role must be admin, editor or viewer")
+	err = assetTransfer.GrantRole(ws.transactionContext, "Org1MSP", "", chaincode.ViewerRole)
+	requireError(t, err, chaincode.ErrValidation, "a client ID is required")
+
+	granted := ws.now
+	require.NoError(t, assetTransfer.GrantRole(ws.transactionContext, "Org1MSP", "auditor1", chaincode.ViewerRole))
+	require.NoError(t, assetTransfer.GrantRole(ws.transactionContext, "Org1MSP", "curator1", chaincode.EditorRole))
+	require.NoError(t, assetTransfer.GrantRole(ws.transactionContext, "Org1MSP", "operator1", chaincode.AdminRole))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	ws.commit()
+
+	assignment, err := assetTransfer.GetRole(ws.transactionContext, "Org1MSP", "auditor1")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.RoleAssignment{
+		ClientID:  "auditor1",
+		GrantedAt: granted,
+		GrantedBy: "admin",
+		MSPID:     "Org1MSP",
+		Role:      chaincode.ViewerRole,
+	}, assignment)
+
+	// viewers may only read
+	ws.setClient("Org1MSP", "auditor1", false)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "ReadAsset"))
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "GetAuditLog"))
+	err = beforeTransaction(t, assetTransfer, ws, "CreateAsset")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to invoke CreateAsset: the viewer role may only call read functions")
+	err = beforeTransaction(t, assetTransfer, ws, "SmartContract:UpdateAsset")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to invoke UpdateAsset: the viewer role may only call read functions")
+	permitted, err := assetTransfer.GetEffectivePermissions(ws.transactionContext)
+	require.NoError(t, err)
+	require.Contains(t, permitted, "ReadAsset")
+	require.NotContains(t, permitted, "CreateAsset")
+
+	// editors may write but not administer
+	ws.setClient("Org1MSP", "curator1", false)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "UpdateAsset"))
+	err = assetTransfer.SetMaintenanceMode(ws.transactionContext, true)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	// registry roles are honoured by the permission matrix
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetFunctionPermission(ws.transactionContext, "DeleteAsset", []string{"Org2MSP"}, []string{chaincode.EditorRole}))
+	ws.commit()
+	ws.setClient("Org1MSP", "curator1", false)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "DeleteAsset"))
+	ws.setClient("Org1MSP", "user1", false)
+	err = beforeTransaction(t, assetTransfer, ws, "DeleteAsset")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to invoke DeleteAsset")
+
+	// the admin role acts for the clients in its organization's registry, but grants neither
+	// administrator rights over the contract nor the right to grant roles
+	ws.setClient("Org1MSP", "curator1", false)
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.google.com", "", 1, "", 100))
+	ws.commit()
+	ws.setClient("Org1MSP", "operator1", false)
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.google.com", "www.xxx.com", 2, "", 200))
+	ws.commit()
+	err = assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 2, "", 200)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+	err = assetTransfer.DeleteAsset(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+	err = assetTransfer.GrantRole(ws.transactionContext, "Org1MSP", "operator2", chaincode.AdminRole)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.RevokeRole(ws.transactionContext, "Org1MSP", "auditor1"))
+	ws.commit()
+	_, err = assetTransfer.GetRole(ws.transactionContext, "Org1MSP", "auditor1")
+	requireError(t, err, chaincode.ErrNotFound, "no role is assigned to auditor1 of Org1MSP")
+	err = assetTransfer.RevokeRole(ws.transactionContext, "Org1MSP", "auditor1")
+	requireError(t, err, chaincode.ErrNotFound, "no role is assigned to auditor1 of Org1MSP")
+
+	// once an organization has granted roles its unregistered clients may only read, while
+	// its certified administrators and the clients of other organizations keep their rights
+	ws.setClient("Org1MSP", "auditor1", false)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "ReadAsset"))
+	err = beforeTransaction(t, assetTransfer, ws, "CreateAsset")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to invoke CreateAsset: clients without a role in the registry of Org1MSP may only call read functions")
+	permitted, err = assetTransfer.GetEffectivePermissions(ws.transactionContext)
+	require.NoError(t, err)
+	require.NotContains(t, permitted, "CreateAsset")
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+	ws.setClient("Org2MSP", "auditor1", false)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+}
+
+func TestRoleRegistryIsScopedToOrganizations(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	// administrators of one organization cannot manage the roles of another's clients
+	ws.setClient("Org1MSP", "admin", true)
+	err := assetTransfer.GrantRole(ws.transactionContext, "Org2MSP", "user2", chaincode.AdminRole)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only administrators of Org2MSP may manage its roles")
+
+	ws.setClient("Org2MSP", "admin2", true)
+	require.NoError(t, assetTransfer.GrantRole(ws.transactionContext, "Org2MSP", "user2", chaincode.ViewerRole))
+	ws.commit()
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.RevokeRole(ws.transactionContext, "Org2MSP", "user2")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only administrators of Org2MSP may manage its roles")
+
+	// a role applies only to the client of the organization it was granted for
+	ws.setClient("Org1MSP", "user2", false)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+	ws.setClient("Org2MSP", "user2", false)
+	err = beforeTransaction(t, assetTransfer, ws, "CreateAsset")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to invoke CreateAsset: the viewer role may only call read functions")
+}
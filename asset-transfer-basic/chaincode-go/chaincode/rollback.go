@@ -0,0 +1,166 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const lastKnownGoodObjectType = "lastknowngood"
+
+// rollbackEventName is the chaincode event emitted when a list is rolled back to its last-known-good version
+const rollbackEventName = "EmergencyRollback"
+
+// operationRollback is the operation recorded in the audit trail when a list is rolled back
+const operationRollback = "rollback"
+
+// LastKnownGood records the version of a list, identified by the transaction that wrote it,
+// that EmergencyRollback restores
+type LastKnownGood struct {
+	ListID   string    `json:"listID"`
+	MarkedAt time.Time `json:"markedAt"`
+	MarkedBy string    `json:"markedBy"`
+	Version  string    `json:"version"`
+}
+
+// RollbackEvent is the payload of an EmergencyRollback event. Its priority is always high,
+// so that event listeners can page the operators on call.
+type RollbackEvent struct {
+	ListID       string `json:"listID"`
+	Priority     string `json:"priority"`
+	RolledBackBy string `json:"rolledBackBy"`
+	TxID         string `json:"txID"`
+	Version      string `json:"version"`
+}
+
+// MarkLastKnownGood records version, the ID of a transaction that wrote the list listID, as
+// the version EmergencyRollback restores
+func (s *SmartContract) MarkLastKnownGood(ctx contractapi.TransactionContextInterface, listID string, version string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if version == "" {
+		return newError(ErrValidation, map[string]string{"field": "version"}, "a version is required")
+	}
+	_, err = listVersion(ctx, listID, version)
+	if err != nil {
+		return err
+	}
+
+	markedBy, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(lastKnownGoodObjectType, []string{listID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, LastKnownGood{ListID: listID, MarkedAt: now, MarkedBy: markedBy, Version: version})
+}
+
+// GetLastKnownGood returns the version of the list listID that EmergencyRollback restores
+func (s *SmartContract) GetLastKnownGood(ctx contractapi.TransactionContextInterface, listID string) (*LastKnownGood, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(lastKnownGoodObjectType, []string{listID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var lastKnownGood LastKnownGood
+	exists, err := getRecord(ctx, key, &lastKnownGood)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, newError(ErrNotFound, map[string]string{"id": listID}, "no last-known-good version of %s is marked", listID)
+	}
+
+	return &lastKnownGood, nil
+}
+
+// EmergencyRollback republishes the last-known-good version of the list listID and emits a
+// high priority EmergencyRollback event. As a transaction carries a single event, it takes the
+// place of the AssetUpdated event an update of the list would emit.
+func (s *SmartContract) EmergencyRollback(ctx contractapi.TransactionContextInterface, listID string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	lastKnownGood, err := s.GetLastKnownGood(ctx, listID)
+	if err != nil {
+		return err
+	}
+	key, err := assetKey(ctx, listID)
+	if err != nil {
+		return err
+	}
+	currentJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if currentJSON == nil {
+		return assetNotFoundError(listID)
+	}
+	err = assertMutable(ctx, listID)
+	if err != nil {
+		return err
+	}
+
+	listJSON, err := listVersion(ctx, listID, lastKnownGood.Version)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, listJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state: %v", err)
+	}
+
+	// the version is republished byte for byte, so only its index entries are rewritten
+	current, err := decodeAsset(listID, currentJSON)
+	if err != nil {
+		return err
+	}
+	restored, err := decodeAsset(listID, listJSON)
+	if err != nil {
+		return err
+	}
+	err = reindexAsset(ctx, current, restored)
+	if err != nil {
+		return err
+	}
+	err = meterOperation(ctx, meterPublish, 1)
+	if err != nil {
+		return err
+	}
+
+	err = recordModification(ctx, listID, operationRollback)
+	if err != nil {
+		return err
+	}
+
+	rolledBackBy, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+	eventJSON, err := json.Marshal(RollbackEvent{
+		ListID:       listID,
+		Priority:     "high",
+		RolledBackBy: rolledBackBy,
+		TxID:         ctx.GetStub().GetTxID(),
+		Version:      lastKnownGood.Version,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().SetEvent(rollbackEventName, eventJSON)
+}
@@ -0,0 +1,64 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmergencyRollback(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	require.NoError(t, ws.putState(assetKey("www.bbc.co.uk"), []byte(`{"blocklist":"www.xxx.com"}`)))
+	ws.commit()
+	require.NoError(t, ws.putState(assetKey("www.bbc.co.uk"), []byte(`{"blocklist":"*"}`)))
+	ws.commit()
+
+	err := assetTransfer.MarkLastKnownGood(ws.transactionContext, "www.bbc.co.uk", "tx000")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.EmergencyRollback(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrNotFound, "no last-known-good version of www.bbc.co.uk is marked")
+	err = assetTransfer.MarkLastKnownGood(ws.transactionContext, "www.bbc.co.uk", "")
+	requireError(t, err, chaincode.ErrValidation, "a version is required")
+	err = assetTransfer.MarkLastKnownGood(ws.transactionContext, "www.bbc.co.uk", "tx999")
+	requireError(t, err, chaincode.ErrNotFound, "the asset www.bbc.co.uk has no version tx999")
+
+	require.NoError(t, assetTransfer.MarkLastKnownGood(ws.transactionContext, "www.bbc.co.uk", "tx000"))
+	ws.commit()
+
+	lastKnownGood, err := assetTransfer.GetLastKnownGood(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.LastKnownGood{
+		ListID:   "www.bbc.co.uk",
+		MarkedAt: ws.now.Add(-time.Minute),
+		MarkedBy: "admin",
+		Version:  "tx000",
+	}, lastKnownGood)
+
+	require.NoError(t, assetTransfer.EmergencyRollback(ws.transactionContext, "www.bbc.co.uk"))
+	require.Equal(t, `{"blocklist":"www.xxx.com"}`, string(ws.writes[assetKey("www.bbc.co.uk")]))
+
+	require.Equal(t, 1, ws.stub.SetEventCallCount())
+	name, payload := ws.stub.SetEventArgsForCall(0)
+	require.Equal(t, "EmergencyRollback", name)
+	require.JSONEq(t, `{"listID":"www.bbc.co.uk","priority":"high","rolledBackBy":"admin","txID":"tx003","version":"tx000"}`, string(payload))
+	ws.commit()
+
+	modification, err := assetTransfer.GetLastModification(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "rollback", modification.Operation)
+
+	report, err := assetTransfer.GetUsageReport(ws.transactionContext, ws.now.Format("2006-01"), "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"publish": 1}, report.Records[0].Operations)
+
+	require.NoError(t, ws.delState(assetKey("www.bbc.co.uk")))
+	ws.commit()
+	err = assetTransfer.EmergencyRollback(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.bbc.co.uk does not exist")
+}
@@ -0,0 +1,94 @@
+package chaincode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// maxSampleSize bounds the number of assets GetRandomAssets may return
+const maxSampleSize = 100
+
+type rankedAsset struct {
+	asset *Asset
+	rank  []byte
+	size  int
+}
+
+// GetRandomAssets returns a pseudo-random sample of n assets. Every asset is ranked by the
+// SHA-256 hash of the seed and its ID, and the n lowest ranked assets are returned in rank
+// order, so every endorser, and every later call with the same seed over the same state,
+// returns the same sample. An empty seed uses the transaction ID.
+func (s *SmartContract) GetRandomAssets(ctx contractapi.TransactionContextInterface, n int, seed string) (*AssetList, error) {
+	if n < 1 || n > maxSampleSize {
+		return nil, newError(ErrValidation, map[string]string{"field": "n"}, "the sample size must be between 1 and %d", maxSampleSize)
+	}
+	if seed == "" {
+		seed = ctx.GetStub().GetTxID()
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sample []rankedAsset
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, err
+		}
+
+		id, _, err := assetIDFromKey(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		rank := sha256.Sum256([]byte(seed + compositeKeySeparator + id))
+		if len(sample) == n && bytes.Compare(rank[:], sample[n-1].rank) >= 0 {
+			continue
+		}
+
+		asset, err := unmarshalAsset(id, queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		sample = append(sample, rankedAsset{asset: asset, rank: rank[:], size: len(queryResponse.Value)})
+		sort.Slice(sample, func(i, j int) bool {
+			return bytes.Compare(sample[i].rank, sample[j].rank) < 0
+		})
+		if len(sample) > n {
+			sample = sample[:n]
+		}
+	}
+
+	assets := []*Asset{}
+	for _, ranked := range sample {
+		err = meter.returned(ranked.size)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, ranked.asset)
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata.Cost = &meter.cost
+
+	return &AssetList{Assets: assets, Metadata: metadata}, nil
+}
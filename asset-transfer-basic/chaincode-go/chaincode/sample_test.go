@@ -0,0 +1,34 @@
+package chaincode_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRandomAssets(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, fmt.Sprintf("www.site%d.com", i), "", 1, "", 100))
+	}
+	ws.commit()
+
+	_, err := assetTransfer.GetRandomAssets(ws.transactionContext, 0, "qa")
+	requireError(t, err, chaincode.ErrValidation, "the sample size must be between 1 and 100")
+
+	sample, err := assetTransfer.GetRandomAssets(ws.transactionContext, 3, "qa")
+	require.NoError(t, err)
+	require.Len(t, sample.Assets, 3)
+
+	again, err := assetTransfer.GetRandomAssets(ws.transactionContext, 3, "qa")
+	require.NoError(t, err)
+	require.Equal(t, sample, again)
+
+	sample, err = assetTransfer.GetRandomAssets(ws.transactionContext, 20, "")
+	require.NoError(t, err)
+	require.Len(t, sample.Assets, 10)
+}
@@ -0,0 +1,137 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// scheduleClock is the layout of the start and end of a schedule
+const scheduleClock = "15:04"
+
+// scheduleDays names the days of the week a schedule can be restricted to, indexed by time.Weekday
+var scheduleDays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Schedule restricts the entries of an asset to a daily window, such as 09:00 to 17:00 on
+// weekdays. Start and End are HH:MM times of day, Start inclusive and End exclusive, and Days
+// names the days the window applies on, every day when empty. The window is evaluated in the
+// UTC offset of the time it is checked against, so a device passes its local time.
+type Schedule struct {
+	Days  []string `json:"days,omitempty" metadata:"days,optional"`
+	End   string   `json:"end"`
+	Start string   `json:"start"`
+}
+
+// SetAssetSchedule restricts the allowlist and blocklist of the asset with given id to the
+// schedule in scheduleJSON, or removes its schedule when scheduleJSON is empty. The schedule
+// only affects IsBlockedAt. Only the owner of the asset or an administrator may set it.
+func (s *SmartContract) SetAssetSchedule(ctx contractapi.TransactionContextInterface, id string, scheduleJSON string) error {
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = assertOwnerOrAdmin(ctx, asset.Owner)
+	if err != nil {
+		return err
+	}
+	err = assertMutable(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	asset.Schedule = nil
+	if scheduleJSON != "" {
+		asset.Schedule, err = parseSchedule(scheduleJSON)
+		if err != nil {
+			return err
+		}
+	}
+	err = putAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+
+	err = recordModification(ctx, id, operationUpdate)
+	if err != nil {
+		return err
+	}
+
+	return emitAssetEvent(ctx, assetUpdatedEventName, asset)
+}
+
+// IsBlockedAt reports whether hostname is blocked at the RFC 3339 time at, matching it like
+// MatchDomain against the assets whose schedule is active at that time. The time is supplied
+// by the caller rather than taken from the clock, so that every endorser reaches the same result.
+func (s *SmartContract) IsBlockedAt(ctx contractapi.TransactionContextInterface, hostname string, at string) (bool, error) {
+	t, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return false, newError(ErrValidation, map[string]string{"field": "at"}, "the time %s must be in RFC 3339 format", at)
+	}
+
+	match, err := matchDomain(ctx, hostname, &t)
+	if err != nil {
+		return false, err
+	}
+
+	return match.Verdict == DomainBlocked, nil
+}
+
+// parseSchedule decodes and validates a schedule
+func parseSchedule(scheduleJSON string) (*Schedule, error) {
+	var schedule Schedule
+	decoder := json.NewDecoder(bytes.NewReader([]byte(scheduleJSON)))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule: %v", err)
+	}
+
+	err = schedule.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// validate checks the schedule and pads its times to HH:MM
+func (s *Schedule) validate() error {
+	start, err := time.Parse(scheduleClock, s.Start)
+	if err != nil {
+		return newError(ErrValidation, map[string]string{"field": "schedule"}, "invalid schedule: the start %q must be a time of day as HH:MM", s.Start)
+	}
+	end, err := time.Parse(scheduleClock, s.End)
+	if err != nil {
+		return newError(ErrValidation, map[string]string{"field": "schedule"}, "invalid schedule: the end %q must be a time of day as HH:MM", s.End)
+	}
+	if !start.Before(end) {
+		return newError(ErrValidation, map[string]string{"field": "schedule"}, "invalid schedule: the start %s must be before the end %s", s.Start, s.End)
+	}
+	s.Start = start.Format(scheduleClock)
+	s.End = end.Format(scheduleClock)
+	for _, day := range s.Days {
+		if !stringInSlice(day, scheduleDays) {
+			return newError(ErrValidation, map[string]string{"field": "schedule"}, "invalid schedule: unknown day %s, expected one of %s", day, strings.Join(scheduleDays, ", "))
+		}
+	}
+
+	return nil
+}
+
+// activeAt reports whether the schedule applies at t. A nil schedule always applies.
+func (s *Schedule) activeAt(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+	if len(s.Days) > 0 && !stringInSlice(scheduleDays[t.Weekday()], s.Days) {
+		return false
+	}
+
+	// the times of a stored schedule are padded, so they compare as HH:MM strings
+	clock := t.Format(scheduleClock)
+	return clock >= s.Start && clock < s.End
+}
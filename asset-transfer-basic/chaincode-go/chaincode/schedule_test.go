@@ -0,0 +1,71 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBlockedAt(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.napier.ac.uk", "www.instagram.com", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "*.ac.uk", 1, "", 100))
+	ws.commit()
+
+	err := assetTransfer.SetAssetSchedule(ws.transactionContext, "www.napier.ac.uk", `{"start":"17:00","end":"09:00"}`)
+	requireError(t, err, chaincode.ErrValidation, "invalid schedule: the start 17:00 must be before the end 09:00")
+	err = assetTransfer.SetAssetSchedule(ws.transactionContext, "www.napier.ac.uk", `{"start":"9:00","end":"17:00","days":["monday"]}`)
+	requireError(t, err, chaincode.ErrValidation, "invalid schedule: unknown day monday, expected one of sun, mon, tue, wed, thu, fri, sat")
+	err = assetTransfer.SetAssetSchedule(ws.transactionContext, "www.napier.ac.uk", `{"start":"noon","end":"17:00"}`)
+	requireError(t, err, chaincode.ErrValidation, `invalid schedule: the start "noon" must be a time of day as HH:MM`)
+
+	require.NoError(t, assetTransfer.SetAssetSchedule(ws.transactionContext, "www.napier.ac.uk", `{"start":"9:00","end":"17:00","days":["mon","tue","wed","thu","fri"]}`))
+	ws.commit()
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.napier.ac.uk")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Schedule{Days: []string{"mon", "tue", "wed", "thu", "fri"}, End: "17:00", Start: "09:00"}, asset.Schedule)
+
+	// 2026-10-12 is a Monday, and the window is evaluated in the offset of the supplied time
+	for at, expected := range map[string]bool{
+		"2026-10-12T09:00:00Z":      true,
+		"2026-10-12T16:59:59Z":      true,
+		"2026-10-12T17:00:00Z":      false,
+		"2026-10-12T08:59:00Z":      false,
+		"2026-10-11T12:00:00Z":      false,
+		"2026-10-12T18:30:00+02:00": false,
+	} {
+		blocked, err := assetTransfer.IsBlockedAt(ws.transactionContext, "www.instagram.com", at)
+		require.NoError(t, err, at)
+		require.Equal(t, expected, blocked, at)
+	}
+
+	// outside its schedule an asset does not take part, so a less specific entry decides
+	blocked, err := assetTransfer.IsBlockedAt(ws.transactionContext, "www.napier.ac.uk", "2026-10-12T12:00:00Z")
+	require.NoError(t, err)
+	require.False(t, blocked)
+	blocked, err = assetTransfer.IsBlockedAt(ws.transactionContext, "www.napier.ac.uk", "2026-10-12T20:00:00Z")
+	require.NoError(t, err)
+	require.True(t, blocked)
+
+	_, err = assetTransfer.IsBlockedAt(ws.transactionContext, "www.instagram.com", "2026-10-12 12:00")
+	requireError(t, err, chaincode.ErrValidation, "the time 2026-10-12 12:00 must be in RFC 3339 format")
+
+	// MatchDomain ignores schedules, and updates keep the schedule
+	match, err := assetTransfer.MatchDomain(ws.transactionContext, "www.instagram.com")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.DomainBlocked, match.Verdict)
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.napier.ac.uk", "www.instagram.com,www.tiktok.com", 1, "", 100))
+	ws.commit()
+	blocked, err = assetTransfer.IsBlockedAt(ws.transactionContext, "www.tiktok.com", "2026-10-11T12:00:00Z")
+	require.NoError(t, err)
+	require.False(t, blocked)
+
+	require.NoError(t, assetTransfer.SetAssetSchedule(ws.transactionContext, "www.napier.ac.uk", ""))
+	ws.commit()
+	blocked, err = assetTransfer.IsBlockedAt(ws.transactionContext, "www.instagram.com", "2026-10-11T12:00:00Z")
+	require.NoError(t, err)
+	require.True(t, blocked)
+}
@@ -0,0 +1,155 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// schemaMigrationEventName is the chaincode event emitted after each page of a schema migration
+const schemaMigrationEventName = "SchemaMigrationProgress"
+
+// unversionedSchema is the schema version of assets written before assets recorded their version
+const unversionedSchema = 1
+
+// schemaMigration upgrades an asset from the schema version preceding Version to Version.
+// Upgrades run on every read, so they must neither fail nor depend on the world state.
+type schemaMigration struct {
+	Description string
+	Upgrade     func(asset *Asset)
+	Version     int
+}
+
+// schemaMigrations lists the migrations in ascending order of version, the last one being
+// SchemaVersion. Evolving the stored layout takes a new migration and an increased SchemaVersion:
+// assets are upgraded lazily whenever they are read, and eagerly by MigrateAll.
+var schemaMigrations = []schemaMigration{
+	{Version: 2, Description: "normalize the domains of the blocklist", Upgrade: normalizeStoredBlocklist},
+	// the document is unchanged: rewriting it with putAsset adds the asset to the index
+	{Version: 3, Description: "index the asset by domain", Upgrade: func(*Asset) {}},
+	// the ID is recovered from the key on every read, and rewriting the document with putAsset
+	// replaces the entry of the asset in the domain index by entries for its allowlist and blocklist
+	{Version: 4, Description: "record the asset ID and index the lists by value", Upgrade: func(*Asset) {}},
+}
+
+// SchemaMigrationProgress reports how far a schema migration has advanced. It is returned by
+// MigrateAll and emitted as the payload of a SchemaMigrationProgress event. Pass Bookmark to
+// the next MigrateAll call until Done is true.
+type SchemaMigrationProgress struct {
+	Bookmark      string `json:"bookmark"`
+	Done          bool   `json:"done"`
+	Migrated      int32  `json:"migrated"`
+	Scanned       int32  `json:"scanned"`
+	SchemaVersion int    `json:"schemaVersion"`
+}
+
+// MigrateAll rewrites up to pageSize assets, starting at bookmark, that are stored in an older
+// schema version in the current one. The migration only changes the layout of a document, so
+// immutable assets are migrated as well. It is resumable: each call continues from the bookmark
+// returned by the previous one.
+func (s *SmartContract) MigrateAll(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*SchemaMigrationProgress, error) {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize <= 0 {
+		return nil, newError(ErrValidation, map[string]string{"field": "pageSize"}, "the page size must be positive")
+	}
+
+	// paginated queries are only available to read-only transactions, so the page is bounded by hand
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	progress := SchemaMigrationProgress{SchemaVersion: SchemaVersion}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		id, _, err := assetIDFromKey(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		if id < bookmark {
+			continue
+		}
+		if progress.Scanned == pageSize {
+			progress.Bookmark = id
+			break
+		}
+		progress.Scanned++
+
+		asset, err := decodeAsset(id, queryResponse.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal the asset %s: %v", id, err)
+		}
+		if asset.SchemaVersion > SchemaVersion {
+			return nil, fmt.Errorf("the asset %s is stored in schema version %d, newer than %d", id, asset.SchemaVersion, SchemaVersion)
+		}
+		if !upgradeAsset(asset) {
+			continue
+		}
+		err = putAsset(ctx, asset)
+		if err != nil {
+			return nil, err
+		}
+		progress.Migrated++
+	}
+	progress.Done = progress.Bookmark == ""
+
+	progressJSON, err := json.Marshal(progress)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().SetEvent(schemaMigrationEventName, progressJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	return &progress, nil
+}
+
+// upgradeAsset applies the migrations newer than the schema version of asset and reports
+// whether there were any
+func upgradeAsset(asset *Asset) bool {
+	if asset.SchemaVersion == 0 {
+		asset.SchemaVersion = unversionedSchema
+	}
+
+	upgraded := false
+	for _, migration := range schemaMigrations {
+		if migration.Version <= asset.SchemaVersion {
+			continue
+		}
+		migration.Upgrade(asset)
+		asset.SchemaVersion = migration.Version
+		upgraded = true
+	}
+
+	return upgraded
+}
+
+// normalizeStoredBlocklist normalizes the entries of a blocklist stored before entries were
+// normalized on write. Entries that cannot be normalized are kept as they are.
+func normalizeStoredBlocklist(asset *Asset) {
+	if asset.Blocklist == "" {
+		return
+	}
+
+	var domains []string
+	for _, entry := range strings.Split(asset.Blocklist, domainListSeparator) {
+		domain, err := normalizeDomainList(entry)
+		if err != nil {
+			domain = entry
+		}
+		if domain != "" && !stringInSlice(domain, domains) {
+			domains = append(domains, domain)
+		}
+	}
+	asset.Blocklist = strings.Join(domains, domainListSeparator)
+}
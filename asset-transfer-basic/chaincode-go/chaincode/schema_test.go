@@ -0,0 +1,73 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAssetUpgradesSchema(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	// written before assets recorded their schema version and blocklists were normalized
+	require.NoError(t, ws.putState(assetKey("www.bbc.co.uk"), []byte(`{"allowlist":"www.bbc.co.uk","blocklist":"https://WWW.XXX.com/,www.xxx.com,bad..entry","webfilterlist":100}`)))
+	ws.commit()
+
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Asset{
+		Allowlist:     "www.bbc.co.uk",
+		Blocklist:     "www.xxx.com,bad..entry",
+		ID:            "www.bbc.co.uk",
+		SchemaVersion: chaincode.SchemaVersion,
+		Webfilterlist: 100,
+	}, asset)
+	require.Empty(t, ws.writes)
+}
+
+func TestMigrateAll(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, ws.putState(assetKey("www.bbc.co.uk"), []byte(`{"allowlist":"www.bbc.co.uk","blocklist":"WWW.XXX.COM"}`)))
+	require.NoError(t, ws.putState(assetKey("www.google.com"), []byte(`{"allowlist":"www.google.com","blocklist":"","id":"www.google.com","schemaVersion":4}`)))
+	require.NoError(t, ws.putState(assetKey("www.napier.ac.uk"), []byte(`{"allowlist":"www.napier.ac.uk","blocklist":""}`)))
+	ws.commit()
+
+	_, err := assetTransfer.MigrateAll(ws.transactionContext, 2, "")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	_, err = assetTransfer.MigrateAll(ws.transactionContext, 0, "")
+	requireError(t, err, chaincode.ErrValidation, "the page size must be positive")
+
+	progress, err := assetTransfer.MigrateAll(ws.transactionContext, 2, "")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.SchemaMigrationProgress{Bookmark: "www.napier.ac.uk", Migrated: 1, Scanned: 2, SchemaVersion: chaincode.SchemaVersion}, progress)
+	require.JSONEq(t, `{"allowlist":"www.bbc.co.uk","attribute1":"","attribute2":0,"blocklist":"www.xxx.com","createdAt":"0001-01-01T00:00:00Z","expiresAt":"0001-01-01T00:00:00Z","id":"www.bbc.co.uk","schemaVersion":4,"updatedAt":"2021-03-01T09:01:00Z","webfilterlist":0}`, string(ws.writes[assetKey("www.bbc.co.uk")]))
+	require.NotContains(t, ws.writes, assetKey("www.google.com"))
+
+	name, payload := ws.stub.SetEventArgsForCall(ws.stub.SetEventCallCount() - 1)
+	require.Equal(t, "SchemaMigrationProgress", name)
+	var event chaincode.SchemaMigrationProgress
+	require.NoError(t, json.Unmarshal(payload, &event))
+	require.Equal(t, *progress, event)
+	ws.commit()
+
+	progress, err = assetTransfer.MigrateAll(ws.transactionContext, 2, progress.Bookmark)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.SchemaMigrationProgress{Done: true, Migrated: 1, Scanned: 1, SchemaVersion: chaincode.SchemaVersion}, progress)
+	ws.commit()
+
+	progress, err = assetTransfer.MigrateAll(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.SchemaMigrationProgress{Done: true, Scanned: 3, SchemaVersion: chaincode.SchemaVersion}, progress)
+
+	require.NoError(t, ws.putState(assetKey("www.google.com"), []byte(`{"allowlist":"www.google.com","blocklist":"","schemaVersion":99}`)))
+	ws.commit()
+	_, err = assetTransfer.MigrateAll(ws.transactionContext, 10, "")
+	require.EqualError(t, err, "the asset www.google.com is stored in schema version 99, newer than 4")
+}
@@ -0,0 +1,418 @@
+package chaincode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const deviceObjectType = "device"
+
+const scopePolicyObjectType = "scopepolicy"
+
+// maxDevicePolicyPageSize caps the number of devices resolved by one page of GetEffectivePoliciesForDevices
+const maxDevicePolicyPageSize = 100
+
+// Policy scopes, from the farthest to the closest to a device
+const (
+	ScopeOrg    = "org"
+	ScopeGroup  = "group"
+	ScopeDevice = "device"
+)
+
+// Device places a device within a group of an organization, which decides the policies it
+// inherits. PolicyID names the filter policy whose lists the device enforces, if any.
+type Device struct {
+	GroupID  string `json:"groupID"`
+	ID       string `json:"id"`
+	MSPID    string `json:"mspID"`
+	PolicyID string `json:"policyID,omitempty" metadata:"policyID,optional"`
+}
+
+// ScopedPolicy holds the policy settings attached to an organization, a group or a device
+type ScopedPolicy struct {
+	MSPID     string            `json:"mspID"`
+	Scope     string            `json:"scope"`
+	ScopeID   string            `json:"scopeID"`
+	Settings  map[string]string `json:"settings"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// PolicyOverride records a setting of a farther scope that a closer scope replaced
+type PolicyOverride struct {
+	OverriddenScope string `json:"overriddenScope"`
+	OverriddenValue string `json:"overriddenValue"`
+	Scope           string `json:"scope"`
+	Setting         string `json:"setting"`
+	Value           string `json:"value"`
+}
+
+// EffectivePolicy is the result of merging the policies of a device's organization, group and
+// the device itself. Sources names the scope each setting was taken from.
+type EffectivePolicy struct {
+	DeviceID  string            `json:"deviceID"`
+	GroupID   string            `json:"groupID"`
+	MSPID     string            `json:"mspID"`
+	Overrides []*PolicyOverride `json:"overrides"`
+	Settings  map[string]string `json:"settings"`
+	Sources   map[string]string `json:"sources"`
+}
+
+// DevicePolicyRequest names a device whose effective policy a fleet manager wants to sync.
+// Marker is the marker of the policy the manager last synced, if any.
+type DevicePolicyRequest struct {
+	DeviceID string `json:"deviceID"`
+	Marker   string `json:"marker,omitempty" metadata:"marker,optional"`
+}
+
+// DevicePolicy is the effective policy of one device in a page of GetEffectivePoliciesForDevices.
+// Marker identifies the current policy; Policy is left out when it matches the marker the client
+// supplied, or when the device is not registered.
+type DevicePolicy struct {
+	DeviceID   string           `json:"deviceID"`
+	Marker     string           `json:"marker"`
+	Policy     *EffectivePolicy `json:"policy,omitempty" metadata:"policy,optional"`
+	Registered bool             `json:"registered"`
+	Unchanged  bool             `json:"unchanged"`
+}
+
+// DevicePolicyPage is the response of GetEffectivePoliciesForDevices. Bookmark is empty on the last page.
+type DevicePolicyPage struct {
+	Bookmark string          `json:"bookmark"`
+	Metadata QueryMetadata   `json:"metadata"`
+	Policies []*DevicePolicy `json:"policies"`
+}
+
+// RegisterDevice places a device in a group of the caller's organization, moving it if it was
+// already registered along with its filter policy. Only the device's organization or an
+// administrator may move a device.
+func (s *SmartContract) RegisterDevice(ctx contractapi.TransactionContextInterface, deviceID string, groupID string) error {
+	if deviceID == "" || groupID == "" {
+		return newError(ErrValidation, nil, "a device ID and a group ID are required")
+	}
+
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	existing, err := readDevice(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+	policyID := ""
+	if existing != nil {
+		err = assertOwnerOrAdmin(ctx, existing.MSPID)
+		if err != nil {
+			return err
+		}
+		mspID = existing.MSPID
+		policyID = existing.PolicyID
+	}
+
+	return putDevice(ctx, &Device{GroupID: groupID, ID: deviceID, MSPID: mspID, PolicyID: policyID})
+}
+
+// AssignPolicyToDevice assigns the filter policy policyID to a registered device, or removes its
+// filter policy when policyID is empty. Only the device's organization or an administrator may
+// assign it a policy.
+func (s *SmartContract) AssignPolicyToDevice(ctx contractapi.TransactionContextInterface, deviceID string, policyID string) error {
+	device, err := readDevice(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+	if device == nil {
+		return newError(ErrNotFound, map[string]string{"deviceID": deviceID}, "the device %s does not exist", deviceID)
+	}
+	err = assertOwnerOrAdmin(ctx, device.MSPID)
+	if err != nil {
+		return err
+	}
+	if policyID != "" {
+		_, err = existingFilterPolicy(ctx, policyID)
+		if err != nil {
+			return err
+		}
+	}
+
+	device.PolicyID = policyID
+
+	return putDevice(ctx, device)
+}
+
+// SetScopePolicy attaches the settings in settingsJSON, a JSON object of strings, to a scope of
+// the organization mspID. The scope ID names the group or device and is ignored for the org
+// scope. An empty object removes the policy from the scope.
+func (s *SmartContract) SetScopePolicy(ctx contractapi.TransactionContextInterface, mspID string, scope string, scopeID string, settingsJSON string) error {
+	err := assertOwnerOrAdmin(ctx, mspID)
+	if err != nil {
+		return err
+	}
+
+	switch scope {
+	case ScopeOrg:
+		scopeID = mspID
+	case ScopeGroup, ScopeDevice:
+		if scopeID == "" {
+			return newError(ErrValidation, map[string]string{"field": "scopeID"}, "a scope ID is required for the %s scope", scope)
+		}
+	default:
+		return newError(ErrValidation, map[string]string{"field": "scope"}, "the scope must be %s, %s or %s", ScopeOrg, ScopeGroup, ScopeDevice)
+	}
+
+	settings := map[string]string{}
+	err = json.Unmarshal([]byte(settingsJSON), &settings)
+	if err != nil {
+		return fmt.Errorf("invalid policy settings: %v", err)
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(scopePolicyObjectType, []string{mspID, scope, scopeID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	if len(settings) == 0 {
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return fmt.Errorf("failed to delete from world state: %v", err)
+		}
+		return nil
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	return putRecord(ctx, key, ScopedPolicy{
+		MSPID:     mspID,
+		Scope:     scope,
+		ScopeID:   scopeID,
+		Settings:  settings,
+		UpdatedAt: now,
+	})
+}
+
+// GetEffectivePolicy merges the policies attached to the device's organization, group and the
+// device itself. The closest scope wins for each setting, and every replaced value is reported
+// in Overrides.
+func (s *SmartContract) GetEffectivePolicy(ctx contractapi.TransactionContextInterface, deviceID string) (*EffectivePolicy, error) {
+	device, err := readDevice(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, newError(ErrNotFound, map[string]string{"deviceID": deviceID}, "the device %s does not exist", deviceID)
+	}
+
+	return effectivePolicy(ctx, device)
+}
+
+// GetEffectivePolicyForDevice returns the lists a device enforces: the merged allowlist and
+// blocklist of the filter policy assigned to it, which are empty when it has none.
+func (s *SmartContract) GetEffectivePolicyForDevice(ctx contractapi.TransactionContextInterface, deviceID string) (*ResolvedFilterPolicy, error) {
+	device, err := readDevice(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, newError(ErrNotFound, map[string]string{"deviceID": deviceID}, "the device %s does not exist", deviceID)
+	}
+	if device.PolicyID == "" {
+		return resolveFilterPolicy(ctx, &FilterPolicy{AssetIDs: []string{}})
+	}
+
+	policy, err := existingFilterPolicy(ctx, device.PolicyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveFilterPolicy(ctx, policy)
+}
+
+// GetEffectivePoliciesForDevices resolves the effective policies of up to pageSize of the devices
+// in deviceIDsJSON, a JSON array of DevicePolicyRequest, starting at the device ID bookmark.
+// Policies whose marker matches the one supplied are reported as unchanged without their
+// settings, so a fleet manager only downloads the policies that changed since its last sync.
+func (s *SmartContract) GetEffectivePoliciesForDevices(ctx contractapi.TransactionContextInterface, deviceIDsJSON string, pageSize int32, bookmark string) (*DevicePolicyPage, error) {
+	if pageSize < 1 || pageSize > maxDevicePolicyPageSize {
+		return nil, newError(ErrValidation, map[string]string{"field": "pageSize"}, "the page size must be between 1 and %d", maxDevicePolicyPageSize)
+	}
+
+	var requests []*DevicePolicyRequest
+	decoder := json.NewDecoder(bytes.NewReader([]byte(deviceIDsJSON)))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&requests)
+	if err != nil {
+		return nil, fmt.Errorf("invalid device list: %v", err)
+	}
+
+	start := 0
+	seen := map[string]bool{}
+	for i, request := range requests {
+		if request == nil || request.DeviceID == "" {
+			return nil, newError(ErrValidation, map[string]string{"field": "deviceIDsJSON"}, "invalid device list: every entry requires a device ID")
+		}
+		if seen[request.DeviceID] {
+			return nil, newError(ErrValidation, map[string]string{"deviceID": request.DeviceID}, "the device %s appears more than once in the list", request.DeviceID)
+		}
+		seen[request.DeviceID] = true
+		if request.DeviceID == bookmark {
+			start = i
+		}
+	}
+	if bookmark != "" && !seen[bookmark] {
+		return nil, newError(ErrValidation, map[string]string{"field": "bookmark"}, "the bookmark %s is not a device of the list", bookmark)
+	}
+
+	page := &DevicePolicyPage{Policies: []*DevicePolicy{}}
+	end := start + int(pageSize)
+	if end < len(requests) {
+		page.Bookmark = requests[end].DeviceID
+	} else {
+		end = len(requests)
+	}
+
+	for _, request := range requests[start:end] {
+		policy, err := devicePolicy(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		page.Policies = append(page.Policies, policy)
+	}
+
+	page.Metadata, err = queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// devicePolicy resolves the effective policy of the requested device and compares it to the
+// marker the client supplied
+func devicePolicy(ctx contractapi.TransactionContextInterface, request *DevicePolicyRequest) (*DevicePolicy, error) {
+	policy := &DevicePolicy{DeviceID: request.DeviceID}
+
+	device, err := readDevice(ctx, request.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return policy, nil
+	}
+	policy.Registered = true
+
+	effective, err := effectivePolicy(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	policy.Marker, err = policyMarker(effective)
+	if err != nil {
+		return nil, err
+	}
+	policy.Unchanged = policy.Marker == request.Marker
+	if !policy.Unchanged {
+		policy.Policy = effective
+	}
+
+	return policy, nil
+}
+
+// policyMarker returns the hex encoded SHA-256 digest of the JSON encoding of an effective
+// policy, which changes whenever any of its settings, sources or overrides change
+func policyMarker(policy *EffectivePolicy) (string, error) {
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(policyJSON)
+
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// effectivePolicy merges the policies attached to the device's organization, group and the device itself
+func effectivePolicy(ctx contractapi.TransactionContextInterface, device *Device) (*EffectivePolicy, error) {
+	effective := &EffectivePolicy{
+		DeviceID:  device.ID,
+		GroupID:   device.GroupID,
+		MSPID:     device.MSPID,
+		Overrides: []*PolicyOverride{},
+		Settings:  map[string]string{},
+		Sources:   map[string]string{},
+	}
+
+	chain := []struct{ scope, scopeID string }{
+		{ScopeOrg, device.MSPID},
+		{ScopeGroup, device.GroupID},
+		{ScopeDevice, device.ID},
+	}
+	for _, link := range chain {
+		key, err := ctx.GetStub().CreateCompositeKey(scopePolicyObjectType, []string{device.MSPID, link.scope, link.scopeID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create composite key: %v", err)
+		}
+
+		var policy ScopedPolicy
+		_, err = getRecord(ctx, key, &policy)
+		if err != nil {
+			return nil, err
+		}
+
+		settings := make([]string, 0, len(policy.Settings))
+		for setting := range policy.Settings {
+			settings = append(settings, setting)
+		}
+		sort.Strings(settings)
+
+		for _, setting := range settings {
+			value := policy.Settings[setting]
+			if source, ok := effective.Sources[setting]; ok {
+				effective.Overrides = append(effective.Overrides, &PolicyOverride{
+					OverriddenScope: source,
+					OverriddenValue: effective.Settings[setting],
+					Scope:           link.scope,
+					Setting:         setting,
+					Value:           value,
+				})
+			}
+			effective.Settings[setting] = value
+			effective.Sources[setting] = link.scope
+		}
+	}
+
+	return effective, nil
+}
+
+// readDevice returns the registered device, or nil if it has not been registered
+func readDevice(ctx contractapi.TransactionContextInterface, deviceID string) (*Device, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(deviceObjectType, []string{deviceID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var device Device
+	exists, err := getRecord(ctx, key, &device)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	return &device, nil
+}
+
+func putDevice(ctx contractapi.TransactionContextInterface, device *Device) error {
+	key, err := ctx.GetStub().CreateCompositeKey(deviceObjectType, []string{device.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, device)
+}
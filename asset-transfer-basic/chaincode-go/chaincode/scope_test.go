@@ -0,0 +1,186 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEffectivePolicy(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.RegisterDevice(ws.transactionContext, "laptop1", "staff"))
+	require.NoError(t, assetTransfer.SetScopePolicy(ws.transactionContext, "Org1MSP", chaincode.ScopeOrg, "", `{"blocklist":"www.xxx.com","safesearch":"on"}`))
+	require.NoError(t, assetTransfer.SetScopePolicy(ws.transactionContext, "Org1MSP", chaincode.ScopeGroup, "staff", `{"blocklist":"www.xxx.com,www.yyy.com"}`))
+	require.NoError(t, assetTransfer.SetScopePolicy(ws.transactionContext, "Org1MSP", chaincode.ScopeDevice, "laptop1", `{"blocklist":"","schedule":"office-hours"}`))
+	ws.commit()
+
+	policy, err := assetTransfer.GetEffectivePolicy(ws.transactionContext, "laptop1")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.EffectivePolicy{
+		DeviceID: "laptop1",
+		GroupID:  "staff",
+		MSPID:    "Org1MSP",
+		Overrides: []*chaincode.PolicyOverride{
+			{OverriddenScope: chaincode.ScopeOrg, OverriddenValue: "www.xxx.com", Scope: chaincode.ScopeGroup, Setting: "blocklist", Value: "www.xxx.com,www.yyy.com"},
+			{OverriddenScope: chaincode.ScopeGroup, OverriddenValue: "www.xxx.com,www.yyy.com", Scope: chaincode.ScopeDevice, Setting: "blocklist", Value: ""},
+		},
+		Settings: map[string]string{"blocklist": "", "safesearch": "on", "schedule": "office-hours"},
+		Sources:  map[string]string{"blocklist": chaincode.ScopeDevice, "safesearch": chaincode.ScopeOrg, "schedule": chaincode.ScopeDevice},
+	}, policy)
+
+	require.NoError(t, assetTransfer.SetScopePolicy(ws.transactionContext, "Org1MSP", chaincode.ScopeDevice, "laptop1", `{}`))
+	ws.commit()
+
+	policy, err = assetTransfer.GetEffectivePolicy(ws.transactionContext, "laptop1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"blocklist": "www.xxx.com,www.yyy.com", "safesearch": "on"}, policy.Settings)
+	require.Len(t, policy.Overrides, 1)
+
+	_, err = assetTransfer.GetEffectivePolicy(ws.transactionContext, "laptop2")
+	requireError(t, err, chaincode.ErrNotFound, "the device laptop2 does not exist")
+}
+
+func TestGetEffectivePoliciesForDevices(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.RegisterDevice(ws.transactionContext, "laptop1", "staff"))
+	require.NoError(t, assetTransfer.RegisterDevice(ws.transactionContext, "laptop2", "staff"))
+	require.NoError(t, assetTransfer.SetScopePolicy(ws.transactionContext, "Org1MSP", chaincode.ScopeGroup, "staff", `{"safesearch":"on"}`))
+	ws.commit()
+
+	devices := `[{"deviceID":"laptop1"},{"deviceID":"laptop2"},{"deviceID":"laptop3"}]`
+	page, err := assetTransfer.GetEffectivePoliciesForDevices(ws.transactionContext, devices, 2, "")
+	require.NoError(t, err)
+	require.Equal(t, "laptop3", page.Bookmark)
+	require.Len(t, page.Policies, 2)
+	require.True(t, page.Policies[0].Registered)
+	require.False(t, page.Policies[0].Unchanged)
+	require.Equal(t, map[string]string{"safesearch": "on"}, page.Policies[0].Policy.Settings)
+	require.Len(t, page.Policies[0].Marker, 64)
+	marker := page.Policies[0].Marker
+
+	page, err = assetTransfer.GetEffectivePoliciesForDevices(ws.transactionContext, devices, 2, page.Bookmark)
+	require.NoError(t, err)
+	require.Equal(t, "", page.Bookmark)
+	require.Equal(t, []*chaincode.DevicePolicy{{DeviceID: "laptop3"}}, page.Policies)
+
+	page, err = assetTransfer.GetEffectivePoliciesForDevices(ws.transactionContext, `[{"deviceID":"laptop1","marker":"`+marker+`"}]`, 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.DevicePolicy{{DeviceID: "laptop1", Marker: marker, Registered: true, Unchanged: true}}, page.Policies)
+
+	require.NoError(t, assetTransfer.SetScopePolicy(ws.transactionContext, "Org1MSP", chaincode.ScopeDevice, "laptop1", `{"schedule":"office-hours"}`))
+	ws.commit()
+
+	page, err = assetTransfer.GetEffectivePoliciesForDevices(ws.transactionContext, `[{"deviceID":"laptop1","marker":"`+marker+`"}]`, 10, "")
+	require.NoError(t, err)
+	require.False(t, page.Policies[0].Unchanged)
+	require.NotEqual(t, marker, page.Policies[0].Marker)
+	require.Equal(t, map[string]string{"safesearch": "on", "schedule": "office-hours"}, page.Policies[0].Policy.Settings)
+
+	_, err = assetTransfer.GetEffectivePoliciesForDevices(ws.transactionContext, devices, 0, "")
+	requireError(t, err, chaincode.ErrValidation, "the page size must be between 1 and 100")
+
+	_, err = assetTransfer.GetEffectivePoliciesForDevices(ws.transactionContext, devices, 2, "laptop4")
+	requireError(t, err, chaincode.ErrValidation, "the bookmark laptop4 is not a device of the list")
+
+	_, err = assetTransfer.GetEffectivePoliciesForDevices(ws.transactionContext, `[{"deviceID":"laptop1"},{"deviceID":"laptop1"}]`, 2, "")
+	requireError(t, err, chaincode.ErrValidation, "the device laptop1 appears more than once in the list")
+
+	_, err = assetTransfer.GetEffectivePoliciesForDevices(ws.transactionContext, `[{"deviceID":"laptop1","version":"1"}]`, 2, "")
+	require.Error(t, err)
+}
+
+func TestSetScopePolicy(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	err := assetTransfer.SetScopePolicy(ws.transactionContext, "Org2MSP", chaincode.ScopeOrg, "", `{"safesearch":"on"}`)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+
+	err = assetTransfer.SetScopePolicy(ws.transactionContext, "Org1MSP", "building", "hq", `{"safesearch":"on"}`)
+	requireError(t, err, chaincode.ErrValidation, "the scope must be org, group or device")
+
+	err = assetTransfer.SetScopePolicy(ws.transactionContext, "Org1MSP", chaincode.ScopeGroup, "", `{"safesearch":"on"}`)
+	requireError(t, err, chaincode.ErrValidation, "a scope ID is required for the group scope")
+
+	err = assetTransfer.SetScopePolicy(ws.transactionContext, "Org1MSP", chaincode.ScopeGroup, "staff", `{"safesearch":true}`)
+	require.Error(t, err)
+
+	ws.setClient("Org2MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetScopePolicy(ws.transactionContext, "Org1MSP", chaincode.ScopeGroup, "staff", `{"safesearch":"on"}`))
+}
+
+func TestRegisterDevice(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	err := assetTransfer.RegisterDevice(ws.transactionContext, "laptop1", "")
+	requireError(t, err, chaincode.ErrValidation, "a device ID and a group ID are required")
+
+	require.NoError(t, assetTransfer.RegisterDevice(ws.transactionContext, "laptop1", "staff"))
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", false)
+	err = assetTransfer.RegisterDevice(ws.transactionContext, "laptop1", "guests")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.RegisterDevice(ws.transactionContext, "laptop1", "guests"))
+	ws.commit()
+
+	policy, err := assetTransfer.GetEffectivePolicy(ws.transactionContext, "laptop1")
+	require.NoError(t, err)
+	require.Equal(t, "guests", policy.GroupID)
+	require.Equal(t, "Org1MSP", policy.MSPID)
+}
+
+func TestAssignPolicyToDevice(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.napier.ac.uk", "www.instagram.com", 1, "", 100))
+	require.NoError(t, assetTransfer.RegisterDevice(ws.transactionContext, "laptop1", "year7"))
+	ws.commit()
+
+	err := assetTransfer.AssignPolicyToDevice(ws.transactionContext, "laptop2", "year7")
+	requireError(t, err, chaincode.ErrNotFound, "the device laptop2 does not exist")
+	err = assetTransfer.AssignPolicyToDevice(ws.transactionContext, "laptop1", "year7")
+	requireError(t, err, chaincode.ErrNotFound, "the policy year7 does not exist")
+
+	lists, err := assetTransfer.GetEffectivePolicyForDevice(ws.transactionContext, "laptop1")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.ResolvedFilterPolicy{Allowlist: []string{}, AssetIDs: []string{}, Blocklist: []string{}, Missing: []string{}}, lists)
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.CreatePolicy(ws.transactionContext, "year7", "Year 7 policy"))
+	ws.commit()
+	require.NoError(t, assetTransfer.AddAssetToPolicy(ws.transactionContext, "year7", "www.napier.ac.uk"))
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", false)
+	err = assetTransfer.AssignPolicyToDevice(ws.transactionContext, "laptop1", "year7")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.AssignPolicyToDevice(ws.transactionContext, "laptop1", "year7"))
+	ws.commit()
+	// moving the device to another group keeps its filter policy
+	require.NoError(t, assetTransfer.RegisterDevice(ws.transactionContext, "laptop1", "year8"))
+	ws.commit()
+
+	lists, err = assetTransfer.GetEffectivePolicyForDevice(ws.transactionContext, "laptop1")
+	require.NoError(t, err)
+	require.Equal(t, "year7", lists.ID)
+	require.Equal(t, []string{"www.napier.ac.uk"}, lists.Allowlist)
+	require.Equal(t, []string{"www.instagram.com"}, lists.Blocklist)
+
+	require.NoError(t, assetTransfer.AssignPolicyToDevice(ws.transactionContext, "laptop1", ""))
+	ws.commit()
+	lists, err = assetTransfer.GetEffectivePolicyForDevice(ws.transactionContext, "laptop1")
+	require.NoError(t, err)
+	require.Empty(t, lists.Blocklist)
+}
@@ -3,8 +3,11 @@ package chaincode
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
 )
 
 // SmartContract provides functions for managing an Asset
@@ -13,128 +16,422 @@ type SmartContract struct {
 }
 
 // Asset describes basic details of what makes up a simple asset
-//Insert struct field in alphabetic order => to achieve determinism accross languages
+// Insert struct field in alphabetic order => to achieve determinism accross languages
 // golang keeps the order when marshal to json but doesn't order automatically
 type Asset struct {
-	webfilterlist int    `json:"webfilterlist"`
-	blocklist     string `json:"blocklist"`
-	allowlist     string `json:"allowlist"`
-	attribute1    string `json:"attribute1"`
-	attribute2    int    `json:"attribute2"`
+	Allowlist           string    `json:"allowlist"`
+	Attribute1          string    `json:"attribute1"`
+	Attribute2          int       `json:"attribute2"`
+	Blocklist           string    `json:"blocklist"`
+	BlocklistCollection string    `json:"blocklistCollection,omitempty" metadata:"blocklistCollection,optional"`
+	BlocklistHash       string    `json:"blocklistHash,omitempty" metadata:"blocklistHash,optional"`
+	Category            string    `json:"category,omitempty" metadata:"category,optional"`
+	CreatedAt           time.Time `json:"createdAt"`
+	ExpiresAt           time.Time `json:"expiresAt"`
+	ID                  string    `json:"id,omitempty" metadata:"id,optional"`
+	Owner               string    `json:"owner,omitempty" metadata:"owner,optional"`
+	RuleType            string    `json:"ruleType,omitempty" metadata:"ruleType,optional"`
+	Schedule            *Schedule `json:"schedule,omitempty" metadata:"schedule,optional"`
+	SchemaVersion       int       `json:"schemaVersion,omitempty" metadata:"schemaVersion,optional"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+	Webfilterlist       int       `json:"webfilterlist"`
 }
 
 // InitLedger adds a base set of assets to the ledger
 func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
 	assets := []Asset{
-		{allowlist: "www.google.com", blocklist: "", attribute2: 5, attribute1: "", webfilterlist: 300},
-		{allowlist: "", blocklist: "www.xxx.com", attribute2: 5, attribute1: "", webfilterlist: 400},
-		{allowlist: "www.bbc.co.uk", blocklist: "", attribute2: 10, attribute1: "", webfilterlist: 500},
-		{allowlist: "https://scholar.google.com/", blocklist: "", attribute2: 10, attribute1: "", webfilterlist: 600},
-		{allowlist: "", blocklist: "www.instagram.com", attribute2: 15, attribute1: "", webfilterlist: 700},
-		{allowlist: "www.napier.ac.uk", blocklist: "", attribute2: 15, attribute1: "", webfilterlist: 800},
+		{Allowlist: "www.google.com", Blocklist: "", Attribute2: 5, Attribute1: "", Webfilterlist: 300},
+		{Allowlist: "", Blocklist: "www.xxx.com", Attribute2: 5, Attribute1: "", Webfilterlist: 400},
+		{Allowlist: "www.bbc.co.uk", Blocklist: "", Attribute2: 10, Attribute1: "", Webfilterlist: 500},
+		{Allowlist: "scholar.google.com", Blocklist: "", Attribute2: 10, Attribute1: "", Webfilterlist: 600},
+		{Allowlist: "", Blocklist: "www.instagram.com", Attribute2: 15, Attribute1: "", Webfilterlist: 700},
+		{Allowlist: "www.napier.ac.uk", Blocklist: "", Attribute2: 15, Attribute1: "", Webfilterlist: 800},
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
 	}
 
 	for _, asset := range assets {
+		asset.ID = defaultAssetID(ctx, asset.Allowlist)
+		asset.CreatedAt = now
+		asset.SchemaVersion = SchemaVersion
+		asset.UpdatedAt = now
+		key, err := assetKey(ctx, asset.ID)
+		if err != nil {
+			return err
+		}
 		assetJSON, err := json.Marshal(asset)
 		if err != nil {
 			return err
 		}
 
-		err = ctx.GetStub().PutState(asset.allowlist, assetJSON)
+		err = ctx.GetStub().PutState(key, assetJSON)
 		if err != nil {
 			return fmt.Errorf("failed to put to world state. %v", err)
 		}
+		err = reindexAsset(ctx, nil, &asset)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// CreateAsset issues a new asset to the world state with given details.
+// CreateAsset issues a new asset to the world state with given details. The allowlist and
+// blocklist are normalized first, and the asset is stored under the normalized allowlist, or
+// under an ID derived from the transaction when the allowlist is empty.
 func (s *SmartContract) CreateAsset(ctx contractapi.TransactionContextInterface, allowlist string, blocklist string, attribute2 int, attribute1 string, webfilterlist int) error {
-	exists, err := s.AssetExists(ctx, allowlist)
+	return s.createAsset(ctx, Asset{
+		Allowlist:     allowlist,
+		Blocklist:     blocklist,
+		Attribute2:    attribute2,
+		Attribute1:    attribute1,
+		Webfilterlist: webfilterlist,
+	})
+}
+
+// CreateAssetWithID issues a new asset like CreateAsset, but stores it under the given ID
+// rather than its allowlist, so that several assets may list the same domain.
+func (s *SmartContract) CreateAssetWithID(ctx contractapi.TransactionContextInterface, id string, allowlist string, blocklist string, attribute2 int, attribute1 string, webfilterlist int) error {
+	if id == "" {
+		return newError(ErrValidation, map[string]string{"field": "id"}, "the asset ID must not be empty")
+	}
+
+	return s.createAsset(ctx, Asset{
+		Allowlist:     allowlist,
+		Blocklist:     blocklist,
+		Attribute2:    attribute2,
+		Attribute1:    attribute1,
+		ID:            id,
+		Webfilterlist: webfilterlist,
+	})
+}
+
+// createAsset normalizes and issues asset, defaulting its ID
+func (s *SmartContract) createAsset(ctx contractapi.TransactionContextInterface, asset Asset) error {
+	err := prepareNewAsset(ctx, &asset)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.AssetExists(ctx, asset.ID)
 	if err != nil {
 		return err
 	}
 	if exists {
-		return fmt.Errorf("the asset %s already exists", allowlist)
+		return assetExistsError(asset.ID)
+	}
+	asset.Owner, err = callerID(ctx)
+	if err != nil {
+		return err
 	}
 
-	asset := Asset{
-		allowlist:     allowlist,
-		blocklist:     blocklist,
-		attribute2:    attribute2,
-		attribute1:    attribute1,
-		webfilterlist: webfilterlist,
+	err = chargeQuota(ctx, &asset)
+	if err != nil {
+		return err
 	}
-	assetJSON, err := json.Marshal(asset)
+
+	err = meterOperation(ctx, meterCreate, 1)
+	if err != nil {
+		return err
+	}
+
+	err = putAsset(ctx, &asset)
+	if err != nil {
+		return err
+	}
+
+	err = recordModification(ctx, asset.ID, operationCreate)
 	if err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(allowlist, assetJSON)
+	return emitAssetEvent(ctx, assetCreatedEventName, &asset)
 }
 
-// ReadAsset returns the asset stored in the world state with given allowlist.
-func (s *SmartContract) ReadAsset(ctx contractapi.TransactionContextInterface, allowlist string) (*Asset, error) {
-	assetJSON, err := ctx.GetStub().GetState(allowlist)
+// prepareNewAsset validates the ID of a new asset, or its allowlist when it has none, its
+// category and its schedule, then normalizes the asset and defaults its ID
+func prepareNewAsset(ctx contractapi.TransactionContextInterface, asset *Asset) error {
+	requested := asset.ID
+	if requested == "" {
+		requested = asset.Allowlist
+	}
+	err := validateAssetID(requested)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read from world state: %v", err)
+		return err
 	}
-	if assetJSON == nil {
-		return nil, fmt.Errorf("the asset %s does not exist", allowlist)
+	err = assertCategoryExists(ctx, asset.Category)
+	if err != nil {
+		return err
+	}
+	if asset.Schedule != nil {
+		err = asset.Schedule.validate()
+		if err != nil {
+			return err
+		}
+	}
+	err = normalizeAsset(asset)
+	if err != nil {
+		return err
+	}
+	// the timestamps are stamped from the transaction, never taken from the client
+	asset.CreatedAt = time.Time{}
+	asset.UpdatedAt = time.Time{}
+	if asset.ID == "" {
+		asset.ID = defaultAssetID(ctx, asset.Allowlist)
+	}
+
+	return nil
+}
+
+// ReadAsset returns the asset stored in the world state with given ID. An asset that has
+// expired by the time of the transaction does not exist.
+func (s *SmartContract) ReadAsset(ctx contractapi.TransactionContextInterface, id string) (*Asset, error) {
+	asset, err := readStoredAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !asset.ExpiresAt.IsZero() {
+		now, err := txTime(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if asset.expiredAt(now) {
+			return nil, assetNotFoundError(id)
+		}
 	}
 
-	var asset Asset
-	err = json.Unmarshal(assetJSON, &asset)
+	return asset, nil
+}
+
+// readStoredAsset returns the asset stored with given ID, whether or not it has expired
+func readStoredAsset(ctx contractapi.TransactionContextInterface, id string) (*Asset, error) {
+	key, err := assetKey(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	assetJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if assetJSON == nil {
+		return nil, assetNotFoundError(id)
+	}
 
-	return &asset, nil
+	return unmarshalAsset(id, assetJSON)
 }
 
-// UpdateAsset updates an existing asset in the world state with provallowlisted parameters.
-func (s *SmartContract) UpdateAsset(ctx contractapi.TransactionContextInterface, allowlist string, blocklist string, attribute2 int, attribute1 string, webfilterlist int) error {
-	exists, err := s.AssetExists(ctx, allowlist)
+// putAsset stamps asset with the transaction time and stores it under the composite key of its
+// ID, moving its index entries from the version it replaces
+func putAsset(ctx contractapi.TransactionContextInterface, asset *Asset) error {
+	asset.SchemaVersion = SchemaVersion
+	key, err := assetKey(ctx, asset.ID)
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return fmt.Errorf("the asset %s does not exist", allowlist)
+	previousJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	var previous *Asset
+	if previousJSON != nil {
+		previous, err = decodeAsset(asset.ID, previousJSON)
+		if err != nil {
+			return err
+		}
 	}
 
-	// overwriting original asset with new asset
-	asset := Asset{
-		allowlist:     allowlist,
-		blocklist:     blocklist,
-		attribute2:    attribute2,
-		attribute1:    attribute1,
-		webfilterlist: webfilterlist,
+	// the transaction timestamp is the same on every endorser, unlike the local clock
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	if asset.CreatedAt.IsZero() && previous == nil {
+		asset.CreatedAt = now
 	}
+	asset.UpdatedAt = now
+
 	assetJSON, err := json.Marshal(asset)
 	if err != nil {
 		return err
 	}
+	err = putState(ctx, key, assetJSON)
+	if err != nil {
+		return err
+	}
+
+	return reindexAsset(ctx, previous, asset)
+}
+
+// UpdateAsset updates an existing asset in the world state with provided parameters.
+func (s *SmartContract) UpdateAsset(ctx contractapi.TransactionContextInterface, id string, blocklist string, attribute2 int, attribute1 string, webfilterlist int) error {
+	asset, err := s.updateAsset(ctx, id, blocklist, attribute2, attribute1, webfilterlist)
+	if err != nil {
+		return err
+	}
 
-	return ctx.GetStub().PutState(allowlist, assetJSON)
+	return emitAssetEvent(ctx, assetUpdatedEventName, asset)
+}
+
+// updateAsset overwrites an existing asset without emitting an event and returns the asset written
+func (s *SmartContract) updateAsset(ctx contractapi.TransactionContextInterface, id string, blocklist string, attribute2 int, attribute1 string, webfilterlist int) (*Asset, error) {
+	existing, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = assertOwnerOrAdmin(ctx, existing.Owner)
+	if err != nil {
+		return nil, err
+	}
+	if existing.BlocklistCollection != "" {
+		return nil, newError(ErrValidation, map[string]string{"id": id}, "the blocklist of %s is private and cannot be updated", id)
+	}
+	blocklist, err = normalizeBlocklist(existing.RuleType, blocklist)
+	if err != nil {
+		return nil, err
+	}
+	err = assertMutable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// overwriting original asset with new asset, which keeps its allowlist, category, creation
+	// time, expiry, owner, rule type and schedule
+	asset := Asset{
+		Allowlist:     existing.Allowlist,
+		Blocklist:     blocklist,
+		Attribute2:    attribute2,
+		Attribute1:    attribute1,
+		Category:      existing.Category,
+		CreatedAt:     existing.CreatedAt,
+		ExpiresAt:     existing.ExpiresAt,
+		ID:            existing.ID,
+		Owner:         existing.Owner,
+		RuleType:      existing.RuleType,
+		Schedule:      existing.Schedule,
+		Webfilterlist: webfilterlist,
+	}
+	err = rechargeQuota(ctx, &asset)
+	if err != nil {
+		return nil, err
+	}
+	err = putAsset(ctx, &asset)
+	if err != nil {
+		return nil, err
+	}
+	err = meterOperation(ctx, meterUpdate, 1)
+	if err != nil {
+		return nil, err
+	}
+	if asset.Blocklist != existing.Blocklist {
+		err = meterOperation(ctx, meterPublish, 1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = recordModification(ctx, id, operationUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &asset, nil
 }
 
 // DeleteAsset deletes an given asset from the world state.
-func (s *SmartContract) DeleteAsset(ctx contractapi.TransactionContextInterface, allowlist string) error {
-	exists, err := s.AssetExists(ctx, allowlist)
+func (s *SmartContract) DeleteAsset(ctx contractapi.TransactionContextInterface, id string) error {
+	err := assertAdmin(ctx)
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return fmt.Errorf("the asset %s does not exist", allowlist)
+
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = deleteAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+	err = releaseQuota(ctx, id)
+	if err != nil {
+		return err
 	}
 
-	return ctx.GetStub().DelState(allowlist)
+	return emitAssetEvent(ctx, assetDeletedEventName, asset)
 }
 
-// AssetExists returns true when asset with given allowlist exists in world state
-func (s *SmartContract) AssetExists(ctx contractapi.TransactionContextInterface, allowlist string) (bool, error) {
-	assetJSON, err := ctx.GetStub().GetState(allowlist)
+// deleteAsset removes an existing asset and the records attached to it without emitting an
+// event or releasing its quota, which the caller releases for all the assets it deletes at once
+func deleteAsset(ctx contractapi.TransactionContextInterface, asset *Asset) error {
+	id := asset.ID
+	err := assertMutable(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = deleteAssetLinks(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = deleteByPartialCompositeKey(ctx, hitObjectType, []string{id})
+	if err != nil {
+		return err
+	}
+
+	err = deletePrivateBlocklist(ctx, asset)
+	if err != nil {
+		return err
+	}
+
+	err = deleteAssetAliases(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	transfer, err := readPendingTransfer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if transfer != nil {
+		err = deletePendingTransfer(ctx, transfer)
+		if err != nil {
+			return err
+		}
+	}
+
+	key, err := assetKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(key)
+	if err != nil {
+		return err
+	}
+	err = reindexAsset(ctx, asset, nil)
+	if err != nil {
+		return err
+	}
+
+	return recordModification(ctx, id, operationDelete)
+}
+
+// AssetExists returns true when asset with given ID exists in world state
+func (s *SmartContract) AssetExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+	key, err := assetKey(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	assetJSON, err := ctx.GetStub().GetState(key)
 	if err != nil {
 		return false, fmt.Errorf("failed to read from world state: %v", err)
 	}
@@ -142,22 +439,24 @@ func (s *SmartContract) AssetExists(ctx contractapi.TransactionContextInterface,
 	return assetJSON != nil, nil
 }
 
-// TransferAsset updates the attribute1 field of asset with given allowlist in world state, and returns the old attribute1.
-func (s *SmartContract) TransferAsset(ctx contractapi.TransactionContextInterface, allowlist string, newattribute1 string) (string, error) {
-	asset, err := s.ReadAsset(ctx, allowlist)
+// TransferAsset updates the attribute1 field of asset with given ID in world state, and returns the old attribute1.
+// The asset is then owned by newattribute1, a client ID or an MSP ID.
+func (s *SmartContract) TransferAsset(ctx contractapi.TransactionContextInterface, id string, newattribute1 string) (string, error) {
+	asset, err := s.ReadAsset(ctx, id)
 	if err != nil {
 		return "", err
 	}
-
-	oldattribute1 := asset.attribute1
-	asset.attribute1 = newattribute1
-
-	assetJSON, err := json.Marshal(asset)
+	err = assertOwnerOrAdmin(ctx, asset.Owner)
+	if err != nil {
+		return "", err
+	}
+	err = assertMutable(ctx, id)
 	if err != nil {
 		return "", err
 	}
 
-	err = ctx.GetStub().PutState(allowlist, assetJSON)
+	oldattribute1 := asset.Attribute1
+	err = s.transferAsset(ctx, asset, newattribute1)
 	if err != nil {
 		return "", err
 	}
@@ -165,30 +464,260 @@ func (s *SmartContract) TransferAsset(ctx contractapi.TransactionContextInterfac
 	return oldattribute1, nil
 }
 
+// transferAsset sets the attribute1 and the owner of asset to newattribute1, carries the
+// transfer to linked assets and records it, without checking that the caller may transfer
+// the asset
+func (s *SmartContract) transferAsset(ctx contractapi.TransactionContextInterface, asset *Asset, newattribute1 string) error {
+	oldOwner := asset.Owner
+	asset.Attribute1 = newattribute1
+	asset.Owner = newattribute1
+
+	err := putAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+	newOwnerMSP, err := ownerOrganization(ctx, asset.Owner)
+	if err != nil {
+		return err
+	}
+	if newOwnerMSP != "" {
+		err = moveQuotaCharge(ctx, asset.ID, newOwnerMSP)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = recordTransfer(ctx, asset.ID, oldOwner, asset.Owner)
+	if err != nil {
+		return err
+	}
+
+	carried, err := s.carryTransfer(ctx, asset.ID, oldOwner, asset.Owner, newOwnerMSP)
+	if err != nil {
+		return err
+	}
+
+	err = recordModification(ctx, asset.ID, operationTransfer)
+	if err != nil {
+		return err
+	}
+
+	return emitAssetEvent(ctx, assetTransferredEventName, append([]*Asset{asset}, carried...)...)
+}
+
+// AssetList is the response of GetAllAssets
+type AssetList struct {
+	Assets   []*Asset      `json:"assets"`
+	Metadata QueryMetadata `json:"metadata"`
+}
+
 // GetAllAssets returns all assets found in world state
-func (s *SmartContract) GetAllAssets(ctx contractapi.TransactionContextInterface) ([]*Asset, error) {
-	// range query with empty string for startKey and endKey does an
-	// open-ended query of all assets in the chaincode namespace.
-	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+func (s *SmartContract) GetAllAssets(ctx contractapi.TransactionContextInterface) (*AssetList, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetObjectType, []string{})
 	if err != nil {
 		return nil, err
 	}
 	defer resultsIterator.Close()
 
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var assets []*Asset
 	for resultsIterator.HasNext() {
 		queryResponse, err := resultsIterator.Next()
 		if err != nil {
 			return nil, err
 		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.returned(len(queryResponse.Value))
+		if err != nil {
+			return nil, err
+		}
+
+		asset, err := unmarshalStoredAsset(ctx, queryResponse.Key, queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Cost = &meter.cost
+
+	return &AssetList{Assets: assets, Metadata: metadata}, nil
+}
+
+// maxAssetPageSize caps the number of assets returned by one page of GetAllAssetsPaginated
+const maxAssetPageSize = 100
+
+// PaginatedAssetList is the response of GetAllAssetsPaginated. Bookmark is empty on the last page.
+type PaginatedAssetList struct {
+	Assets              []*Asset      `json:"assets"`
+	Bookmark            string        `json:"bookmark"`
+	FetchedRecordsCount int32         `json:"fetchedRecordsCount"`
+	Metadata            QueryMetadata `json:"metadata"`
+}
+
+// GetAllAssetsPaginated returns one page of at most pageSize assets found in world state,
+// starting at bookmark, together with the bookmark of the next page
+func (s *SmartContract) GetAllAssetsPaginated(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*PaginatedAssetList, error) {
+	if pageSize < 1 || pageSize > maxAssetPageSize {
+		return nil, newError(ErrValidation, map[string]string{"field": "pageSize"}, "the page size must be between 1 and %d", maxAssetPageSize)
+	}
+
+	resultsIterator, responseMetadata, err := getAssetsWithPagination(ctx, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := []*Asset{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.returned(len(queryResponse.Value))
+		if err != nil {
+			return nil, err
+		}
 
-		var asset Asset
-		err = json.Unmarshal(queryResponse.Value, &asset)
+		asset, err := unmarshalStoredAsset(ctx, queryResponse.Key, queryResponse.Value)
 		if err != nil {
 			return nil, err
 		}
-		assets = append(assets, &asset)
+		assets = append(assets, asset)
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Cost = &meter.cost
+
+	return &PaginatedAssetList{
+		Assets:              assets,
+		Bookmark:            responseMetadata.Bookmark,
+		FetchedRecordsCount: responseMetadata.FetchedRecordsCount,
+		Metadata:            metadata,
+	}, nil
+}
+
+// GetAssetsByRange returns the assets whose IDs lie between startKey, inclusive, and endKey,
+// exclusive. An empty startKey or endKey leaves that end of the range open. The range is read
+// in pages starting at startKey, so the assets before it are not scanned.
+func (s *SmartContract) GetAssetsByRange(ctx contractapi.TransactionContextInterface, startKey string, endKey string) (*AssetList, error) {
+	if startKey != "" && endKey != "" && startKey > endKey {
+		return nil, newError(ErrValidation, map[string]string{"field": "startKey"}, "the start key %s must not come after the end key %s", startKey, endKey)
+	}
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := []*Asset{}
+	bookmark := startKey
+	for {
+		resultsIterator, responseMetadata, err := getAssetsWithPagination(ctx, maxAssetPageSize, bookmark)
+		if err != nil {
+			return nil, err
+		}
+		done, err := collectAssetRange(ctx, resultsIterator, meter, endKey, &assets)
+		resultsIterator.Close()
+		if err != nil {
+			return nil, err
+		}
+		if done || responseMetadata.Bookmark == "" {
+			break
+		}
+		bookmark = responseMetadata.Bookmark
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Cost = &meter.cost
+
+	return &AssetList{Assets: assets, Metadata: metadata}, nil
+}
+
+// collectAssetRange appends the assets of one page whose IDs come before endKey and reports
+// whether the end of the range was reached
+func collectAssetRange(ctx contractapi.TransactionContextInterface, resultsIterator shim.StateQueryIteratorInterface, meter *costMeter, endKey string, assets *[]*Asset) (bool, error) {
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return false, err
+		}
+		id, _, err := assetIDFromKey(ctx, queryResponse.Key)
+		if err != nil {
+			return false, err
+		}
+		if endKey != "" && id >= endKey {
+			return true, nil
+		}
+		err = meter.scanned()
+		if err != nil {
+			return false, err
+		}
+		err = meter.returned(len(queryResponse.Value))
+		if err != nil {
+			return false, err
+		}
+
+		asset, err := unmarshalAsset(id, queryResponse.Value)
+		if err != nil {
+			return false, err
+		}
+		*assets = append(*assets, asset)
+	}
+
+	return false, nil
+}
+
+// getAssetsWithPagination returns one page of at most pageSize assets starting at the asset ID
+// bookmark. Bookmarks are exchanged with clients as asset IDs rather than composite keys, so the
+// returned bookmark is the ID of the asset the next page starts from.
+func getAssetsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	startKey := ""
+	if bookmark != "" {
+		var err error
+		startKey, err = assetKey(ctx, bookmark)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(assetObjectType, []string{}, pageSize, startKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if responseMetadata.Bookmark != "" {
+		responseMetadata.Bookmark, _, err = assetIDFromKey(ctx, responseMetadata.Bookmark)
+		if err != nil {
+			resultsIterator.Close()
+			return nil, nil, err
+		}
 	}
 
-	return assets, nil
+	return resultsIterator, responseMetadata, nil
 }
@@ -5,9 +5,9 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
-	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
 	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
 	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode/mocks"
 	"github.com/stretchr/testify/require"
@@ -28,157 +28,262 @@ type stateQueryIterator interface {
 	shim.StateQueryIteratorInterface
 }
 
-func TestInitLedger(t *testing.T) {
-	chaincodeStub := &mocks.ChaincodeStub{}
-	transactionContext := &mocks.TransactionContext{}
-	transactionContext.GetStubReturns(chaincodeStub)
+//go:generate counterfeiter -o mocks/clientidentity.go -fake-name ClientIdentity . clientIdentity
+type clientIdentity interface {
+	cid.ClientIdentity
+}
 
+func TestInitLedger(t *testing.T) {
+	ws := newWorldState()
 	assetTransfer := chaincode.SmartContract{}
-	err := assetTransfer.InitLedger(transactionContext)
+	err := assetTransfer.InitLedger(ws.transactionContext)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.InitLedger(ws.transactionContext)
 	require.NoError(t, err)
 
-	chaincodeStub.PutStateReturns(fmt.Errorf("failed inserting key"))
-	err = assetTransfer.InitLedger(transactionContext)
+	ws.stub.PutStateStub = nil
+	ws.stub.PutStateReturns(fmt.Errorf("failed inserting key"))
+	err = assetTransfer.InitLedger(ws.transactionContext)
 	require.EqualError(t, err, "failed to put to world state. failed inserting key")
 }
 
 func TestCreateAsset(t *testing.T) {
-	chaincodeStub := &mocks.ChaincodeStub{}
-	transactionContext := &mocks.TransactionContext{}
-	transactionContext.GetStubReturns(chaincodeStub)
-
+	ws := newWorldState()
 	assetTransfer := chaincode.SmartContract{}
-	err := assetTransfer.CreateAsset(transactionContext, "", "", 0, "", 0)
+	err := assetTransfer.CreateAsset(ws.transactionContext, "asset1", "", 0, "", 0)
 	require.NoError(t, err)
+	ws.commit()
 
-	chaincodeStub.GetStateReturns([]byte{}, nil)
-	err = assetTransfer.CreateAsset(transactionContext, "asset1", "", 0, "", 0)
-	require.EqualError(t, err, "the asset asset1 already exists")
+	err = assetTransfer.CreateAsset(ws.transactionContext, "asset1", "", 0, "", 0)
+	requireError(t, err, chaincode.ErrAssetExists, "the asset asset1 already exists")
 
-	chaincodeStub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
-	err = assetTransfer.CreateAsset(transactionContext, "asset1", "", 0, "", 0)
+	ws.stub.GetStateStub = nil
+	ws.stub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
+	err = assetTransfer.CreateAsset(ws.transactionContext, "asset1", "", 0, "", 0)
 	require.EqualError(t, err, "failed to read from world state: unable to retrieve asset")
 }
 
 func TestReadAsset(t *testing.T) {
-	chaincodeStub := &mocks.ChaincodeStub{}
-	transactionContext := &mocks.TransactionContext{}
-	transactionContext.GetStubReturns(chaincodeStub)
-
-	expectedAsset := &chaincode.Asset{ID: "asset1"}
+	ws := newWorldState()
+	expectedAsset := &chaincode.Asset{Allowlist: "asset1", ID: "asset1", SchemaVersion: chaincode.SchemaVersion}
 	bytes, err := json.Marshal(expectedAsset)
 	require.NoError(t, err)
+	require.NoError(t, ws.putState(assetKey("asset1"), bytes))
+	// written while the fields of Asset were unexported
+	require.NoError(t, ws.putState(assetKey("asset2"), []byte("{}")))
+	ws.commit()
 
-	chaincodeStub.GetStateReturns(bytes, nil)
 	assetTransfer := chaincode.SmartContract{}
-	asset, err := assetTransfer.ReadAsset(transactionContext, "")
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "asset1")
 	require.NoError(t, err)
 	require.Equal(t, expectedAsset, asset)
 
-	chaincodeStub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
-	_, err = assetTransfer.ReadAsset(transactionContext, "")
-	require.EqualError(t, err, "failed to read from world state: unable to retrieve asset")
+	asset, err = assetTransfer.ReadAsset(ws.transactionContext, "asset2")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Asset{Allowlist: "asset2", ID: "asset2", SchemaVersion: chaincode.SchemaVersion}, asset)
 
-	chaincodeStub.GetStateReturns(nil, nil)
-	asset, err = assetTransfer.ReadAsset(transactionContext, "asset1")
-	require.EqualError(t, err, "the asset asset1 does not exist")
+	asset, err = assetTransfer.ReadAsset(ws.transactionContext, "asset3")
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset asset3 does not exist")
 	require.Nil(t, asset)
+
+	ws.stub.GetStateStub = nil
+	ws.stub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
+	_, err = assetTransfer.ReadAsset(ws.transactionContext, "asset1")
+	require.EqualError(t, err, "failed to read from world state: unable to retrieve asset")
 }
 
 func TestUpdateAsset(t *testing.T) {
-	chaincodeStub := &mocks.ChaincodeStub{}
-	transactionContext := &mocks.TransactionContext{}
-	transactionContext.GetStubReturns(chaincodeStub)
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+	created := ws.now
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "asset1", "", 0, "", 0))
+	ws.commit()
 
-	expectedAsset := &chaincode.Asset{ID: "asset1"}
-	bytes, err := json.Marshal(expectedAsset)
+	updated := ws.now
+	err := assetTransfer.UpdateAsset(ws.transactionContext, "asset1", "www.xxx.com", 5, "", 300)
 	require.NoError(t, err)
+	ws.commit()
 
-	chaincodeStub.GetStateReturns(bytes, nil)
-	assetTransfer := chaincode.SmartContract{}
-	err = assetTransfer.UpdateAsset(transactionContext, "", "", 0, "", 0)
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "asset1")
 	require.NoError(t, err)
+	require.Equal(t, &chaincode.Asset{Allowlist: "asset1", Attribute2: 5, Blocklist: "www.xxx.com", CreatedAt: created, ID: "asset1", Owner: "user1", SchemaVersion: chaincode.SchemaVersion, UpdatedAt: updated, Webfilterlist: 300}, asset)
 
-	chaincodeStub.GetStateReturns(nil, nil)
-	err = assetTransfer.UpdateAsset(transactionContext, "asset1", "", 0, "", 0)
-	require.EqualError(t, err, "the asset asset1 does not exist")
+	err = assetTransfer.UpdateAsset(ws.transactionContext, "asset2", "", 0, "", 0)
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset asset2 does not exist")
 
-	chaincodeStub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
-	err = assetTransfer.UpdateAsset(transactionContext, "asset1", "", 0, "", 0)
+	ws.stub.GetStateStub = nil
+	ws.stub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
+	err = assetTransfer.UpdateAsset(ws.transactionContext, "asset1", "", 0, "", 0)
 	require.EqualError(t, err, "failed to read from world state: unable to retrieve asset")
 }
 
 func TestDeleteAsset(t *testing.T) {
-	chaincodeStub := &mocks.ChaincodeStub{}
-	transactionContext := &mocks.TransactionContext{}
-	transactionContext.GetStubReturns(chaincodeStub)
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "asset1", "", 0, "", 0))
+	ws.commit()
 
-	asset := &chaincode.Asset{ID: "asset1"}
-	bytes, err := json.Marshal(asset)
-	require.NoError(t, err)
+	err := assetTransfer.DeleteAsset(ws.transactionContext, "asset1")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
 
-	chaincodeStub.GetStateReturns(bytes, nil)
-	chaincodeStub.DelStateReturns(nil)
-	assetTransfer := chaincode.SmartContract{}
-	err = assetTransfer.DeleteAsset(transactionContext, "")
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.DeleteAsset(ws.transactionContext, "asset1")
 	require.NoError(t, err)
+	ws.commit()
 
-	chaincodeStub.GetStateReturns(nil, nil)
-	err = assetTransfer.DeleteAsset(transactionContext, "asset1")
-	require.EqualError(t, err, "the asset asset1 does not exist")
+	err = assetTransfer.DeleteAsset(ws.transactionContext, "asset1")
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset asset1 does not exist")
 
-	chaincodeStub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
-	err = assetTransfer.DeleteAsset(transactionContext, "")
+	ws.stub.GetStateStub = nil
+	ws.stub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
+	err = assetTransfer.DeleteAsset(ws.transactionContext, "asset1")
 	require.EqualError(t, err, "failed to read from world state: unable to retrieve asset")
 }
 
 func TestTransferAsset(t *testing.T) {
-	chaincodeStub := &mocks.ChaincodeStub{}
-	transactionContext := &mocks.TransactionContext{}
-	transactionContext.GetStubReturns(chaincodeStub)
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "asset1", "", 0, "Org1MSP", 0))
+	ws.commit()
 
-	asset := &chaincode.Asset{ID: "asset1"}
-	bytes, err := json.Marshal(asset)
+	oldOwner, err := assetTransfer.TransferAsset(ws.transactionContext, "asset1", "Org2MSP")
 	require.NoError(t, err)
+	require.Equal(t, "Org1MSP", oldOwner)
+	ws.commit()
 
-	chaincodeStub.GetStateReturns(bytes, nil)
-	assetTransfer := chaincode.SmartContract{}
-	_, err = assetTransfer.TransferAsset(transactionContext, "", "")
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "asset1")
 	require.NoError(t, err)
+	require.Equal(t, "Org2MSP", asset.Attribute1)
 
-	chaincodeStub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
-	_, err = assetTransfer.TransferAsset(transactionContext, "", "")
+	ws.stub.GetStateStub = nil
+	ws.stub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
+	_, err = assetTransfer.TransferAsset(ws.transactionContext, "asset1", "")
 	require.EqualError(t, err, "failed to read from world state: unable to retrieve asset")
 }
 
-func TestGetAllAssets(t *testing.T) {
-	asset := &chaincode.Asset{ID: "asset1"}
-	bytes, err := json.Marshal(asset)
+func TestAssetOwnership(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "asset1", "", 0, "Org1MSP", 0))
+	ws.commit()
+
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "asset1")
 	require.NoError(t, err)
+	require.Equal(t, "user1", asset.Owner)
 
-	iterator := &mocks.StateQueryIterator{}
-	iterator.HasNextReturnsOnCall(0, true)
-	iterator.HasNextReturnsOnCall(1, false)
-	iterator.NextReturns(&queryresult.KV{Value: bytes}, nil)
+	ws.setClient("Org1MSP", "user2", false)
+	err = assetTransfer.UpdateAsset(ws.transactionContext, "asset1", "www.xxx.com", 0, "Org1MSP", 0)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+	_, err = assetTransfer.TransferAsset(ws.transactionContext, "asset1", "Org2MSP")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
 
-	chaincodeStub := &mocks.ChaincodeStub{}
-	transactionContext := &mocks.TransactionContext{}
-	transactionContext.GetStubReturns(chaincodeStub)
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "asset1", "www.xxx.com", 0, "Org1MSP", 0))
+	ws.commit()
+
+	asset, err = assetTransfer.ReadAsset(ws.transactionContext, "asset1")
+	require.NoError(t, err)
+	require.Equal(t, "user1", asset.Owner)
+
+	// assets written before they recorded an owner may only be changed by administrators
+	require.NoError(t, ws.putState(assetKey("asset2"), []byte(`{"allowlist":"asset2"}`)))
+	ws.commit()
+	ws.setClient("Org1MSP", "user1", false)
+	_, err = assetTransfer.TransferAsset(ws.transactionContext, "asset2", "Org2MSP")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+}
+
+func TestGetAllAssets(t *testing.T) {
+	ws := newWorldState()
+	asset := &chaincode.Asset{Allowlist: "asset1", ID: "asset1", SchemaVersion: chaincode.SchemaVersion}
+	bytes, err := json.Marshal(asset)
+	require.NoError(t, err)
+	require.NoError(t, ws.putState(assetKey("asset1"), bytes))
+	ws.commit()
 
-	chaincodeStub.GetStateByRangeReturns(iterator, nil)
 	assetTransfer := &chaincode.SmartContract{}
-	assets, err := assetTransfer.GetAllAssets(transactionContext)
+	assets, err := assetTransfer.GetAllAssets(ws.transactionContext)
 	require.NoError(t, err)
-	require.Equal(t, []*chaincode.Asset{asset}, assets)
+	require.Equal(t, []*chaincode.Asset{asset}, assets.Assets)
 
+	iterator := &mocks.StateQueryIterator{}
 	iterator.HasNextReturns(true)
 	iterator.NextReturns(nil, fmt.Errorf("failed retrieving next item"))
-	assets, err = assetTransfer.GetAllAssets(transactionContext)
+	ws.stub.GetStateByPartialCompositeKeyStub = nil
+	ws.stub.GetStateByPartialCompositeKeyReturns(iterator, nil)
+	assets, err = assetTransfer.GetAllAssets(ws.transactionContext)
 	require.EqualError(t, err, "failed retrieving next item")
 	require.Nil(t, assets)
 
-	chaincodeStub.GetStateByRangeReturns(nil, fmt.Errorf("failed retrieving all assets"))
-	assets, err = assetTransfer.GetAllAssets(transactionContext)
+	ws.stub.GetStateByPartialCompositeKeyReturns(nil, fmt.Errorf("failed retrieving all assets"))
+	assets, err = assetTransfer.GetAllAssets(ws.transactionContext)
 	require.EqualError(t, err, "failed retrieving all assets")
 	require.Nil(t, assets)
 }
+
+func TestGetAssetsByRange(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	// more assets than fit a page, so that the range spans several pages
+	for i := 0; i < 120; i++ {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, fmt.Sprintf("site%03d.com", i), "", 1, "", 100))
+	}
+	ws.commit()
+
+	_, err := assetTransfer.GetAssetsByRange(ws.transactionContext, "site050.com", "site010.com")
+	requireError(t, err, chaincode.ErrValidation, "the start key site050.com must not come after the end key site010.com")
+
+	assets, err := assetTransfer.GetAssetsByRange(ws.transactionContext, "site010.com", "site115.com")
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 105)
+	require.Equal(t, "site010.com", assets.Assets[0].ID)
+	require.Equal(t, "site114.com", assets.Assets[104].ID)
+	require.Equal(t, 105, assets.Metadata.Cost.KeysScanned)
+
+	assets, err = assetTransfer.GetAssetsByRange(ws.transactionContext, "site117", "")
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 3)
+	require.Equal(t, "site117.com", assets.Assets[0].ID)
+
+	assets, err = assetTransfer.GetAssetsByRange(ws.transactionContext, "", "site002.com")
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 2)
+
+	assets, err = assetTransfer.GetAssetsByRange(ws.transactionContext, "site050.com", "site050.com")
+	require.NoError(t, err)
+	require.Empty(t, assets.Assets)
+}
+
+func TestGetAllAssetsPaginated(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	for _, id := range []string{"www.bbc.co.uk", "www.google.com", "www.napier.ac.uk"} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "", 1, "", 100))
+	}
+	ws.commit()
+
+	_, err := assetTransfer.GetAllAssetsPaginated(ws.transactionContext, 0, "")
+	requireError(t, err, chaincode.ErrValidation, "the page size must be between 1 and 100")
+
+	page, err := assetTransfer.GetAllAssetsPaginated(ws.transactionContext, 2, "")
+	require.NoError(t, err)
+	require.Len(t, page.Assets, 2)
+	require.Equal(t, int32(2), page.FetchedRecordsCount)
+	require.Equal(t, "www.napier.ac.uk", page.Bookmark)
+
+	page, err = assetTransfer.GetAllAssetsPaginated(ws.transactionContext, 2, page.Bookmark)
+	require.NoError(t, err)
+	require.Len(t, page.Assets, 1)
+	require.Equal(t, int32(1), page.FetchedRecordsCount)
+	require.Empty(t, page.Bookmark)
+
+	ws.stub.GetStateByPartialCompositeKeyWithPaginationReturns(nil, nil, fmt.Errorf("failed retrieving all assets"))
+	ws.stub.GetStateByPartialCompositeKeyWithPaginationStub = nil
+	page, err = assetTransfer.GetAllAssetsPaginated(ws.transactionContext, 2, "")
+	require.EqualError(t, err, "failed retrieving all assets")
+	require.Nil(t, page)
+}
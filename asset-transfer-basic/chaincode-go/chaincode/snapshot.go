@@ -0,0 +1,134 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// VerifyListSnapshot reports whether sha256, a hex encoded SHA-256 digest, matches the list
+// stored under listID as written by the transaction version. An empty version verifies
+// against the current state. The digest is computed over the JSON document exactly as stored.
+func (s *SmartContract) VerifyListSnapshot(ctx contractapi.TransactionContextInterface, listID string, version string, sha256Hex string) (bool, error) {
+	expected, err := hex.DecodeString(sha256Hex)
+	if err != nil || len(expected) != sha256.Size {
+		return false, newError(ErrValidation, map[string]string{"field": "sha256Hex"}, "the digest must be a hex encoded SHA-256 hash")
+	}
+
+	listJSON, err := listVersion(ctx, listID, version)
+	if err != nil {
+		return false, err
+	}
+
+	actual := sha256.Sum256(listJSON)
+	return strings.EqualFold(hex.EncodeToString(actual[:]), sha256Hex), nil
+}
+
+// ReconciliationReport is the response of ReconcileWithSnapshot. Mismatched lists the assets
+// whose stored document hashes differently, Missing the assets the counterparty holds but the
+// ledger does not, both in ascending order.
+type ReconciliationReport struct {
+	Matched    int      `json:"matched"`
+	Mismatched []string `json:"mismatched"`
+	Missing    []string `json:"missing"`
+}
+
+// ReconcileWithSnapshot compares the hashes of an off-chain mirror kept by another organization,
+// a JSON object mapping asset IDs to hex encoded SHA-256 digests of their documents, against
+// the current state, so that drift can be detected without exchanging the documents.
+func (s *SmartContract) ReconcileWithSnapshot(ctx contractapi.TransactionContextInterface, counterpartySnapshotHashesJSON string) (*ReconciliationReport, error) {
+	hashes := map[string]string{}
+	err := json.Unmarshal([]byte(counterpartySnapshotHashesJSON), &hashes)
+	if err != nil {
+		return nil, newError(ErrValidation, nil, "invalid snapshot hashes: %v", err)
+	}
+	limits, err := readPayloadLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = limits.assertBatchSize(len(hashes))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(hashes))
+	for id, sha256Hex := range hashes {
+		expected, err := hex.DecodeString(sha256Hex)
+		if err != nil || len(expected) != sha256.Size {
+			return nil, newError(ErrValidation, map[string]string{"id": id}, "the digest of %s must be a hex encoded SHA-256 hash", id)
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	report := ReconciliationReport{Mismatched: []string{}, Missing: []string{}}
+	for _, id := range ids {
+		key, err := assetKey(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		assetJSON, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if assetJSON == nil {
+			report.Missing = append(report.Missing, id)
+			continue
+		}
+
+		actual := sha256.Sum256(assetJSON)
+		if strings.EqualFold(hex.EncodeToString(actual[:]), hashes[id]) {
+			report.Matched++
+		} else {
+			report.Mismatched = append(report.Mismatched, id)
+		}
+	}
+
+	return &report, nil
+}
+
+// listVersion returns the document stored under listID by the transaction version, or the
+// current document if version is empty
+func listVersion(ctx contractapi.TransactionContextInterface, listID string, version string) ([]byte, error) {
+	if version == "" {
+		key, err := assetKey(ctx, listID)
+		if err != nil {
+			return nil, err
+		}
+		listJSON, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if listJSON == nil {
+			return nil, assetNotFoundError(listID)
+		}
+		return listJSON, nil
+	}
+
+	resultsIterator, err := assetHistory(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if modification.TxId != version {
+			continue
+		}
+		if modification.IsDelete {
+			return nil, newError(ErrNotFound, map[string]string{"id": listID, "version": version}, "the asset %s was deleted by %s", listID, version)
+		}
+		return modification.Value, nil
+	}
+
+	return nil, newError(ErrNotFound, map[string]string{"id": listID, "version": version}, "the asset %s has no version %s", listID, version)
+}
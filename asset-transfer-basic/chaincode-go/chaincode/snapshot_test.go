@@ -0,0 +1,88 @@
+package chaincode_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyListSnapshot(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	first := []byte(`{"allowlist":"www.bbc.co.uk","blocklist":"www.xxx.com"}`)
+	second := []byte(`{"allowlist":"www.bbc.co.uk","blocklist":"www.yyy.com"}`)
+	firstHash := sha256.Sum256(first)
+	secondHash := sha256.Sum256(second)
+
+	require.NoError(t, ws.putState(assetKey("www.bbc.co.uk"), first))
+	ws.commit()
+	require.NoError(t, ws.putState(assetKey("www.bbc.co.uk"), second))
+	ws.commit()
+
+	_, err := assetTransfer.VerifyListSnapshot(ws.transactionContext, "www.bbc.co.uk", "", "abc")
+	requireError(t, err, chaincode.ErrValidation, "the digest must be a hex encoded SHA-256 hash")
+
+	matches, err := assetTransfer.VerifyListSnapshot(ws.transactionContext, "www.bbc.co.uk", "tx000", hex.EncodeToString(firstHash[:]))
+	require.NoError(t, err)
+	require.True(t, matches)
+
+	matches, err = assetTransfer.VerifyListSnapshot(ws.transactionContext, "www.bbc.co.uk", "tx001", hex.EncodeToString(firstHash[:]))
+	require.NoError(t, err)
+	require.False(t, matches)
+
+	matches, err = assetTransfer.VerifyListSnapshot(ws.transactionContext, "www.bbc.co.uk", "", hex.EncodeToString(secondHash[:]))
+	require.NoError(t, err)
+	require.True(t, matches)
+
+	_, err = assetTransfer.VerifyListSnapshot(ws.transactionContext, "www.bbc.co.uk", "tx009", hex.EncodeToString(firstHash[:]))
+	requireError(t, err, chaincode.ErrNotFound, "the asset www.bbc.co.uk has no version tx009")
+
+	_, err = assetTransfer.VerifyListSnapshot(ws.transactionContext, "www.google.com", "", hex.EncodeToString(firstHash[:]))
+	requireError(t, err, chaincode.ErrAssetNotFound, "the asset www.google.com does not exist")
+
+	require.NoError(t, ws.delState(assetKey("www.bbc.co.uk")))
+	ws.commit()
+	_, err = assetTransfer.VerifyListSnapshot(ws.transactionContext, "www.bbc.co.uk", "tx002", hex.EncodeToString(firstHash[:]))
+	requireError(t, err, chaincode.ErrNotFound, "the asset www.bbc.co.uk was deleted by tx002")
+}
+
+func TestReconcileWithSnapshot(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	bbc := []byte(`{"allowlist":"www.bbc.co.uk","blocklist":"www.xxx.com"}`)
+	google := []byte(`{"allowlist":"www.google.com","blocklist":"www.xxx.com"}`)
+	bbcHash := sha256.Sum256(bbc)
+	staleHash := sha256.Sum256([]byte(`{"allowlist":"www.google.com"}`))
+
+	require.NoError(t, ws.putState(assetKey("www.bbc.co.uk"), bbc))
+	require.NoError(t, ws.putState(assetKey("www.google.com"), google))
+	ws.commit()
+
+	_, err := assetTransfer.ReconcileWithSnapshot(ws.transactionContext, `["www.bbc.co.uk"]`)
+	requireError(t, err, chaincode.ErrValidation, "invalid snapshot hashes: json: cannot unmarshal array into Go value of type map[string]string")
+
+	_, err = assetTransfer.ReconcileWithSnapshot(ws.transactionContext, `{"www.bbc.co.uk":"abc"}`)
+	requireError(t, err, chaincode.ErrValidation, "the digest of www.bbc.co.uk must be a hex encoded SHA-256 hash")
+
+	report, err := assetTransfer.ReconcileWithSnapshot(ws.transactionContext, `{
+		"www.bbc.co.uk":"`+strings.ToUpper(hex.EncodeToString(bbcHash[:]))+`",
+		"www.google.com":"`+hex.EncodeToString(staleHash[:])+`",
+		"www.napier.ac.uk":"`+hex.EncodeToString(bbcHash[:])+`"
+	}`)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.ReconciliationReport{
+		Matched:    1,
+		Mismatched: []string{"www.google.com"},
+		Missing:    []string{"www.napier.ac.uk"},
+	}, report)
+
+	report, err = assetTransfer.ReconcileWithSnapshot(ws.transactionContext, "{}")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.ReconciliationReport{Mismatched: []string{}, Missing: []string{}}, report)
+}
@@ -0,0 +1,72 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// getRecord reads the JSON record stored under key into record and reports whether it exists
+func getRecord(ctx contractapi.TransactionContextInterface, key string, record interface{}) (bool, error) {
+	recordJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if recordJSON == nil {
+		return false, nil
+	}
+
+	err = json.Unmarshal(recordJSON, record)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// putRecord stores record as JSON under key
+func putRecord(ctx contractapi.TransactionContextInterface, key string, record interface{}) error {
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return putState(ctx, key, recordJSON)
+}
+
+// putState stores value under key unless it exceeds the size limit on stored values
+func putState(ctx contractapi.TransactionContextInterface, key string, value []byte) error {
+	limits, err := readPayloadLimits(ctx)
+	if err != nil {
+		return err
+	}
+	err = limits.assertValueSize(len(value))
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(key, value)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state: %v", err)
+	}
+
+	return nil
+}
+
+// txTime returns the transaction timestamp chosen by the client, which is identical on every endorser
+func txTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	ts, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid transaction timestamp: %v", err)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,278 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// State databases a peer may use, named as in the ledger.state.stateDatabase peer setting
+const (
+	StateDatabaseCouchDB = "CouchDB"
+	StateDatabaseLevelDB = "goleveldb"
+)
+
+// SetStateDatabase records the state database of the peers of the channel. On goleveldb, rich
+// queries are answered by scanning the assets in the contract instead of by the database. While
+// no database is recorded, rich queries fall back to the scan when the peer rejects them.
+func (s *SmartContract) SetStateDatabase(ctx contractapi.TransactionContextInterface, database string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if database != StateDatabaseCouchDB && database != StateDatabaseLevelDB {
+		return newError(ErrValidation, map[string]string{"field": "database"}, "the state database must be %s or %s", StateDatabaseCouchDB, StateDatabaseLevelDB)
+	}
+
+	config, err := readConfig(ctx)
+	if err != nil {
+		return err
+	}
+	config.StateDatabase = database
+
+	return putConfig(ctx, config)
+}
+
+// richQuery is the part of a Mango query the contract can answer without the state database
+type richQuery struct {
+	Selector map[string]interface{} `json:"selector"`
+	Limit    int                    `json:"limit"`
+	Skip     int                    `json:"skip"`
+	Fields   []string               `json:"fields"`
+	Sort     []interface{}          `json:"sort"`
+}
+
+// queryState runs a rich query on the state database or, where it does not support them,
+// answers it by scanning the assets
+func queryState(ctx contractapi.TransactionContextInterface, queryString string) (shim.StateQueryIteratorInterface, error) {
+	scan, err := scanInsteadOfQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !scan {
+		resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+		if err == nil || !richQueriesUnsupported(err) {
+			return resultsIterator, err
+		}
+	}
+
+	return scanQuery(ctx, queryString, 0, "")
+}
+
+// queryStateWithPagination runs a rich query for one page of results on the state database or,
+// where it does not support them, answers it by scanning the assets. When scanning, the
+// bookmark is the key the next page starts from.
+func queryStateWithPagination(ctx contractapi.TransactionContextInterface, queryString string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	scan, err := scanInsteadOfQuery(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !scan {
+		resultsIterator, responseMetadata, err := ctx.GetStub().GetQueryResultWithPagination(queryString, pageSize, bookmark)
+		if err == nil || !richQueriesUnsupported(err) {
+			return resultsIterator, responseMetadata, err
+		}
+	}
+
+	resultsIterator, err := scanQuery(ctx, queryString, pageSize, bookmark)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responseMetadata := &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(resultsIterator.results))}
+	if len(resultsIterator.results) > int(pageSize) {
+		responseMetadata.Bookmark = resultsIterator.results[pageSize].Key
+		resultsIterator.results = resultsIterator.results[:pageSize]
+		responseMetadata.FetchedRecordsCount = pageSize
+	}
+
+	return resultsIterator, responseMetadata, nil
+}
+
+func scanInsteadOfQuery(ctx contractapi.TransactionContextInterface) (bool, error) {
+	config, err := readConfig(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return config.StateDatabase == StateDatabaseLevelDB, nil
+}
+
+// richQueriesUnsupported reports whether the peer rejected a rich query because its state database is goleveldb
+func richQueriesUnsupported(err error) bool {
+	return strings.Contains(err.Error(), "not supported for leveldb")
+}
+
+// scanQuery answers a rich query by matching its selector against every asset in key order,
+// which is the order CouchDB returns documents in when no sort is given. With a page size,
+// one result more than the page is returned so the caller can bookmark the next page.
+func scanQuery(ctx contractapi.TransactionContextInterface, queryString string, pageSize int32, bookmark string) (*sliceIterator, error) {
+	var query richQuery
+	err := json.Unmarshal([]byte(queryString), &query)
+	if err != nil || query.Selector == nil {
+		return nil, newError(ErrValidation, map[string]string{"field": "queryString"}, "the query must be a JSON object with a selector")
+	}
+	if query.Fields != nil || query.Sort != nil {
+		return nil, newError(ErrValidation, map[string]string{"field": "queryString"}, "fields and sort are only supported on CouchDB")
+	}
+	if pageSize > 0 && (query.Limit != 0 || query.Skip != 0) {
+		return nil, newError(ErrValidation, map[string]string{"field": "queryString"}, "limit and skip cannot be used with pagination")
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	matches := &sliceIterator{}
+	skipped := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		// partial composite key queries cannot start at a key, so the page is skipped to by hand
+		if queryResponse.Key < bookmark {
+			continue
+		}
+
+		var document map[string]interface{}
+		if json.Unmarshal(queryResponse.Value, &document) != nil {
+			continue
+		}
+		match, err := matchesSelector(document, query.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if !match {
+			continue
+		}
+		if skipped < query.Skip {
+			skipped++
+			continue
+		}
+
+		matches.results = append(matches.results, queryResponse)
+		if (query.Limit > 0 && len(matches.results) == query.Limit) || (pageSize > 0 && len(matches.results) > int(pageSize)) {
+			break
+		}
+	}
+
+	return matches, nil
+}
+
+// matchesSelector evaluates a selector on the top level fields of a document. Fields are
+// compared for equality or with the $eq, $ne, $gt, $gte, $lt, $lte and $in operators.
+func matchesSelector(document map[string]interface{}, selector map[string]interface{}) (bool, error) {
+	for field, condition := range selector {
+		if strings.HasPrefix(field, "$") {
+			return false, newError(ErrValidation, map[string]string{"operator": field}, "the selector operator %s is only supported on CouchDB", field)
+		}
+
+		operators, ok := condition.(map[string]interface{})
+		if !ok {
+			operators = map[string]interface{}{"$eq": condition}
+		}
+		value, present := document[field]
+		for operator, operand := range operators {
+			match, err := matchesOperator(value, present, operator, operand)
+			if err != nil || !match {
+				return false, err
+			}
+		}
+	}
+
+	return true, nil
+}
+
+func matchesOperator(value interface{}, present bool, operator string, operand interface{}) (bool, error) {
+	switch operator {
+	case "$eq":
+		return present && reflect.DeepEqual(value, operand), nil
+	case "$ne":
+		return !present || !reflect.DeepEqual(value, operand), nil
+	case "$in":
+		candidates, ok := operand.([]interface{})
+		if !ok {
+			return false, newError(ErrValidation, map[string]string{"operator": "$in"}, "the operand of $in must be an array")
+		}
+		for _, candidate := range candidates {
+			if present && reflect.DeepEqual(value, candidate) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$gt", "$gte", "$lt", "$lte":
+		order, comparable := compareJSON(value, operand)
+		if !present || !comparable {
+			return false, nil
+		}
+		switch operator {
+		case "$gt":
+			return order > 0, nil
+		case "$gte":
+			return order >= 0, nil
+		case "$lt":
+			return order < 0, nil
+		default:
+			return order <= 0, nil
+		}
+	}
+
+	return false, newError(ErrValidation, map[string]string{"operator": operator}, "the selector operator %s is only supported on CouchDB", operator)
+}
+
+// compareJSON orders two numbers or two strings decoded from JSON
+func compareJSON(a interface{}, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case float64:
+		b, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		b, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(a, b), true
+	}
+
+	return 0, false
+}
+
+// sliceIterator iterates over query results already read from the world state
+type sliceIterator struct {
+	results []*queryresult.KV
+	next    int
+}
+
+func (it *sliceIterator) HasNext() bool {
+	return it.next < len(it.results)
+}
+
+func (it *sliceIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	it.next++
+	return it.results[it.next-1], nil
+}
+
+func (it *sliceIterator) Close() error {
+	return nil
+}
@@ -0,0 +1,100 @@
+package chaincode_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func putLists(t *testing.T, ws *worldState) {
+	require.NoError(t, ws.putState(assetKey("www.aaa.com"), []byte(`{"blocklist":"www.xxx.com","webfilterlist":50}`)))
+	require.NoError(t, ws.putState(assetKey("www.bbb.com"), []byte(`{"blocklist":"www.xxx.com","webfilterlist":150}`)))
+	require.NoError(t, ws.putState(assetKey("www.ccc.com"), []byte(`{"blocklist":"www.yyy.com","webfilterlist":200}`)))
+	require.NoError(t, ws.putState(assetKey("www.ddd.com"), []byte(`{"blocklist":"www.xxx.com","webfilterlist":300}`)))
+	require.NoError(t, ws.putState(compositeKeyNamespace+"config"+compositeKeyNamespace, []byte(`{"blocklist":"www.xxx.com"}`)))
+	ws.commit()
+}
+
+func TestSetStateDatabase(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+	putLists(t, ws)
+
+	err := assetTransfer.SetStateDatabase(ws.transactionContext, chaincode.StateDatabaseLevelDB)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.SetStateDatabase(ws.transactionContext, "sqlite")
+	requireError(t, err, chaincode.ErrValidation, "the state database must be CouchDB or goleveldb")
+	require.NoError(t, assetTransfer.SetStateDatabase(ws.transactionContext, chaincode.StateDatabaseLevelDB))
+	ws.commit()
+
+	ws.stub.GetQueryResultStub = nil
+	ws.stub.GetQueryResultReturns(nil, fmt.Errorf("unexpected rich query"))
+
+	assets, err := assetTransfer.QueryAssets(ws.transactionContext, `{"selector":{"blocklist":"www.xxx.com","webfilterlist":{"$gt":100,"$lte":300}}}`)
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 2)
+
+	assets, err = assetTransfer.QueryAssets(ws.transactionContext, `{"selector":{"webfilterlist":{"$in":[50,200]}},"skip":1}`)
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 1)
+
+	assets, err = assetTransfer.QueryAssets(ws.transactionContext, `{"selector":{"blocklist":{"$ne":"www.yyy.com"}},"limit":2}`)
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 2)
+
+	_, err = assetTransfer.QueryAssets(ws.transactionContext, `{"selector":{"blocklist":{"$regex":"xxx"}}}`)
+	requireError(t, err, chaincode.ErrValidation, "the selector operator $regex is only supported on CouchDB")
+	_, err = assetTransfer.QueryAssets(ws.transactionContext, `{"selector":{"$or":[]}}`)
+	requireError(t, err, chaincode.ErrValidation, "the selector operator $or is only supported on CouchDB")
+	_, err = assetTransfer.QueryAssets(ws.transactionContext, `{"selector":{},"sort":["blocklist"]}`)
+	requireError(t, err, chaincode.ErrValidation, "fields and sort are only supported on CouchDB")
+	_, err = assetTransfer.QueryAssets(ws.transactionContext, `[]`)
+	requireError(t, err, chaincode.ErrValidation, "the query must be a JSON object with a selector")
+	require.Equal(t, 0, ws.stub.GetQueryResultCallCount())
+
+	page, err := assetTransfer.QueryAssetsWithPagination(ws.transactionContext, `{"selector":{"blocklist":"www.xxx.com"}}`, 2, "")
+	require.NoError(t, err)
+	require.Len(t, page.Assets, 2)
+	require.Equal(t, int32(2), page.FetchedRecordsCount)
+	require.Equal(t, assetKey("www.ddd.com"), page.Bookmark)
+
+	page, err = assetTransfer.QueryAssetsWithPagination(ws.transactionContext, `{"selector":{"blocklist":"www.xxx.com"}}`, 2, page.Bookmark)
+	require.NoError(t, err)
+	require.Len(t, page.Assets, 1)
+	require.Equal(t, int32(1), page.FetchedRecordsCount)
+	require.Empty(t, page.Bookmark)
+}
+
+func TestRichQueryFallback(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+	putLists(t, ws)
+
+	ws.stub.GetQueryResultStub = func(string) (shim.StateQueryIteratorInterface, error) {
+		return nil, fmt.Errorf("ExecuteQuery not supported for leveldb")
+	}
+	ws.stub.GetQueryResultWithPaginationStub = func(string, int32, string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return nil, nil, fmt.Errorf("ExecuteQueryWithPagination not supported for leveldb")
+	}
+
+	assets, err := assetTransfer.QueryAssets(ws.transactionContext, `{"selector":{"blocklist":"www.xxx.com"}}`)
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 3)
+
+	page, err := assetTransfer.QueryAssetsWithPagination(ws.transactionContext, `{"selector":{"blocklist":"www.xxx.com"}}`, 5, "")
+	require.NoError(t, err)
+	require.Len(t, page.Assets, 3)
+	require.Empty(t, page.Bookmark)
+
+	ws.stub.GetQueryResultStub = func(string) (shim.StateQueryIteratorInterface, error) {
+		return nil, fmt.Errorf("connection refused")
+	}
+	_, err = assetTransfer.QueryAssets(ws.transactionContext, `{"selector":{"blocklist":"www.xxx.com"}}`)
+	require.EqualError(t, err, "connection refused")
+}
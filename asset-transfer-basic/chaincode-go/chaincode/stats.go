@@ -0,0 +1,209 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const assetStatsObjectType = "stats"
+
+// assetStatsShards spreads the asset statistics over several keys. Every transaction that
+// writes assets updates one shard, chosen by its transaction ID, so concurrent writes rarely
+// conflict on the statistics.
+const assetStatsShards = 16
+
+// JobRecountAssetStats rebuilds the asset statistics from the stored assets, for assets
+// stored before the statistics were kept. It takes no parameters. Assets written while it
+// runs may be counted twice, so it should run while no other assets are written.
+const JobRecountAssetStats = "recountAssetStats"
+
+// assetStatsShard holds the changes to the asset statistics made by the transactions that
+// chose the shard, and the last of those transactions
+type assetStatsShard struct {
+	Allowlists int            `json:"allowlists"`
+	Assets     int            `json:"assets"`
+	Blocklists int            `json:"blocklists"`
+	Categories map[string]int `json:"categories"`
+	TxID       string         `json:"txID"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
+}
+
+// AssetStats is the response of GetAssetStats. AllowlistAssets and BlocklistAssets count the
+// assets with a non-empty allowlist and blocklist, and Categories the assets in each category
+// with at least one asset. LastUpdateTxID is the last transaction that wrote an asset.
+type AssetStats struct {
+	AllowlistAssets int            `json:"allowlistAssets"`
+	BlocklistAssets int            `json:"blocklistAssets"`
+	Categories      map[string]int `json:"categories"`
+	LastUpdateTxID  string         `json:"lastUpdateTxID"`
+	LastUpdatedAt   time.Time      `json:"lastUpdatedAt"`
+	Metadata        QueryMetadata  `json:"metadata"`
+	TotalAssets     int            `json:"totalAssets"`
+}
+
+func init() {
+	jobKinds[JobRecountAssetStats] = jobKind{
+		validate: func(params map[string]string) error {
+			return nil
+		},
+		run: recountAssetStatsPage,
+	}
+}
+
+// GetAssetStats returns the number of stored assets in total, per category and with an
+// allowlist or blocklist. The statistics are kept up to date as assets are written, so they
+// are read from a few counters rather than by scanning the assets.
+func (s *SmartContract) GetAssetStats(ctx contractapi.TransactionContextInterface) (*AssetStats, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetStatsObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	stats := AssetStats{Categories: map[string]int{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var shard assetStatsShard
+		err = json.Unmarshal(queryResponse.Value, &shard)
+		if err != nil {
+			return nil, err
+		}
+		stats.AllowlistAssets += shard.Allowlists
+		stats.BlocklistAssets += shard.Blocklists
+		stats.TotalAssets += shard.Assets
+		for category, count := range shard.Categories {
+			stats.Categories[category] += count
+		}
+		if shard.UpdatedAt.After(stats.LastUpdatedAt) {
+			stats.LastUpdatedAt = shard.UpdatedAt
+			stats.LastUpdateTxID = shard.TxID
+		}
+	}
+	for category, count := range stats.Categories {
+		if count == 0 {
+			delete(stats.Categories, category)
+		}
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats.Metadata = metadata
+
+	return &stats, nil
+}
+
+// GetAssetCount returns the number of stored assets, as counted by GetAssetStats
+func (s *SmartContract) GetAssetCount(ctx contractapi.TransactionContextInterface) (int, error) {
+	stats, err := s.GetAssetStats(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return stats.TotalAssets, nil
+}
+
+// countAsset updates the asset statistics for an asset replacing its previous version. Either
+// may be nil, for an asset being created or deleted.
+func countAsset(ctx contractapi.TransactionContextInterface, previous *Asset, current *Asset) error {
+	key, err := ctx.GetStub().CreateCompositeKey(assetStatsObjectType, []string{txShard(ctx, assetStatsShards)})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	shard := assetStatsShard{Categories: map[string]int{}}
+	_, err = getRecord(ctx, key, &shard)
+	if err != nil {
+		return err
+	}
+	if shard.Categories == nil {
+		shard.Categories = map[string]int{}
+	}
+
+	shard.add(previous, -1)
+	shard.add(current, 1)
+	for category, count := range shard.Categories {
+		if count == 0 {
+			delete(shard.Categories, category)
+		}
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	shard.TxID = ctx.GetStub().GetTxID()
+	shard.UpdatedAt = now
+
+	return putRecord(ctx, key, shard)
+}
+
+// add counts asset delta times in the shard
+func (s *assetStatsShard) add(asset *Asset, delta int) {
+	if asset == nil {
+		return
+	}
+
+	s.Assets += delta
+	if asset.Allowlist != "" {
+		s.Allowlists += delta
+	}
+	if asset.Blocklist != "" {
+		s.Blocklists += delta
+	}
+	if asset.Category != "" {
+		s.Categories[asset.Category] += delta
+	}
+}
+
+// recountAssetStatsPage counts up to pageSize assets into the asset statistics, after
+// clearing them on the first page
+func recountAssetStatsPage(ctx contractapi.TransactionContextInterface, job *Job, pageSize int) (int, string, error) {
+	if job.Bookmark == "" {
+		_, err := deleteByPartialCompositeKey(ctx, assetStatsObjectType, []string{})
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetObjectType, []string{})
+	if err != nil {
+		return 0, "", err
+	}
+	defer resultsIterator.Close()
+
+	counted := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, "", err
+		}
+		// partial composite key queries cannot start at a key, and paginated queries are not
+		// available to a transaction that writes, so the page is skipped to by hand
+		if queryResponse.Key < job.Bookmark {
+			continue
+		}
+		if counted == pageSize {
+			return counted, queryResponse.Key, nil
+		}
+		counted++
+
+		asset, err := unmarshalStoredAsset(ctx, queryResponse.Key, queryResponse.Value)
+		if err != nil {
+			return 0, "", err
+		}
+		err = countAsset(ctx, nil, asset)
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
+	return counted, "", nil
+}
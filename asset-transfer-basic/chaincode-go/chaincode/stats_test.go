@@ -0,0 +1,94 @@
+package chaincode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAssetStats(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	stats, err := assetTransfer.GetAssetStats(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, 0, stats.TotalAssets)
+	require.Empty(t, stats.Categories)
+	require.Empty(t, stats.LastUpdateTxID)
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.InitLedger(ws.transactionContext))
+	ws.commit()
+	require.NoError(t, assetTransfer.CreateCategory(ws.transactionContext, "education", "Schools and universities"))
+	ws.commit()
+	require.NoError(t, assetTransfer.SetAssetCategory(ws.transactionContext, "www.napier.ac.uk", "education"))
+	require.NoError(t, assetTransfer.SetAssetCategory(ws.transactionContext, "scholar.google.com", "education"))
+	ws.commit()
+
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "scholar.google.com", "www.xxx.com", 10, "", 600))
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.google.com"))
+	txID := ws.txID()
+	ws.commit()
+
+	stats, err = assetTransfer.GetAssetStats(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, 5, stats.TotalAssets)
+	require.Equal(t, 3, stats.AllowlistAssets)
+	require.Equal(t, 3, stats.BlocklistAssets)
+	require.Equal(t, map[string]int{"education": 2}, stats.Categories)
+	require.Equal(t, txID, stats.LastUpdateTxID)
+
+	require.NoError(t, assetTransfer.SetAssetCategory(ws.transactionContext, "www.napier.ac.uk", ""))
+	ws.commit()
+	stats, err = assetTransfer.GetAssetStats(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"education": 1}, stats.Categories)
+
+	count, err := assetTransfer.GetAssetCount(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, 5, count)
+}
+
+func TestRecountAssetStatsJob(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.InitLedger(ws.transactionContext))
+	ws.commit()
+	expected, err := assetTransfer.GetAssetStats(ws.transactionContext)
+	require.NoError(t, err)
+
+	// assets stored before the statistics were kept
+	for key := range ws.state {
+		if strings.HasPrefix(key, compositeKeyNamespace+"stats"+compositeKeyNamespace) {
+			delete(ws.state, key)
+		}
+	}
+	count, err := assetTransfer.GetAssetCount(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+
+	jobID, err := assetTransfer.StartJob(ws.transactionContext, chaincode.JobRecountAssetStats, "")
+	require.NoError(t, err)
+	ws.commit()
+
+	job, err := assetTransfer.ContinueJob(ws.transactionContext, jobID, 4)
+	require.NoError(t, err)
+	require.False(t, job.Done)
+	ws.commit()
+	job, err = assetTransfer.ContinueJob(ws.transactionContext, jobID, 4)
+	require.NoError(t, err)
+	require.True(t, job.Done)
+	require.Equal(t, 6, job.Processed)
+	ws.commit()
+
+	stats, err := assetTransfer.GetAssetStats(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, expected.TotalAssets, stats.TotalAssets)
+	require.Equal(t, expected.AllowlistAssets, stats.AllowlistAssets)
+	require.Equal(t, expected.BlocklistAssets, stats.BlocklistAssets)
+	require.Equal(t, expected.Categories, stats.Categories)
+}
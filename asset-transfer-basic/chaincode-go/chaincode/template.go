@@ -0,0 +1,224 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const templateObjectType = "template"
+
+// Template is a preset combination of asset values from which new assets can be created
+type Template struct {
+	Allowlist     string    `json:"allowlist"`
+	Attribute2    int       `json:"attribute2"`
+	Blocklist     string    `json:"blocklist"`
+	Category      string    `json:"category,omitempty" metadata:"category,optional"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	ID            string    `json:"id"`
+	Schedule      *Schedule `json:"schedule,omitempty" metadata:"schedule,optional"`
+	Webfilterlist int       `json:"webfilterlist"`
+}
+
+// templateOverrides holds the values a caller supplies to CreateAssetFromTemplate.
+// Fields left out of the JSON document keep the template's value.
+type templateOverrides struct {
+	Allowlist     *string   `json:"allowlist"`
+	Attribute1    *string   `json:"attribute1"`
+	Attribute2    *int      `json:"attribute2"`
+	Blocklist     *string   `json:"blocklist"`
+	Category      *string   `json:"category"`
+	ExpiresAt     *string   `json:"expiresAt"`
+	Schedule      *Schedule `json:"schedule"`
+	Webfilterlist *int      `json:"webfilterlist"`
+}
+
+// CreateTemplate stores a new asset template
+func (s *SmartContract) CreateTemplate(ctx contractapi.TransactionContextInterface, templateID string, allowlist string, blocklist string, attribute2 int, webfilterlist int) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if templateID == "" {
+		return newError(ErrValidation, map[string]string{"field": "templateID"}, "a template ID is required")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(templateObjectType, []string{templateID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	var existing Template
+	exists, err := getRecord(ctx, key, &existing)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return newError(ErrAlreadyExists, map[string]string{"templateID": templateID}, "the template %s already exists", templateID)
+	}
+
+	return putTemplate(ctx, Template{
+		Allowlist:     allowlist,
+		Attribute2:    attribute2,
+		Blocklist:     blocklist,
+		ID:            templateID,
+		Webfilterlist: webfilterlist,
+	})
+}
+
+// UpdateTemplate replaces the values of an existing asset template, keeping its category,
+// schedule and expiry. Assets already created from the template are not affected.
+func (s *SmartContract) UpdateTemplate(ctx contractapi.TransactionContextInterface, templateID string, allowlist string, blocklist string, attribute2 int, webfilterlist int) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.ReadTemplate(ctx, templateID)
+	if err != nil {
+		return err
+	}
+
+	return putTemplate(ctx, Template{
+		Allowlist:     allowlist,
+		Attribute2:    attribute2,
+		Blocklist:     blocklist,
+		Category:      existing.Category,
+		ExpiresAt:     existing.ExpiresAt,
+		ID:            templateID,
+		Schedule:      existing.Schedule,
+		Webfilterlist: webfilterlist,
+	})
+}
+
+// SetTemplateDefaults sets the category, the schedule in scheduleJSON and the RFC 3339
+// expiry given to assets created from the template, each left unset when empty. They are
+// validated like those of SetAssetCategory, SetAssetSchedule and SetAssetExpiry.
+func (s *SmartContract) SetTemplateDefaults(ctx contractapi.TransactionContextInterface, templateID string, categoryID string, scheduleJSON string, expiresAt string) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	template, err := s.ReadTemplate(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	err = assertCategoryExists(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+	template.Category = categoryID
+	template.Schedule = nil
+	if scheduleJSON != "" {
+		template.Schedule, err = parseSchedule(scheduleJSON)
+		if err != nil {
+			return err
+		}
+	}
+	template.ExpiresAt, err = parseExpiry(ctx, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	return putTemplate(ctx, *template)
+}
+
+// ReadTemplate returns the template stored in the world state with given id
+func (s *SmartContract) ReadTemplate(ctx contractapi.TransactionContextInterface, templateID string) (*Template, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(templateObjectType, []string{templateID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var template Template
+	exists, err := getRecord(ctx, key, &template)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, newError(ErrNotFound, map[string]string{"templateID": templateID}, "the template %s does not exist", templateID)
+	}
+
+	return &template, nil
+}
+
+// CreateAssetFromTemplate issues a new asset whose values, category, schedule and expiry come
+// from the template, with any fields present in overridesJSON (for example
+// {"allowlist":"www.bbc.co.uk"}) taking precedence. An empty "category" or "expiresAt" override
+// leaves the asset without one. A template whose expiry has passed can no longer be used
+// without overriding it.
+func (s *SmartContract) CreateAssetFromTemplate(ctx contractapi.TransactionContextInterface, templateID string, overridesJSON string) error {
+	template, err := s.ReadTemplate(ctx, templateID)
+	if err != nil {
+		return err
+	}
+
+	var overrides templateOverrides
+	if overridesJSON != "" {
+		decoder := json.NewDecoder(bytes.NewReader([]byte(overridesJSON)))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&overrides)
+		if err != nil {
+			return newError(ErrValidation, nil, "invalid template overrides: %v", err)
+		}
+	}
+
+	asset := Asset{
+		Allowlist:     template.Allowlist,
+		Attribute2:    template.Attribute2,
+		Blocklist:     template.Blocklist,
+		Category:      template.Category,
+		ExpiresAt:     template.ExpiresAt,
+		Schedule:      template.Schedule,
+		Webfilterlist: template.Webfilterlist,
+	}
+	if overrides.Allowlist != nil {
+		asset.Allowlist = *overrides.Allowlist
+	}
+	if overrides.Blocklist != nil {
+		asset.Blocklist = *overrides.Blocklist
+	}
+	if overrides.Attribute1 != nil {
+		asset.Attribute1 = *overrides.Attribute1
+	}
+	if overrides.Attribute2 != nil {
+		asset.Attribute2 = *overrides.Attribute2
+	}
+	if overrides.Category != nil {
+		asset.Category = *overrides.Category
+	}
+	if overrides.Schedule != nil {
+		asset.Schedule = overrides.Schedule
+	}
+	if overrides.Webfilterlist != nil {
+		asset.Webfilterlist = *overrides.Webfilterlist
+	}
+	if overrides.ExpiresAt != nil {
+		asset.ExpiresAt, err = parseExpiry(ctx, *overrides.ExpiresAt)
+		if err != nil {
+			return err
+		}
+	} else if !asset.ExpiresAt.IsZero() {
+		now, err := txTime(ctx)
+		if err != nil {
+			return err
+		}
+		if !asset.ExpiresAt.After(now) {
+			return newError(ErrValidation, map[string]string{"templateID": templateID}, "the template %s expired at %s", templateID, asset.ExpiresAt.Format(time.RFC3339))
+		}
+	}
+
+	return s.createAsset(ctx, asset)
+}
+
+func putTemplate(ctx contractapi.TransactionContextInterface, template Template) error {
+	key, err := ctx.GetStub().CreateCompositeKey(templateObjectType, []string{template.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, template)
+}
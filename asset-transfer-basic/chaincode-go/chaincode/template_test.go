@@ -0,0 +1,114 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTemplate(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	err := assetTransfer.CreateTemplate(ws.transactionContext, "school", "", "www.instagram.com", 5, 300)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.CreateTemplate(ws.transactionContext, "school", "", "www.instagram.com", 5, 300))
+	ws.commit()
+
+	err = assetTransfer.CreateTemplate(ws.transactionContext, "school", "", "www.instagram.com", 5, 300)
+	requireError(t, err, chaincode.ErrAlreadyExists, "the template school already exists")
+
+	err = assetTransfer.UpdateTemplate(ws.transactionContext, "office", "", "www.instagram.com", 5, 300)
+	requireError(t, err, chaincode.ErrNotFound, "the template office does not exist")
+
+	require.NoError(t, assetTransfer.UpdateTemplate(ws.transactionContext, "school", "", "www.xxx.com", 10, 400))
+	ws.commit()
+
+	template, err := assetTransfer.ReadTemplate(ws.transactionContext, "school")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Template{Blocklist: "www.xxx.com", Attribute2: 10, ID: "school", Webfilterlist: 400}, template)
+}
+
+func TestCreateAssetFromTemplate(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.CreateTemplate(ws.transactionContext, "school", "", "www.instagram.com", 5, 300))
+	ws.commit()
+
+	err := assetTransfer.CreateAssetFromTemplate(ws.transactionContext, "office", `{"allowlist":"www.bbc.co.uk"}`)
+	requireError(t, err, chaincode.ErrNotFound, "the template office does not exist")
+
+	err = assetTransfer.CreateAssetFromTemplate(ws.transactionContext, "school", `{"colour":"red"}`)
+	requireError(t, err, chaincode.ErrValidation, `invalid template overrides: json: unknown field "colour"`)
+
+	require.NoError(t, assetTransfer.CreateAssetFromTemplate(ws.transactionContext, "school", `{"allowlist":"www.bbc.co.uk","attribute2":10}`))
+	ws.commit()
+
+	exists, err := assetTransfer.AssetExists(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	usage, err := assetTransfer.GetQuotaUsage(ws.transactionContext, "Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, 2, usage.Entries)
+}
+
+func TestCreateAssetFromTemplateCarriesCategoryScheduleAndExpiry(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.CreateCategory(ws.transactionContext, "education", "Schools and universities"))
+	require.NoError(t, assetTransfer.CreateTemplate(ws.transactionContext, "school", "", "www.instagram.com", 5, 300))
+	ws.commit()
+
+	err := assetTransfer.SetTemplateDefaults(ws.transactionContext, "school", "social", "", "")
+	requireError(t, err, chaincode.ErrNotFound, "the category social does not exist")
+	err = assetTransfer.SetTemplateDefaults(ws.transactionContext, "school", "", "", "2021-03-01T08:00:00Z")
+	requireError(t, err, chaincode.ErrValidation, "the expiry 2021-03-01T08:00:00Z must be after the transaction time")
+
+	schedule := `{"days":["mon","tue","wed","thu","fri"],"end":"17:00","start":"9:00"}`
+	require.NoError(t, assetTransfer.SetTemplateDefaults(ws.transactionContext, "school", "education", schedule, "2021-03-01T09:05:00Z"))
+	ws.commit()
+
+	// updating the values of the template keeps its category, schedule and expiry
+	require.NoError(t, assetTransfer.UpdateTemplate(ws.transactionContext, "school", "", "www.xxx.com", 10, 400))
+	ws.commit()
+	template, err := assetTransfer.ReadTemplate(ws.transactionContext, "school")
+	require.NoError(t, err)
+	want := &chaincode.Schedule{Days: []string{"mon", "tue", "wed", "thu", "fri"}, End: "17:00", Start: "09:00"}
+	require.Equal(t, "education", template.Category)
+	require.Equal(t, want, template.Schedule)
+	require.Equal(t, "2021-03-01T09:05:00Z", template.ExpiresAt.Format(time.RFC3339))
+
+	require.NoError(t, assetTransfer.CreateAssetFromTemplate(ws.transactionContext, "school", `{"allowlist":"www.bbc.co.uk"}`))
+	ws.commit()
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "education", asset.Category)
+	require.Equal(t, want, asset.Schedule)
+	require.Equal(t, template.ExpiresAt, asset.ExpiresAt)
+
+	// the overrides take precedence, and empty ones leave the asset without a category or expiry
+	require.NoError(t, assetTransfer.CreateAssetFromTemplate(ws.transactionContext, "school", `{"allowlist":"www.ed.ac.uk","category":"","expiresAt":"","schedule":{"end":"12:00","start":"08:00"}}`))
+	ws.commit()
+	asset, err = assetTransfer.ReadAsset(ws.transactionContext, "www.ed.ac.uk")
+	require.NoError(t, err)
+	require.Empty(t, asset.Category)
+	require.True(t, asset.ExpiresAt.IsZero())
+	require.Equal(t, &chaincode.Schedule{End: "12:00", Start: "08:00"}, asset.Schedule)
+
+	// an expiry that has passed is not given to new assets
+	ws.commit()
+	err = assetTransfer.CreateAssetFromTemplate(ws.transactionContext, "school", `{"allowlist":"www.napier.ac.uk"}`)
+	requireError(t, err, chaincode.ErrValidation, "the template school expired at 2021-03-01T09:05:00Z")
+	err = assetTransfer.CreateAssetFromTemplate(ws.transactionContext, "school", `{"allowlist":"www.napier.ac.uk","expiresAt":"2021-03-01T09:00:00Z"}`)
+	requireError(t, err, chaincode.ErrValidation, "the expiry 2021-03-01T09:00:00Z must be after the transaction time")
+	require.NoError(t, assetTransfer.CreateAssetFromTemplate(ws.transactionContext, "school", `{"allowlist":"www.napier.ac.uk","expiresAt":"2021-03-02T09:00:00Z"}`))
+}
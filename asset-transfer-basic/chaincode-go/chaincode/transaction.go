@@ -0,0 +1,144 @@
+package chaincode
+
+import (
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// evaluateTransactions lists the functions that only read the world state. They are tagged
+// as evaluate in the contract metadata and stay available during maintenance mode.
+var evaluateTransactions = []string{
+	"AssetExists",
+	"ExportDeviceFilterList",
+	"ExportFilterList",
+	"GetAllAssetFields",
+	"GetAllAssets",
+	"GetAllAssetsPaginated",
+	"GetAssetChangeLog",
+	"GetAssetCount",
+	"GetAssetEndorsementPolicy",
+	"GetAssetHistory",
+	"GetAssetImmutability",
+	"GetAssetStats",
+	"GetAssetsByBlocklist",
+	"GetAssetsByCategory",
+	"GetAssetsByPrefix",
+	"GetAssetsByRange",
+	"GetAuditLog",
+	"GetCapabilities",
+	"GetConfig",
+	"GetDelegation",
+	"GetEffectivePermissions",
+	"GetEffectivePoliciesForDevices",
+	"GetEffectivePolicy",
+	"GetEffectivePolicyForDevice",
+	"GetJobStatus",
+	"GetLastKnownGood",
+	"GetLastModification",
+	"GetListActivityReport",
+	"GetMyPendingActions",
+	"GetMyWatches",
+	"GetPendingTransfers",
+	"GetQuotaUsage",
+	"GetRandomAssets",
+	"GetRelatedAssets",
+	"GetRole",
+	"GetTransferHistory",
+	"GetTransferPolicy",
+	"GetUsageMetrics",
+	"GetUsageReport",
+	"IsBlockedAt",
+	"ListCategories",
+	"ListKeyNamespaces",
+	"ListReservedPrefixes",
+	"MatchDomain",
+	"QueryAssets",
+	"QueryAssetsWithPagination",
+	"ReadAgreement",
+	"ReadAsset",
+	"ReadAssetFields",
+	"ReadErasureCertificate",
+	"ReadOrganization",
+	"ReadPrivateAsset",
+	"ReadTemplate",
+	"ReconcileWithSnapshot",
+	"ResolveAlias",
+	"ResolvePolicy",
+	"VerifyListSnapshot",
+}
+
+// GetEvaluateTransactions returns the functions that clients should evaluate rather than submit
+func (s *SmartContract) GetEvaluateTransactions() []string {
+	return evaluateTransactions
+}
+
+// GetBeforeTransaction returns the function called before every transaction of the contract
+func (s *SmartContract) GetBeforeTransaction() interface{} {
+	return beforeTransaction
+}
+
+// beforeTransaction enforces the permission matrix, the role registry and the size limit on
+// arguments, rejects writes while the contract is in maintenance mode or that replay a
+// processed idempotency key, counts submitted transactions towards the ledger height and the
+// usage metrics, and records their audit entries. The steps of ComposeTransaction are
+// authorized but not counted or audited on their own.
+func beforeTransaction(ctx contractapi.TransactionContextInterface) error {
+	function := transactionName(ctx)
+	err := assertPermitted(ctx, function)
+	if err != nil {
+		return err
+	}
+	err = assertRoleAllows(ctx, function)
+	if err != nil {
+		return err
+	}
+	err = assertArgumentSizes(ctx, function)
+	if err != nil {
+		return err
+	}
+
+	if stringInSlice(function, evaluateTransactions) {
+		return nil
+	}
+	if function != "SetMaintenanceMode" {
+		err = assertWritable(ctx, function)
+		if err != nil {
+			return err
+		}
+	}
+	if composedStep(ctx) {
+		// the composing transaction is checked for replays and counted once for all its steps
+		return nil
+	}
+	err = assertNotReplayed(ctx, function)
+	if err != nil {
+		return err
+	}
+	err = countInvocation(ctx, function)
+	if err != nil {
+		return err
+	}
+	err = recordAuditEntry(ctx, function)
+	if err != nil {
+		return err
+	}
+
+	return advanceLedgerHeight(ctx)
+}
+
+// transactionName returns the name of the invoked function without its contract namespace
+func transactionName(ctx contractapi.TransactionContextInterface) string {
+	function, _ := ctx.GetStub().GetFunctionAndParameters()
+
+	return functionName(function)
+}
+
+// functionName strips the contract namespace from a function name
+func functionName(function string) string {
+	if i := strings.LastIndex(function, ":"); i >= 0 {
+		function = function[i+1:]
+	}
+
+	return function
+}
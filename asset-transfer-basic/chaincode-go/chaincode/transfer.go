@@ -0,0 +1,298 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	transferObjectType          = "transfer"
+	transferRecipientObjectType = "transfer~recipient"
+)
+
+// PendingTransfer is a transfer of an asset to another organization that waits for the
+// receiving organization to accept or reject it. An asset has at most one pending transfer.
+type PendingTransfer struct {
+	AssetID       string    `json:"assetID"`
+	From          string    `json:"from"`
+	ProposedAt    time.Time `json:"proposedAt"`
+	ProposedBy    string    `json:"proposedBy"`
+	RecipientMSP  string    `json:"recipientMSP"`
+	TransactionID string    `json:"transactionID"`
+}
+
+// PendingTransferList is the response of GetPendingTransfers
+type PendingTransferList struct {
+	Metadata  QueryMetadata      `json:"metadata"`
+	Transfers []*PendingTransfer `json:"transfers"`
+}
+
+// ProposeTransfer offers the asset with given id to the organization recipientMSP. Unlike
+// TransferAsset, the attribute1 and owner of the asset keep their values until a client of the receiving
+// organization calls AcceptTransfer. The receiving organization is notified of the proposal.
+// Only the owner of the asset or an administrator may propose its transfer.
+func (s *SmartContract) ProposeTransfer(ctx contractapi.TransactionContextInterface, id string, recipientMSP string) error {
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = assertOwnerOrAdmin(ctx, asset.Owner)
+	if err != nil {
+		return err
+	}
+	err = assertMutable(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if recipientMSP == "" {
+		return newError(ErrValidation, map[string]string{"field": "recipientMSP"}, "an MSP ID is required")
+	}
+	if recipientMSP == asset.Attribute1 {
+		return newError(ErrValidation, map[string]string{"id": id, "mspID": recipientMSP}, "the asset %s already belongs to %s", id, recipientMSP)
+	}
+	org, err := readOrganization(ctx, recipientMSP)
+	if err != nil {
+		return err
+	}
+	if org != nil && org.Status == OrgOffboarded {
+		return newError(ErrValidation, map[string]string{"mspID": recipientMSP}, "the organization %s has been offboarded", recipientMSP)
+	}
+
+	pending, err := readPendingTransfer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if pending != nil {
+		return newError(ErrValidation, map[string]string{"id": id, "mspID": pending.RecipientMSP}, "the asset %s already has a transfer pending to %s", id, pending.RecipientMSP)
+	}
+
+	proposedBy, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	transfer := PendingTransfer{
+		AssetID:       id,
+		From:          asset.Owner,
+		ProposedAt:    now,
+		ProposedBy:    proposedBy,
+		RecipientMSP:  recipientMSP,
+		TransactionID: ctx.GetStub().GetTxID(),
+	}
+	key, err := transferKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = putRecord(ctx, key, transfer)
+	if err != nil {
+		return err
+	}
+
+	indexKey, err := ctx.GetStub().CreateCompositeKey(transferRecipientObjectType, []string{recipientMSP, id})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	err = ctx.GetStub().PutState(indexKey, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put to world state: %v", err)
+	}
+
+	return notify(ctx, recipientMSP, "AcceptTransfer", id)
+}
+
+// AcceptTransfer completes the pending transfer of the asset with given id, setting its
+// attribute1 and owner to the receiving organization as TransferAsset does, and returns the
+// old attribute1. Only a client of the receiving organization may accept. The transfer fails
+// if the owner of the asset has changed since the transfer was proposed. The recipient's
+// notification of the proposal is cleared.
+func (s *SmartContract) AcceptTransfer(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	transfer, err := assertTransferRecipient(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	err = assertMutable(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if asset.Owner != transfer.From {
+		return "", newError(ErrValidation, map[string]string{"id": id}, "the asset %s has changed hands since the transfer was proposed", id)
+	}
+	oldattribute1 := asset.Attribute1
+
+	err = deletePendingTransfer(ctx, transfer)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.transferAsset(ctx, asset, transfer.RecipientMSP)
+	if err != nil {
+		return "", err
+	}
+
+	return oldattribute1, nil
+}
+
+// RejectTransfer discards the pending transfer of the asset with given id and leaves the
+// asset unchanged. A client of the receiving organization may reject the transfer, and the
+// owner of the asset or an administrator may withdraw it. Either way the recipient's
+// notification of the proposal is cleared.
+func (s *SmartContract) RejectTransfer(ctx contractapi.TransactionContextInterface, id string) error {
+	transfer, err := readPendingTransfer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if transfer == nil {
+		return newError(ErrNotFound, map[string]string{"id": id}, "the asset %s has no pending transfer", id)
+	}
+
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if mspID != transfer.RecipientMSP {
+		asset, err := readStoredAsset(ctx, id)
+		if err != nil {
+			return err
+		}
+		err = assertOwnerOrAdmin(ctx, asset.Owner)
+		if err != nil {
+			return err
+		}
+	}
+
+	return deletePendingTransfer(ctx, transfer)
+}
+
+// GetPendingTransfers returns the transfers waiting for the submitting organization to
+// accept or reject them, ordered by asset ID
+func (s *SmartContract) GetPendingTransfers(ctx contractapi.TransactionContextInterface) (*PendingTransferList, error) {
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(transferRecipientObjectType, []string{mspID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	transfers := []*PendingTransfer{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %v", err)
+		}
+
+		transfer, err := readPendingTransfer(ctx, attributes[1])
+		if err != nil {
+			return nil, err
+		}
+		if transfer != nil {
+			transfers = append(transfers, transfer)
+		}
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PendingTransferList{Metadata: metadata, Transfers: transfers}, nil
+}
+
+// assertTransferRecipient returns the pending transfer of the asset with given id, or an error
+// unless there is one and the submitting organization is its recipient
+func assertTransferRecipient(ctx contractapi.TransactionContextInterface, id string) (*PendingTransfer, error) {
+	transfer, err := readPendingTransfer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, newError(ErrNotFound, map[string]string{"id": id}, "the asset %s has no pending transfer", id)
+	}
+
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if mspID != transfer.RecipientMSP {
+		return nil, newError(ErrUnauthorized, map[string]string{"id": id}, "only %s may accept the transfer of %s", transfer.RecipientMSP, id)
+	}
+
+	return transfer, nil
+}
+
+func transferKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(transferObjectType, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readPendingTransfer returns the pending transfer of the asset with given id, or nil if there is none
+func readPendingTransfer(ctx contractapi.TransactionContextInterface, id string) (*PendingTransfer, error) {
+	key, err := transferKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	transferJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if transferJSON == nil {
+		return nil, nil
+	}
+
+	var transfer PendingTransfer
+	err = json.Unmarshal(transferJSON, &transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+// deletePendingTransfer removes a pending transfer, its entry in the recipient's index and the
+// recipient's request to accept it
+func deletePendingTransfer(ctx contractapi.TransactionContextInterface, transfer *PendingTransfer) error {
+	key, err := transferKey(ctx, transfer.AssetID)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(key)
+	if err != nil {
+		return fmt.Errorf("failed to delete from world state: %v", err)
+	}
+
+	indexKey, err := ctx.GetStub().CreateCompositeKey(transferRecipientObjectType, []string{transfer.RecipientMSP, transfer.AssetID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	err = ctx.GetStub().DelState(indexKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete from world state: %v", err)
+	}
+
+	return resolveNotifications(ctx, transfer.RecipientMSP, "AcceptTransfer", transfer.AssetID)
+}
@@ -0,0 +1,178 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProposeTransfer(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "Org1MSP", 100))
+	ws.commit()
+
+	err := assetTransfer.ProposeTransfer(ws.transactionContext, "www.bbc.co.uk", "Org1MSP")
+	requireError(t, err, chaincode.ErrValidation, "the asset www.bbc.co.uk already belongs to Org1MSP")
+	err = assetTransfer.ProposeTransfer(ws.transactionContext, "www.bbc.co.uk", "")
+	requireError(t, err, chaincode.ErrValidation, "an MSP ID is required")
+
+	ws.setClient("Org1MSP", "user2", false)
+	err = assetTransfer.ProposeTransfer(ws.transactionContext, "www.bbc.co.uk", "Org2MSP")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.ProposeTransfer(ws.transactionContext, "www.bbc.co.uk", "Org2MSP"))
+	proposed := ws.now
+	ws.commit()
+
+	err = assetTransfer.ProposeTransfer(ws.transactionContext, "www.bbc.co.uk", "Org3MSP")
+	requireError(t, err, chaincode.ErrValidation, "the asset www.bbc.co.uk already has a transfer pending to Org2MSP")
+
+	// the asset keeps its attribute1 until the transfer is accepted
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "Org1MSP", asset.Attribute1)
+
+	ws.setClient("Org2MSP", "user3", false)
+	transfers, err := assetTransfer.GetPendingTransfers(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.PendingTransfer{{
+		AssetID:       "www.bbc.co.uk",
+		From:          "user1",
+		ProposedAt:    proposed,
+		ProposedBy:    "user1",
+		RecipientMSP:  "Org2MSP",
+		TransactionID: transfers.Transfers[0].TransactionID,
+	}}, transfers.Transfers)
+
+	pending, err := assetTransfer.GetMyPendingActions(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	require.Len(t, pending.Notifications, 1)
+	require.Equal(t, "AcceptTransfer", pending.Notifications[0].Action)
+	require.Equal(t, "www.bbc.co.uk", pending.Notifications[0].Subject)
+}
+
+func TestAcceptTransfer(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "Org1MSP", 100))
+	ws.commit()
+
+	_, err := assetTransfer.AcceptTransfer(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrNotFound, "the asset www.bbc.co.uk has no pending transfer")
+
+	require.NoError(t, assetTransfer.ProposeTransfer(ws.transactionContext, "www.bbc.co.uk", "Org2MSP"))
+	ws.commit()
+
+	ws.setClient("Org3MSP", "user3", false)
+	_, err = assetTransfer.AcceptTransfer(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrUnauthorized, "only Org2MSP may accept the transfer of www.bbc.co.uk")
+
+	ws.setClient("Org2MSP", "user2", false)
+	previous, err := assetTransfer.AcceptTransfer(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "Org1MSP", previous)
+	name, event := lastAssetEvent(t, ws)
+	require.Equal(t, "AssetTransferred", name)
+	require.Equal(t, "Org2MSP", event.Assets[0].Attribute1)
+	ws.commit()
+
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "Org2MSP", asset.Attribute1)
+	require.Equal(t, "Org2MSP", asset.Owner)
+	history, err := assetTransfer.GetTransferHistory(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.TransferRecord{{NewOwner: "Org2MSP", PreviousOwner: "user1", Timestamp: history.Transfers[0].Timestamp, TxID: history.Transfers[0].TxID}}, history.Transfers)
+
+	// the receiving organization now owns the asset, and the sender no longer does
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "www.yyy.com", 2, "Org2MSP", 200))
+	ws.commit()
+	ws.setClient("Org1MSP", "user1", false)
+	err = assetTransfer.UpdateAsset(ws.transactionContext, "www.bbc.co.uk", "www.zzz.com", 3, "Org2MSP", 300)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+	ws.setClient("Org2MSP", "user2", false)
+
+	transfers, err := assetTransfer.GetPendingTransfers(ws.transactionContext)
+	require.NoError(t, err)
+	require.Empty(t, transfers.Transfers)
+	pending, err := assetTransfer.GetMyPendingActions(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	require.Empty(t, pending.Notifications)
+	_, err = assetTransfer.AcceptTransfer(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrNotFound, "the asset www.bbc.co.uk has no pending transfer")
+}
+
+func TestAcceptTransferAfterOwnerChange(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "Org1MSP", 100))
+	ws.commit()
+	require.NoError(t, assetTransfer.ProposeTransfer(ws.transactionContext, "www.bbc.co.uk", "Org2MSP"))
+	ws.commit()
+	_, err := assetTransfer.TransferAsset(ws.transactionContext, "www.bbc.co.uk", "Org3MSP")
+	require.NoError(t, err)
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", false)
+	_, err = assetTransfer.AcceptTransfer(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrValidation, "the asset www.bbc.co.uk has changed hands since the transfer was proposed")
+}
+
+func TestRejectTransfer(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "Org1MSP", 100))
+	ws.commit()
+	require.NoError(t, assetTransfer.ProposeTransfer(ws.transactionContext, "www.bbc.co.uk", "Org2MSP"))
+	ws.commit()
+
+	ws.setClient("Org3MSP", "user3", false)
+	err := assetTransfer.RejectTransfer(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: only the owner or an administrator may do so")
+
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, assetTransfer.RejectTransfer(ws.transactionContext, "www.bbc.co.uk"))
+	ws.commit()
+
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "Org1MSP", asset.Attribute1)
+	pending, err := assetTransfer.GetMyPendingActions(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	require.Empty(t, pending.Notifications)
+	err = assetTransfer.RejectTransfer(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrNotFound, "the asset www.bbc.co.uk has no pending transfer")
+
+	// the owner may withdraw a proposal, and deleting the asset discards it
+	ws.setClient("Org1MSP", "user1", false)
+	require.NoError(t, assetTransfer.ProposeTransfer(ws.transactionContext, "www.bbc.co.uk", "Org2MSP"))
+	ws.commit()
+	require.NoError(t, assetTransfer.RejectTransfer(ws.transactionContext, "www.bbc.co.uk"))
+	ws.commit()
+	ws.setClient("Org2MSP", "user2", false)
+	pending, err = assetTransfer.GetMyPendingActions(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	require.Empty(t, pending.Notifications)
+	ws.setClient("Org1MSP", "user1", false)
+
+	require.NoError(t, assetTransfer.ProposeTransfer(ws.transactionContext, "www.bbc.co.uk", "Org2MSP"))
+	ws.commit()
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.bbc.co.uk"))
+	ws.commit()
+
+	ws.setClient("Org2MSP", "user2", false)
+	transfers, err := assetTransfer.GetPendingTransfers(ws.transactionContext)
+	require.NoError(t, err)
+	require.Empty(t, transfers.Transfers)
+	pending, err = assetTransfer.GetMyPendingActions(ws.transactionContext, 10, "")
+	require.NoError(t, err)
+	require.Empty(t, pending.Notifications)
+}
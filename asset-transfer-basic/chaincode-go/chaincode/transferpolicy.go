@@ -0,0 +1,161 @@
+package chaincode
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const transferPolicyObjectType = "transferpolicy"
+
+// TransferPolicy governs which related assets move with an asset when it is transferred.
+// Assets linked below the transferred asset by one of the carried relations, and owned by
+// the previous owner, are reassigned to the new owner in the same transaction. Linked assets
+// owned by someone else are left alone, or fail the transfer if RejectForeignOwners is set.
+type TransferPolicy struct {
+	CarriedRelations    []string `json:"carriedRelations"`
+	RejectForeignOwners bool     `json:"rejectForeignOwners"`
+}
+
+// GetTransferPolicy returns the policy applied by TransferAsset
+func (s *SmartContract) GetTransferPolicy(ctx contractapi.TransactionContextInterface) (*TransferPolicy, error) {
+	return readTransferPolicy(ctx)
+}
+
+// SetTransferPolicy replaces the policy applied by TransferAsset
+func (s *SmartContract) SetTransferPolicy(ctx contractapi.TransactionContextInterface, carriedRelations []string, rejectForeignOwners bool) error {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	for _, relation := range carriedRelations {
+		if relation == "" {
+			return newError(ErrValidation, map[string]string{"field": "carriedRelations"}, "carried relations must not be empty")
+		}
+	}
+
+	key, err := transferPolicyKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	return putRecord(ctx, key, TransferPolicy{
+		CarriedRelations:    carriedRelations,
+		RejectForeignOwners: rejectForeignOwners,
+	})
+}
+
+func transferPolicyKey(ctx contractapi.TransactionContextInterface) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(transferPolicyObjectType, []string{"contract"})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readTransferPolicy returns the stored policy, or one carrying nothing if none has been saved
+func readTransferPolicy(ctx contractapi.TransactionContextInterface) (*TransferPolicy, error) {
+	key, err := transferPolicyKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := TransferPolicy{CarriedRelations: []string{}}
+	_, err = getRecord(ctx, key, &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// carryTransfer reassigns the assets linked below id by the policy's carried relations from
+// oldOwner to newOwner, charging them to the quota of newOwnerMSP unless it is empty, and
+// returns the assets it reassigned. Expired children are left behind rather than failing the
+// transfer, and links to children that no longer exist are removed.
+func (s *SmartContract) carryTransfer(ctx contractapi.TransactionContextInterface, id string, oldOwner string, newOwner string, newOwnerMSP string) ([]*Asset, error) {
+	policy, err := readTransferPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	carried := []*Asset{}
+	visited := map[string]bool{id: true}
+	frontier := []string{id}
+	for level := 1; level <= maxLinkDepth && len(frontier) > 0; level++ {
+		var next []string
+		for _, parentID := range frontier {
+			for _, relation := range policy.CarriedRelations {
+				links, err := queryLinks(ctx, linkObjectType, parentID, relation)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, link := range links {
+					if visited[link.Child] {
+						continue
+					}
+					visited[link.Child] = true
+
+					asset, err := readStoredAsset(ctx, link.Child)
+					if errors.Is(err, ErrAssetNotFound) {
+						err = deleteLink(ctx, link.Parent, relation, link.Child)
+						if err != nil {
+							return nil, err
+						}
+						continue
+					}
+					if err != nil {
+						return nil, err
+					}
+					if asset.expiredAt(now) {
+						continue
+					}
+					err = assertMutable(ctx, link.Child)
+					if err != nil {
+						return nil, err
+					}
+					if asset.Owner != oldOwner {
+						if policy.RejectForeignOwners {
+							return nil, newError(ErrValidation, map[string]string{"id": link.Child}, "the asset %s linked to %s by %s is owned by %s and cannot be transferred", link.Child, link.Parent, relation, asset.Owner)
+						}
+						continue
+					}
+
+					asset.Attribute1 = newOwner
+					asset.Owner = newOwner
+					err = putAsset(ctx, asset)
+					if err != nil {
+						return nil, err
+					}
+					if newOwnerMSP != "" {
+						err = moveQuotaCharge(ctx, link.Child, newOwnerMSP)
+						if err != nil {
+							return nil, err
+						}
+					}
+					err = recordModification(ctx, link.Child, operationTransfer)
+					if err != nil {
+						return nil, err
+					}
+					err = recordTransfer(ctx, link.Child, oldOwner, newOwner)
+					if err != nil {
+						return nil, err
+					}
+
+					carried = append(carried, asset)
+					next = append(next, link.Child)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return carried, nil
+}
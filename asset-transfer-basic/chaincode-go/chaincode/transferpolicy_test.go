@@ -0,0 +1,98 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTransferPolicy(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	policy, err := assetTransfer.GetTransferPolicy(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TransferPolicy{CarriedRelations: []string{}}, policy)
+
+	err = assetTransfer.SetTransferPolicy(ws.transactionContext, []string{"includes"}, true)
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	err = assetTransfer.SetTransferPolicy(ws.transactionContext, []string{""}, true)
+	requireError(t, err, chaincode.ErrValidation, "carried relations must not be empty")
+
+	require.NoError(t, assetTransfer.SetTransferPolicy(ws.transactionContext, []string{"includes"}, true))
+	ws.commit()
+
+	policy, err = assetTransfer.GetTransferPolicy(ws.transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TransferPolicy{CarriedRelations: []string{"includes"}, RejectForeignOwners: true}, policy)
+}
+
+func TestTransferAssetCarriesLinkedAssets(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	for _, id := range []string{"policy1", "list1", "feed1", "list2"} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "", 1, "", 100))
+	}
+	ws.commit()
+	require.NoError(t, assetTransfer.LinkAssets(ws.transactionContext, "policy1", "list1", "includes"))
+	require.NoError(t, assetTransfer.LinkAssets(ws.transactionContext, "list1", "feed1", "includes"))
+	require.NoError(t, assetTransfer.LinkAssets(ws.transactionContext, "policy1", "list2", "references"))
+	ws.commit()
+
+	_, err := assetTransfer.TransferAsset(ws.transactionContext, "policy1", "Org2MSP")
+	require.NoError(t, err)
+	require.Contains(t, ws.writes, assetKey("policy1"))
+	require.NotContains(t, ws.writes, assetKey("list1"))
+	ws.rollback()
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetTransferPolicy(ws.transactionContext, []string{"includes"}, false))
+	ws.commit()
+
+	_, err = assetTransfer.TransferAsset(ws.transactionContext, "policy1", "Org2MSP")
+	require.NoError(t, err)
+	require.Contains(t, ws.writes, assetKey("policy1"))
+	require.Contains(t, ws.writes, assetKey("list1"))
+	require.Contains(t, ws.writes, assetKey("feed1"))
+	require.NotContains(t, ws.writes, assetKey("list2"))
+
+	name, payload := ws.stub.SetEventArgsForCall(ws.stub.SetEventCallCount() - 1)
+	require.Equal(t, "AssetTransferred", name)
+	var event chaincode.AssetEvent
+	require.NoError(t, json.Unmarshal(payload, &event))
+	ids := []string{}
+	for _, asset := range event.Assets {
+		require.Equal(t, "Org2MSP", asset.Attribute1)
+		ids = append(ids, asset.Allowlist)
+	}
+	require.Equal(t, []string{"policy1", "list1", "feed1"}, ids)
+}
+
+func TestTransferAssetLeavesExpiredLinkedAssets(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	for _, id := range []string{"policy1", "list1", "list2"} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "", 1, "", 100))
+	}
+	ws.commit()
+	require.NoError(t, assetTransfer.LinkAssets(ws.transactionContext, "policy1", "list1", "includes"))
+	require.NoError(t, assetTransfer.LinkAssets(ws.transactionContext, "policy1", "list2", "includes"))
+	require.NoError(t, assetTransfer.SetAssetExpiry(ws.transactionContext, "list1", "2021-03-01T10:00:00Z"))
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.SetTransferPolicy(ws.transactionContext, []string{"includes"}, true))
+	ws.commit()
+	ws.now = time.Date(2021, time.March, 1, 11, 0, 0, 0, time.UTC)
+
+	_, err := assetTransfer.TransferAsset(ws.transactionContext, "policy1", "Org2MSP")
+	require.NoError(t, err)
+	require.Contains(t, ws.writes, assetKey("policy1"))
+	require.NotContains(t, ws.writes, assetKey("list1"))
+	require.Contains(t, ws.writes, assetKey("list2"))
+}
@@ -0,0 +1,215 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const invocationMetricObjectType = "fnmetric"
+
+// invocationMetricShards spreads the counter of each function and organization over several
+// keys, so that concurrent invocations rarely conflict on the same counter
+const invocationMetricShards = 16
+
+// compactedShard names the counter into which JobCompactUsageMetrics folds the other shards
+const compactedShard = "compacted"
+
+// JobCompactUsageMetrics folds the sharded invocation counters into one counter per function
+// and organization. It takes no parameters.
+const JobCompactUsageMetrics = "compactUsageMetrics"
+
+type invocationCounter struct {
+	Count uint64 `json:"count"`
+}
+
+// FunctionMetric counts the committed invocations of one function by one organization
+type FunctionMetric struct {
+	Function    string `json:"function"`
+	Invocations uint64 `json:"invocations"`
+	MSPID       string `json:"mspID"`
+}
+
+// UsageMetrics is the response of GetUsageMetrics
+type UsageMetrics struct {
+	Metadata QueryMetadata     `json:"metadata"`
+	Metrics  []*FunctionMetric `json:"metrics"`
+}
+
+func init() {
+	jobKinds[JobCompactUsageMetrics] = jobKind{
+		validate: func(params map[string]string) error {
+			return nil
+		},
+		run: compactUsageMetricsPage,
+	}
+}
+
+// GetUsageMetrics returns the number of committed invocations of each submitted function per
+// organization, ordered by function and MSP ID. When function is empty every function is reported.
+// Queries are evaluated rather than committed and are therefore not counted.
+func (s *SmartContract) GetUsageMetrics(ctx contractapi.TransactionContextInterface, function string) (*UsageMetrics, error) {
+	err := assertAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := []string{}
+	if function != "" {
+		attributes = append(attributes, function)
+	}
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(invocationMetricObjectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	meter, err := newCostMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := []*FunctionMetric{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		err = meter.scanned()
+		if err != nil {
+			return nil, err
+		}
+
+		metricFunction, mspID, _, err := splitInvocationMetricKey(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		var counter invocationCounter
+		err = json.Unmarshal(queryResponse.Value, &counter)
+		if err != nil {
+			return nil, err
+		}
+
+		// the shards of a counter are adjacent in key order
+		last := len(metrics) - 1
+		if last >= 0 && metrics[last].Function == metricFunction && metrics[last].MSPID == mspID {
+			metrics[last].Invocations += counter.Count
+			continue
+		}
+		metrics = append(metrics, &FunctionMetric{Function: metricFunction, Invocations: counter.Count, MSPID: mspID})
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Cost = &meter.cost
+
+	return &UsageMetrics{Metadata: metadata, Metrics: metrics}, nil
+}
+
+// countInvocation counts the current transaction towards the metrics of function and the caller's organization
+func countInvocation(ctx contractapi.TransactionContextInterface, function string) error {
+	mspID, err := callerMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(invocationMetricObjectType, []string{function, mspID, txShard(ctx, invocationMetricShards)})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var counter invocationCounter
+	_, err = getRecord(ctx, key, &counter)
+	if err != nil {
+		return err
+	}
+	counter.Count++
+
+	return putRecord(ctx, key, counter)
+}
+
+// compactUsageMetricsPage folds up to pageSize counter shards, starting at the job's bookmark,
+// into the compacted counter of their function and organization
+func compactUsageMetricsPage(ctx contractapi.TransactionContextInterface, job *Job, pageSize int) (int, string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(invocationMetricObjectType, []string{})
+	if err != nil {
+		return 0, "", err
+	}
+	defer resultsIterator.Close()
+
+	// the counts folded into each compacted counter are accumulated and it is written once
+	compacted := map[string]uint64{}
+	var order []string
+	folded := 0
+	bookmark := ""
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, "", err
+		}
+		if queryResponse.Key < job.Bookmark {
+			continue
+		}
+		function, mspID, shard, err := splitInvocationMetricKey(ctx, queryResponse.Key)
+		if err != nil {
+			return 0, "", err
+		}
+		if shard == compactedShard {
+			continue
+		}
+		if folded == pageSize {
+			bookmark = queryResponse.Key
+			break
+		}
+		folded++
+
+		var counter invocationCounter
+		err = json.Unmarshal(queryResponse.Value, &counter)
+		if err != nil {
+			return 0, "", err
+		}
+		compactedKey, err := ctx.GetStub().CreateCompositeKey(invocationMetricObjectType, []string{function, mspID, compactedShard})
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to create composite key: %v", err)
+		}
+		if _, ok := compacted[compactedKey]; !ok {
+			order = append(order, compactedKey)
+		}
+		compacted[compactedKey] += counter.Count
+
+		err = ctx.GetStub().DelState(queryResponse.Key)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to delete from world state: %v", err)
+		}
+	}
+
+	for _, key := range order {
+		var counter invocationCounter
+		_, err = getRecord(ctx, key, &counter)
+		if err != nil {
+			return 0, "", err
+		}
+		counter.Count += compacted[key]
+		err = putRecord(ctx, key, counter)
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
+	return folded, bookmark, nil
+}
+
+func splitInvocationMetricKey(ctx contractapi.TransactionContextInterface, key string) (string, string, string, error) {
+	_, parts, err := ctx.GetStub().SplitCompositeKey(key)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to split composite key: %v", err)
+	}
+	if len(parts) != 3 {
+		return "", "", "", fmt.Errorf("malformed invocation metric key %q", key)
+	}
+
+	return parts[0], parts[1], parts[2], nil
+}
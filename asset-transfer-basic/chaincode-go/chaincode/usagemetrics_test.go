@@ -0,0 +1,89 @@
+package chaincode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUsageMetrics(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+		ws.commit()
+	}
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+	ws.commit()
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "DeleteAsset"))
+	ws.commit()
+	require.NoError(t, beforeTransaction(t, assetTransfer, ws, "ReadAsset"))
+	ws.commit()
+
+	_, err := assetTransfer.GetUsageMetrics(ws.transactionContext, "")
+	requireError(t, err, chaincode.ErrUnauthorized, "the client is not authorized to perform this operation: attribute webfilter.admin proposed value of true does not match")
+
+	ws.setClient("Org1MSP", "admin", true)
+	usage, err := assetTransfer.GetUsageMetrics(ws.transactionContext, "")
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.FunctionMetric{
+		{Function: "CreateAsset", Invocations: 5, MSPID: "Org1MSP"},
+		{Function: "CreateAsset", Invocations: 1, MSPID: "Org2MSP"},
+		{Function: "DeleteAsset", Invocations: 1, MSPID: "Org2MSP"},
+	}, usage.Metrics)
+
+	usage, err = assetTransfer.GetUsageMetrics(ws.transactionContext, "DeleteAsset")
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.FunctionMetric{{Function: "DeleteAsset", Invocations: 1, MSPID: "Org2MSP"}}, usage.Metrics)
+}
+
+func TestCompactUsageMetricsJob(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := &chaincode.SmartContract{}
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, beforeTransaction(t, assetTransfer, ws, "CreateAsset"))
+		ws.commit()
+	}
+	shards := countKeys(ws, "fnmetric")
+	require.Greater(t, shards, 2)
+
+	ws.setClient("Org1MSP", "admin", true)
+	jobID, err := assetTransfer.StartJob(ws.transactionContext, chaincode.JobCompactUsageMetrics, "")
+	require.NoError(t, err)
+	ws.commit()
+
+	job, err := assetTransfer.ContinueJob(ws.transactionContext, jobID, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, job.Processed)
+	require.False(t, job.Done)
+	ws.commit()
+	require.Equal(t, shards-1, countKeys(ws, "fnmetric"))
+
+	for !job.Done {
+		job, err = assetTransfer.ContinueJob(ws.transactionContext, jobID, 2)
+		require.NoError(t, err)
+		ws.commit()
+	}
+	require.Equal(t, shards, job.Processed)
+	require.Equal(t, 1, countKeys(ws, "fnmetric"))
+
+	usage, err := assetTransfer.GetUsageMetrics(ws.transactionContext, "CreateAsset")
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.FunctionMetric{{Function: "CreateAsset", Invocations: 20, MSPID: "Org1MSP"}}, usage.Metrics)
+}
+
+func countKeys(ws *worldState, objectType string) int {
+	count := 0
+	for key := range ws.state {
+		if strings.HasPrefix(key, compositeKeyNamespace+objectType+compositeKeyNamespace) {
+			count++
+		}
+	}
+
+	return count
+}
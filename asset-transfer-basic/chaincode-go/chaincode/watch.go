@@ -0,0 +1,165 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const watchObjectType = "watch"
+
+// Asset operations recorded in the audit trail
+const (
+	operationCreate   = "create"
+	operationUpdate   = "update"
+	operationDelete   = "delete"
+	operationTransfer = "transfer"
+)
+
+// Watch registers a client's interest in an asset key, or in every key starting with a
+// prefix when the pattern ends in "*"
+type Watch struct {
+	Pattern string `json:"pattern"`
+	Watcher string `json:"watcher"`
+}
+
+// RegisterWatch records the submitting client's interest in keys matching keyPattern
+func (s *SmartContract) RegisterWatch(ctx contractapi.TransactionContextInterface, keyPattern string) error {
+	err := validateWatchPattern(keyPattern)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(watchObjectType, []string{watcher, keyPattern})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return putRecord(ctx, key, Watch{Pattern: keyPattern, Watcher: watcher})
+}
+
+// UnregisterWatch removes a watch previously registered by the submitting client
+func (s *SmartContract) UnregisterWatch(ctx contractapi.TransactionContextInterface, keyPattern string) error {
+	watcher, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(watchObjectType, []string{watcher, keyPattern})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	var watch Watch
+	exists, err := getRecord(ctx, key, &watch)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return newError(ErrNotFound, map[string]string{"keyPattern": keyPattern}, "no watch is registered for %s", keyPattern)
+	}
+
+	return ctx.GetStub().DelState(key)
+}
+
+// WatchList is the response of GetMyWatches
+type WatchList struct {
+	Metadata QueryMetadata `json:"metadata"`
+	Watches  []*Watch      `json:"watches"`
+}
+
+// GetMyWatches returns the watches registered by the submitting client
+func (s *SmartContract) GetMyWatches(ctx contractapi.TransactionContextInterface) (*WatchList, error) {
+	watcher, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	watches, err := queryWatches(ctx, []string{watcher})
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := queryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WatchList{Metadata: metadata, Watches: watches}, nil
+}
+
+func validateWatchPattern(pattern string) error {
+	if strings.TrimSuffix(pattern, "*") == "" {
+		return newError(ErrValidation, map[string]string{"field": "keyPattern"}, "a watch pattern must name a key or a key prefix")
+	}
+	if strings.Contains(strings.TrimSuffix(pattern, "*"), "*") {
+		return newError(ErrValidation, map[string]string{"field": "keyPattern"}, "the watch pattern %s may only contain a trailing wildcard", pattern)
+	}
+
+	return nil
+}
+
+func (w *Watch) matches(key string) bool {
+	if strings.HasSuffix(w.Pattern, "*") {
+		return strings.HasPrefix(key, strings.TrimSuffix(w.Pattern, "*"))
+	}
+
+	return w.Pattern == key
+}
+
+func queryWatches(ctx contractapi.TransactionContextInterface, attributes []string) ([]*Watch, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(watchObjectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	watches := []*Watch{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var watch Watch
+		err = json.Unmarshal(queryResponse.Value, &watch)
+		if err != nil {
+			return nil, err
+		}
+		watches = append(watches, &watch)
+	}
+
+	return watches, nil
+}
+
+// assetWatchers returns the sorted IDs of every client watching one of the asset keys ids
+func assetWatchers(ctx contractapi.TransactionContextInterface, ids []string) ([]string, error) {
+	watches, err := queryWatches(ctx, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	watchers := []string{}
+	for _, watch := range watches {
+		if stringInSlice(watch.Watcher, watchers) {
+			continue
+		}
+		for _, id := range ids {
+			if watch.matches(id) {
+				watchers = append(watchers, watch.Watcher)
+				break
+			}
+		}
+	}
+	sort.Strings(watchers)
+
+	return watchers, nil
+}
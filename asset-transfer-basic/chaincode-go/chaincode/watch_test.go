@@ -0,0 +1,63 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterWatch(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	err := assetTransfer.RegisterWatch(ws.transactionContext, "*")
+	requireError(t, err, chaincode.ErrValidation, "a watch pattern must name a key or a key prefix")
+
+	err = assetTransfer.RegisterWatch(ws.transactionContext, "www.*.com")
+	requireError(t, err, chaincode.ErrValidation, "the watch pattern www.*.com may only contain a trailing wildcard")
+
+	require.NoError(t, assetTransfer.RegisterWatch(ws.transactionContext, "www.bbc.co.uk"))
+	require.NoError(t, assetTransfer.RegisterWatch(ws.transactionContext, "www.google.*"))
+	ws.commit()
+
+	watchList, err := assetTransfer.GetMyWatches(ws.transactionContext)
+	require.NoError(t, err)
+	watches := watchList.Watches
+	require.Equal(t, []*chaincode.Watch{
+		{Pattern: "www.bbc.co.uk", Watcher: "user1"},
+		{Pattern: "www.google.*", Watcher: "user1"},
+	}, watches)
+
+	require.NoError(t, assetTransfer.UnregisterWatch(ws.transactionContext, "www.bbc.co.uk"))
+	ws.commit()
+
+	err = assetTransfer.UnregisterWatch(ws.transactionContext, "www.bbc.co.uk")
+	requireError(t, err, chaincode.ErrNotFound, "no watch is registered for www.bbc.co.uk")
+}
+
+func TestAssetEventWatchers(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.RegisterWatch(ws.transactionContext, "www.google.*"))
+	ws.setClient("Org2MSP", "user2", false)
+	require.NoError(t, assetTransfer.RegisterWatch(ws.transactionContext, "www.google.com"))
+	ws.commit()
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "", 1, "", 100))
+	_, payload := ws.stub.SetEventArgsForCall(0)
+	var event chaincode.AssetEvent
+	require.NoError(t, json.Unmarshal(payload, &event))
+	require.Empty(t, event.Watchers)
+	ws.commit()
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.google.com", "", 1, "", 100))
+	require.Equal(t, 2, ws.stub.SetEventCallCount())
+
+	name, payload := ws.stub.SetEventArgsForCall(1)
+	require.Equal(t, "AssetCreated", name)
+	require.NoError(t, json.Unmarshal(payload, &event))
+	require.Equal(t, []string{"user1", "user2"}, event.Watchers)
+}
@@ -0,0 +1,384 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode/mocks"
+)
+
+const compositeKeyNamespace = "\x00"
+
+// worldState is an in-memory ledger wired into the counterfeiter mocks so that
+// tests can exercise functions touching several keys. Like a peer, writes made
+// during a transaction only become visible to reads once commit is called.
+// Private data is kept per collection and the peer belongs to Org1MSP.
+type worldState struct {
+	state         map[string][]byte
+	writes        map[string][]byte
+	history       map[string][]*queryresult.KeyModification
+	private       map[string]map[string][]byte
+	privateWrites map[string]map[string][]byte
+	txCount       int
+	now           time.Time
+
+	stub               *mocks.ChaincodeStub
+	clientIdentity     *mocks.ClientIdentity
+	transactionContext *chaincode.TransactionContext
+}
+
+func newWorldState() *worldState {
+	ws := &worldState{
+		state:          map[string][]byte{},
+		writes:         map[string][]byte{},
+		history:        map[string][]*queryresult.KeyModification{},
+		private:        map[string]map[string][]byte{},
+		privateWrites:  map[string]map[string][]byte{},
+		now:            time.Date(2021, time.March, 1, 9, 0, 0, 0, time.UTC),
+		stub:           &mocks.ChaincodeStub{},
+		clientIdentity: &mocks.ClientIdentity{},
+	}
+
+	ws.newTransactionContext()
+	ws.setClient("Org1MSP", "user1", false)
+	os.Setenv("CORE_PEER_LOCALMSPID", "Org1MSP")
+
+	ws.stub.GetStateStub = ws.getState
+	ws.stub.PutStateStub = ws.putState
+	ws.stub.DelStateStub = ws.delState
+	ws.stub.GetStateByRangeStub = ws.getStateByRange
+	ws.stub.GetStateByRangeWithPaginationStub = ws.getStateByRangeWithPagination
+	ws.stub.GetStateByPartialCompositeKeyStub = ws.getStateByPartialCompositeKey
+	ws.stub.GetStateByPartialCompositeKeyWithPaginationStub = ws.getStateByPartialCompositeKeyWithPagination
+	ws.stub.GetQueryResultStub = ws.getQueryResult
+	ws.stub.GetQueryResultWithPaginationStub = ws.getQueryResultWithPagination
+	ws.stub.CreateCompositeKeyStub = createCompositeKey
+	ws.stub.SplitCompositeKeyStub = splitCompositeKey
+	ws.stub.GetHistoryForKeyStub = ws.getHistoryForKey
+	ws.stub.GetPrivateDataStub = ws.getPrivateData
+	ws.stub.PutPrivateDataStub = ws.putPrivateData
+	ws.stub.DelPrivateDataStub = ws.delPrivateData
+	ws.stub.GetTxIDStub = ws.txID
+	ws.stub.GetTxTimestampStub = ws.txTimestamp
+
+	return ws
+}
+
+// newTransactionContext starts a fresh transaction context, as the contract API does for every transaction.
+func (ws *worldState) newTransactionContext() {
+	ws.transactionContext = &chaincode.TransactionContext{}
+	ws.transactionContext.SetStub(ws.stub)
+	ws.transactionContext.SetClientIdentity(ws.clientIdentity)
+}
+
+// setClient changes the identity submitting subsequent transactions.
+func (ws *worldState) setClient(mspID string, id string, admin bool) {
+	ws.clientIdentity.GetMSPIDReturns(mspID, nil)
+	ws.clientIdentity.GetIDReturns(id, nil)
+	ws.clientIdentity.AssertAttributeValueStub = func(name string, value string) error {
+		if admin && name == "webfilter.admin" && value == "true" {
+			return nil
+		}
+		return fmt.Errorf("attribute %s proposed value of %s does not match", name, value)
+	}
+}
+
+// commit applies the pending writes of the current transaction and starts a new one.
+func (ws *worldState) commit() {
+	keys := make([]string, 0, len(ws.writes))
+	for key := range ws.writes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ts, _ := ws.txTimestamp()
+	for _, key := range keys {
+		value := ws.writes[key]
+		ws.history[key] = append(ws.history[key], &queryresult.KeyModification{
+			TxId:      ws.txID(),
+			Value:     value,
+			Timestamp: ts,
+			IsDelete:  value == nil,
+		})
+		if value == nil {
+			delete(ws.state, key)
+		} else {
+			ws.state[key] = value
+		}
+	}
+
+	for collection, writes := range ws.privateWrites {
+		if ws.private[collection] == nil {
+			ws.private[collection] = map[string][]byte{}
+		}
+		for key, value := range writes {
+			if value == nil {
+				delete(ws.private[collection], key)
+			} else {
+				ws.private[collection][key] = value
+			}
+		}
+	}
+
+	ws.writes = map[string][]byte{}
+	ws.privateWrites = map[string]map[string][]byte{}
+	ws.newTransactionContext()
+	ws.txCount++
+	ws.now = ws.now.Add(time.Minute)
+}
+
+// rollback discards the pending writes of a failed transaction and starts a new one.
+func (ws *worldState) rollback() {
+	ws.writes = map[string][]byte{}
+	ws.privateWrites = map[string]map[string][]byte{}
+	ws.newTransactionContext()
+	ws.txCount++
+}
+
+func (ws *worldState) txID() string {
+	return fmt.Sprintf("tx%03d", ws.txCount)
+}
+
+func (ws *worldState) txTimestamp() (*timestamp.Timestamp, error) {
+	return ptypes.TimestampProto(ws.now)
+}
+
+func (ws *worldState) getState(key string) ([]byte, error) {
+	return ws.state[key], nil
+}
+
+func (ws *worldState) putState(key string, value []byte) error {
+	ws.writes[key] = value
+	return nil
+}
+
+func (ws *worldState) delState(key string) error {
+	ws.writes[key] = nil
+	return nil
+}
+
+func (ws *worldState) getPrivateData(collection string, key string) ([]byte, error) {
+	return ws.private[collection][key], nil
+}
+
+func (ws *worldState) putPrivateData(collection string, key string, value []byte) error {
+	if ws.privateWrites[collection] == nil {
+		ws.privateWrites[collection] = map[string][]byte{}
+	}
+	ws.privateWrites[collection][key] = value
+	return nil
+}
+
+func (ws *worldState) delPrivateData(collection string, key string) error {
+	return ws.putPrivateData(collection, key, nil)
+}
+
+func (ws *worldState) sortedKeys(match func(string) bool) []string {
+	var keys []string
+	for key := range ws.state {
+		if match(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (ws *worldState) iterator(keys []string) *stateIterator {
+	results := make([]*queryresult.KV, 0, len(keys))
+	for _, key := range keys {
+		results = append(results, &queryresult.KV{Key: key, Value: ws.state[key]})
+	}
+	return &stateIterator{results: results}
+}
+
+func (ws *worldState) getStateByRange(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	keys := ws.sortedKeys(func(key string) bool {
+		return !strings.HasPrefix(key, compositeKeyNamespace) && key >= startKey && (endKey == "" || key < endKey)
+	})
+	return ws.iterator(keys), nil
+}
+
+// getStateByRangeWithPagination returns up to pageSize keys of the range starting at the
+// bookmark, which like on a peer is the key the next page starts from.
+func (ws *worldState) getStateByRangeWithPagination(startKey string, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	if bookmark > startKey {
+		startKey = bookmark
+	}
+	keys := ws.sortedKeys(func(key string) bool {
+		return !strings.HasPrefix(key, compositeKeyNamespace) && key >= startKey && (endKey == "" || key < endKey)
+	})
+
+	next := ""
+	if len(keys) > int(pageSize) {
+		next = keys[pageSize]
+		keys = keys[:pageSize]
+	}
+	return ws.iterator(keys), &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(keys)), Bookmark: next}, nil
+}
+
+func (ws *worldState) getStateByPartialCompositeKey(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	prefix, err := createCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	keys := ws.sortedKeys(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+	return ws.iterator(keys), nil
+}
+
+// getStateByPartialCompositeKeyWithPagination returns up to pageSize keys of the object type
+// starting at the bookmark, which like on a peer is the key the next page starts from.
+func (ws *worldState) getStateByPartialCompositeKeyWithPagination(objectType string, attributes []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	prefix, err := createCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := ws.sortedKeys(func(key string) bool {
+		return strings.HasPrefix(key, prefix) && key >= bookmark
+	})
+
+	next := ""
+	if len(keys) > int(pageSize) {
+		next = keys[pageSize]
+		keys = keys[:pageSize]
+	}
+	return ws.iterator(keys), &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(keys)), Bookmark: next}, nil
+}
+
+// getQueryResult supports the subset of CouchDB rich queries used by the contract: a selector
+// whose fields must all equal the corresponding top level fields of the document, and a limit.
+func (ws *worldState) getQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	keys, limit, err := ws.queryKeys(query)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return ws.iterator(keys), nil
+}
+
+// getQueryResultWithPagination returns up to pageSize keys matching the query starting at the
+// bookmark, which here is the key the next page starts from.
+func (ws *worldState) getQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	keys, _, err := ws.queryKeys(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	for len(keys) > 0 && keys[0] < bookmark {
+		keys = keys[1:]
+	}
+
+	next := ""
+	if len(keys) > int(pageSize) {
+		next = keys[pageSize]
+		keys = keys[:pageSize]
+	}
+	return ws.iterator(keys), &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(keys)), Bookmark: next}, nil
+}
+
+func (ws *worldState) queryKeys(query string) ([]string, int, error) {
+	var q struct {
+		Selector map[string]interface{} `json:"selector"`
+		Limit    int                    `json:"limit"`
+	}
+	err := json.Unmarshal([]byte(query), &q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid query: %v", err)
+	}
+
+	keys := ws.sortedKeys(func(key string) bool {
+		var document map[string]interface{}
+		if json.Unmarshal(ws.state[key], &document) != nil {
+			return false
+		}
+		for field, value := range q.Selector {
+			if !reflect.DeepEqual(document[field], value) {
+				return false
+			}
+		}
+		return true
+	})
+	return keys, q.Limit, nil
+}
+
+func (ws *worldState) getHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	return &historyIterator{results: ws.history[key]}, nil
+}
+
+// assetKey returns the composite key the contract stores the asset with the given ID under
+func assetKey(id string) string {
+	key, _ := createCompositeKey("asset", []string{id})
+	return key
+}
+
+func createCompositeKey(objectType string, attributes []string) (string, error) {
+	key := compositeKeyNamespace + objectType + compositeKeyNamespace
+	for _, attribute := range attributes {
+		key += attribute + compositeKeyNamespace
+	}
+	return key, nil
+}
+
+func splitCompositeKey(compositeKey string) (string, []string, error) {
+	parts := strings.Split(strings.TrimPrefix(compositeKey, compositeKeyNamespace), compositeKeyNamespace)
+	if len(parts) < 2 {
+		return "", nil, fmt.Errorf("invalid composite key %q", compositeKey)
+	}
+	return parts[0], parts[1 : len(parts)-1], nil
+}
+
+type stateIterator struct {
+	results []*queryresult.KV
+	next    int
+}
+
+func (it *stateIterator) HasNext() bool {
+	return it.next < len(it.results)
+}
+
+func (it *stateIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	it.next++
+	return it.results[it.next-1], nil
+}
+
+func (it *stateIterator) Close() error {
+	return nil
+}
+
+type historyIterator struct {
+	results []*queryresult.KeyModification
+	next    int
+}
+
+func (it *historyIterator) HasNext() bool {
+	return it.next < len(it.results)
+}
+
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	it.next++
+	return it.results[it.next-1], nil
+}
+
+func (it *historyIterator) Close() error {
+	return nil
+}
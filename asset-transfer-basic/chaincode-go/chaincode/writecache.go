@@ -0,0 +1,192 @@
+package chaincode
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// SetStub sets the stub of the transaction behind a write-through cache of its writes, so that
+// the transaction reads its own writes. A peer answers reads from the committed world state
+// only, so without the cache a function reading back a key it wrote sees the value from before
+// the write.
+func (c *TransactionContext) SetStub(stub shim.ChaincodeStubInterface) {
+	if step, ok := stub.(*composedStub); ok {
+		c.parent = step.parent
+	}
+	c.TransactionContext.SetStub(withWriteCache(stub))
+}
+
+// SetClientIdentity sets the client identity of the transaction. A step of ComposeTransaction
+// keeps the identity of the transaction composing it.
+func (c *TransactionContext) SetClientIdentity(ci cid.ClientIdentity) {
+	if step, ok := c.GetStub().(*composedStub); ok {
+		ci = step.identity
+	}
+	c.TransactionContext.SetClientIdentity(ci)
+}
+
+// writeCache passes writes through to the stub it wraps and remembers them, so that later
+// reads of the same transaction see them. GetState and the unpaginated range queries answer
+// from the writes; paginated and rich queries, history and private data see committed state
+// only, as they would without the cache.
+type writeCache struct {
+	shim.ChaincodeStubInterface
+	// writes holds the value written to each key, or nil for a deleted key
+	writes map[string][]byte
+}
+
+// withWriteCache wraps stub in a write cache unless it already has one
+func withWriteCache(stub shim.ChaincodeStubInterface) shim.ChaincodeStubInterface {
+	switch stub.(type) {
+	case *writeCache, *composedStub:
+		return stub
+	}
+
+	return &writeCache{ChaincodeStubInterface: stub, writes: map[string][]byte{}}
+}
+
+func (c *writeCache) GetState(key string) ([]byte, error) {
+	if value, ok := c.writes[key]; ok {
+		return value, nil
+	}
+
+	return c.ChaincodeStubInterface.GetState(key)
+}
+
+func (c *writeCache) PutState(key string, value []byte) error {
+	err := c.ChaincodeStubInterface.PutState(key, value)
+	if err != nil {
+		return err
+	}
+	c.writes[key] = append([]byte{}, value...)
+
+	return nil
+}
+
+func (c *writeCache) DelState(key string) error {
+	err := c.ChaincodeStubInterface.DelState(key)
+	if err != nil {
+		return err
+	}
+	c.writes[key] = nil
+
+	return nil
+}
+
+func (c *writeCache) GetStateByRange(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	resultsIterator, err := c.ChaincodeStubInterface.GetStateByRange(startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	if startKey == "" {
+		// like the shim, an open range starts after the composite keys
+		startKey = "\x01"
+	}
+
+	return c.merge(resultsIterator, func(key string) bool {
+		return key >= startKey && (endKey == "" || key < endKey)
+	})
+}
+
+func (c *writeCache) GetStateByPartialCompositeKey(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	resultsIterator, err := c.ChaincodeStubInterface.GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := c.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.merge(resultsIterator, func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// merge returns the results of resultsIterator with the writes to the keys for which inRange
+// is true applied to them, in key order. The committed results are read as the merged
+// iterator is advanced, so callers that stop early, such as queries hitting their cost caps,
+// do not read the whole range.
+func (c *writeCache) merge(resultsIterator shim.StateQueryIteratorInterface, inRange func(key string) bool) (shim.StateQueryIteratorInterface, error) {
+	// the writes are taken as they stand, so writes made while iterating do not change the results
+	replaced := map[string]bool{}
+	var written []*queryresult.KV
+	for key, value := range c.writes {
+		if !inRange(key) {
+			continue
+		}
+		replaced[key] = true
+		if value != nil {
+			written = append(written, &queryresult.KV{Key: key, Value: value})
+		}
+	}
+	sort.Slice(written, func(i, j int) bool {
+		return written[i].Key < written[j].Key
+	})
+
+	return &mergeIterator{committed: resultsIterator, replaced: replaced, written: written}, nil
+}
+
+// mergeIterator merges the committed results of a range query with the cached writes in the
+// range, advancing whichever holds the smaller key. Committed results for written keys are
+// skipped, as the writes replace them.
+type mergeIterator struct {
+	committed shim.StateQueryIteratorInterface
+	// replaced holds the keys written or deleted in the range
+	replaced map[string]bool
+	written  []*queryresult.KV
+	// next is the committed result read ahead of the merge, if any
+	next *queryresult.KV
+	err  error
+}
+
+func (it *mergeIterator) HasNext() bool {
+	it.readAhead()
+
+	return it.next != nil || it.err != nil || len(it.written) > 0
+}
+
+func (it *mergeIterator) Next() (*queryresult.KV, error) {
+	it.readAhead()
+	if it.err != nil {
+		err := it.err
+		it.err = nil
+		return nil, err
+	}
+
+	switch {
+	case it.next != nil && (len(it.written) == 0 || it.next.Key < it.written[0].Key):
+		result := it.next
+		it.next = nil
+		return result, nil
+	case len(it.written) > 0:
+		result := it.written[0]
+		it.written = it.written[1:]
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("no more results")
+}
+
+func (it *mergeIterator) Close() error {
+	return it.committed.Close()
+}
+
+// readAhead reads the next committed result whose key was not written, unless one is held
+func (it *mergeIterator) readAhead() {
+	for it.next == nil && it.err == nil && it.committed.HasNext() {
+		queryResponse, err := it.committed.Next()
+		if err != nil {
+			it.err = err
+			return
+		}
+		if !it.replaced[queryResponse.Key] {
+			it.next = queryResponse
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.bbc.co.uk", "www.xxx.com", 1, "", 100))
+	require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, "www.google.com", "www.xxx.com", 1, "", 100))
+
+	// the assets are read back before the transaction is committed
+	asset, err := assetTransfer.ReadAsset(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.Equal(t, "www.xxx.com", asset.Blocklist)
+	require.NotContains(t, ws.state, assetKey("www.bbc.co.uk"))
+
+	assets, err := assetTransfer.GetAssetsByBlocklist(ws.transactionContext, "www.xxx.com")
+	require.NoError(t, err)
+	require.Len(t, assets.Assets, 2)
+	ws.commit()
+
+	ws.setClient("Org1MSP", "admin", true)
+	require.NoError(t, assetTransfer.DeleteAsset(ws.transactionContext, "www.google.com"))
+	exists, err := assetTransfer.AssetExists(ws.transactionContext, "www.google.com")
+	require.NoError(t, err)
+	require.False(t, exists)
+	all, err := assetTransfer.GetAllAssets(ws.transactionContext)
+	require.NoError(t, err)
+	require.Len(t, all.Assets, 1)
+	require.Equal(t, "www.bbc.co.uk", all.Assets[0].ID)
+
+	// a new transaction starts from the committed world state
+	ws.commit()
+	require.NotContains(t, ws.state, assetKey("www.google.com"))
+	exists, err = assetTransfer.AssetExists(ws.transactionContext, "www.bbc.co.uk")
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+func TestWriteCacheReadsRangesLazily(t *testing.T) {
+	ws := newWorldState()
+	assetTransfer := chaincode.SmartContract{}
+
+	ws.setClient("Org1MSP", "admin", true)
+	for _, id := range []string{"www.bbc.co.uk", "www.google.com", "www.napier.ac.uk", "www.yahoo.com", "www.youtube.com"} {
+		require.NoError(t, assetTransfer.CreateAsset(ws.transactionContext, id, "", 1, "", 100))
+	}
+	require.NoError(t, assetTransfer.SetQueryLimits(ws.transactionContext, 2, 2000))
+	ws.commit()
+
+	var committed *stateIterator
+	ws.stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		resultsIterator, err := ws.getStateByPartialCompositeKey(objectType, attributes)
+		committed, _ = resultsIterator.(*stateIterator)
+		return resultsIterator, err
+	}
+
+	// the query stops at its cost cap without reading the rest of the range
+	require.NoError(t, assetTransfer.UpdateAsset(ws.transactionContext, "www.google.com", "www.xxx.com", 2, "", 100))
+	_, err := assetTransfer.GetAllAssets(ws.transactionContext)
+	requireError(t, err, chaincode.ErrQueryTooExpensive, "the query scanned more than 2 keys; narrow your query")
+	require.Equal(t, 3, committed.next)
+}
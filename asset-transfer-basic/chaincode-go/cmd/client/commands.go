@@ -0,0 +1,233 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	gwproto "github.com/hyperledger/fabric-protos-go/gateway"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/cmd/internal/connection"
+	"google.golang.org/grpc/status"
+)
+
+// command is a subcommand of the client. execute validates the arguments, returning errUsage
+// if they are invalid, before connecting to the gateway.
+type command struct {
+	execute func(options *connection.Options, args []string) error
+	usage   string
+}
+
+var createCommand = &command{
+	usage: "[-id ID] ALLOWLIST BLOCKLIST ATTRIBUTE2 ATTRIBUTE1 WEBFILTERLIST",
+	execute: func(options *connection.Options, args []string) error {
+		flags := flag.NewFlagSet("create", flag.ContinueOnError)
+		id := flags.String("id", "", "ID of the asset (default the allowlist)")
+		if flags.Parse(args) != nil || flags.NArg() != 5 || !integers(flags.Arg(2), flags.Arg(4)) {
+			return errUsage
+		}
+
+		if *id != "" {
+			return submit(options, "CreateAssetWithID", append([]string{*id}, flags.Args()...)...)
+		}
+		return submit(options, "CreateAsset", flags.Args()...)
+	},
+}
+
+var readCommand = &command{
+	usage: "ID",
+	execute: func(options *connection.Options, args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+
+		return evaluate(options, "ReadAsset", args...)
+	},
+}
+
+var updateCommand = &command{
+	usage: "ID BLOCKLIST ATTRIBUTE2 ATTRIBUTE1 WEBFILTERLIST",
+	execute: func(options *connection.Options, args []string) error {
+		if len(args) != 5 || !integers(args[2], args[4]) {
+			return errUsage
+		}
+
+		return submit(options, "UpdateAsset", args...)
+	},
+}
+
+var deleteCommand = &command{
+	usage: "ID",
+	execute: func(options *connection.Options, args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+
+		return submit(options, "DeleteAsset", args...)
+	},
+}
+
+var listCommand = &command{
+	usage: "[-page-size N] [-bookmark ID]",
+	execute: func(options *connection.Options, args []string) error {
+		flags := flag.NewFlagSet("list", flag.ContinueOnError)
+		pageSize := flags.Int("page-size", 0, "number of assets per page (default all assets)")
+		bookmark := flags.String("bookmark", "", "ID of the first asset of the page, as returned with the previous page")
+		if flags.Parse(args) != nil || flags.NArg() != 0 || *pageSize < 0 || (*bookmark != "" && *pageSize == 0) {
+			return errUsage
+		}
+
+		if *pageSize > 0 {
+			return evaluate(options, "GetAllAssetsPaginated", strconv.Itoa(*pageSize), *bookmark)
+		}
+		return evaluate(options, "GetAllAssets")
+	},
+}
+
+var watchEventsCommand = &command{
+	usage: "[-start-block N]",
+	execute: func(options *connection.Options, args []string) error {
+		flags := flag.NewFlagSet("watch-events", flag.ContinueOnError)
+		startBlock := flags.Uint64("start-block", 0, "block to replay the events from (default the next block)")
+		if flags.Parse(args) != nil || flags.NArg() != 0 {
+			return errUsage
+		}
+		var eventsOptions []client.ChaincodeEventsOption
+		flags.Visit(func(f *flag.Flag) {
+			if f.Name == "start-block" {
+				eventsOptions = append(eventsOptions, client.WithStartBlock(*startBlock))
+			}
+		})
+
+		gateway, clientConnection, err := options.Connect()
+		if err != nil {
+			return err
+		}
+		defer clientConnection.Close()
+		defer gateway.Close()
+
+		// events are read until the command is interrupted
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		events, err := gateway.GetNetwork(options.ChannelName).ChaincodeEvents(ctx, options.Chaincode, eventsOptions...)
+		if err != nil {
+			return fmt.Errorf("failed to start chaincode event listening: %w", err)
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		for event := range events {
+			payload := json.RawMessage(event.Payload)
+			if !json.Valid(payload) {
+				payload, _ = json.Marshal(string(event.Payload))
+			}
+			err = encoder.Encode(struct {
+				BlockNumber   uint64          `json:"blockNumber"`
+				EventName     string          `json:"eventName"`
+				Payload       json.RawMessage `json:"payload"`
+				TransactionID string          `json:"transactionID"`
+			}{event.BlockNumber, event.EventName, payload, event.TransactionID})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+// submit submits the transaction function with args and waits for it to be committed
+func submit(options *connection.Options, function string, args ...string) error {
+	return withContract(options, func(contract *client.Contract) ([]byte, error) {
+		return contract.SubmitTransaction(function, args...)
+	})
+}
+
+// evaluate evaluates the transaction function with args on the gateway peer
+func evaluate(options *connection.Options, function string, args ...string) error {
+	return withContract(options, func(contract *client.Contract) ([]byte, error) {
+		return contract.EvaluateTransaction(function, args...)
+	})
+}
+
+// withContract runs a transaction against the chaincode and prints its result
+func withContract(options *connection.Options, transaction func(contract *client.Contract) ([]byte, error)) error {
+	gateway, clientConnection, err := options.Connect()
+	if err != nil {
+		return err
+	}
+	defer clientConnection.Close()
+	defer gateway.Close()
+
+	response, err := transaction(gateway.GetNetwork(options.ChannelName).GetContract(options.Chaincode))
+	if err != nil {
+		return err
+	}
+
+	return printResponse(os.Stdout, os.Stderr, response)
+}
+
+// response is the envelope in which the smart contract returns every transaction result
+type response struct {
+	Result   json.RawMessage `json:"result"`
+	Warnings []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"warnings"`
+}
+
+// printResponse prints the result of the response envelope data to stdout as indented JSON
+// and its warnings to stderr. A transaction without a result prints nothing.
+func printResponse(stdout io.Writer, stderr io.Writer, data []byte) error {
+	var envelope response
+	err := json.Unmarshal(data, &envelope)
+	if err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	for _, warning := range envelope.Warnings {
+		fmt.Fprintf(stderr, "warning %s: %s\n", warning.Code, warning.Message)
+	}
+	if len(envelope.Result) == 0 || string(envelope.Result) == "null" {
+		return nil
+	}
+
+	var result bytes.Buffer
+	err = json.Indent(&result, envelope.Result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to parse result: %w", err)
+	}
+	fmt.Fprintln(stdout, result.String())
+
+	return nil
+}
+
+// printError prints err to stderr, followed by the errors the peers and orderers behind the
+// gateway reported, which hold the error returned by the smart contract
+func printError(stderr io.Writer, err error) {
+	fmt.Fprintf(stderr, "Error: %v\n", err)
+	for _, detail := range status.Convert(err).Details() {
+		if errDetail, ok := detail.(*gwproto.ErrorDetail); ok {
+			fmt.Fprintf(stderr, "  %s (%s): %s\n", errDetail.Address, errDetail.MspId, errDetail.Message)
+		}
+	}
+}
+
+// integers reports whether every value is a decimal integer
+func integers(values ...string) bool {
+	for _, value := range values {
+		if _, err := strconv.Atoi(value); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
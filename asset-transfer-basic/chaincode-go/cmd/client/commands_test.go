@@ -0,0 +1,142 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gwproto "github.com/hyperledger/fabric-protos-go/gateway"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/cmd/internal/connection"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCommandArguments(t *testing.T) {
+	// valid arguments get as far as reading the connection profile, which does not exist
+	options := &connection.Options{ProfilePath: filepath.Join(t.TempDir(), "connection-org1.json")}
+
+	tests := []struct {
+		command string
+		args    []string
+		usage   bool
+	}{
+		{command: "create", args: []string{"www.bbc.co.uk", "www.xxx.com", "1", "news", "100"}},
+		{command: "create", args: []string{"-id", "bbc", "www.bbc.co.uk", "www.xxx.com", "1", "news", "100"}},
+		{command: "create", args: []string{"www.bbc.co.uk", "www.xxx.com", "1", "news"}, usage: true},
+		{command: "create", args: []string{"www.bbc.co.uk", "www.xxx.com", "one", "news", "100"}, usage: true},
+		{command: "create", args: []string{"www.bbc.co.uk", "www.xxx.com", "1", "news", "1e2"}, usage: true},
+		{command: "create", args: []string{"-colour", "blue", "www.bbc.co.uk", "www.xxx.com", "1", "news", "100"}, usage: true},
+		{command: "read", args: []string{"www.bbc.co.uk"}},
+		{command: "read", args: []string{}, usage: true},
+		{command: "read", args: []string{"www.bbc.co.uk", "www.google.com"}, usage: true},
+		{command: "update", args: []string{"www.bbc.co.uk", "www.xxx.com", "2", "news", "100"}},
+		{command: "update", args: []string{"www.bbc.co.uk", "www.xxx.com", "2", "news", "all"}, usage: true},
+		{command: "delete", args: []string{"www.bbc.co.uk"}},
+		{command: "delete", args: []string{"www.bbc.co.uk", "www.google.com"}, usage: true},
+		{command: "list", args: []string{}},
+		{command: "list", args: []string{"-page-size", "10", "-bookmark", "www.bbc.co.uk"}},
+		{command: "list", args: []string{"-page-size", "-1"}, usage: true},
+		{command: "list", args: []string{"-bookmark", "www.bbc.co.uk"}, usage: true},
+		{command: "list", args: []string{"all"}, usage: true},
+		{command: "watch-events", args: []string{"-start-block", "5"}},
+		{command: "watch-events", args: []string{"-start-block", "latest"}, usage: true},
+		{command: "watch-events", args: []string{"AssetCreated"}, usage: true},
+	}
+	for _, test := range tests {
+		t.Run(test.command+" "+strings.Join(test.args, " "), func(t *testing.T) {
+			err := commands[test.command].execute(options, test.args)
+			require.Error(t, err)
+			require.Equal(t, test.usage, errors.Is(err, errUsage), err.Error())
+		})
+	}
+}
+
+func TestPrintResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		stdout   string
+		stderr   string
+		err      string
+	}{
+		{
+			name:     "the result is indented",
+			response: `{"result":{"id":"www.bbc.co.uk","tags":["news"]},"warnings":[]}`,
+			stdout:   "{\n  \"id\": \"www.bbc.co.uk\",\n  \"tags\": [\n    \"news\"\n  ]\n}\n",
+		},
+		{
+			name:     "warnings are printed to standard error",
+			response: `{"result":"ok","warnings":[{"code":"NEAR_QUOTA","message":"Org1MSP has used 9 of 10 assets"},{"code":"IDEMPOTENT_REPLAY","message":"the request was already processed by transaction tx1, whose result is returned"}]}`,
+			stdout:   "\"ok\"\n",
+			stderr:   "warning NEAR_QUOTA: Org1MSP has used 9 of 10 assets\nwarning IDEMPOTENT_REPLAY: the request was already processed by transaction tx1, whose result is returned\n",
+		},
+		{
+			name:     "a null result prints nothing",
+			response: `{"result":null}`,
+		},
+		{
+			name:     "a missing result prints nothing",
+			response: `{"warnings":[{"code":"NEAR_QUOTA","message":"Org1MSP has used 9 of 10 assets"}]}`,
+			stderr:   "warning NEAR_QUOTA: Org1MSP has used 9 of 10 assets\n",
+		},
+		{
+			name:     "a response that is not an envelope",
+			response: "true",
+			err:      "failed to parse response: json: cannot unmarshal bool into Go value of type main.response",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := printResponse(&stdout, &stderr, []byte(test.response))
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.stdout, stdout.String())
+			require.Equal(t, test.stderr, stderr.String())
+		})
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	grpcStatus, err := status.New(codes.Aborted, "failed to endorse transaction").WithDetails(
+		&gwproto.ErrorDetail{Address: "peer0.org1.example.com:7051", MspId: "Org1MSP", Message: `chaincode response 500, {"code":"NOT_FOUND","message":"the asset www.bbc.co.uk does not exist"}`},
+		&gwproto.ErrorDetail{Address: "peer0.org2.example.com:9051", MspId: "Org2MSP", Message: "chaincode response 500, the asset www.bbc.co.uk does not exist"},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		err    error
+		stderr string
+	}{
+		{
+			name: "the errors of the peers follow the error",
+			err:  grpcStatus.Err(),
+			stderr: "Error: rpc error: code = Aborted desc = failed to endorse transaction\n" +
+				"  peer0.org1.example.com:7051 (Org1MSP): chaincode response 500, {\"code\":\"NOT_FOUND\",\"message\":\"the asset www.bbc.co.uk does not exist\"}\n" +
+				"  peer0.org2.example.com:9051 (Org2MSP): chaincode response 500, the asset www.bbc.co.uk does not exist\n",
+		},
+		{
+			name:   "an error without details",
+			err:    errors.New("failed to read connection profile"),
+			stderr: "Error: failed to read connection profile\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			printError(&stderr, test.err)
+			require.Equal(t, test.stderr, stderr.String())
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command client manages the filter lists of a deployed asset-transfer-basic chaincode through
+// the Fabric Gateway, without handcrafted peer CLI invocations. The gateway peer is taken from
+// a common connection profile and transactions are signed with the identity of the -cert and
+// -key flags, which default to User1 of Org1 of the test network.
+//
+// Usage:
+//
+//	go run . [flags] create [-id ID] ALLOWLIST BLOCKLIST ATTRIBUTE2 ATTRIBUTE1 WEBFILTERLIST
+//	go run . [flags] read ID
+//	go run . [flags] update ID BLOCKLIST ATTRIBUTE2 ATTRIBUTE1 WEBFILTERLIST
+//	go run . [flags] delete ID
+//	go run . [flags] list [-page-size N] [-bookmark ID]
+//	go run . [flags] watch-events [-start-block N]
+//
+// Results are printed to standard output as indented JSON and warnings to standard error.
+// The command exits with status 1 when the transaction fails and 2 on invalid usage.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/cmd/internal/connection"
+)
+
+// errUsage reports invalid command line arguments
+var errUsage = errors.New("invalid usage")
+
+// commands maps the name of every command to its implementation
+var commands = map[string]*command{
+	"create":       createCommand,
+	"delete":       deleteCommand,
+	"list":         listCommand,
+	"read":         readCommand,
+	"update":       updateCommand,
+	"watch-events": watchEventsCommand,
+}
+
+func main() {
+	options := connection.Options{}
+	options.AddFlags(flag.CommandLine)
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %s\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	err := cmd.execute(&options, flag.Args()[1:])
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "usage: client [flags] %s %s\n", flag.Arg(0), cmd.usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		printError(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: client [flags] COMMAND [ARGS]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, name := range []string{"create", "read", "update", "delete", "list", "watch-events"} {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nflags:")
+	flag.PrintDefaults()
+}
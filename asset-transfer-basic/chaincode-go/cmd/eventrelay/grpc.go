@@ -0,0 +1,75 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	gwproto "github.com/hyperledger/fabric-protos-go/gateway"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// eventRelayServiceDesc describes the EventRelay service of relay.proto. The service reuses
+// the messages of the Fabric Gateway protos, so needs no generated code of its own.
+var eventRelayServiceDesc = grpc.ServiceDesc{
+	ServiceName: "eventrelay.EventRelay",
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       subscribeHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "relay.proto",
+}
+
+// newGRPCServer creates a gRPC server whose EventRelay service streams the events of the hub
+func newGRPCServer(h *hub) *grpc.Server {
+	server := grpc.NewServer()
+	server.RegisterService(&eventRelayServiceDesc, h)
+
+	return server
+}
+
+// subscribeHandler streams the events of the hub named by the request, or every event if the
+// request is empty, one event per response. Unlike the server-sent events endpoint the stream
+// does not replay missed events; subscribers that need them reconnect over server-sent events.
+func subscribeHandler(srv interface{}, stream grpc.ServerStream) error {
+	h := srv.(*hub)
+	request := &wrapperspb.StringValue{}
+	err := stream.RecvMsg(request)
+	if err != nil {
+		return err
+	}
+
+	s := h.subscribe(request.GetValue(), nil)
+	defer h.unsubscribe(s)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case published, ok := <-s.events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "the subscriber fell behind the event stream")
+			}
+			err = stream.SendMsg(&gwproto.ChaincodeEventsResponse{
+				BlockNumber: published.BlockNumber,
+				Events: []*peer.ChaincodeEvent{{
+					ChaincodeId: published.chaincodeName,
+					EventName:   published.EventName,
+					Payload:     published.raw,
+					TxId:        published.TransactionID,
+				}},
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
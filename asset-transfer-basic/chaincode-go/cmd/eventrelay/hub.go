@@ -0,0 +1,142 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// event is a chaincode event as the relay publishes it. Sequence numbers every event the relay
+// received since it started, so subscribers can resume after the last event they saw.
+type event struct {
+	BlockNumber   uint64          `json:"blockNumber"`
+	EventName     string          `json:"eventName"`
+	Payload       json.RawMessage `json:"payload"`
+	Sequence      uint64          `json:"sequence"`
+	TransactionID string          `json:"transactionID"`
+
+	chaincodeName string
+	raw           []byte
+}
+
+func newEvent(sequence uint64, chaincodeEvent *client.ChaincodeEvent) *event {
+	payload := json.RawMessage(chaincodeEvent.Payload)
+	if !json.Valid(payload) {
+		payload, _ = json.Marshal(string(chaincodeEvent.Payload))
+	}
+
+	return &event{
+		BlockNumber:   chaincodeEvent.BlockNumber,
+		EventName:     chaincodeEvent.EventName,
+		Payload:       payload,
+		Sequence:      sequence,
+		TransactionID: chaincodeEvent.TransactionID,
+		chaincodeName: chaincodeEvent.ChaincodeName,
+		raw:           chaincodeEvent.Payload,
+	}
+}
+
+// subscriberBuffer is the number of events a subscriber may fall behind before the hub drops it
+const subscriberBuffer = 256
+
+// subscription receives the events published after it was created. The hub closes events when
+// the subscriber falls too far behind or the hub stops.
+type subscription struct {
+	events    chan *event
+	eventName string
+}
+
+// hub fans the events of the single chaincode event stream out to every subscriber, keeping
+// the most recent events so reconnecting subscribers can replay what they missed
+type hub struct {
+	mutex         sync.Mutex
+	history       []*event
+	historySize   int
+	sequence      uint64
+	subscriptions map[*subscription]struct{}
+}
+
+func newHub(historySize int) *hub {
+	return &hub{
+		historySize:   historySize,
+		subscriptions: map[*subscription]struct{}{},
+	}
+}
+
+// publish numbers the chaincode event and delivers it to every subscriber of its name
+func (h *hub) publish(chaincodeEvent *client.ChaincodeEvent) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.sequence++
+	published := newEvent(h.sequence, chaincodeEvent)
+	if h.historySize > 0 {
+		if len(h.history) == h.historySize {
+			h.history = h.history[1:]
+		}
+		h.history = append(h.history, published)
+	}
+
+	for s := range h.subscriptions {
+		if s.eventName != "" && s.eventName != published.EventName {
+			continue
+		}
+		select {
+		case s.events <- published:
+		default:
+			// a subscriber that stops reading must not hold up the others
+			delete(h.subscriptions, s)
+			close(s.events)
+		}
+	}
+}
+
+// subscribe creates a subscription to the events named eventName, or to every event if it
+// is empty. If after is not nil, the kept events with a greater sequence number are delivered
+// first.
+func (h *hub) subscribe(eventName string, after *uint64) *subscription {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	s := &subscription{
+		events:    make(chan *event, subscriberBuffer+h.historySize),
+		eventName: eventName,
+	}
+	if after != nil {
+		for _, kept := range h.history {
+			if kept.Sequence > *after && (eventName == "" || eventName == kept.EventName) {
+				s.events <- kept
+			}
+		}
+	}
+	h.subscriptions[s] = struct{}{}
+
+	return s
+}
+
+// unsubscribe stops delivering events to the subscription
+func (h *hub) unsubscribe(s *subscription) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.subscriptions[s]; ok {
+		delete(h.subscriptions, s)
+		close(s.events)
+	}
+}
+
+// close ends every subscription
+func (h *hub) close() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for s := range h.subscriptions {
+		delete(h.subscriptions, s)
+		close(s.events)
+	}
+}
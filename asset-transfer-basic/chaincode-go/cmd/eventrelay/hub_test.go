@@ -0,0 +1,165 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/stretchr/testify/require"
+)
+
+// testEvents are chaincode events as the relay receives them, numbered 1 to 4 by the hub
+var testEvents = []*client.ChaincodeEvent{
+	{BlockNumber: 7, ChaincodeName: "basic", EventName: "AssetCreated", Payload: []byte(`{"id":"www.bbc.co.uk"}`), TransactionID: "tx1"},
+	{BlockNumber: 7, ChaincodeName: "basic", EventName: "AssetUpdated", Payload: []byte(`{"id":"www.bbc.co.uk"}`), TransactionID: "tx2"},
+	{BlockNumber: 8, ChaincodeName: "basic", EventName: "AssetCreated", Payload: []byte("not JSON"), TransactionID: "tx3"},
+	{BlockNumber: 9, ChaincodeName: "basic", EventName: "EmergencyRollback", Payload: []byte(`{"listID":"www.bbc.co.uk"}`), TransactionID: "tx4"},
+}
+
+// received returns the sequence numbers of the events delivered to s so far
+func received(s *subscription) []uint64 {
+	sequences := []uint64{}
+	for {
+		select {
+		case published := <-s.events:
+			sequences = append(sequences, published.Sequence)
+		default:
+			return sequences
+		}
+	}
+}
+
+func uint64Pointer(value uint64) *uint64 {
+	return &value
+}
+
+func TestHubFiltersEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		historySize int
+		eventName   string
+		after       *uint64
+		// published is the number of test events published before the subscription
+		published int
+		want      []uint64
+	}{
+		{
+			name: "every event published after the subscription",
+			want: []uint64{1, 2, 3, 4},
+		},
+		{
+			name:      "the events of one name",
+			eventName: "AssetCreated",
+			want:      []uint64{1, 3},
+		},
+		{
+			name:      "a name no event has",
+			eventName: "AssetDeleted",
+			want:      []uint64{},
+		},
+		{
+			name:        "kept events are not replayed without a sequence number",
+			historySize: 10,
+			published:   2,
+			want:        []uint64{3, 4},
+		},
+		{
+			name:        "kept events after the sequence number are replayed",
+			historySize: 10,
+			after:       uint64Pointer(1),
+			published:   3,
+			want:        []uint64{2, 3, 4},
+		},
+		{
+			name:        "replayed events are filtered by name",
+			historySize: 10,
+			eventName:   "AssetCreated",
+			after:       uint64Pointer(0),
+			published:   3,
+			want:        []uint64{1, 3},
+		},
+		{
+			name:        "only the most recent events are kept",
+			historySize: 1,
+			after:       uint64Pointer(0),
+			published:   3,
+			want:        []uint64{3, 4},
+		},
+		{
+			name:      "nothing is replayed without a history",
+			after:     uint64Pointer(0),
+			published: 3,
+			want:      []uint64{4},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newHub(test.historySize)
+			for _, chaincodeEvent := range testEvents[:test.published] {
+				h.publish(chaincodeEvent)
+			}
+			s := h.subscribe(test.eventName, test.after)
+			for _, chaincodeEvent := range testEvents[test.published:] {
+				h.publish(chaincodeEvent)
+			}
+			require.Equal(t, test.want, received(s))
+		})
+	}
+}
+
+func TestHubDropsSubscribersThatFallBehind(t *testing.T) {
+	h := newHub(0)
+	slow := h.subscribe("", nil)
+	other := h.subscribe("EmergencyRollback", nil)
+	for i := 0; i <= subscriberBuffer; i++ {
+		h.publish(testEvents[0])
+	}
+
+	for i := 0; i < subscriberBuffer; i++ {
+		_, ok := <-slow.events
+		require.True(t, ok)
+	}
+	_, ok := <-slow.events
+	require.False(t, ok, "the subscriber that fell behind is dropped")
+
+	h.publish(testEvents[3])
+	require.Equal(t, []uint64{uint64(subscriberBuffer + 2)}, received(other))
+}
+
+func TestNewEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{
+			name:    "a JSON payload is published as is",
+			payload: `{"id":"www.bbc.co.uk"}`,
+			want:    `{"blockNumber":7,"eventName":"AssetCreated","payload":{"id":"www.bbc.co.uk"},"sequence":5,"transactionID":"tx1"}`,
+		},
+		{
+			name:    "another payload is published as a string",
+			payload: "not JSON",
+			want:    `{"blockNumber":7,"eventName":"AssetCreated","payload":"not JSON","sequence":5,"transactionID":"tx1"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			published := newEvent(5, &client.ChaincodeEvent{
+				BlockNumber:   7,
+				ChaincodeName: "basic",
+				EventName:     "AssetCreated",
+				Payload:       []byte(test.payload),
+				TransactionID: "tx1",
+			})
+			data, err := json.Marshal(published)
+			require.NoError(t, err)
+			require.JSONEq(t, test.want, string(data))
+			require.Equal(t, []byte(test.payload), published.raw)
+		})
+	}
+}
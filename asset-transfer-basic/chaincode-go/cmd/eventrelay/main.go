@@ -0,0 +1,115 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command eventrelay listens to the chaincode events of a deployed asset-transfer-basic
+// chaincode through the Fabric Gateway and re-publishes them, so downstream DNS filters get
+// push updates of the filter lists without running Fabric clients of their own. A single
+// chaincode event stream, signed with the identity of the -cert and -key flags, is fanned out
+// to any number of subscribers over:
+//
+//   - server-sent events at GET /events on the -listen address. The event query parameter
+//     selects the events of one name, such as /events?event=AssetUpdated. Every event is sent
+//     with its sequence number as the event ID, so reconnecting clients receive the events
+//     they missed, as far as the relay keeps them (-history).
+//   - the server streaming EventRelay.Subscribe call of relay.proto on the -grpc-listen
+//     address, which sends every event as a gateway.ChaincodeEventsResponse.
+//
+// Events are published as JSON objects holding the blockNumber, eventName, payload, sequence
+// and transactionID. When the chaincode event stream fails, the relay reconnects and resumes
+// after the last event it published.
+//
+// Usage:
+//
+//	go run . [-listen :8081] [-grpc-listen :7070] [-start-block N] [flags]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/cmd/internal/connection"
+)
+
+func main() {
+	options := connection.Options{}
+	options.AddFlags(flag.CommandLine)
+	listen := flag.String("listen", ":8081", "address of the server-sent events endpoint (empty to disable)")
+	grpcListen := flag.String("grpc-listen", ":7070", "address of the gRPC EventRelay service (empty to disable)")
+	startBlock := flag.Uint64("start-block", 0, "block to relay the events from (default the next block)")
+	history := flag.Int("history", 1000, "number of recent events kept for reconnecting subscribers")
+	flag.Parse()
+
+	var start *uint64
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "start-block" {
+			start = startBlock
+		}
+	})
+
+	err := run(&options, *listen, *grpcListen, start, *history)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(options *connection.Options, listen string, grpcListen string, startBlock *uint64, history int) error {
+	if listen == "" && grpcListen == "" {
+		return errors.New("at least one of -listen and -grpc-listen is required")
+	}
+
+	gateway, clientConnection, err := options.Connect()
+	if err != nil {
+		return err
+	}
+	defer clientConnection.Close()
+	defer gateway.Close()
+
+	// events are relayed until the command is interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	h := newHub(history)
+	defer h.close()
+	errs := make(chan error, 2)
+
+	if listen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/events", eventsHandler(h))
+		httpServer := &http.Server{Addr: listen, Handler: mux}
+		go func() {
+			errs <- httpServer.ListenAndServe()
+		}()
+		defer httpServer.Close()
+		log.Printf("serving server-sent events on %s/events", listen)
+	}
+	if grpcListen != "" {
+		listener, err := net.Listen("tcp", grpcListen)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", grpcListen, err)
+		}
+		grpcServer := newGRPCServer(h)
+		go func() {
+			errs <- grpcServer.Serve(listener)
+		}()
+		defer grpcServer.Stop()
+		log.Printf("serving the EventRelay gRPC service on %s", grpcListen)
+	}
+
+	go relay(ctx, gateway.GetNetwork(options.ChannelName), options.Chaincode, h, startBlock)
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errs:
+		return err
+	}
+}
@@ -0,0 +1,63 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// reconnectDelay is how long the relay waits before reopening a chaincode event stream that
+// failed or ended
+const reconnectDelay = 5 * time.Second
+
+// relay publishes the events of the chaincode to the hub until ctx is done, from startBlock if
+// it is not nil and otherwise from the next block. A stream that ends is reopened from the
+// block of the last event it delivered, skipping the events of that block already published,
+// so subscribers see every event once.
+func relay(ctx context.Context, network *client.Network, chaincode string, h *hub, startBlock *uint64) {
+	var lastBlock uint64
+	delivered := 0
+	resuming := false
+
+	for ctx.Err() == nil {
+		var eventsOptions []client.ChaincodeEventsOption
+		if resuming {
+			eventsOptions = append(eventsOptions, client.WithStartBlock(lastBlock))
+		} else if startBlock != nil {
+			eventsOptions = append(eventsOptions, client.WithStartBlock(*startBlock))
+		}
+
+		events, err := network.ChaincodeEvents(ctx, chaincode, eventsOptions...)
+		if err != nil {
+			log.Printf("failed to start chaincode event listening: %v", err)
+		} else {
+			skip := delivered
+			for chaincodeEvent := range events {
+				if resuming && chaincodeEvent.BlockNumber == lastBlock {
+					if skip > 0 {
+						skip--
+						continue
+					}
+					delivered++
+				} else {
+					lastBlock = chaincodeEvent.BlockNumber
+					delivered = 1
+					resuming = true
+				}
+				h.publish(chaincodeEvent)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(reconnectDelay):
+			log.Printf("reconnecting the chaincode event stream of %s", chaincode)
+		}
+	}
+}
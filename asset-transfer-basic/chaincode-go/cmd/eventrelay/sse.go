@@ -0,0 +1,74 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// heartbeatInterval is how often an idle server-sent events stream receives a comment, so
+// proxies do not close it
+const heartbeatInterval = 15 * time.Second
+
+// eventsHandler streams the events of the hub as server-sent events. The optional event
+// query parameter selects the events of one name, and a Last-Event-ID header, which browsers
+// send when they reconnect, replays the kept events after that sequence number.
+func eventsHandler(h *hub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "the event stream only supports GET", http.StatusMethodNotAllowed)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+		var after *uint64
+		if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+			sequence, err := strconv.ParseUint(lastEventID, 10, 64)
+			if err != nil {
+				http.Error(w, "the Last-Event-ID header must be an event sequence number", http.StatusBadRequest)
+				return
+			}
+			after = &sequence
+		}
+
+		s := h.subscribe(r.URL.Query().Get("event"), after)
+		defer h.unsubscribe(s)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			case published, ok := <-s.events:
+				if !ok {
+					// the subscriber fell behind; the client reconnects with Last-Event-ID
+					return
+				}
+				data, err := json.Marshal(published)
+				if err != nil {
+					return
+				}
+				fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", published.Sequence, published.EventName, data)
+			}
+			flusher.Flush()
+		}
+	}
+}
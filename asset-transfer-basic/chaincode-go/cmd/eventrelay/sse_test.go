@@ -0,0 +1,95 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventsHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		lastEventID string
+		status      int
+		body        string
+	}{
+		{
+			name:   "the stream only supports GET",
+			method: http.MethodPost,
+			target: "/events",
+			status: http.StatusMethodNotAllowed,
+			body:   "the event stream only supports GET\n",
+		},
+		{
+			name:        "the Last-Event-ID header must be a sequence number",
+			method:      http.MethodGet,
+			target:      "/events",
+			lastEventID: "tx1",
+			status:      http.StatusBadRequest,
+			body:        "the Last-Event-ID header must be an event sequence number\n",
+		},
+		{
+			name:        "kept events are replayed after the Last-Event-ID",
+			method:      http.MethodGet,
+			target:      "/events",
+			lastEventID: "2",
+			status:      http.StatusOK,
+			body: "id: 3\nevent: AssetCreated\ndata: {\"blockNumber\":8,\"eventName\":\"AssetCreated\",\"payload\":\"not JSON\",\"sequence\":3,\"transactionID\":\"tx3\"}\n\n" +
+				"id: 4\nevent: EmergencyRollback\ndata: {\"blockNumber\":9,\"eventName\":\"EmergencyRollback\",\"payload\":{\"listID\":\"www.bbc.co.uk\"},\"sequence\":4,\"transactionID\":\"tx4\"}\n\n",
+		},
+		{
+			name:        "the event query parameter selects the events of one name",
+			method:      http.MethodGet,
+			target:      "/events?event=AssetCreated",
+			lastEventID: "0",
+			status:      http.StatusOK,
+			body: "id: 1\nevent: AssetCreated\ndata: {\"blockNumber\":7,\"eventName\":\"AssetCreated\",\"payload\":{\"id\":\"www.bbc.co.uk\"},\"sequence\":1,\"transactionID\":\"tx1\"}\n\n" +
+				"id: 3\nevent: AssetCreated\ndata: {\"blockNumber\":8,\"eventName\":\"AssetCreated\",\"payload\":\"not JSON\",\"sequence\":3,\"transactionID\":\"tx3\"}\n\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newHub(10)
+			for _, chaincodeEvent := range testEvents {
+				h.publish(chaincodeEvent)
+			}
+			r := httptest.NewRequest(test.method, test.target, nil)
+			if test.lastEventID != "" {
+				r.Header.Set("Last-Event-ID", test.lastEventID)
+			}
+			w := httptest.NewRecorder()
+
+			done := make(chan struct{})
+			go func() {
+				eventsHandler(h)(w, r)
+				close(done)
+			}()
+			if test.status == http.StatusOK {
+				// closing the hub ends the stream once the handler has subscribed and replayed
+				require.Eventually(t, func() bool {
+					h.mutex.Lock()
+					defer h.mutex.Unlock()
+					return len(h.subscriptions) == 1
+				}, time.Second, time.Millisecond)
+				h.close()
+			}
+			<-done
+
+			require.Equal(t, test.status, w.Code)
+			require.Equal(t, test.body, w.Body.String())
+			if test.status == http.StatusOK {
+				require.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream"))
+			}
+		})
+	}
+}
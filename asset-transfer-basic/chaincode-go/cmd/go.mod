@@ -0,0 +1,12 @@
+module github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/cmd
+
+go 1.16
+
+require (
+	github.com/golang/protobuf v1.5.2
+	github.com/hyperledger/fabric-gateway v1.0.0
+	github.com/hyperledger/fabric-protos-go v0.0.0-20211118165945-23d738fc3553
+	github.com/stretchr/testify v1.7.0
+	google.golang.org/grpc v1.42.0
+	google.golang.org/protobuf v1.27.1
+)
@@ -0,0 +1,253 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package connection connects the commands of this module to the Fabric Gateway: it finds the
+// gateway peer in a common connection profile, dials it, and signs transactions with an
+// identity whose certificate and private key are kept in files.
+package connection
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// TestNetworkOrg1 is the directory of Org1 of the test network, relative to the directory of
+// every command of this module
+const TestNetworkOrg1 = "../../../../test-network/organizations/peerOrganizations/org1.example.com"
+
+// connectionProfile holds the parts of a common connection profile, such as the
+// connection-org1.json the test network generates, that locate the gateway peer of the client
+// organization
+type connectionProfile struct {
+	Client struct {
+		Organization string `json:"organization"`
+	} `json:"client"`
+	Organizations map[string]struct {
+		MSPID string   `json:"mspid"`
+		Peers []string `json:"peers"`
+	} `json:"organizations"`
+	Peers map[string]struct {
+		URL        string `json:"url"`
+		TLSCACerts struct {
+			PEM  string `json:"pem"`
+			Path string `json:"path"`
+		} `json:"tlsCACerts"`
+		GRPCOptions struct {
+			HostnameOverride   string `json:"hostnameOverride"`
+			TargetNameOverride string `json:"ssl-target-name-override"`
+		} `json:"grpcOptions"`
+	} `json:"peers"`
+}
+
+// Peer is the gateway peer, as found in the connection profile
+type Peer struct {
+	Endpoint         string
+	HostnameOverride string
+	// MSPID is the MSP ID of the client organization of the connection profile
+	MSPID      string
+	TLSCertPEM []byte
+}
+
+// LoadPeer finds the gateway peer in the connection profile at path: the peer named peerName,
+// or else the first peer of the client organization
+func LoadPeer(path string, peerName string) (*Peer, error) {
+	profileJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read connection profile: %w", err)
+	}
+	var profile connectionProfile
+	err = json.Unmarshal(profileJSON, &profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection profile %s: %w", path, err)
+	}
+
+	organization, ok := profile.Organizations[profile.Client.Organization]
+	if !ok {
+		return nil, fmt.Errorf("the connection profile %s does not describe the client organization %q", path, profile.Client.Organization)
+	}
+	if peerName == "" {
+		if len(organization.Peers) == 0 {
+			return nil, fmt.Errorf("the connection profile %s lists no peers of %s", path, profile.Client.Organization)
+		}
+		peerName = organization.Peers[0]
+	}
+	peer, ok := profile.Peers[peerName]
+	if !ok {
+		return nil, fmt.Errorf("the connection profile %s does not describe the peer %s", path, peerName)
+	}
+
+	tlsCertPEM := []byte(peer.TLSCACerts.PEM)
+	if len(tlsCertPEM) == 0 && peer.TLSCACerts.Path != "" {
+		tlsCertPEM, err = ioutil.ReadFile(RelativeTo(path, peer.TLSCACerts.Path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS certificate of %s: %w", peerName, err)
+		}
+	}
+
+	hostnameOverride := peer.GRPCOptions.HostnameOverride
+	if hostnameOverride == "" {
+		hostnameOverride = peer.GRPCOptions.TargetNameOverride
+	}
+
+	return &Peer{
+		Endpoint:         strings.TrimPrefix(strings.TrimPrefix(peer.URL, "grpcs://"), "grpc://"),
+		HostnameOverride: hostnameOverride,
+		MSPID:            organization.MSPID,
+		TLSCertPEM:       tlsCertPEM,
+	}, nil
+}
+
+// Dial opens a gRPC connection to the peer, which the caller closes
+func (p *Peer) Dial() (*grpc.ClientConn, error) {
+	tlsCertificate, err := identity.CertificateFromPEM(p.TLSCertPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TLS certificate: %w", err)
+	}
+	certPool := x509.NewCertPool()
+	certPool.AddCert(tlsCertificate)
+	transportCredentials := credentials.NewClientTLSFromCert(certPool, p.HostnameOverride)
+	clientConnection, err := grpc.Dial(p.Endpoint, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC connection: %w", err)
+	}
+
+	return clientConnection, nil
+}
+
+// Connect opens a Gateway connection over clientConnection for the identity of mspID with the
+// certificate at certPath and the private key at keyPath. The caller closes the gateway.
+func Connect(clientConnection *grpc.ClientConn, mspID string, certPath string, keyPath string) (*client.Gateway, error) {
+	certificate, err := ReadCertificate(certPath)
+	if err != nil {
+		return nil, err
+	}
+	id, err := identity.NewX509Identity(mspID, certificate)
+	if err != nil {
+		return nil, err
+	}
+	sign, err := NewSign(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithClientConnection(clientConnection),
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+}
+
+// Options are the command line flags that select the gateway peer, the identity transactions
+// are signed with and the chaincode
+type Options struct {
+	CertPath    string
+	ChannelName string
+	Chaincode   string
+	KeyPath     string
+	MSPID       string
+	PeerName    string
+	ProfilePath string
+}
+
+// AddFlags defines the flags of the options in flags, defaulting to User1 of Org1 of the test
+// network and the basic chaincode on mychannel
+func (o *Options) AddFlags(flags *flag.FlagSet) {
+	flags.StringVar(&o.ProfilePath, "profile", TestNetworkOrg1+"/connection-org1.json", "connection profile locating the gateway peer")
+	flags.StringVar(&o.PeerName, "peer", "", "gateway peer in the connection profile (default the first peer of the client organization)")
+	flags.StringVar(&o.MSPID, "mspid", "", "MSP ID of the identity (default the client organization of the connection profile)")
+	flags.StringVar(&o.CertPath, "cert", TestNetworkOrg1+"/users/User1@org1.example.com/msp/signcerts/cert.pem", "X.509 certificate of the identity")
+	flags.StringVar(&o.KeyPath, "key", TestNetworkOrg1+"/users/User1@org1.example.com/msp/keystore", "private key of the identity, or a keystore directory holding it")
+	flags.StringVar(&o.ChannelName, "channel", "mychannel", "channel the chaincode is deployed on")
+	flags.StringVar(&o.Chaincode, "chaincode", "basic", "name of the chaincode")
+}
+
+// Connect opens a Gateway connection to the peer of the connection profile for the identity
+// of the options. The caller closes both the gateway and the gRPC connection.
+func (o *Options) Connect() (*client.Gateway, *grpc.ClientConn, error) {
+	peer, err := LoadPeer(o.ProfilePath, o.PeerName)
+	if err != nil {
+		return nil, nil, err
+	}
+	mspID := o.MSPID
+	if mspID == "" {
+		mspID = peer.MSPID
+	}
+
+	clientConnection, err := peer.Dial()
+	if err != nil {
+		return nil, nil, err
+	}
+	gateway, err := Connect(clientConnection, mspID, o.CertPath, o.KeyPath)
+	if err != nil {
+		clientConnection.Close()
+		return nil, nil, err
+	}
+
+	return gateway, clientConnection, nil
+}
+
+// ReadCertificate reads the PEM encoded X.509 certificate at certPath
+func ReadCertificate(certPath string) (*x509.Certificate, error) {
+	certificatePEM, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate file: %w", err)
+	}
+
+	return identity.CertificateFromPEM(certificatePEM)
+}
+
+// NewSign creates a function that signs message digests with the private key at keyPath, a
+// PEM file or an MSP keystore directory holding a single key
+func NewSign(keyPath string) (identity.Sign, error) {
+	info, err := os.Stat(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	if info.IsDir() {
+		files, err := ioutil.ReadDir(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key directory: %w", err)
+		}
+		if len(files) != 1 {
+			return nil, fmt.Errorf("the private key directory %s must hold exactly one key", keyPath)
+		}
+		keyPath = filepath.Join(keyPath, files[0].Name())
+	}
+
+	privateKeyPEM, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key file: %w", err)
+	}
+	privateKey, err := identity.PrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return identity.NewPrivateKeySign(privateKey)
+}
+
+// RelativeTo resolves path against the directory of the file base, unless it is absolute
+func RelativeTo(base string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(filepath.Dir(base), path)
+}
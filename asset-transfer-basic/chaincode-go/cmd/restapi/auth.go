@@ -0,0 +1,120 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/asn1"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/cmd/internal/connection"
+)
+
+// apiKeyHeader holds the API key authenticating a request, as for the TypeScript REST server
+const apiKeyHeader = "X-Api-Key"
+
+// adminAttribute is the certificate attribute the chaincode grants administrator rights to
+const adminAttribute = "webfilter.admin"
+
+// attributesOID is the certificate extension in which the Fabric CA records the attributes of
+// an identity, as a JSON object such as {"attrs":{"webfilter.admin":"true"}}
+var attributesOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// authenticator chooses the identity a request is signed with from its API key. Each key
+// selects one identity, so a caller can only act as the identity it holds the key of.
+type authenticator struct {
+	// anonymousIdentity signs requests without an API key, or is empty to reject them
+	anonymousIdentity string
+	keys              []apiKey
+}
+
+// apiKey is the API key of an identity
+type apiKey struct {
+	identity string
+	key      []byte
+}
+
+// newAuthenticator returns an authenticator for the API keys of identities. An anonymous
+// identity may not carry the admin attribute, so that administrator rights are never granted
+// to callers that did not authenticate.
+func newAuthenticator(identities map[string]*identityConfig, anonymousIdentity string) (*authenticator, error) {
+	a := &authenticator{anonymousIdentity: anonymousIdentity}
+	owners := map[string]string{}
+	for name, config := range identities {
+		if config.APIKey == "" {
+			continue
+		}
+		if owner, ok := owners[config.APIKey]; ok {
+			return nil, fmt.Errorf("the identities %s and %s have the same API key", owner, name)
+		}
+		owners[config.APIKey] = name
+		a.keys = append(a.keys, apiKey{identity: name, key: []byte(config.APIKey)})
+	}
+	sort.Slice(a.keys, func(i, j int) bool {
+		return a.keys[i].identity < a.keys[j].identity
+	})
+
+	if anonymousIdentity != "" {
+		config, ok := identities[anonymousIdentity]
+		if !ok {
+			return nil, fmt.Errorf("the anonymous identity %s is not configured", anonymousIdentity)
+		}
+		admin, err := certifiedAdmin(config.CertPath)
+		if err != nil {
+			return nil, err
+		}
+		if admin {
+			return nil, fmt.Errorf("the anonymous identity %s carries the %s attribute", anonymousIdentity, adminAttribute)
+		}
+	}
+
+	return a, nil
+}
+
+// identity returns the identity the request authenticates as, or false if it presents an
+// unknown API key, or none while anonymous requests are rejected
+func (a *authenticator) identity(r *http.Request) (string, bool) {
+	key := r.Header.Get(apiKeyHeader)
+	if key == "" {
+		return a.anonymousIdentity, a.anonymousIdentity != ""
+	}
+
+	// every key is compared, so the time taken does not reveal which one matched
+	name := ""
+	for _, candidate := range a.keys {
+		if subtle.ConstantTimeCompare(candidate.key, []byte(key)) == 1 {
+			name = candidate.identity
+		}
+	}
+
+	return name, name != ""
+}
+
+// certifiedAdmin reports whether the certificate at certPath carries the admin attribute
+func certifiedAdmin(certPath string) (bool, error) {
+	certificate, err := connection.ReadCertificate(certPath)
+	if err != nil {
+		return false, err
+	}
+
+	for _, extension := range certificate.Extensions {
+		if !extension.Id.Equal(attributesOID) {
+			continue
+		}
+		var attributes struct {
+			Attrs map[string]string `json:"attrs"`
+		}
+		err = json.Unmarshal(extension.Value, &attributes)
+		if err != nil {
+			return false, fmt.Errorf("failed to parse the attributes of %s: %w", certPath, err)
+		}
+		return attributes.Attrs[adminAttribute] == "true", nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,179 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeCertificate writes a self-signed certificate for name to a file of dir, recording the
+// attributes attrsJSON in the extension of the Fabric CA unless it is empty
+func writeCertificate(t *testing.T, dir string, name string, attrsJSON string) string {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if attrsJSON != "" {
+		template.ExtraExtensions = []pkix.Extension{{Id: attributesOID, Value: []byte(attrsJSON)}}
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, name+".pem")
+	err = ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER}), 0600)
+	require.NoError(t, err)
+
+	return certPath
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	dir := t.TempDir()
+	identities := map[string]*identityConfig{
+		"org1-admin":  {APIKey: "admin-key", CertPath: writeCertificate(t, dir, "admin", `{"attrs":{"webfilter.admin":"true"}}`)},
+		"org1-user":   {APIKey: "user-key", CertPath: writeCertificate(t, dir, "user", `{"attrs":{"hf.EnrollmentID":"user1"}}`)},
+		"org1-viewer": {CertPath: writeCertificate(t, dir, "viewer", "")},
+	}
+
+	tests := []struct {
+		name              string
+		identities        map[string]*identityConfig
+		anonymousIdentity string
+		err               string
+	}{
+		{
+			name:       "anonymous requests are rejected",
+			identities: identities,
+		},
+		{
+			name:              "an anonymous identity without the admin attribute",
+			identities:        identities,
+			anonymousIdentity: "org1-user",
+		},
+		{
+			name:              "an anonymous identity without attributes",
+			identities:        identities,
+			anonymousIdentity: "org1-viewer",
+		},
+		{
+			name:              "an anonymous identity with the admin attribute",
+			identities:        identities,
+			anonymousIdentity: "org1-admin",
+			err:               "the anonymous identity org1-admin carries the webfilter.admin attribute",
+		},
+		{
+			name:              "an anonymous identity that is not configured",
+			identities:        identities,
+			anonymousIdentity: "guest",
+			err:               "the anonymous identity guest is not configured",
+		},
+		{
+			name: "an anonymous identity with a missing certificate",
+			identities: map[string]*identityConfig{
+				"org1-user": {CertPath: filepath.Join(dir, "missing.pem")},
+			},
+			anonymousIdentity: "org1-user",
+			err:               "failed to read certificate file: open " + filepath.Join(dir, "missing.pem") + ": no such file or directory",
+		},
+		{
+			name: "identities sharing an API key",
+			identities: map[string]*identityConfig{
+				"org1-user": {APIKey: "shared-key"},
+				"org2-user": {APIKey: "shared-key"},
+			},
+			err: "have the same API key",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := newAuthenticator(test.identities, test.anonymousIdentity)
+			if test.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), test.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAuthenticatorIdentity(t *testing.T) {
+	dir := t.TempDir()
+	identities := map[string]*identityConfig{
+		"org1-admin":  {APIKey: "admin-key"},
+		"org1-user":   {APIKey: "user-key"},
+		"org1-viewer": {CertPath: writeCertificate(t, dir, "viewer", "")},
+	}
+
+	tests := []struct {
+		name              string
+		anonymousIdentity string
+		apiKey            string
+		identity          string
+		ok                bool
+	}{
+		{
+			name:     "the API key selects its identity",
+			apiKey:   "admin-key",
+			identity: "org1-admin",
+			ok:       true,
+		},
+		{
+			name:              "an API key takes precedence over the anonymous identity",
+			anonymousIdentity: "org1-viewer",
+			apiKey:            "user-key",
+			identity:          "org1-user",
+			ok:                true,
+		},
+		{
+			name:   "an unknown API key is rejected",
+			apiKey: "admin-key2",
+		},
+		{
+			name:              "an unknown API key is rejected despite an anonymous identity",
+			anonymousIdentity: "org1-viewer",
+			apiKey:            "admin",
+		},
+		{
+			name: "a request without an API key is rejected",
+		},
+		{
+			name:              "a request without an API key is signed by the anonymous identity",
+			anonymousIdentity: "org1-viewer",
+			identity:          "org1-viewer",
+			ok:                true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, err := newAuthenticator(identities, test.anonymousIdentity)
+			require.NoError(t, err)
+
+			r := httptest.NewRequest("GET", "/openapi.json", nil)
+			if test.apiKey != "" {
+				r.Header.Set(apiKeyHeader, test.apiKey)
+			}
+			identity, ok := a.identity(r)
+			require.Equal(t, test.ok, ok)
+			require.Equal(t, test.identity, identity)
+		})
+	}
+}
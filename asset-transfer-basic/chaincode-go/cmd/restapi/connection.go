@@ -0,0 +1,157 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/cmd/internal/connection"
+	"google.golang.org/grpc"
+)
+
+// queryChaincode is the system chaincode answering queries about the blocks of a channel
+const queryChaincode = "qscc"
+
+// identityConfig is an identity requests may authenticate as, as listed in the identities file
+type identityConfig struct {
+	APIKey   string `json:"apiKey"`
+	CertPath string `json:"cert"`
+	KeyPath  string `json:"key"`
+	MSPID    string `json:"mspID"`
+}
+
+// loadIdentities reads the identities file at path, a JSON object mapping the name of every
+// identity to its MSP ID, certificate file, private key file or keystore directory and the API
+// key selecting it, such as
+// {"org1-user":{"mspID":"Org1MSP","cert":"user1/cert.pem","key":"user1/keystore","apiKey":"..."}}.
+// Relative paths are resolved against the directory of the file.
+func loadIdentities(path string) (map[string]*identityConfig, error) {
+	identitiesJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identities file: %w", err)
+	}
+	var identities map[string]*identityConfig
+	err = json.Unmarshal(identitiesJSON, &identities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identities file %s: %w", path, err)
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("the identities file %s lists no identities", path)
+	}
+
+	for name, config := range identities {
+		if config == nil || config.MSPID == "" || config.CertPath == "" || config.KeyPath == "" {
+			return nil, fmt.Errorf("the identity %s of %s requires an mspID, a cert and a key", name, path)
+		}
+		config.CertPath = connection.RelativeTo(path, config.CertPath)
+		config.KeyPath = connection.RelativeTo(path, config.KeyPath)
+	}
+
+	return identities, nil
+}
+
+// gatewayPool holds a Gateway connection for every identity that has made a request, all of
+// them sharing the gRPC connection to the gateway peer
+type gatewayPool struct {
+	chaincode        string
+	channelName      string
+	clientConnection *grpc.ClientConn
+	identities       map[string]*identityConfig
+	mutex            sync.Mutex
+	gateways         map[string]*client.Gateway
+}
+
+func newGatewayPool(clientConnection *grpc.ClientConn, identities map[string]*identityConfig, channelName string, chaincode string) *gatewayPool {
+	return &gatewayPool{
+		chaincode:        chaincode,
+		channelName:      channelName,
+		clientConnection: clientConnection,
+		identities:       identities,
+		gateways:         map[string]*client.Gateway{},
+	}
+}
+
+// identityNames returns the names of the identities requests may select, sorted
+func (p *gatewayPool) identityNames() []string {
+	names := make([]string, 0, len(p.identities))
+	for name := range p.identities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// contract returns the chaincode as seen by the identity name, or errUnknownIdentity if no
+// such identity is configured
+func (p *gatewayPool) contract(name string) (transactor, error) {
+	gateway, err := p.gateway(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return gateway.GetNetwork(p.channelName).GetContract(p.chaincode), nil
+}
+
+// blockHeight returns the number of blocks of the channel, as the gateway peer reports it to
+// the identity name
+func (p *gatewayPool) blockHeight(name string) (uint64, error) {
+	gateway, err := p.gateway(name)
+	if err != nil {
+		return 0, err
+	}
+
+	chainInfoProto, err := gateway.GetNetwork(p.channelName).GetContract(queryChaincode).EvaluateTransaction("GetChainInfo", p.channelName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query chain info: %w", err)
+	}
+	var chainInfo common.BlockchainInfo
+	err = proto.Unmarshal(chainInfoProto, &chainInfo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse chain info: %w", err)
+	}
+
+	return chainInfo.Height, nil
+}
+
+// gateway returns the Gateway connection of the identity name, connecting it on first use
+func (p *gatewayPool) gateway(name string) (*client.Gateway, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	gateway, ok := p.gateways[name]
+	if !ok {
+		config, ok := p.identities[name]
+		if !ok {
+			return nil, errUnknownIdentity
+		}
+
+		var err error
+		gateway, err = connection.Connect(p.clientConnection, config.MSPID, config.CertPath, config.KeyPath)
+		if err != nil {
+			return nil, err
+		}
+		p.gateways[name] = gateway
+	}
+
+	return gateway, nil
+}
+
+// close closes the Gateway connections of every identity
+func (p *gatewayPool) close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, gateway := range p.gateways {
+		gateway.Close()
+	}
+}
@@ -0,0 +1,153 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// filterListExports maps the collections of the filter list routes to the chaincode
+// transactions rendering a filter list of one of their members
+var filterListExports = map[string]string{
+	"devices":  "ExportDeviceFilterList",
+	"policies": "ExportFilterList",
+}
+
+// filterListKey identifies a rendered filter list. Identities may be permitted to see
+// different lists, so each has its own.
+type filterListKey struct {
+	format      string
+	id          string
+	identity    string
+	transaction string
+}
+
+// renderedFilterList is a filter list as the chaincode rendered it
+type renderedFilterList struct {
+	text     []byte
+	warnings []warning
+}
+
+// warning is a warning of the result envelope of the chaincode
+type warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// filterListCache holds the filter lists rendered at the current block height. A list can only
+// change with a new block, so the cache is emptied whenever the height moves on.
+type filterListCache struct {
+	height uint64
+	lists  map[filterListKey]*renderedFilterList
+	mutex  sync.Mutex
+}
+
+func newFilterListCache() *filterListCache {
+	return &filterListCache{lists: map[filterListKey]*renderedFilterList{}}
+}
+
+// get returns the list cached under key at height, if any
+func (c *filterListCache) get(key filterListKey, height uint64) (*renderedFilterList, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if height != c.height {
+		return nil, false
+	}
+	list, ok := c.lists[key]
+
+	return list, ok
+}
+
+// put caches the list rendered at height under key, unless a later height is cached
+func (c *filterListCache) put(key filterListKey, height uint64, list *renderedFilterList) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch {
+	case height < c.height:
+		return
+	case height > c.height:
+		c.height = height
+		c.lists = map[filterListKey]*renderedFilterList{}
+	}
+	c.lists[key] = list
+}
+
+// handleFilterList serves GET /{devices|policies}/{id}/filterlist?format={format}: the
+// effective filter list of a device, or the filter list of a policy, in a format of the
+// chaincode such as squid or dnsmasq, for proxies and resolvers to reload their configuration
+// from. Lists are rendered once per block height, which is also their ETag.
+func (s *server) handleFilterList(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	transaction, ok := filterListExports[parts[0]]
+	if !ok || len(parts) != 3 || parts[1] == "" || parts[2] != "filterlist" {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("no route matches %s", r.URL.Path), nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "filter lists only support GET", nil)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "the format query parameter is required", nil)
+		return
+	}
+
+	identity, contract, ok := s.requestContract(w, r)
+	if !ok {
+		return
+	}
+	height, err := s.blockHeight(identity)
+	if err != nil {
+		log.Printf("failed to read the block height as %s: %v", identity, err)
+		writeError(w, http.StatusBadGateway, "GATEWAY_ERROR", "failed to read the block height", nil)
+		return
+	}
+
+	key := filterListKey{format: format, id: parts[1], identity: identity, transaction: transaction}
+	list, ok := s.filterLists.get(key, height)
+	if !ok {
+		response, err := contract.Evaluate(transaction, client.WithArguments(parts[1], format))
+		if err != nil {
+			httpStatus, code, message, details := transactionError(err)
+			writeError(w, httpStatus, code, message, details)
+			return
+		}
+		var envelope struct {
+			Result   string    `json:"result"`
+			Warnings []warning `json:"warnings"`
+		}
+		err = json.Unmarshal(response, &envelope)
+		if err != nil {
+			log.Printf("unexpected response of %s: %v", transaction, err)
+			writeError(w, http.StatusBadGateway, "GATEWAY_ERROR", "the chaincode returned an unexpected response", nil)
+			return
+		}
+		list = &renderedFilterList{text: []byte(envelope.Result), warnings: envelope.Warnings}
+		s.filterLists.put(key, height, list)
+	}
+
+	etag := strconv.Quote(strconv.FormatUint(height, 10))
+	w.Header().Set("ETag", etag)
+	for _, warning := range list.warnings {
+		w.Header().Add("Warning", fmt.Sprintf("199 - %s", strconv.Quote(warning.Code+": "+warning.Message)))
+	}
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(list.text)
+}
@@ -0,0 +1,86 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterListCache(t *testing.T) {
+	squid := filterListKey{format: "squid", id: "laptop1", identity: "org1-user", transaction: "ExportDeviceFilterList"}
+	dnsmasq := filterListKey{format: "dnsmasq", id: "laptop1", identity: "org1-user", transaction: "ExportDeviceFilterList"}
+	otherIdentity := filterListKey{format: "squid", id: "laptop1", identity: "org2-user", transaction: "ExportDeviceFilterList"}
+	list5 := &renderedFilterList{text: []byte("acl blocked dstdomain .example.com\n")}
+	list6 := &renderedFilterList{text: []byte("acl blocked dstdomain .example.org\n")}
+
+	// step puts list under key at height, or gets the list of key at height if list is nil
+	type step struct {
+		key    filterListKey
+		height uint64
+		list   *renderedFilterList
+		want   *renderedFilterList
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name:  "a list is not cached before it is put",
+			steps: []step{{key: squid, height: 5}},
+		},
+		{
+			name: "a list is cached at the height it was rendered at",
+			steps: []step{
+				{key: squid, height: 5, list: list5},
+				{key: squid, height: 5, want: list5},
+				{key: dnsmasq, height: 5},
+				{key: otherIdentity, height: 5},
+			},
+		},
+		{
+			name: "a list is not served at another height",
+			steps: []step{
+				{key: squid, height: 5, list: list5},
+				{key: squid, height: 6},
+				{key: squid, height: 4},
+			},
+		},
+		{
+			name: "a later height empties the cache",
+			steps: []step{
+				{key: squid, height: 5, list: list5},
+				{key: dnsmasq, height: 6, list: list6},
+				{key: squid, height: 5},
+				{key: dnsmasq, height: 6, want: list6},
+			},
+		},
+		{
+			name: "a list rendered at an earlier height is not cached",
+			steps: []step{
+				{key: squid, height: 6, list: list6},
+				{key: dnsmasq, height: 5, list: list5},
+				{key: squid, height: 6, want: list6},
+				{key: dnsmasq, height: 5},
+				{key: dnsmasq, height: 6},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cache := newFilterListCache()
+			for _, step := range test.steps {
+				if step.list != nil {
+					cache.put(step.key, step.height, step.list)
+					continue
+				}
+				list, ok := cache.get(step.key, step.height)
+				require.Equal(t, step.want != nil, ok)
+				require.Equal(t, step.want, list)
+			}
+		})
+	}
+}
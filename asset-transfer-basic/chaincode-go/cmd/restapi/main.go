@@ -0,0 +1,130 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command restapi serves the transactions of a deployed asset-transfer-basic chaincode over
+// HTTP, so web dashboards can manage filter lists without the Fabric SDK. The routes and the
+// OpenAPI document are generated from the metadata of the chaincode at startup:
+//
+//	GET  /openapi.json                               the OpenAPI 3 document of the API
+//	POST /transactions/{function}                    runs the transaction function
+//	GET  /devices/{deviceID}/filterlist?format=...   the effective filter list of a device
+//	GET  /policies/{policyID}/filterlist?format=...  the filter list of a filter policy
+//
+// A transaction request body is a JSON object holding the arguments by parameter name, such
+// as {"param0":"www.bbc.co.uk"} for ReadAsset. Transactions the metadata tags as evaluate are
+// evaluated on the gateway peer, and the others are submitted and waited on until committed.
+// The response is the result envelope of the chaincode; failed transactions respond with the
+// {"code","message","details"} error of the chaincode and a matching HTTP status.
+//
+// The filter list routes return the text ExportDeviceFilterList and ExportFilterList render,
+// in a format such as squid or dnsmasq, so that proxies and resolvers can reload their
+// configuration straight from the API:
+//
+//	curl -H 'X-Api-Key: ...' -o /etc/squid/filter.conf 'http://localhost:8080/devices/laptop1/filterlist?format=squid'
+//
+// A list is rendered once per block height and cached until the next block. The height is the
+// ETag of the response, so a client sending it back in If-None-Match gets 304 Not Modified
+// until the next block. Warnings of the chaincode are returned in Warning headers.
+//
+// Requests authenticate with the API key of an identity in the X-Api-Key header, and are signed
+// with that identity, from the JSON file of the -identities flag:
+//
+//	{"org1-admin": {"mspID": "Org1MSP", "cert": "admin/signcerts/cert.pem", "key": "admin/keystore", "apiKey": "..."}}
+//
+// Requests without a key are rejected, unless the -anonymous-identity flag names an identity to
+// sign them with. That identity may not carry the webfilter.admin attribute. The server listens
+// on the loopback interface unless -listen says otherwise.
+//
+// Usage:
+//
+//	go run . -identities identities.json [-anonymous-identity org1-user] [-listen 127.0.0.1:8080]
+//	go run . -identities identities.json -openapi openapi.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/cmd/internal/connection"
+)
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:8080", "address the HTTP server listens on")
+	profilePath := flag.String("profile", connection.TestNetworkOrg1+"/connection-org1.json", "connection profile locating the gateway peer")
+	peerName := flag.String("peer", "", "gateway peer in the connection profile (default the first peer of the client organization)")
+	identitiesPath := flag.String("identities", "identities.json", "JSON file of the identities requests may authenticate as")
+	anonymousIdentity := flag.String("anonymous-identity", "", "identity of requests without an API key (default reject them)")
+	channelName := flag.String("channel", "mychannel", "channel the chaincode is deployed on")
+	chaincode := flag.String("chaincode", "basic", "name of the chaincode")
+	openAPIPath := flag.String("openapi", "", "write the OpenAPI document to this file and exit")
+	flag.Parse()
+
+	err := run(*listen, *profilePath, *peerName, *identitiesPath, *anonymousIdentity, *channelName, *chaincode, *openAPIPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(listen, profilePath, peerName, identitiesPath, anonymousIdentity, channelName, chaincode, openAPIPath string) error {
+	identities, err := loadIdentities(identitiesPath)
+	if err != nil {
+		return err
+	}
+	authenticator, err := newAuthenticator(identities, anonymousIdentity)
+	if err != nil {
+		return err
+	}
+	peer, err := connection.LoadPeer(profilePath, peerName)
+	if err != nil {
+		return err
+	}
+	clientConnection, err := peer.Dial()
+	if err != nil {
+		return err
+	}
+	defer clientConnection.Close()
+	gateways := newGatewayPool(clientConnection, identities, channelName, chaincode)
+	defer gateways.close()
+
+	// the metadata is the same for every identity
+	metadataIdentity := gateways.identityNames()[0]
+	contract, err := gateways.contract(metadataIdentity)
+	if err != nil {
+		return fmt.Errorf("failed to connect identity %s: %w", metadataIdentity, err)
+	}
+	metadata, err := contract.Evaluate(metadataFunction)
+	if err != nil {
+		return fmt.Errorf("failed to read chaincode metadata: %w", err)
+	}
+	transactions, schemas, err := parseMetadata(metadata)
+	if err != nil {
+		return err
+	}
+	openAPI, err := json.MarshalIndent(openAPIDocument(transactions, schemas, chaincode+" chaincode"), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if openAPIPath != "" {
+		return ioutil.WriteFile(openAPIPath, append(openAPI, '\n'), 0644)
+	}
+
+	s := &server{
+		authenticator: authenticator,
+		blockHeight:   gateways.blockHeight,
+		contract:      gateways.contract,
+		filterLists:   newFilterListCache(),
+		openAPI:       openAPI,
+		transactions:  transactions,
+	}
+	log.Printf("serving %d transactions of %s on %s as %v", len(transactions), chaincode, listen, gateways.identityNames())
+
+	return http.ListenAndServe(listen, s.routes())
+}
@@ -0,0 +1,247 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// metadataFunction is the system contract transaction returning the metadata of the chaincode
+const metadataFunction = "org.hyperledger.fabric:GetMetadata"
+
+// contractMetadata holds the parts of the chaincode metadata the server routes requests and
+// generates its OpenAPI document from
+type contractMetadata struct {
+	Contracts map[string]struct {
+		Default      bool                  `json:"default"`
+		Name         string                `json:"name"`
+		Transactions []transactionMetadata `json:"transactions"`
+	} `json:"contracts"`
+	Components struct {
+		Schemas map[string]map[string]interface{} `json:"schemas"`
+	} `json:"components"`
+}
+
+// transactionMetadata describes a transaction function of the default contract
+type transactionMetadata struct {
+	Name       string `json:"name"`
+	Parameters []struct {
+		Name   string                 `json:"name"`
+		Schema map[string]interface{} `json:"schema"`
+	} `json:"parameters"`
+	Returns map[string]interface{} `json:"returns"`
+	Tag     []string               `json:"tag"`
+}
+
+// evaluate reports whether the transaction only reads the ledger, so is evaluated on the
+// gateway peer rather than submitted
+func (t *transactionMetadata) evaluate() bool {
+	for _, tag := range t.Tag {
+		if tag == "evaluate" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseMetadata parses the chaincode metadata and returns the transactions of its default
+// contract by name, along with the component schemas they refer to
+func parseMetadata(data []byte) (map[string]*transactionMetadata, map[string]map[string]interface{}, error) {
+	var metadata contractMetadata
+	err := json.Unmarshal(data, &metadata)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse chaincode metadata: %w", err)
+	}
+
+	for _, contract := range metadata.Contracts {
+		if !contract.Default {
+			continue
+		}
+		transactions := map[string]*transactionMetadata{}
+		for i := range contract.Transactions {
+			transactions[contract.Transactions[i].Name] = &contract.Transactions[i]
+		}
+		return transactions, metadata.Components.Schemas, nil
+	}
+
+	return nil, nil, fmt.Errorf("the chaincode metadata describes no default contract")
+}
+
+// openAPIDocument generates an OpenAPI 3 document describing a POST operation for every
+// transaction, whose request body holds the transaction arguments by parameter name
+func openAPIDocument(transactions map[string]*transactionMetadata, schemas map[string]map[string]interface{}, title string) map[string]interface{} {
+	names := make([]string, 0, len(transactions))
+	for name := range transactions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	paths := map[string]interface{}{}
+	for _, name := range names {
+		transaction := transactions[name]
+		properties := map[string]interface{}{}
+		required := []string{}
+		for _, parameter := range transaction.Parameters {
+			properties[parameter.Name] = openAPISchema(parameter.Schema)
+			required = append(required, parameter.Name)
+		}
+		requestSchema := map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+		if len(required) > 0 {
+			requestSchema["required"] = required
+		}
+
+		result := map[string]interface{}{"nullable": true}
+		if transaction.Returns != nil {
+			result = openAPISchema(transaction.Returns)
+		}
+		kind := "Submits"
+		tag := "submit"
+		if transaction.evaluate() {
+			kind = "Evaluates"
+			tag = "evaluate"
+		}
+
+		paths["/transactions/"+name] = map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": name,
+				"summary":     fmt.Sprintf("%s the %s transaction", kind, name),
+				"tags":        []string{tag},
+				"parameters":  []interface{}{map[string]interface{}{"$ref": "#/components/parameters/IdempotencyKey"}},
+				"requestBody": map[string]interface{}{
+					"required": len(required) > 0,
+					"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": requestSchema}},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "The transaction result and its warnings",
+						"content": map[string]interface{}{"application/json": map[string]interface{}{"schema": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"result":   result,
+								"warnings": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/Warning"}},
+							},
+						}}},
+					},
+					"default": map[string]interface{}{"$ref": "#/components/responses/Error"},
+				},
+			},
+		}
+	}
+
+	for collection, transaction := range filterListExports {
+		paths["/"+collection+"/{id}/filterlist"] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "Get" + strings.TrimPrefix(transaction, "Export"),
+				"summary":     fmt.Sprintf("Renders the filter list %s returns as text, cached per block height", transaction),
+				"tags":        []string{"filterlist"},
+				"parameters": []interface{}{
+					map[string]interface{}{"name": "id", "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"}},
+					map[string]interface{}{"name": "format", "in": "query", "required": true, "schema": map[string]interface{}{"type": "string", "enum": []string{"dnsmasq", "hosts", "rpz", "squid"}}},
+					map[string]interface{}{"name": "If-None-Match", "in": "header", "description": "ETag of a list already held", "schema": map[string]interface{}{"type": "string"}},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "The filter list, whose ETag is the block height it was rendered at",
+						"content":     map[string]interface{}{"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}},
+					},
+					"304":     map[string]interface{}{"description": "The list has not changed since the block height of If-None-Match"},
+					"default": map[string]interface{}{"$ref": "#/components/responses/Error"},
+				},
+			},
+		}
+	}
+
+	components := map[string]interface{}{}
+	for name, schema := range schemas {
+		components[name] = openAPISchema(schema)
+	}
+	components["Warning"] = map[string]interface{}{
+		"type":     "object",
+		"required": []string{"code", "message"},
+		"properties": map[string]interface{}{
+			"code":    map[string]interface{}{"type": "string"},
+			"message": map[string]interface{}{"type": "string"},
+		},
+	}
+	components["Error"] = map[string]interface{}{
+		"type":     "object",
+		"required": []string{"code", "message"},
+		"properties": map[string]interface{}{
+			"code":    map[string]interface{}{"type": "string"},
+			"message": map[string]interface{}{"type": "string"},
+			"details": map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+		},
+	}
+
+	return map[string]interface{}{
+		"openapi":  "3.0.3",
+		"info":     map[string]interface{}{"title": title, "version": "1.0.0"},
+		"paths":    paths,
+		"security": []interface{}{map[string]interface{}{"ApiKey": []string{}}},
+		"components": map[string]interface{}{
+			"schemas": components,
+			"securitySchemes": map[string]interface{}{
+				"ApiKey": map[string]interface{}{"type": "apiKey", "in": "header", "name": apiKeyHeader},
+			},
+			"parameters": map[string]interface{}{
+				"IdempotencyKey": map[string]interface{}{
+					"name":        idempotencyKeyHeader,
+					"in":          "header",
+					"description": "Key making a submitted transaction safe to retry",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+			},
+			"responses": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"description": "The transaction failed",
+					"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"}}},
+				},
+			},
+		},
+	}
+}
+
+// openAPISchema converts a JSON schema of the chaincode metadata to an OpenAPI schema object:
+// references to component schemas are rewritten to their OpenAPI location and the keywords
+// OpenAPI does not support are dropped
+func openAPISchema(schema map[string]interface{}) map[string]interface{} {
+	converted := map[string]interface{}{}
+	for keyword, value := range schema {
+		switch keyword {
+		case "$id", "$schema":
+			continue
+		case "$ref":
+			if ref, ok := value.(string); ok && !strings.HasPrefix(ref, "#/") {
+				value = "#/components/schemas/" + ref
+			}
+		}
+		converted[keyword] = convertSchemaValue(value)
+	}
+
+	return converted
+}
+
+func convertSchemaValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return openAPISchema(value)
+	case []interface{}:
+		converted := make([]interface{}, len(value))
+		for i, item := range value {
+			converted[i] = convertSchemaValue(item)
+		}
+		return converted
+	default:
+		return value
+	}
+}
@@ -0,0 +1,229 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testMetadata is chaincode metadata as the contract API generates it, reduced to two
+// transactions of the default contract and the schema one of them returns
+const testMetadata = `{
+	"contracts": {
+		"org.hyperledger.fabric": {"name": "org.hyperledger.fabric", "transactions": [{"name": "GetMetadata", "tag": ["evaluate"]}]},
+		"SmartContract": {
+			"default": true,
+			"name": "SmartContract",
+			"transactions": [
+				{"name": "InitLedger", "tag": ["submit"]},
+				{
+					"name": "ReadAsset",
+					"parameters": [{"name": "param0", "schema": {"type": "string"}}],
+					"returns": {"$ref": "#/components/schemas/Asset"},
+					"tag": ["evaluate"]
+				}
+			]
+		}
+	},
+	"components": {
+		"schemas": {
+			"Asset": {
+				"$id": "Asset",
+				"type": "object",
+				"properties": {"owner": {"type": "string"}, "history": {"type": "array", "items": {"$ref": "HistoryEntry"}}},
+				"required": ["owner"]
+			}
+		}
+	}
+}`
+
+func TestParseMetadata(t *testing.T) {
+	transactions, schemas, err := parseMetadata([]byte(testMetadata))
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	require.False(t, transactions["InitLedger"].evaluate())
+	require.True(t, transactions["ReadAsset"].evaluate())
+	require.Equal(t, "param0", transactions["ReadAsset"].Parameters[0].Name)
+	require.Contains(t, schemas, "Asset")
+
+	tests := []struct {
+		name     string
+		metadata string
+		err      string
+	}{
+		{
+			name:     "metadata that is not JSON",
+			metadata: "GetMetadata",
+			err:      "failed to parse chaincode metadata: invalid character 'G' looking for beginning of value",
+		},
+		{
+			name:     "metadata without a default contract",
+			metadata: `{"contracts": {"org.hyperledger.fabric": {"name": "org.hyperledger.fabric"}}}`,
+			err:      "the chaincode metadata describes no default contract",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := parseMetadata([]byte(test.metadata))
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestOpenAPISchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{
+			name:   "references to components are rewritten",
+			schema: `{"$ref": "Asset"}`,
+			want:   `{"$ref": "#/components/schemas/Asset"}`,
+		},
+		{
+			name:   "local references are kept",
+			schema: `{"$ref": "#/components/schemas/Asset"}`,
+			want:   `{"$ref": "#/components/schemas/Asset"}`,
+		},
+		{
+			name:   "unsupported keywords are dropped",
+			schema: `{"$id": "Asset", "$schema": "http://json-schema.org/draft-04/schema#", "type": "object"}`,
+			want:   `{"type": "object"}`,
+		},
+		{
+			name:   "nested schemas are converted",
+			schema: `{"type": "object", "properties": {"history": {"type": "array", "items": {"$ref": "HistoryEntry"}}}, "anyOf": [{"$ref": "Asset"}, {"type": "null"}]}`,
+			want:   `{"type": "object", "properties": {"history": {"type": "array", "items": {"$ref": "#/components/schemas/HistoryEntry"}}}, "anyOf": [{"$ref": "#/components/schemas/Asset"}, {"type": "null"}]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var schema map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(test.schema), &schema))
+			converted, err := json.Marshal(openAPISchema(schema))
+			require.NoError(t, err)
+			require.JSONEq(t, test.want, string(converted))
+		})
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	transactions, schemas, err := parseMetadata([]byte(testMetadata))
+	require.NoError(t, err)
+	documentJSON, err := json.Marshal(openAPIDocument(transactions, schemas, "basic chaincode"))
+	require.NoError(t, err)
+
+	type operation struct {
+		OperationID string   `json:"operationId"`
+		Summary     string   `json:"summary"`
+		Tags        []string `json:"tags"`
+		RequestBody struct {
+			Required bool `json:"required"`
+			Content  map[string]struct {
+				Schema struct {
+					Properties map[string]json.RawMessage `json:"properties"`
+					Required   []string                   `json:"required"`
+				} `json:"schema"`
+			} `json:"content"`
+		} `json:"requestBody"`
+		Responses map[string]struct {
+			Content map[string]struct {
+				Schema struct {
+					Properties map[string]json.RawMessage `json:"properties"`
+				} `json:"schema"`
+			} `json:"content"`
+		} `json:"responses"`
+	}
+	var document struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title string `json:"title"`
+		} `json:"info"`
+		Paths map[string]struct {
+			Get  *operation `json:"get"`
+			Post *operation `json:"post"`
+		} `json:"paths"`
+		Components struct {
+			Schemas         map[string]json.RawMessage `json:"schemas"`
+			SecuritySchemes map[string]json.RawMessage `json:"securitySchemes"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(documentJSON, &document))
+
+	require.Equal(t, "3.0.3", document.OpenAPI)
+	require.Equal(t, "basic chaincode", document.Info.Title)
+	require.Len(t, document.Paths, 4)
+	require.JSONEq(t, `{"type": "apiKey", "in": "header", "name": "X-Api-Key"}`, string(document.Components.SecuritySchemes["ApiKey"]))
+	require.JSONEq(t, `{"type": "object", "properties": {"owner": {"type": "string"}, "history": {"type": "array", "items": {"$ref": "#/components/schemas/HistoryEntry"}}}, "required": ["owner"]}`, string(document.Components.Schemas["Asset"]))
+	require.Contains(t, document.Components.Schemas, "Error")
+	require.Contains(t, document.Components.Schemas, "Warning")
+
+	tests := []struct {
+		path            string
+		operationID     string
+		summary         string
+		tags            []string
+		bodyRequired    bool
+		bodyProperties  []string
+		requiredInBody  []string
+		resultReference string
+	}{
+		{
+			path:         "/transactions/InitLedger",
+			operationID:  "InitLedger",
+			summary:      "Submits the InitLedger transaction",
+			tags:         []string{"submit"},
+			bodyRequired: false,
+		},
+		{
+			path:            "/transactions/ReadAsset",
+			operationID:     "ReadAsset",
+			summary:         "Evaluates the ReadAsset transaction",
+			tags:            []string{"evaluate"},
+			bodyRequired:    true,
+			bodyProperties:  []string{"param0"},
+			requiredInBody:  []string{"param0"},
+			resultReference: `{"$ref": "#/components/schemas/Asset"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			path, ok := document.Paths[test.path]
+			require.True(t, ok)
+			require.Nil(t, path.Get)
+			post := path.Post
+			require.NotNil(t, post)
+			require.Equal(t, test.operationID, post.OperationID)
+			require.Equal(t, test.summary, post.Summary)
+			require.Equal(t, test.tags, post.Tags)
+			require.Equal(t, test.bodyRequired, post.RequestBody.Required)
+
+			body := post.RequestBody.Content["application/json"].Schema
+			properties := []string{}
+			for name := range body.Properties {
+				properties = append(properties, name)
+			}
+			require.ElementsMatch(t, test.bodyProperties, properties)
+			require.Equal(t, test.requiredInBody, body.Required)
+
+			result := post.Responses["200"].Content["application/json"].Schema.Properties["result"]
+			if test.resultReference == "" {
+				require.JSONEq(t, `{"nullable": true}`, string(result))
+			} else {
+				require.JSONEq(t, test.resultReference, string(result))
+			}
+		})
+	}
+
+	for _, path := range []string{"/devices/{id}/filterlist", "/policies/{id}/filterlist"} {
+		require.NotNil(t, document.Paths[path].Get, path)
+		require.Equal(t, []string{"filterlist"}, document.Paths[path].Get.Tags)
+	}
+	require.Equal(t, "GetDeviceFilterList", document.Paths["/devices/{id}/filterlist"].Get.OperationID)
+}
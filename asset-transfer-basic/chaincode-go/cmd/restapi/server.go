@@ -0,0 +1,245 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	gwproto "github.com/hyperledger/fabric-protos-go/gateway"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// idempotencyKeyHeader holds the idempotency key of a submitted transaction, passed to
+	// the chaincode in the idempotencyKey transient field
+	idempotencyKeyHeader = "Idempotency-Key"
+	idempotencyTransient = "idempotencyKey"
+	// maxRequestBytes bounds the request bodies the server reads
+	maxRequestBytes = 4 << 20
+)
+
+// errUnknownIdentity reports an identity that is not configured
+var errUnknownIdentity = errors.New("unknown identity")
+
+// transactor runs transactions against the chaincode, as a client.Contract does
+type transactor interface {
+	Evaluate(transactionName string, options ...client.ProposalOption) ([]byte, error)
+	Submit(transactionName string, options ...client.ProposalOption) ([]byte, error)
+}
+
+// server exposes every transaction of the default contract as POST /transactions/{name},
+// with the arguments in a JSON object keyed by parameter name, and the filter lists of
+// devices and policies as text
+type server struct {
+	authenticator *authenticator
+	blockHeight   func(identity string) (uint64, error)
+	contract      func(identity string) (transactor, error)
+	filterLists   *filterListCache
+	openAPI       []byte
+	transactions  map[string]*transactionMetadata
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/devices/", s.handleFilterList)
+	mux.HandleFunc("/openapi.json", s.handleOpenAPI)
+	mux.HandleFunc("/policies/", s.handleFilterList)
+	mux.HandleFunc("/transactions/", s.handleTransaction)
+
+	return mux
+}
+
+func (s *server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "the OpenAPI document only supports GET", nil)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(s.openAPI)
+}
+
+func (s *server) handleTransaction(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/transactions/")
+	transaction, ok := s.transactions[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "UNKNOWN_TRANSACTION", fmt.Sprintf("the chaincode has no transaction %s", name), nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "transactions only support POST", nil)
+		return
+	}
+
+	args, err := transactionArguments(transaction, http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	_, contract, ok := s.requestContract(w, r)
+	if !ok {
+		return
+	}
+
+	options := []client.ProposalOption{client.WithArguments(args...)}
+	var response []byte
+	if transaction.evaluate() {
+		response, err = contract.Evaluate(name, options...)
+	} else {
+		if key := r.Header.Get(idempotencyKeyHeader); key != "" {
+			options = append(options, client.WithTransient(map[string][]byte{idempotencyTransient: []byte(key)}))
+		}
+		response, err = contract.Submit(name, options...)
+	}
+	if err != nil {
+		httpStatus, code, message, details := transactionError(err)
+		writeError(w, httpStatus, code, message, details)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
+
+// requestContract returns the identity the request authenticates as and the chaincode as seen
+// by it, or writes the error response and returns false
+func (s *server) requestContract(w http.ResponseWriter, r *http.Request) (string, transactor, bool) {
+	identity, ok := s.authenticator.identity(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", apiKeyHeader)
+		writeError(w, http.StatusUnauthorized, "UNAUTHENTICATED", fmt.Sprintf("the request requires a valid %s header", apiKeyHeader), nil)
+		return "", nil, false
+	}
+	contract, err := s.contract(identity)
+	if err != nil {
+		log.Printf("failed to connect identity %s: %v", identity, err)
+		writeError(w, http.StatusInternalServerError, "GATEWAY_ERROR", "failed to connect to the gateway", nil)
+		return "", nil, false
+	}
+
+	return identity, contract, true
+}
+
+// transactionArguments reads the JSON object of body and returns its values in the order of
+// the transaction parameters. Strings are passed as is and other values as JSON, as the
+// contract API expects.
+func transactionArguments(transaction *transactionMetadata, body io.Reader) ([]string, error) {
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %v", err)
+	}
+	values := map[string]json.RawMessage{}
+	if len(strings.TrimSpace(string(data))) > 0 {
+		err = json.Unmarshal(data, &values)
+		if err != nil {
+			return nil, fmt.Errorf("the request body must be a JSON object: %v", err)
+		}
+	}
+
+	args := make([]string, 0, len(transaction.Parameters))
+	for _, parameter := range transaction.Parameters {
+		value, ok := values[parameter.Name]
+		if !ok {
+			return nil, fmt.Errorf("the request body requires %s", parameter.Name)
+		}
+		delete(values, parameter.Name)
+
+		var text string
+		if json.Unmarshal(value, &text) != nil {
+			text = string(value)
+		}
+		args = append(args, text)
+	}
+	for name := range values {
+		return nil, fmt.Errorf("the transaction %s has no parameter %s", transaction.Name, name)
+	}
+
+	return args, nil
+}
+
+// errorBody is the body of every error response
+type errorBody struct {
+	Code    string            `json:"code"`
+	Details map[string]string `json:"details,omitempty"`
+	Message string            `json:"message"`
+}
+
+// errorStatuses maps the error codes of the chaincode to HTTP statuses
+var errorStatuses = map[string]int{
+	"ALREADY_EXISTS":      http.StatusConflict,
+	"ASSET_EXISTS":        http.StatusConflict,
+	"ASSET_NOT_FOUND":     http.StatusNotFound,
+	"IDEMPOTENT_REPLAY":   http.StatusConflict,
+	"MAINTENANCE_MODE":    http.StatusServiceUnavailable,
+	"NOT_FOUND":           http.StatusNotFound,
+	"PAYLOAD_TOO_LARGE":   http.StatusRequestEntityTooLarge,
+	"QUERY_TOO_EXPENSIVE": http.StatusUnprocessableEntity,
+	"QUOTA_EXCEEDED":      http.StatusForbidden,
+	"UNAUTHORIZED":        http.StatusForbidden,
+	"VALIDATION":          http.StatusBadRequest,
+}
+
+var chaincodeResponsePrefix = regexp.MustCompile(`^chaincode response \d+, `)
+
+// transactionError maps an error of the gateway to an HTTP status and error body. Errors the
+// chaincode returned keep their code, message and details.
+func transactionError(err error) (int, string, string, map[string]string) {
+	var commitErr *client.CommitError
+	if errors.As(err, &commitErr) {
+		return http.StatusConflict, "COMMIT_FAILED", commitErr.Error(), nil
+	}
+
+	message, ok := chaincodeMessage(err)
+	if !ok {
+		log.Printf("gateway error: %v", err)
+		return http.StatusBadGateway, "GATEWAY_ERROR", status.Convert(err).Message(), nil
+	}
+
+	var coded errorBody
+	if json.Unmarshal([]byte(message), &coded) == nil && coded.Code != "" {
+		if httpStatus, ok := errorStatuses[coded.Code]; ok {
+			return httpStatus, coded.Code, coded.Message, coded.Details
+		}
+		return http.StatusUnprocessableEntity, coded.Code, coded.Message, coded.Details
+	}
+
+	return http.StatusUnprocessableEntity, "TRANSACTION_FAILED", message, nil
+}
+
+// chaincodeMessage returns the error message the chaincode returned, as reported in the
+// details of the gateway error or else in its message
+func chaincodeMessage(err error) (string, bool) {
+	grpcStatus := status.Convert(err)
+	for _, detail := range grpcStatus.Details() {
+		if errDetail, ok := detail.(*gwproto.ErrorDetail); ok {
+			if match := chaincodeResponsePrefix.FindString(errDetail.Message); match != "" {
+				return strings.TrimPrefix(errDetail.Message, match), true
+			}
+		}
+	}
+
+	message := grpcStatus.Message()
+	if index := strings.Index(message, "chaincode response "); index >= 0 {
+		if match := chaincodeResponsePrefix.FindString(message[index:]); match != "" {
+			return message[index+len(match):], true
+		}
+	}
+
+	return "", false
+}
+
+func writeError(w http.ResponseWriter, httpStatus int, code string, message string, details map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(errorBody{Code: code, Details: details, Message: message})
+}
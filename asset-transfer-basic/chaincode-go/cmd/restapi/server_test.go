@@ -0,0 +1,198 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	gwproto "github.com/hyperledger/fabric-protos-go/gateway"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTransactionArguments(t *testing.T) {
+	var transaction transactionMetadata
+	err := json.Unmarshal([]byte(`{"name":"UpdateAsset","parameters":[{"name":"id"},{"name":"size"},{"name":"tags"}]}`), &transaction)
+	require.NoError(t, err)
+	var noParameters transactionMetadata
+	err = json.Unmarshal([]byte(`{"name":"InitLedger"}`), &noParameters)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		transaction *transactionMetadata
+		body        string
+		args        []string
+		err         string
+	}{
+		{
+			name:        "strings are passed as is and other values as JSON",
+			transaction: &transaction,
+			body:        `{"tags":["ads","trackers"],"id":"www.bbc.co.uk","size":3}`,
+			args:        []string{"www.bbc.co.uk", "3", `["ads","trackers"]`},
+		},
+		{
+			name:        "an empty body has no arguments",
+			transaction: &noParameters,
+			body:        " \n",
+			args:        []string{},
+		},
+		{
+			name:        "a missing parameter",
+			transaction: &transaction,
+			body:        `{"id":"www.bbc.co.uk","tags":[]}`,
+			err:         "the request body requires size",
+		},
+		{
+			name:        "an unknown parameter",
+			transaction: &noParameters,
+			body:        `{"colour":"blue"}`,
+			err:         "the transaction InitLedger has no parameter colour",
+		},
+		{
+			name:        "a body that is not JSON",
+			transaction: &transaction,
+			body:        `{"id":`,
+			err:         "the request body must be a JSON object: unexpected end of JSON input",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args, err := transactionArguments(test.transaction, strings.NewReader(test.body))
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.args, args)
+		})
+	}
+}
+
+// endorseError returns the error the gateway reports when the chaincode of a peer returns message
+func endorseError(t *testing.T, message string) error {
+	grpcStatus, err := status.New(codes.Aborted, "failed to endorse transaction, see attached details for more info").WithDetails(&gwproto.ErrorDetail{
+		Address: "peer0.org1.example.com:7051",
+		MspId:   "Org1MSP",
+		Message: "chaincode response 500, " + message,
+	})
+	require.NoError(t, err)
+
+	return grpcStatus.Err()
+}
+
+func TestTransactionError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		httpStatus int
+		code       string
+		message    string
+		details    map[string]string
+	}{
+		{
+			name:       "a coded error of the chaincode keeps its code, message and details",
+			err:        endorseError(t, `{"code":"NOT_FOUND","message":"the job job-tx1-1 does not exist","details":{"jobID":"job-tx1-1"}}`),
+			httpStatus: http.StatusNotFound,
+			code:       "NOT_FOUND",
+			message:    "the job job-tx1-1 does not exist",
+			details:    map[string]string{"jobID": "job-tx1-1"},
+		},
+		{
+			name:       "a quota error is forbidden",
+			err:        endorseError(t, `{"code":"QUOTA_EXCEEDED","message":"the quota of Org1MSP allows 1 assets","details":{"mspID":"Org1MSP"}}`),
+			httpStatus: http.StatusForbidden,
+			code:       "QUOTA_EXCEEDED",
+			message:    "the quota of Org1MSP allows 1 assets",
+			details:    map[string]string{"mspID": "Org1MSP"},
+		},
+		{
+			name:       "an unknown code is kept",
+			err:        endorseError(t, `{"code":"OFFBOARDED","message":"the organization Org3MSP has been offboarded"}`),
+			httpStatus: http.StatusUnprocessableEntity,
+			code:       "OFFBOARDED",
+			message:    "the organization Org3MSP has been offboarded",
+		},
+		{
+			name:       "an uncoded error of the chaincode",
+			err:        endorseError(t, "the asset www.bbc.co.uk does not exist"),
+			httpStatus: http.StatusUnprocessableEntity,
+			code:       "TRANSACTION_FAILED",
+			message:    "the asset www.bbc.co.uk does not exist",
+		},
+		{
+			name:       "an error of the chaincode in the message of the gateway",
+			err:        status.Error(codes.Unknown, "evaluate call to endorser returned error: chaincode response 500, the asset www.bbc.co.uk does not exist"),
+			httpStatus: http.StatusUnprocessableEntity,
+			code:       "TRANSACTION_FAILED",
+			message:    "the asset www.bbc.co.uk does not exist",
+		},
+		{
+			name:       "an error of the gateway",
+			err:        status.Error(codes.Unavailable, "connection refused"),
+			httpStatus: http.StatusBadGateway,
+			code:       "GATEWAY_ERROR",
+			message:    "connection refused",
+		},
+		{
+			name:       "an error unrelated to gRPC",
+			err:        errors.New("context deadline exceeded"),
+			httpStatus: http.StatusBadGateway,
+			code:       "GATEWAY_ERROR",
+			message:    "context deadline exceeded",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			httpStatus, code, message, details := transactionError(test.err)
+			require.Equal(t, test.httpStatus, httpStatus)
+			require.Equal(t, test.code, code)
+			require.Equal(t, test.message, message)
+			require.Equal(t, test.details, details)
+		})
+	}
+}
+
+func TestChaincodeMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		message string
+		ok      bool
+	}{
+		{
+			name:    "the message of the error detail",
+			err:     endorseError(t, `{"code":"VALIDATION","message":"a version is required"}`),
+			message: `{"code":"VALIDATION","message":"a version is required"}`,
+			ok:      true,
+		},
+		{
+			name:    "the message of the status",
+			err:     status.Error(codes.Unknown, "evaluate call to endorser returned error: chaincode response 500, a version is required"),
+			message: "a version is required",
+			ok:      true,
+		},
+		{
+			name: "a status without a chaincode response",
+			err:  status.Error(codes.Unavailable, "connection refused"),
+		},
+		{
+			name: "a chaincode response without a status code",
+			err:  status.Error(codes.Unknown, "chaincode response was malformed"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message, ok := chaincodeMessage(test.err)
+			require.Equal(t, test.ok, ok)
+			require.Equal(t, test.message, message)
+		})
+	}
+}
@@ -8,4 +8,5 @@ require (
 	github.com/hyperledger/fabric-contract-api-go v1.1.0
 	github.com/hyperledger/fabric-protos-go v0.0.0-20200424173316-dd554ba3746e
 	github.com/stretchr/testify v1.5.1
+	golang.org/x/net v0.0.0-20190827160401-ba9fcec4b297
 )
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+)
+
+// startTime is the timestamp of the first transaction of every scenario. Each following
+// transaction is one minute later.
+var startTime = time.Date(2021, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+// generate runs every scenario on a fresh ledger and records the vectors of its steps
+func generate(cc shim.Chaincode) (*Vectors, error) {
+	vectors := &Vectors{ContractSchemaVersion: chaincode.SchemaVersion, Version: vectorsVersion}
+
+	clients := map[string]*identity{}
+	for _, client := range clientIdentities {
+		id, err := newIdentity(client.name, client.mspID, client.commonName, client.admin)
+		if err != nil {
+			return nil, err
+		}
+		clients[client.name] = id
+		vectors.Identities = append(vectors.Identities, id)
+	}
+
+	for _, s := range scenarios {
+		scenarioVector, err := runScenario(cc, clients, s)
+		if err != nil {
+			return nil, fmt.Errorf("scenario %s: %v", s.name, err)
+		}
+		vectors.Scenarios = append(vectors.Scenarios, scenarioVector)
+	}
+
+	return vectors, nil
+}
+
+func runScenario(cc shim.Chaincode, clients map[string]*identity, s scenario) (*ScenarioVector, error) {
+	l := newLedger()
+	evaluate := (&chaincode.SmartContract{}).GetEvaluateTransactions()
+	scenarioVector := &ScenarioVector{Description: s.description, Name: s.name, Steps: []*StepVector{}}
+
+	for i, st := range s.steps {
+		client, ok := clients[st.client]
+		if !ok {
+			return nil, fmt.Errorf("step %d: unknown client %s", i, st.client)
+		}
+
+		args := []string{st.function}
+		for _, arg := range st.args {
+			rendered, err := renderArg(arg, clients, l, scenarioVector.Steps)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: %v", i, err)
+			}
+			args = append(args, rendered)
+		}
+		var transient map[string][]byte
+		for name, value := range st.transient {
+			if transient == nil {
+				transient = map[string][]byte{}
+			}
+			transient[name] = []byte(value)
+		}
+
+		digest := sha256.Sum256([]byte(fmt.Sprintf("%s/%d", s.name, i)))
+		now := startTime.Add(time.Duration(i) * time.Minute)
+		stub, err := newTxStub(l, args, client.Creator, transient, hex.EncodeToString(digest[:]), now)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %v", i, err)
+		}
+
+		// every step is endorsed by a peer of the client's own organization
+		os.Setenv("CORE_PEER_LOCALMSPID", client.MSPID)
+		response := cc.Invoke(stub)
+		stepVector := &StepVector{
+			Args:      args[1:],
+			Client:    client.Name,
+			Evaluate:  stringInSlice(st.function, evaluate),
+			Function:  st.function,
+			Response:  ResponseVector{Message: response.Message, Payload: response.Payload, Status: response.Status},
+			Timestamp: now,
+			Transient: transient,
+			TxID:      stub.txID,
+			Writes:    []*WriteVector{},
+		}
+		if stub.event != nil {
+			stepVector.Event = &EventVector{Name: stub.event.EventName, Payload: stub.event.Payload}
+		}
+		for _, key := range sortedKeys(stub.writes) {
+			value := stub.writes[key]
+			stepVector.Writes = append(stepVector.Writes, &WriteVector{Delete: value == nil, Key: key, Value: value})
+		}
+		scenarioVector.Steps = append(scenarioVector.Steps, stepVector)
+
+		if response.Status < shim.ERRORTHRESHOLD && !stepVector.Evaluate {
+			l.commit(stub)
+		}
+	}
+
+	scenarioVector.State = []*WriteVector{}
+	for _, key := range sortedKeys(l.state) {
+		scenarioVector.State = append(scenarioVector.State, &WriteVector{Key: key, Value: l.state[key]})
+	}
+
+	return scenarioVector, nil
+}
+
+// renderArg expands the templates in a scenario argument. They may refer to the ID of a
+// client, to the result or transaction ID of an earlier step of the scenario, and to the hex
+// encoded SHA-256 digest of the committed document of an asset.
+func renderArg(arg string, clients map[string]*identity, l *ledger, steps []*StepVector) (string, error) {
+	if !strings.Contains(arg, "{{") {
+		return arg, nil
+	}
+
+	step := func(i int) (*StepVector, error) {
+		if i < 0 || i >= len(steps) {
+			return nil, fmt.Errorf("no step %d precedes this one", i)
+		}
+		return steps[i], nil
+	}
+	funcs := template.FuncMap{
+		"id": func(name string) (string, error) {
+			client, ok := clients[name]
+			if !ok {
+				return "", fmt.Errorf("unknown client %s", name)
+			}
+			return client.ID, nil
+		},
+		"result": func(i int) (string, error) {
+			earlier, err := step(i)
+			if err != nil {
+				return "", err
+			}
+			if earlier.Response.Status >= shim.ERRORTHRESHOLD {
+				return "", fmt.Errorf("step %d has no result, it failed with %s", i, earlier.Response.Message)
+			}
+			var envelope chaincode.Response
+			err = json.Unmarshal(earlier.Response.Payload, &envelope)
+			if err != nil {
+				return "", fmt.Errorf("the response of step %d is not an envelope: %v", i, err)
+			}
+			var result string
+			if json.Unmarshal(envelope.Result, &result) == nil {
+				return result, nil
+			}
+			return string(envelope.Result), nil
+		},
+		"txid": func(i int) (string, error) {
+			earlier, err := step(i)
+			if err != nil {
+				return "", err
+			}
+			return earlier.TxID, nil
+		},
+		"digest": func(id string) (string, error) {
+			key, err := (&txStub{}).CreateCompositeKey("asset", []string{id})
+			if err != nil {
+				return "", err
+			}
+			digest := sha256.Sum256(l.state[key])
+			return hex.EncodeToString(digest[:]), nil
+		},
+	}
+
+	tmpl, err := template.New("arg").Funcs(funcs).Parse(arg)
+	if err != nil {
+		return "", err
+	}
+	var rendered bytes.Buffer
+	err = tmpl.Execute(&rendered, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return rendered.String(), nil
+}
+
+func stringInSlice(s string, list []string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/attrmgr"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// identity is a client submitting transactions in the vectors. Its certificate is derived
+// from its name alone, so that the creator bytes and client ID are the same on every run.
+type identity struct {
+	Name    string `json:"name"`
+	MSPID   string `json:"mspID"`
+	ID      string `json:"id"`
+	Admin   bool   `json:"admin"`
+	Creator []byte `json:"creator"`
+}
+
+// certificateValidity is the period every generated certificate is valid for
+var certificateValidity = [2]time.Time{
+	time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// newIdentity issues a certificate for commonName from the CA of mspID. Administrators carry
+// the webfilter.admin attribute the contract checks.
+func newIdentity(name string, mspID string, commonName string, admin bool) (*identity, error) {
+	caKey := deterministicKey("ca." + mspID)
+	ca := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ca." + mspID, Organization: []string{mspID}},
+		NotBefore:    certificateValidity[0],
+		NotAfter:     certificateValidity[1],
+		PublicKey:    caKey.Public(),
+	}
+
+	key := deterministicKey(mspID + "/" + commonName)
+	serial := sha256.Sum256([]byte(mspID + "/" + commonName))
+	template := &x509.Certificate{
+		SerialNumber: new(big.Int).SetBytes(serial[:8]),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{mspID}, OrganizationalUnit: []string{"client"}},
+		NotBefore:    certificateValidity[0],
+		NotAfter:     certificateValidity[1],
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if admin {
+		err := attrmgr.New().AddAttributesToCert(&attrmgr.Attributes{Attrs: map[string]string{"webfilter.admin": "true"}}, template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add attributes to the certificate of %s: %v", name, err)
+		}
+		// the attribute manager adds the extension for parsed certificates, only extra
+		// extensions are written to new ones
+		template.ExtraExtensions, template.Extensions = template.Extensions, nil
+	}
+
+	der, err := x509.CreateCertificate(nil, template, ca, key.Public(), caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the certificate of %s: %v", name, err)
+	}
+	creator, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the identity of %s: %v", name, err)
+	}
+
+	client := &identity{Name: name, MSPID: mspID, Admin: admin, Creator: creator}
+	clientIdentity, err := cid.New(&txStub{creator: creator})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the identity of %s: %v", name, err)
+	}
+	client.ID, err = clientIdentity.GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the ID of %s: %v", name, err)
+	}
+
+	return client, nil
+}
+
+// deterministicKey derives an Ed25519 key from seed. Ed25519 signatures are deterministic,
+// so certificates signed with these keys are identical on every run.
+func deterministicKey(seed string) ed25519.PrivateKey {
+	digest := sha256.Sum256([]byte(seed))
+	return ed25519.NewKeyFromSeed(digest[:])
+}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+const compositeKeyNamespace = "\x00"
+
+// ledger is the world state, key history and private data of a single channel. Writes only
+// become visible once the transaction that made them is committed, as on a peer.
+type ledger struct {
+	state   map[string][]byte
+	history map[string][]*queryresult.KeyModification
+	private map[string]map[string][]byte
+
+	validationParameters map[string][]byte
+}
+
+func newLedger() *ledger {
+	return &ledger{
+		state:   map[string][]byte{},
+		history: map[string][]*queryresult.KeyModification{},
+		private: map[string]map[string][]byte{},
+
+		validationParameters: map[string][]byte{},
+	}
+}
+
+// commit applies the writes of a successful transaction
+func (l *ledger) commit(stub *txStub) {
+	for _, key := range sortedKeys(stub.writes) {
+		value := stub.writes[key]
+		l.history[key] = append(l.history[key], &queryresult.KeyModification{
+			TxId:      stub.txID,
+			Value:     value,
+			Timestamp: stub.timestamp,
+			IsDelete:  value == nil,
+		})
+		if value == nil {
+			delete(l.state, key)
+		} else {
+			l.state[key] = value
+		}
+	}
+
+	for collection, writes := range stub.privateWrites {
+		if l.private[collection] == nil {
+			l.private[collection] = map[string][]byte{}
+		}
+		for key, value := range writes {
+			if value == nil {
+				delete(l.private[collection], key)
+			} else {
+				l.private[collection][key] = value
+			}
+		}
+	}
+
+	for key, ep := range stub.validationWrites {
+		if ep == nil {
+			delete(l.validationParameters, key)
+		} else {
+			l.validationParameters[key] = ep
+		}
+	}
+}
+
+// txStub is the stub of one transaction simulated against a ledger. It implements the part of
+// the stub interface the contract uses; calling any other method panics.
+type txStub struct {
+	shim.ChaincodeStubInterface
+
+	ledger    *ledger
+	args      [][]byte
+	creator   []byte
+	transient map[string][]byte
+	txID      string
+	timestamp *timestamp.Timestamp
+	writes    map[string][]byte
+	event     *peer.ChaincodeEvent
+
+	privateWrites    map[string]map[string][]byte
+	validationWrites map[string][]byte
+}
+
+func newTxStub(l *ledger, args []string, creator []byte, transient map[string][]byte, txID string, now time.Time) (*txStub, error) {
+	ts, err := ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := &txStub{
+		ledger:    l,
+		creator:   creator,
+		transient: transient,
+		txID:      txID,
+		timestamp: ts,
+		writes:    map[string][]byte{},
+
+		privateWrites:    map[string]map[string][]byte{},
+		validationWrites: map[string][]byte{},
+	}
+	for _, arg := range args {
+		stub.args = append(stub.args, []byte(arg))
+	}
+
+	return stub, nil
+}
+
+func (s *txStub) GetArgs() [][]byte {
+	return s.args
+}
+
+func (s *txStub) GetStringArgs() []string {
+	args := make([]string, 0, len(s.args))
+	for _, arg := range s.args {
+		args = append(args, string(arg))
+	}
+	return args
+}
+
+func (s *txStub) GetFunctionAndParameters() (string, []string) {
+	args := s.GetStringArgs()
+	if len(args) == 0 {
+		return "", []string{}
+	}
+	return args[0], args[1:]
+}
+
+func (s *txStub) GetTxID() string {
+	return s.txID
+}
+
+func (s *txStub) GetChannelID() string {
+	return "mychannel"
+}
+
+func (s *txStub) GetCreator() ([]byte, error) {
+	return s.creator, nil
+}
+
+func (s *txStub) GetTransient() (map[string][]byte, error) {
+	return s.transient, nil
+}
+
+func (s *txStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return s.timestamp, nil
+}
+
+func (s *txStub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return fmt.Errorf("event name can not be empty string")
+	}
+	s.event = &peer.ChaincodeEvent{EventName: name, Payload: payload}
+	return nil
+}
+
+func (s *txStub) GetState(key string) ([]byte, error) {
+	return s.ledger.state[key], nil
+}
+
+func (s *txStub) PutState(key string, value []byte) error {
+	if key == "" {
+		return fmt.Errorf("key must not be an empty string")
+	}
+	s.writes[key] = value
+	return nil
+}
+
+func (s *txStub) DelState(key string) error {
+	s.writes[key] = nil
+	return nil
+}
+
+func (s *txStub) GetStateValidationParameter(key string) ([]byte, error) {
+	return s.ledger.validationParameters[key], nil
+}
+
+func (s *txStub) SetStateValidationParameter(key string, ep []byte) error {
+	s.validationWrites[key] = ep
+	return nil
+}
+
+func (s *txStub) GetPrivateData(collection string, key string) ([]byte, error) {
+	return s.ledger.private[collection][key], nil
+}
+
+func (s *txStub) PutPrivateData(collection string, key string, value []byte) error {
+	if key == "" {
+		return fmt.Errorf("key must not be an empty string")
+	}
+	if s.privateWrites[collection] == nil {
+		s.privateWrites[collection] = map[string][]byte{}
+	}
+	s.privateWrites[collection][key] = value
+	return nil
+}
+
+func (s *txStub) DelPrivateData(collection string, key string) error {
+	if s.privateWrites[collection] == nil {
+		s.privateWrites[collection] = map[string][]byte{}
+	}
+	s.privateWrites[collection][key] = nil
+	return nil
+}
+
+func (s *txStub) GetStateByRange(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	return s.iterator(s.rangeKeys(startKey, endKey)), nil
+}
+
+// GetStateByRangeWithPagination returns up to pageSize keys of the range starting at the
+// bookmark, which like on a peer is the key the next page starts from
+func (s *txStub) GetStateByRangeWithPagination(startKey string, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	if bookmark > startKey {
+		startKey = bookmark
+	}
+	keys := s.rangeKeys(startKey, endKey)
+
+	next := ""
+	if len(keys) > int(pageSize) {
+		next = keys[pageSize]
+		keys = keys[:pageSize]
+	}
+	return s.iterator(keys), &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(keys)), Bookmark: next}, nil
+}
+
+func (s *txStub) GetStateByPartialCompositeKey(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	prefix, err := s.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	return s.iterator(s.matchingKeys(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})), nil
+}
+
+// GetStateByPartialCompositeKeyWithPagination returns up to pageSize keys of the object type
+// starting at the bookmark, which like on a peer is the key the next page starts from
+func (s *txStub) GetStateByPartialCompositeKeyWithPagination(objectType string, attributes []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	prefix, err := s.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := s.matchingKeys(func(key string) bool {
+		return strings.HasPrefix(key, prefix) && key >= bookmark
+	})
+
+	next := ""
+	if len(keys) > int(pageSize) {
+		next = keys[pageSize]
+		keys = keys[:pageSize]
+	}
+	return s.iterator(keys), &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(keys)), Bookmark: next}, nil
+}
+
+// GetQueryResult supports the subset of CouchDB rich queries used by the contract: a selector
+// whose fields must all equal the corresponding top level fields of the document, and a limit
+func (s *txStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	keys, limit, err := s.queryKeys(query)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return s.iterator(keys), nil
+}
+
+// GetQueryResultWithPagination returns up to pageSize keys matching the query starting at the
+// bookmark, which here is the key the next page starts from
+func (s *txStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	keys, _, err := s.queryKeys(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	for len(keys) > 0 && keys[0] < bookmark {
+		keys = keys[1:]
+	}
+
+	next := ""
+	if len(keys) > int(pageSize) {
+		next = keys[pageSize]
+		keys = keys[:pageSize]
+	}
+	return s.iterator(keys), &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(keys)), Bookmark: next}, nil
+}
+
+func (s *txStub) queryKeys(query string) ([]string, int, error) {
+	var q struct {
+		Selector map[string]interface{} `json:"selector"`
+		Limit    int                    `json:"limit"`
+	}
+	err := json.Unmarshal([]byte(query), &q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid query: %v", err)
+	}
+
+	keys := s.matchingKeys(func(key string) bool {
+		var document map[string]interface{}
+		if json.Unmarshal(s.ledger.state[key], &document) != nil {
+			return false
+		}
+		for field, value := range q.Selector {
+			if !reflect.DeepEqual(document[field], value) {
+				return false
+			}
+		}
+		return true
+	})
+	return keys, q.Limit, nil
+}
+
+func (s *txStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	return &historyIterator{results: s.ledger.history[key]}, nil
+}
+
+func (s *txStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	key := compositeKeyNamespace + objectType + compositeKeyNamespace
+	for _, attribute := range append([]string{objectType}, attributes...) {
+		if strings.Contains(attribute, compositeKeyNamespace) {
+			return "", fmt.Errorf("input contains U+0000 which is not allowed in a composite key")
+		}
+	}
+	for _, attribute := range attributes {
+		key += attribute + compositeKeyNamespace
+	}
+	return key, nil
+}
+
+func (s *txStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	parts := strings.Split(strings.TrimPrefix(compositeKey, compositeKeyNamespace), compositeKeyNamespace)
+	if len(parts) < 2 {
+		return "", nil, fmt.Errorf("invalid composite key %q", compositeKey)
+	}
+	return parts[0], parts[1 : len(parts)-1], nil
+}
+
+// rangeKeys returns the simple keys in the range, skipping composite keys as a peer does
+func (s *txStub) rangeKeys(startKey string, endKey string) []string {
+	return s.matchingKeys(func(key string) bool {
+		return !strings.HasPrefix(key, compositeKeyNamespace) && key >= startKey && (endKey == "" || key < endKey)
+	})
+}
+
+func (s *txStub) matchingKeys(match func(string) bool) []string {
+	var keys []string
+	for _, key := range sortedKeys(s.ledger.state) {
+		if match(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (s *txStub) iterator(keys []string) *stateIterator {
+	results := make([]*queryresult.KV, 0, len(keys))
+	for _, key := range keys {
+		results = append(results, &queryresult.KV{Key: key, Value: s.ledger.state[key]})
+	}
+	return &stateIterator{results: results}
+}
+
+func sortedKeys(values map[string][]byte) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type stateIterator struct {
+	results []*queryresult.KV
+	next    int
+}
+
+func (it *stateIterator) HasNext() bool {
+	return it.next < len(it.results)
+}
+
+func (it *stateIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	it.next++
+	return it.results[it.next-1], nil
+}
+
+func (it *stateIterator) Close() error {
+	return nil
+}
+
+type historyIterator struct {
+	results []*queryresult.KeyModification
+	next    int
+}
+
+func (it *historyIterator) HasNext() bool {
+	return it.next < len(it.results)
+}
+
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	it.next++
+	return it.results[it.next-1], nil
+}
+
+func (it *historyIterator) Close() error {
+	return nil
+}
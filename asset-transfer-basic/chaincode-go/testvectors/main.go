@@ -0,0 +1,172 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command testvectors runs a fixed set of scenarios against the Go implementation of the
+// asset-transfer-basic contract and writes, for every transaction of every scenario, its
+// inputs together with the response, state writes and event it produced. The Node and Java
+// implementations replay the vectors to prove they behave identically before they endorse
+// on the same channel as the Go chaincode.
+//
+// Usage:
+//
+//	go run ./testvectors -out vectors.json
+//
+// The command fails when a transaction of the contract is not exercised by any scenario.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+)
+
+// vectorsVersion identifies the layout of the vectors file
+const vectorsVersion = 1
+
+// Vectors is the document written by the command
+type Vectors struct {
+	ContractSchemaVersion int               `json:"contractSchemaVersion"`
+	Identities            []*identity       `json:"identities"`
+	Scenarios             []*ScenarioVector `json:"scenarios"`
+	Version               int               `json:"version"`
+}
+
+// ScenarioVector holds the transactions of one scenario, each run on the state left by the
+// previous ones starting from an empty ledger, and the state left by the last
+type ScenarioVector struct {
+	Description string         `json:"description"`
+	Name        string         `json:"name"`
+	State       []*WriteVector `json:"state"`
+	Steps       []*StepVector  `json:"steps"`
+}
+
+// StepVector is one transaction and its observable effects. The writes of evaluated and
+// failed transactions are recorded but not committed.
+type StepVector struct {
+	Args      []string          `json:"args"`
+	Client    string            `json:"client"`
+	Evaluate  bool              `json:"evaluate"`
+	Event     *EventVector      `json:"event"`
+	Function  string            `json:"function"`
+	Response  ResponseVector    `json:"response"`
+	Timestamp time.Time         `json:"timestamp"`
+	Transient map[string][]byte `json:"transient,omitempty"`
+	TxID      string            `json:"txID"`
+	Writes    []*WriteVector    `json:"writes"`
+}
+
+// ResponseVector is the response returned by the chaincode to the peer
+type ResponseVector struct {
+	Message string `json:"message"`
+	Payload []byte `json:"payload"`
+	Status  int32  `json:"status"`
+}
+
+// EventVector is the chaincode event set by a transaction
+type EventVector struct {
+	Name    string `json:"name"`
+	Payload []byte `json:"payload"`
+}
+
+// WriteVector is a key written or deleted by a transaction, or a key of the final state
+type WriteVector struct {
+	Delete bool   `json:"delete,omitempty"`
+	Key    string `json:"key"`
+	Value  []byte `json:"value"`
+}
+
+func main() {
+	out := flag.String("out", "", "file to write the vectors to, standard output if empty")
+	flag.Parse()
+
+	assetChaincode, err := contractapi.NewChaincode(&chaincode.SmartContract{})
+	if err != nil {
+		log.Fatalf("Error creating asset-transfer-basic chaincode: %v", err)
+	}
+	cc := chaincode.WithResponseEnvelope(assetChaincode)
+
+	vectors, err := generate(cc)
+	if err != nil {
+		log.Fatalf("Error generating test vectors: %v", err)
+	}
+
+	uncovered, err := uncoveredTransactions(cc, vectors)
+	if err != nil {
+		log.Fatalf("Error reading the contract metadata: %v", err)
+	}
+	if len(uncovered) > 0 {
+		log.Fatalf("No scenario exercises %v", uncovered)
+	}
+
+	vectorsJSON, err := json.MarshalIndent(vectors, "", "  ")
+	if err != nil {
+		log.Fatalf("Error marshalling test vectors: %v", err)
+	}
+	vectorsJSON = append(vectorsJSON, '\n')
+
+	if *out == "" {
+		_, err = os.Stdout.Write(vectorsJSON)
+	} else {
+		err = ioutil.WriteFile(*out, vectorsJSON, 0644)
+	}
+	if err != nil {
+		log.Fatalf("Error writing test vectors: %v", err)
+	}
+}
+
+// uncoveredTransactions returns the transactions listed in the contract metadata that no
+// step of the vectors invokes
+func uncoveredTransactions(cc shim.Chaincode, vectors *Vectors) ([]string, error) {
+	stub, err := newTxStub(newLedger(), []string{"org.hyperledger.fabric:GetMetadata"}, nil, nil, "metadata", startTime)
+	if err != nil {
+		return nil, err
+	}
+	response := cc.Invoke(stub)
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("%s", response.Message)
+	}
+
+	var metadata struct {
+		Contracts map[string]struct {
+			Transactions []struct {
+				Name string `json:"name"`
+			} `json:"transactions"`
+		} `json:"contracts"`
+	}
+	err = json.Unmarshal(response.Payload, &metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	covered := map[string]bool{}
+	for _, scenario := range vectors.Scenarios {
+		for _, step := range scenario.Steps {
+			covered[step.Function] = true
+		}
+	}
+
+	var uncovered []string
+	for name, contract := range metadata.Contracts {
+		if name == "org.hyperledger.fabric" {
+			continue
+		}
+		for _, transaction := range contract.Transactions {
+			if !covered[transaction.Name] {
+				uncovered = append(uncovered, transaction.Name)
+			}
+		}
+	}
+	sort.Strings(uncovered)
+
+	return uncovered, nil
+}
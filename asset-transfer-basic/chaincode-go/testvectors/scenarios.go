@@ -0,0 +1,264 @@
+package main
+
+// clientIdentities are the clients that submit the transactions of the scenarios
+var clientIdentities = []struct {
+	name       string
+	mspID      string
+	commonName string
+	admin      bool
+}{
+	{name: "org1-admin", mspID: "Org1MSP", commonName: "admin", admin: true},
+	{name: "org1-user", mspID: "Org1MSP", commonName: "user1"},
+	{name: "org2-user", mspID: "Org2MSP", commonName: "user2"},
+}
+
+// scenario is a sequence of transactions run on a fresh ledger
+type scenario struct {
+	name        string
+	description string
+	steps       []step
+}
+
+// step is one transaction of a scenario. Its arguments may contain the templates described by renderArg.
+type step struct {
+	client    string
+	function  string
+	args      []string
+	transient map[string]string
+}
+
+var scenarios = []scenario{
+	{
+		name:        "asset-lifecycle",
+		description: "Create, read, update, transfer and delete lists",
+		steps: []step{
+			{client: "org1-admin", function: "InitLedger"},
+			{client: "org1-user", function: "CreateAsset", args: []string{"www.ed.ac.uk", "www.xxx.com", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"www.ed.ac.uk", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "CreateAssetWithID", args: []string{"ed-staff", "www.ed.ac.uk", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "CreateAssetFromJSON", args: []string{`{"allowlist":"www.st-andrews.ac.uk","webfilterlist":2000}`}},
+			{client: "org1-user", function: "CreateAssetFromJSON", args: []string{`{"allowlist":"www.st-andrews.ac.uk","blocklist":"www.xxx.com","webfilterlist":100}`}},
+			{client: "org1-user", function: "UpdateAssetFromJSON", args: []string{"www.st-andrews.ac.uk", `{"blocklist":"","webfilterlist":200}`}},
+			{client: "org1-user", function: "ReadAsset", args: []string{"www.ed.ac.uk"}},
+			{client: "org1-user", function: "AssetExists", args: []string{"www.ed.ac.uk"}},
+			{client: "org1-user", function: "AssetExists", args: []string{"www.unknown.com"}},
+			{client: "org1-user", function: "UpdateAsset", args: []string{"www.ed.ac.uk", "www.yyy.com", "2", `{{id "org1-user"}}`, "200"}},
+			{client: "org1-user", function: "ReadAssetFields", args: []string{"www.ed.ac.uk", `["blocklist","webfilterlist"]`}},
+			{client: "org1-user", function: "GetAllAssets"},
+			{client: "org1-user", function: "GetAllAssetsPaginated", args: []string{"2", ""}},
+			{client: "org1-user", function: "GetAllAssetsPaginated", args: []string{"2", "www.google.com"}},
+			{client: "org1-user", function: "GetAssetsByRange", args: []string{"www.bbc.co.uk", "www.napier.ac.uk"}},
+			{client: "org1-user", function: "GetAssetsByPrefix", args: []string{"*.google.com", "10", ""}},
+			{client: "org1-user", function: "GetAssetsByBlocklist", args: []string{"www.instagram.com"}},
+			{client: "org1-admin", function: "CreateCategory", args: []string{"education", "Schools and universities"}},
+			{client: "org1-user", function: "SetAssetCategory", args: []string{"www.ed.ac.uk", "education"}},
+			{client: "org1-user", function: "ListCategories"},
+			{client: "org1-user", function: "GetAssetsByCategory", args: []string{"education"}},
+			{client: "org1-admin", function: "CreatePolicy", args: []string{"year7", "Year 7 policy"}},
+			{client: "org1-admin", function: "AddAssetToPolicy", args: []string{"year7", "www.ed.ac.uk"}},
+			{client: "org1-admin", function: "AddAssetToPolicy", args: []string{"year7", "www.google.com"}},
+			{client: "org2-user", function: "ResolvePolicy", args: []string{"year7"}},
+			{client: "org2-user", function: "ExportFilterList", args: []string{"year7", "rpz"}},
+			{client: "org1-admin", function: "RemoveAssetFromPolicy", args: []string{"year7", "www.google.com"}},
+			{client: "org1-user", function: "GetAllAssetFields", args: []string{`["allowlist"]`}},
+			{client: "org1-user", function: "QueryAssets", args: []string{`{"selector":{"attribute2":2}}`}},
+			{client: "org1-user", function: "QueryAssetsWithPagination", args: []string{`{"selector":{"webfilterlist":100}}`, "2", ""}},
+			{client: "org1-user", function: "GetRandomAssets", args: []string{"2", "vectors"}},
+			{client: "org1-user", function: "GetAssetChangeLog", args: []string{"www.ed.ac.uk"}},
+			{client: "org1-user", function: "GetAssetStats"},
+			{client: "org2-user", function: "GetAssetCount"},
+			{client: "org1-user", function: "GetLastModification", args: []string{"www.ed.ac.uk"}},
+			{client: "org1-user", function: "GetAuditLog", args: []string{"2000-01-01T00:00:00Z", "2100-01-01T00:00:00Z", "10", ""}},
+			{client: "org1-user", function: "GetAssetHistory", args: []string{"www.ed.ac.uk"}},
+			{client: "org1-user", function: "VerifyListSnapshot", args: []string{"www.ed.ac.uk", "", `{{digest "www.ed.ac.uk"}}`}},
+			{client: "org1-user", function: "VerifyListSnapshot", args: []string{"www.ed.ac.uk", "{{txid 1}}", `{{digest "www.ed.ac.uk"}}`}},
+			{client: "org2-user", function: "ReconcileWithSnapshot", args: []string{`{"www.ed.ac.uk":"{{digest "www.ed.ac.uk"}}","www.google.com":"{{digest "www.ed.ac.uk"}}","www.unknown.com":"{{digest "www.ed.ac.uk"}}"}`}},
+			{client: "org2-user", function: "MatchDomain", args: []string{"https://WWW.ed.ac.uk/"}},
+			{client: "org1-user", function: "CreateAssetFromJSON", args: []string{`{"allowlist":"www.abp.example","blocklist":"||doubleclick.net^,@@||ads.doubleclick.net^","ruleType":"abp","webfilterlist":100}`}},
+			{client: "org2-user", function: "MatchDomain", args: []string{"stats.doubleclick.net"}},
+			{client: "org1-user", function: "SetAssetSchedule", args: []string{"www.ed.ac.uk", `{"days":["mon","tue","wed","thu","fri"],"end":"17:00","start":"09:00"}`}},
+			{client: "org2-user", function: "IsBlockedAt", args: []string{"www.yyy.com", "2026-10-12T10:00:00+01:00"}},
+			{client: "org1-user", function: "TransferAsset", args: []string{"www.ed.ac.uk", `{{id "org2-user"}}`}},
+			{client: "org2-user", function: "GetTransferHistory", args: []string{"www.ed.ac.uk"}},
+			{client: "org1-user", function: "DeleteAsset", args: []string{"www.ed.ac.uk"}},
+			{client: "org1-admin", function: "DeleteAsset", args: []string{"www.ed.ac.uk"}},
+			{client: "org1-user", function: "ReadAsset", args: []string{"www.ed.ac.uk"}},
+		},
+	},
+	{
+		name:        "bulk-and-templates",
+		description: "Create lists in batches and from templates and patch lists matching a selector",
+		steps: []step{
+			{client: "org1-admin", function: "CreateTemplate", args: []string{"school", "", "www.xxx.com", "1", "100"}},
+			{client: "org1-user", function: "ReadTemplate", args: []string{"school"}},
+			{client: "org1-admin", function: "UpdateTemplate", args: []string{"school", "", "www.xxx.com,www.yyy.com", "2", "100"}},
+			{client: "org1-admin", function: "SetTemplateDefaults", args: []string{"school", "", `{"days":["sat","sun"],"end":"23:59","start":"00:00"}`, ""}},
+			{client: "org1-user", function: "CreateAssetFromTemplate", args: []string{"school", `{"allowlist":"www.napier.ac.uk"}`}},
+			{client: "org1-user", function: "CreateAssetFromTemplate", args: []string{"school", `{"colour":"red"}`}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"www.bbc.co.uk", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-admin", function: "CreateAssets", args: []string{`[{"allowlist":"www.google.com","attribute2":2},{"allowlist":"www.google.com"}]`}},
+			{client: "org1-admin", function: "CreateAssets", args: []string{`[{"allowlist":"www.google.com","attribute2":2},{"allowlist":"scholar.google.com","attribute2":2}]`}},
+			{client: "org1-admin", function: "ImportHostsFile", args: []string{"# ads\n0.0.0.0 ads.example.com tracker.example.com\nads.example.com\n", "blocklist"}},
+			{client: "org1-admin", function: "UpdateAssetsWhere", args: []string{`{"attribute2":2}`, `{"attribute2":3}`, "10"}},
+			{client: "org1-user", function: "ReadAsset", args: []string{"www.napier.ac.uk"}},
+			{client: "org1-admin", function: "DeleteAssetsByQuery", args: []string{`{"attribute2":3}`, "10"}},
+			{client: "org1-user", function: "SetAssetExpiry", args: []string{"www.bbc.co.uk", "2030-01-01T00:00:00Z"}},
+			{client: "org1-admin", function: "SetAssetEndorsementPolicy", args: []string{"www.bbc.co.uk", `["Org1MSP","Org2MSP"]`}},
+			{client: "org1-user", function: "GetAssetEndorsementPolicy", args: []string{"www.bbc.co.uk"}},
+			{client: "org1-admin", function: "PruneExpiredAssets", args: []string{"2021-03-01T09:00:00Z"}},
+			{client: "org1-user", function: "ComposeTransaction", args: []string{`[{"function":"CreateAsset","args":["www.ed.ac.uk","www.xxx.com","1","Org1MSP","100"]},{"function":"ReadAsset","args":["www.ed.ac.uk"]}]`}},
+		},
+	},
+	{
+		name:        "links-aliases-and-immutability",
+		description: "Relate lists, refer to them by alias and freeze them",
+		steps: []step{
+			{client: "org1-user", function: "CreateAsset", args: []string{"policy1", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"list1", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"list2", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "LinkAssets", args: []string{"policy1", "list1", "includes"}},
+			{client: "org1-user", function: "LinkAssets", args: []string{"list1", "list2", "includes"}},
+			{client: "org1-user", function: "GetRelatedAssets", args: []string{"policy1", "", "2"}},
+			{client: "org1-user", function: "UnlinkAssets", args: []string{"list1", "list2", "includes"}},
+			{client: "org1-user", function: "RegisterAlias", args: []string{"office-policy", "policy1"}},
+			{client: "org2-user", function: "ResolveAlias", args: []string{"office-policy"}},
+			{client: "org1-user", function: "ReassignAlias", args: []string{"office-policy", "list2"}},
+			{client: "org1-user", function: "ResolveAlias", args: []string{"office-policy"}},
+			{client: "org1-admin", function: "MarkAssetImmutable", args: []string{"list1"}},
+			{client: "org1-user", function: "GetAssetImmutability", args: []string{"list1"}},
+			{client: "org1-user", function: "UpdateAsset", args: []string{"list1", "www.xxx.com", "1", `{{id "org1-user"}}`, "100"}},
+		},
+	},
+	{
+		name:        "watches-and-activity",
+		description: "Watch lists for changes and report how often they are used",
+		steps: []step{
+			{client: "org2-user", function: "RegisterWatch", args: []string{"www.*"}},
+			{client: "org2-user", function: "GetMyWatches"},
+			{client: "org1-user", function: "CreateAsset", args: []string{"www.aaa.com", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"www.bbb.com", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org2-user", function: "UnregisterWatch", args: []string{"www.*"}},
+			{client: "org1-user", function: "RecordListHits", args: []string{`{"www.aaa.com":3,"www.bbb.com":0}`}},
+			{client: "org1-user", function: "GetListActivityReport", args: []string{"30", "10", ""}},
+		},
+	},
+	{
+		name:        "governance",
+		description: "Configure the contract, quotas and permissions and inspect its usage",
+		steps: []step{
+			{client: "org1-admin", function: "SetMaintenanceMode", args: []string{"true"}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"www.bbc.co.uk", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "GetConfig"},
+			{client: "org1-admin", function: "SetMaintenanceMode", args: []string{"false"}},
+			{client: "org1-admin", function: "SetQueryLimits", args: []string{"100", "65536"}},
+			{client: "org1-admin", function: "SetPayloadLimits", args: []string{"65536", "50", "262144"}},
+			{client: "org1-admin", function: "SetQuota", args: []string{"Org1MSP", "2", "10"}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"www.bbc.co.uk", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"www.google.com", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"www.napier.ac.uk", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "GetQuotaUsage", args: []string{"Org1MSP"}},
+			{client: "org1-admin", function: "GetUsageReport", args: []string{"2021-03", ""}},
+			{client: "org1-admin", function: "SetFunctionPermission", args: []string{"DeleteAsset", `["Org1MSP"]`, `["curator"]`}},
+			{client: "org1-user", function: "GetEffectivePermissions"},
+			{client: "org1-admin", function: "RemoveFunctionPermission", args: []string{"DeleteAsset"}},
+			{client: "org1-admin", function: "SetTransferPolicy", args: []string{`["includes"]`, "true"}},
+			{client: "org1-user", function: "GetTransferPolicy"},
+			{client: "org1-admin", function: "ListKeyNamespaces"},
+			{client: "org1-user", function: "ListReservedPrefixes"},
+			{client: "org1-user", function: "GetCapabilities"},
+			{client: "org1-admin", function: "GetUsageMetrics", args: []string{""}},
+			{client: "org1-admin", function: "MigrateLegacyAssets", args: []string{"10", ""}},
+			{client: "org1-admin", function: "MigrateAll", args: []string{"10", ""}},
+			{client: "org1-admin", function: "SetStateDatabase", args: []string{"goleveldb"}},
+			{client: "org1-user", function: "QueryAssets", args: []string{`{"selector":{"webfilterlist":{"$gte":1}}}`}},
+		},
+	},
+	{
+		name:        "organizations-and-agreements",
+		description: "Onboard an organization, share lists with it and offboard it",
+		steps: []step{
+			{client: "org1-admin", function: "SetOrgLifecyclePolicy", args: []string{"10", "20", "freeze", ""}},
+			{client: "org1-admin", function: "OnboardOrg", args: []string{"Org2MSP", `["curator"]`}},
+			{client: "org2-user", function: "ReadOrganization", args: []string{"Org2MSP"}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"www.bbc.co.uk", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "ProposeAgreement", args: []string{"Org2MSP", `["www.bbc.co.uk"]`, "research use"}},
+			{client: "org2-user", function: "ReadAgreement", args: []string{"{{result 4}}"}},
+			{client: "org2-user", function: "GetMyPendingActions", args: []string{"10", ""}},
+			{client: "org2-user", function: "AcknowledgeNotification", args: []string{"notification-{{txid 4}}-2"}},
+			{client: "org2-user", function: "CountersignAgreement", args: []string{"{{result 4}}"}},
+			{client: "org1-user", function: "TerminateAgreement", args: []string{"{{result 4}}"}},
+			{client: "org1-user", function: "ProposeTransfer", args: []string{"www.bbc.co.uk", "Org2MSP"}},
+			{client: "org2-user", function: "GetPendingTransfers"},
+			{client: "org2-user", function: "RejectTransfer", args: []string{"www.bbc.co.uk"}},
+			{client: "org1-user", function: "ProposeTransfer", args: []string{"www.bbc.co.uk", "Org2MSP"}},
+			{client: "org2-user", function: "AcceptTransfer", args: []string{"www.bbc.co.uk"}},
+			{client: "org1-admin", function: "OffboardOrg", args: []string{"Org2MSP"}},
+			{client: "org2-user", function: "CreateAsset", args: []string{"www.google.com", "", "1", `{{id "org2-user"}}`, "100"}},
+		},
+	},
+	{
+		name:        "delegation-and-scopes",
+		description: "Delegate administration and resolve policies scoped to organizations, groups and devices",
+		steps: []step{
+			{client: "org1-admin", function: "DelegateAdmin", args: []string{`{{id "org1-user"}}`, "lease", "3600"}},
+			{client: "org1-user", function: "GetDelegation", args: []string{`{{id "org1-user"}}`}},
+			{client: "org1-admin", function: "HeartbeatDelegation", args: []string{`{{id "org1-user"}}`}},
+			{client: "org1-user", function: "SetScopePolicy", args: []string{"Org1MSP", "org", "", `{"blocklist":"www.xxx.com","safesearch":"on"}`}},
+			{client: "org1-admin", function: "RevokeDelegation", args: []string{`{{id "org1-user"}}`}},
+			{client: "org1-user", function: "SetScopePolicy", args: []string{"Org1MSP", "group", "staff", `{"blocklist":"www.yyy.com"}`}},
+			{client: "org1-admin", function: "SetScopePolicy", args: []string{"Org1MSP", "group", "staff", `{"blocklist":"www.yyy.com"}`}},
+			{client: "org1-user", function: "RegisterDevice", args: []string{"laptop1", "staff"}},
+			{client: "org1-user", function: "GetEffectivePolicy", args: []string{"laptop1"}},
+			{client: "org1-admin", function: "CreatePolicy", args: []string{"staff", "Staff policy"}},
+			{client: "org1-user", function: "AssignPolicyToDevice", args: []string{"laptop1", "staff"}},
+			{client: "org1-user", function: "GetEffectivePolicyForDevice", args: []string{"laptop1"}},
+			{client: "org1-user", function: "ExportDeviceFilterList", args: []string{"laptop1", "squid"}},
+			{client: "org1-user", function: "GetEffectivePoliciesForDevices", args: []string{`[{"deviceID":"laptop1"},{"deviceID":"laptop9"}]`, "10", ""}},
+			{client: "org1-admin", function: "GrantRole", args: []string{"Org2MSP", `{{id "org2-user"}}`, "viewer"}},
+			{client: "org1-admin", function: "GrantRole", args: []string{"Org1MSP", `{{id "org1-user"}}`, "viewer"}},
+			{client: "org1-user", function: "GetRole", args: []string{"Org1MSP", `{{id "org1-user"}}`}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"www.google.com", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-admin", function: "RevokeRole", args: []string{"Org1MSP", `{{id "org1-user"}}`}},
+		},
+	},
+	{
+		name:        "jobs-erasure-and-idempotency",
+		description: "Migrate keys, erase a data subject and replay idempotent requests",
+		steps: []step{
+			{client: "org1-user", function: "CreateAsset", args: []string{"https://www.bbc.co.uk", "", "1", `{{id "org1-user"}}`, "100"}, transient: map[string]string{"idempotencyKey": "request1"}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"https://www.bbc.co.uk", "", "1", `{{id "org1-user"}}`, "100"}, transient: map[string]string{"idempotencyKey": "request1"}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"https://www.google.com", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "CreateAsset", args: []string{"https://www.napier.ac.uk", "", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-admin", function: "MigrateKeys", args: []string{"www.b", "web:www.b", "10", ""}},
+			{client: "org1-admin", function: "StartJob", args: []string{"migrateKeys", `{"fromPrefix":"www.","toPrefix":"web:www."}`}},
+			{client: "org1-admin", function: "ContinueJob", args: []string{"{{result 5}}", "1"}},
+			{client: "org1-admin", function: "ContinueJob", args: []string{"{{result 5}}", "10"}},
+			{client: "org1-admin", function: "GetJobStatus", args: []string{"{{result 5}}"}},
+			{client: "org1-admin", function: "EraseSubjectData", args: []string{`{{id "org1-user"}}`}},
+			{client: "org1-admin", function: "ReadErasureCertificate", args: []string{"{{result 9}}"}},
+		},
+	},
+	{
+		name:        "emergency-rollback",
+		description: "Mark a version of a list as last-known-good and roll back a bad push to it",
+		steps: []step{
+			{client: "org1-user", function: "CreateAsset", args: []string{"www.bbc.co.uk", "www.xxx.com", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-admin", function: "MarkLastKnownGood", args: []string{"www.bbc.co.uk", "{{txid 0}}"}},
+			{client: "org1-user", function: "GetLastKnownGood", args: []string{"www.bbc.co.uk"}},
+			{client: "org1-user", function: "UpdateAsset", args: []string{"www.bbc.co.uk", "*", "1", `{{id "org1-user"}}`, "100"}},
+			{client: "org1-user", function: "EmergencyRollback", args: []string{"www.bbc.co.uk"}},
+			{client: "org1-admin", function: "EmergencyRollback", args: []string{"www.bbc.co.uk"}},
+			{client: "org1-user", function: "ReadAsset", args: []string{"www.bbc.co.uk"}},
+		},
+	},
+	{
+		name:        "private-blocklists",
+		description: "Keep the blocklist of a list in the private data collection of its organization",
+		steps: []step{
+			{client: "org1-user", function: "CreatePrivateAsset", args: []string{"www.bbc.co.uk", "1", `{{id "org1-user"}}`, "100"}, transient: map[string]string{"blocklist": "www.xxx.com,www.yyy.com"}},
+			{client: "org1-user", function: "ReadAsset", args: []string{"www.bbc.co.uk"}},
+			{client: "org1-user", function: "ReadPrivateAsset", args: []string{"www.bbc.co.uk"}},
+			{client: "org2-user", function: "ReadPrivateAsset", args: []string{"www.bbc.co.uk"}},
+		},
+	},
+}
@@ -6,6 +6,7 @@ import { Job, Queue } from 'bullmq';
 import { Application } from 'express';
 import { Contract, Transaction } from 'fabric-network';
 import * as fabricProtos from 'fabric-protos';
+import IORedis from 'ioredis';
 import { mock, MockProxy } from 'jest-mock-extended';
 import { mocked } from 'ts-jest/utils';
 import request from 'supertest';
@@ -14,26 +15,34 @@ import { createServer } from '../server';
 
 jest.mock('../config');
 jest.mock('bullmq');
+jest.mock('ioredis', () => require('ioredis-mock/jest'));
 
 const mockAsset1 = {
-  ID: 'asset1',
-  Color: 'blue',
-  Size: 5,
-  Owner: 'Tomoko',
-  AppraisedValue: 300,
+  id: 'asset1',
+  allowlist: 'www.google.com',
+  blocklist: '',
+  attribute1: 'Tomoko',
+  attribute2: 5,
+  webfilterlist: 300,
 };
-const mockAsset1Buffer = Buffer.from(JSON.stringify(mockAsset1));
+const mockAsset1Buffer = Buffer.from(
+  JSON.stringify({ result: mockAsset1, warnings: [] })
+);
 
 const mockAsset2 = {
-  ID: 'asset2',
-  Color: 'red',
-  Size: 5,
-  Owner: 'Brad',
-  AppraisedValue: 400,
+  id: 'asset2',
+  allowlist: '',
+  blocklist: 'www.xxx.com',
+  attribute1: 'Brad',
+  attribute2: 5,
+  webfilterlist: 400,
 };
 
 const mockAllAssetsBuffer = Buffer.from(
-  JSON.stringify([mockAsset1, mockAsset2])
+  JSON.stringify({
+    result: { assets: [mockAsset1, mockAsset2], metadata: {} },
+    warnings: [],
+  })
 );
 
 // TODO add tests for server errors
@@ -165,18 +174,20 @@ describe('Asset Transfer Besic REST API', () => {
       );
       expect(response.body).toEqual([
         {
-          ID: 'asset1',
-          Color: 'blue',
-          Size: 5,
-          Owner: 'Tomoko',
-          AppraisedValue: 300,
+          id: 'asset1',
+          allowlist: 'www.google.com',
+          blocklist: '',
+          attribute1: 'Tomoko',
+          attribute2: 5,
+          webfilterlist: 300,
         },
         {
-          ID: 'asset2',
-          Color: 'red',
-          Size: 5,
-          Owner: 'Brad',
-          AppraisedValue: 400,
+          id: 'asset2',
+          allowlist: '',
+          blocklist: 'www.xxx.com',
+          attribute1: 'Brad',
+          attribute2: 5,
+          webfilterlist: 400,
         },
       ]);
     });
@@ -185,11 +196,11 @@ describe('Asset Transfer Besic REST API', () => {
       const response = await request(app)
         .post('/api/assets')
         .send({
-          ID: 'asset6',
-          Color: 'white',
-          Size: 15,
-          Owner: 'Michel',
-          AppraisedValue: 800,
+          allowlist: 'www.napier.ac.uk',
+          blocklist: '',
+          attribute1: 'Michel',
+          attribute2: 15,
+          webfilterlist: 800,
         })
         .set('X-Api-Key', 'NOTTHERIGHTAPIKEY');
       expect(response.statusCode).toEqual(401);
@@ -208,11 +219,11 @@ describe('Asset Transfer Besic REST API', () => {
       const response = await request(app)
         .post('/api/assets')
         .send({
-          wrongidfield: 'asset3',
-          Color: 'red',
-          Size: 5,
-          Owner: 'Brad',
-          AppraisedValue: 400,
+          wrongallowlistfield: 'www.bbc.co.uk',
+          blocklist: '',
+          attribute1: 'Brad',
+          attribute2: 10,
+          webfilterlist: 500,
         })
         .set('X-Api-Key', 'ORG1MOCKAPIKEY');
       expect(response.statusCode).toEqual(400);
@@ -227,7 +238,7 @@ describe('Asset Transfer Besic REST API', () => {
           {
             location: 'body',
             msg: 'must be a string',
-            param: 'ID',
+            param: 'allowlist',
           },
         ],
         message: 'Invalid request body',
@@ -239,11 +250,11 @@ describe('Asset Transfer Besic REST API', () => {
       const response = await request(app)
         .post('/api/assets')
         .send({
-          ID: 'asset3',
-          Color: 'red',
-          Size: 5,
-          Owner: 'Brad',
-          AppraisedValue: 400,
+          allowlist: 'www.bbc.co.uk',
+          blocklist: '',
+          attribute1: 'Brad',
+          attribute2: 10,
+          webfilterlist: 500,
         })
         .set('X-Api-Key', 'ORG1MOCKAPIKEY');
       expect(response.statusCode).toEqual(202);
@@ -300,7 +311,9 @@ describe('Asset Transfer Besic REST API', () => {
     it('OPTIONS should respond with 404 not found json without the allow header when there is no asset with the specified ID', async () => {
       mockAssetExistsTransaction.evaluate
         .calledWith('asset3')
-        .mockResolvedValue(Buffer.from('false'));
+        .mockResolvedValue(
+          Buffer.from(JSON.stringify({ result: false, warnings: [] }))
+        );
 
       const response = await request(app)
         .options('/api/assets/asset3')
@@ -320,7 +333,9 @@ describe('Asset Transfer Besic REST API', () => {
     it('OPTIONS should respond with 200 OK json with the allow header', async () => {
       mockAssetExistsTransaction.evaluate
         .calledWith('asset1')
-        .mockResolvedValue(Buffer.from('true'));
+        .mockResolvedValue(
+          Buffer.from(JSON.stringify({ result: true, warnings: [] }))
+        );
 
       const response = await request(app)
         .options('/api/assets/asset1')
@@ -389,23 +404,48 @@ describe('Asset Transfer Besic REST API', () => {
         'application/json; charset=utf-8'
       );
       expect(response.body).toEqual({
-        ID: 'asset1',
-        Color: 'blue',
-        Size: 5,
-        Owner: 'Tomoko',
-        AppraisedValue: 300,
+        id: 'asset1',
+        allowlist: 'www.google.com',
+        blocklist: '',
+        attribute1: 'Tomoko',
+        attribute2: 5,
+        webfilterlist: 300,
       });
     });
 
+    it('GET should respond with a warning header for each chaincode warning', async () => {
+      const warning = {
+        code: 'NEAR_QUOTA',
+        message: 'organization Org1MSP owns 9 of 10 permitted assets',
+      };
+      mockReadAssetTransaction.evaluate
+        .calledWith('asset1')
+        .mockResolvedValue(
+          Buffer.from(
+            JSON.stringify({ result: mockAsset1, warnings: [warning] })
+          )
+        );
+
+      const response = await request(app)
+        .get('/api/assets/asset1')
+        .set('X-Api-Key', 'ORG1MOCKAPIKEY');
+      expect(response.statusCode).toEqual(200);
+      expect(response.header).toHaveProperty(
+        'warning',
+        '199 - "NEAR_QUOTA: organization Org1MSP owns 9 of 10 permitted assets"'
+      );
+      expect(response.body).toEqual(mockAsset1);
+    });
+
     it('PUT should respond with 401 unauthorized json when an invalid API key is specified', async () => {
       const response = await request(app)
         .put('/api/assets/asset1')
         .send({
-          ID: 'asset3',
-          Color: 'red',
-          Size: 5,
-          Owner: 'Brad',
-          AppraisedValue: 400,
+          id: 'asset3',
+          blocklist: 'www.xxx.com',
+          attribute1: 'Brad',
+          attribute2: 5,
+          webfilterlist: 400,
         })
         .set('X-Api-Key', 'NOTTHERIGHTAPIKEY');
       expect(response.statusCode).toEqual(401);
@@ -424,11 +464,11 @@ describe('Asset Transfer Besic REST API', () => {
       const response = await request(app)
         .put('/api/assets/asset1')
         .send({
-          ID: 'asset2',
-          Color: 'red',
-          Size: 5,
-          Owner: 'Brad',
-          AppraisedValue: 400,
+          id: 'asset2',
+          blocklist: 'www.xxx.com',
+          attribute1: 'Brad',
+          attribute2: 5,
+          webfilterlist: 400,
         })
         .set('X-Api-Key', 'ORG1MOCKAPIKEY');
       expect(response.statusCode).toEqual(400);
@@ -449,10 +489,10 @@ describe('Asset Transfer Besic REST API', () => {
         .put('/api/assets/asset1')
         .send({
           wrongID: 'asset1',
-          Color: 'red',
-          Size: 5,
-          Owner: 'Brad',
-          AppraisedValue: 400,
+          blocklist: 'www.xxx.com',
+          attribute1: 'Brad',
+          attribute2: 5,
+          webfilterlist: 400,
         })
         .set('X-Api-Key', 'ORG1MOCKAPIKEY');
       expect(response.statusCode).toEqual(400);
@@ -467,7 +507,7 @@ describe('Asset Transfer Besic REST API', () => {
           {
             location: 'body',
             msg: 'must be a string',
-            param: 'ID',
+            param: 'id',
           },
         ],
         message: 'Invalid request body',
@@ -479,11 +519,11 @@ describe('Asset Transfer Besic REST API', () => {
       const response = await request(app)
         .put('/api/assets/asset1')
         .send({
-          ID: 'asset1',
-          Color: 'red',
-          Size: 5,
-          Owner: 'Brad',
-          AppraisedValue: 400,
+          id: 'asset1',
+          blocklist: 'www.xxx.com',
+          attribute1: 'Brad',
+          attribute2: 5,
+          webfilterlist: 400,
         })
         .set('X-Api-Key', 'ORG1MOCKAPIKEY');
       expect(response.statusCode).toEqual(202);
@@ -501,7 +541,7 @@ describe('Asset Transfer Besic REST API', () => {
     it('PATCH should respond with 401 unauthorized json when an invalid API key is specified', async () => {
       const response = await request(app)
         .patch('/api/assets/asset1')
-        .send([{ op: 'replace', path: '/Owner', value: 'Ashleigh' }])
+        .send([{ op: 'replace', path: '/attribute1', value: 'Ashleigh' }])
         .set('X-Api-Key', 'NOTTHERIGHTAPIKEY');
       expect(response.statusCode).toEqual(401);
       expect(response.header).toHaveProperty(
@@ -518,7 +558,7 @@ describe('Asset Transfer Besic REST API', () => {
     it('PATCH should respond with 400 bad request json for invalid patch op/path', async () => {
       const response = await request(app)
         .patch('/api/assets/asset1')
-        .send([{ op: 'replace', path: '/color', value: 'orange' }])
+        .send([{ op: 'replace', path: '/allowlist', value: 'www.bbc.co.uk' }])
         .set('X-Api-Key', 'ORG1MOCKAPIKEY');
       expect(response.statusCode).toEqual(400);
       expect(response.header).toHaveProperty(
@@ -531,9 +571,9 @@ describe('Asset Transfer Besic REST API', () => {
         errors: [
           {
             location: 'body',
-            msg: "path must be '/Owner'",
+            msg: "path must be '/attribute1'",
             param: '[0].path',
-            value: '/color',
+            value: '/allowlist',
           },
         ],
         message: 'Invalid request body',
@@ -544,7 +584,7 @@ describe('Asset Transfer Besic REST API', () => {
     it('PATCH should respond with 202 accepted json', async () => {
       const response = await request(app)
         .patch('/api/assets/asset1')
-        .send([{ op: 'replace', path: '/Owner', value: 'Ashleigh' }])
+        .send([{ op: 'replace', path: '/attribute1', value: 'Ashleigh' }])
         .set('X-Api-Key', 'ORG1MOCKAPIKEY');
       expect(response.statusCode).toEqual(202);
       expect(response.header).toHaveProperty(
@@ -650,6 +690,7 @@ describe('Asset Transfer Besic REST API', () => {
         transactionIds: ['txn1', 'txn2'],
         transactionError: 'Mock error',
         transactionPayload: 'Mock payload',
+        transactionWarnings: [],
       });
     });
   });
@@ -735,4 +776,158 @@ describe('Asset Transfer Besic REST API', () => {
       });
     });
   });
+
+  describe('/api/tokens', () => {
+    let mockGetAllAssetsTransaction: MockProxy<Transaction>;
+
+    const createToken = async (
+      functions: string[]
+    ): Promise<request.Response> =>
+      await request(app)
+        .post('/api/tokens')
+        .send({ functions, description: 'Mock integration' })
+        .set('X-Api-Key', 'ORG1MOCKAPIKEY');
+
+    beforeEach(() => {
+      app.locals.redis = new IORedis();
+
+      mockGetAllAssetsTransaction = mock<Transaction>();
+      mockGetAllAssetsTransaction.evaluate.mockResolvedValue(
+        mockAllAssetsBuffer
+      );
+      const mockBasicContract = mock<Contract>();
+      mockBasicContract.createTransaction
+        .calledWith('GetAllAssets')
+        .mockReturnValue(mockGetAllAssetsTransaction);
+      app.locals[config.mspIdOrg1] = {
+        assetContract: mockBasicContract,
+      };
+    });
+
+    afterEach(async () => {
+      await app.locals.redis.flushall();
+    });
+
+    it('POST should respond with 201 created json including the new token', async () => {
+      const response = await createToken(['GetAllAssets', 'ReadAsset']);
+      expect(response.statusCode).toEqual(201);
+      expect(response.header).toHaveProperty(
+        'content-type',
+        'application/json; charset=utf-8'
+      );
+      expect(response.body).toEqual({
+        status: 'Created',
+        token: expect.stringMatching(/^[0-9a-f]{16}\.[0-9a-f]{64}$/),
+        tokenId: expect.any(String),
+        mspId: 'Org1MSP',
+        functions: ['GetAllAssets', 'ReadAsset'],
+        description: 'Mock integration',
+        createdAt: expect.any(String),
+        timestamp: expect.any(String),
+      });
+    });
+
+    it('POST should respond with 400 bad request json for unknown functions', async () => {
+      const response = await createToken(['GetAllAssets', 'InitLedger']);
+      expect(response.statusCode).toEqual(400);
+      expect(response.body).toEqual({
+        status: 'Bad Request',
+        reason: 'VALIDATION_ERROR',
+        message: 'Invalid request body',
+        timestamp: expect.any(String),
+        errors: [
+          {
+            location: 'body',
+            msg: 'must be one of AssetExists, CreateAsset, DeleteAsset, GetAllAssets, ReadAsset, TransferAsset, UpdateAsset',
+            param: 'functions[1]',
+            value: 'InitLedger',
+          },
+        ],
+      });
+    });
+
+    it('POST should respond with 403 forbidden json when a token is used', async () => {
+      const { token } = (await createToken(['GetAllAssets'])).body;
+
+      const response = await request(app)
+        .post('/api/tokens')
+        .send({ functions: ['DeleteAsset'] })
+        .set('X-Api-Key', token);
+      expect(response.statusCode).toEqual(403);
+      expect(response.body).toEqual({
+        status: 'Forbidden',
+        reason: 'ORG_APIKEY_REQUIRED',
+        message: 'API tokens can only be managed with an organization API key',
+        timestamp: expect.any(String),
+      });
+    });
+
+    it('GET should respond with the tokens of the organization without their secrets', async () => {
+      const { tokenId } = (await createToken(['GetAllAssets'])).body;
+
+      const org1Response = await request(app)
+        .get('/api/tokens')
+        .set('X-Api-Key', 'ORG1MOCKAPIKEY');
+      expect(org1Response.statusCode).toEqual(200);
+      expect(org1Response.body).toEqual([
+        {
+          tokenId,
+          mspId: 'Org1MSP',
+          functions: ['GetAllAssets'],
+          description: 'Mock integration',
+          createdAt: expect.any(String),
+        },
+      ]);
+
+      const org2Response = await request(app)
+        .get('/api/tokens')
+        .set('X-Api-Key', 'ORG2MOCKAPIKEY');
+      expect(org2Response.statusCode).toEqual(200);
+      expect(org2Response.body).toEqual([]);
+    });
+
+    it('should only allow a token to invoke the permitted functions', async () => {
+      const { token } = (await createToken(['GetAllAssets'])).body;
+
+      const permittedResponse = await request(app)
+        .get('/api/assets')
+        .set('X-Api-Key', token);
+      expect(permittedResponse.statusCode).toEqual(200);
+      expect(permittedResponse.body).toEqual([mockAsset1, mockAsset2]);
+
+      const forbiddenResponse = await request(app)
+        .get('/api/assets/asset1')
+        .set('X-Api-Key', token);
+      expect(forbiddenResponse.statusCode).toEqual(403);
+      expect(forbiddenResponse.body).toEqual({
+        status: 'Forbidden',
+        reason: 'FUNCTION_NOT_PERMITTED',
+        message: 'The API token is not permitted to invoke ReadAsset',
+        timestamp: expect.any(String),
+      });
+    });
+
+    it('DELETE should revoke the token', async () => {
+      const { token, tokenId } = (await createToken(['GetAllAssets'])).body;
+
+      const org2Response = await request(app)
+        .delete(`/api/tokens/${tokenId}`)
+        .set('X-Api-Key', 'ORG2MOCKAPIKEY');
+      expect(org2Response.statusCode).toEqual(404);
+
+      const response = await request(app)
+        .delete(`/api/tokens/${tokenId}`)
+        .set('X-Api-Key', 'ORG1MOCKAPIKEY');
+      expect(response.statusCode).toEqual(200);
+      expect(response.body).toEqual({
+        status: 'OK',
+        timestamp: expect.any(String),
+      });
+
+      const revokedResponse = await request(app)
+        .get('/api/assets')
+        .set('X-Api-Key', token);
+      expect(revokedResponse.statusCode).toEqual(401);
+    });
+  });
 });
@@ -23,8 +23,13 @@ import { body, validationResult } from 'express-validator';
 import { Contract } from 'fabric-network';
 import { getReasonPhrase, StatusCodes } from 'http-status-codes';
 import { Queue } from 'bullmq';
+import { requireFunction } from './auth';
 import { AssetNotFoundError } from './errors';
-import { evatuateTransaction } from './fabric';
+import {
+  evatuateTransaction,
+  parseResponseEnvelope,
+  Warning,
+} from './fabric';
 import { addSubmitTransactionJob } from './jobs';
 import { logger } from './logger';
 
@@ -33,6 +38,28 @@ const { ACCEPTED, BAD_REQUEST, INTERNAL_SERVER_ERROR, NOT_FOUND, OK } =
 
 export const assetsRouter = express.Router();
 
+// Scoped API tokens may only use the routes of the functions they permit
+assetsRouter.get('/', requireFunction('GetAllAssets'));
+assetsRouter.post('/', requireFunction('CreateAsset'));
+assetsRouter.options('/:assetId', requireFunction('AssetExists'));
+assetsRouter.get('/:assetId', requireFunction('ReadAsset'));
+assetsRouter.put('/:assetId', requireFunction('UpdateAsset'));
+assetsRouter.patch('/:assetId', requireFunction('TransferAsset'));
+assetsRouter.delete('/:assetId', requireFunction('DeleteAsset'));
+
+/**
+ * Add a Warning header for each warning the chaincode returned, leaving the
+ * response body as it was before the chaincode returned warnings
+ */
+const setWarningHeaders = (res: Response, warnings: Warning[]): void => {
+  for (const warning of warnings) {
+    res.append(
+      'Warning',
+      `199 - ${JSON.stringify(`${warning.code}: ${warning.message}`)}`
+    );
+  }
+};
+
 assetsRouter.get('/', async (req: Request, res: Response) => {
   logger.debug('Get all assets request received');
   try {
@@ -40,10 +67,9 @@ assetsRouter.get('/', async (req: Request, res: Response) => {
     const contract = req.app.locals[mspId]?.assetContract as Contract;
 
     const data = await evatuateTransaction(contract, 'GetAllAssets');
-    let assets = [];
-    if (data.length > 0) {
-      assets = JSON.parse(data.toString());
-    }
+    const { result, warnings } = parseResponseEnvelope(data);
+    const assets = (result as { assets?: unknown[] } | null)?.assets ?? [];
+    setWarningHeaders(res, warnings);
 
     return res.status(OK).json(assets);
   } catch (err) {
@@ -58,11 +84,11 @@ assetsRouter.get('/', async (req: Request, res: Response) => {
 assetsRouter.post(
   '/',
   body().isObject().withMessage('body must contain an asset object'),
-  body('ID', 'must be a string').notEmpty(),
-  body('Color', 'must be a string').notEmpty(),
-  body('Size', 'must be a number').isNumeric(),
-  body('Owner', 'must be a string').notEmpty(),
-  body('AppraisedValue', 'must be a number').isNumeric(),
+  body('allowlist', 'must be a string').notEmpty(),
+  body('blocklist', 'must be a string').isString(),
+  body('attribute1', 'must be a string').isString(),
+  body('attribute2', 'must be a number').isNumeric(),
+  body('webfilterlist', 'must be a number').isNumeric(),
   async (req: Request, res: Response) => {
     logger.debug(req.body, 'Create asset request received');
 
@@ -78,7 +104,8 @@ assetsRouter.post(
     }
 
     const mspId = req.user as string;
-    const assetId = req.body.ID;
+    // the chaincode stores a new asset under its normalized allowlist
+    const assetId = req.body.allowlist;
 
     try {
       const submitQueue = req.app.locals.jobq as Queue;
@@ -86,11 +113,11 @@ assetsRouter.post(
         submitQueue,
         mspId,
         'CreateAsset',
-        assetId,
-        req.body.Color,
-        req.body.Size,
-        req.body.Owner,
-        req.body.AppraisedValue
+        req.body.allowlist,
+        req.body.blocklist,
+        req.body.attribute2,
+        req.body.attribute1,
+        req.body.webfilterlist
       );
 
       return res.status(ACCEPTED).json({
@@ -122,7 +149,9 @@ assetsRouter.options('/:assetId', async (req: Request, res: Response) => {
     const contract = req.app.locals[mspId]?.assetContract as Contract;
 
     const data = await evatuateTransaction(contract, 'AssetExists', assetId);
-    const exists = data.toString() === 'true';
+    const { result, warnings } = parseResponseEnvelope(data);
+    const exists = result === true;
+    setWarningHeaders(res, warnings);
 
     if (exists) {
       return res
@@ -162,7 +191,8 @@ assetsRouter.get('/:assetId', async (req: Request, res: Response) => {
     const contract = req.app.locals[mspId]?.assetContract as Contract;
 
     const data = await evatuateTransaction(contract, 'ReadAsset', assetId);
-    const asset = JSON.parse(data.toString());
+    const { result: asset, warnings } = parseResponseEnvelope(data);
+    setWarningHeaders(res, warnings);
 
     return res.status(OK).json(asset);
   } catch (err) {
@@ -189,11 +219,11 @@ assetsRouter.get('/:assetId', async (req: Request, res: Response) => {
 assetsRouter.put(
   '/:assetId',
   body().isObject().withMessage('body must contain an asset object'),
-  body('ID', 'must be a string').notEmpty(),
-  body('Color', 'must be a string').notEmpty(),
-  body('Size', 'must be a number').isNumeric(),
-  body('Owner', 'must be a string').notEmpty(),
-  body('AppraisedValue', 'must be a number').isNumeric(),
+  body('id', 'must be a string').notEmpty(),
+  body('blocklist', 'must be a string').isString(),
+  body('attribute1', 'must be a string').isString(),
+  body('attribute2', 'must be a number').isNumeric(),
+  body('webfilterlist', 'must be a number').isNumeric(),
   async (req: Request, res: Response) => {
     logger.debug(req.body, 'Update asset request received');
 
@@ -208,7 +238,7 @@ assetsRouter.put(
       });
     }
 
-    if (req.params.assetId != req.body.ID) {
+    if (req.params.assetId != req.body.id) {
       return res.status(BAD_REQUEST).json({
         status: getReasonPhrase(BAD_REQUEST),
         reason: 'ASSET_ID_MISMATCH',
@@ -227,10 +257,10 @@ assetsRouter.put(
         mspId,
         'UpdateAsset',
         assetId,
-        req.body.color,
-        req.body.size,
-        req.body.owner,
-        req.body.appraisedValue
+        req.body.blocklist,
+        req.body.attribute2,
+        req.body.attribute1,
+        req.body.webfilterlist
       );
 
       return res.status(ACCEPTED).json({
@@ -262,7 +292,7 @@ assetsRouter.patch(
     })
     .withMessage('body must contain an array with a single patch operation'),
   body('*.op', "operation must be 'replace'").equals('replace'),
-  body('*.path', "path must be '/Owner'").equals('/Owner'),
+  body('*.path', "path must be '/attribute1'").equals('/attribute1'),
   body('*.value', 'must be a string').isString(),
   async (req: Request, res: Response) => {
     logger.debug(req.body, 'Transfer asset request received');
@@ -280,7 +310,7 @@ assetsRouter.patch(
 
     const mspId = req.user as string;
     const assetId = req.params.assetId;
-    const newOwner = req.body[0].value;
+    const newAttribute1 = req.body[0].value;
 
     try {
       const submitQueue = req.app.locals.jobq as Queue;
@@ -289,7 +319,7 @@ assetsRouter.patch(
         mspId,
         'TransferAsset',
         assetId,
-        newOwner
+        newAttribute1
       );
 
       return res.status(ACCEPTED).json({
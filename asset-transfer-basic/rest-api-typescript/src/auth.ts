@@ -7,16 +7,18 @@ import passport from 'passport';
 import { NextFunction, Request, Response } from 'express';
 import { HeaderAPIKeyStrategy } from 'passport-headerapikey';
 import { StatusCodes, getReasonPhrase } from 'http-status-codes';
+import { Redis } from 'ioredis';
 import * as config from './config';
+import { ApiTokenScope, verifyToken } from './tokens';
 
-const { UNAUTHORIZED } = StatusCodes;
+const { FORBIDDEN, UNAUTHORIZED } = StatusCodes;
 
 export const fabricAPIKeyStrategy: HeaderAPIKeyStrategy =
   new HeaderAPIKeyStrategy(
     { header: 'X-API-Key', prefix: '' },
-    false,
-    function (apikey, done) {
-      logger.debug({ apikey }, 'Checking X-API-Key');
+    true,
+    function (apikey, done, req) {
+      logger.debug('Checking X-API-Key');
       if (apikey === config.org1ApiKey) {
         const user = config.mspIdOrg1;
         logger.debug('User set to %s', user);
@@ -26,8 +28,31 @@ export const fabricAPIKeyStrategy: HeaderAPIKeyStrategy =
         logger.debug('User set to %s', user);
         done(null, user);
       } else {
-        logger.debug({ apikey }, 'No valid X-API-Key');
-        return done(null, false);
+        const redis = req?.app.locals.redis as Redis | undefined;
+        if (redis === undefined) {
+          logger.debug('No valid X-API-Key');
+          return done(null, false);
+        }
+
+        verifyToken(redis, apikey)
+          .then((apiToken) => {
+            if (apiToken === undefined) {
+              logger.debug('No valid X-API-Key');
+              return done(null, false);
+            }
+
+            const scope: ApiTokenScope = {
+              tokenId: apiToken.tokenId,
+              functions: apiToken.functions,
+            };
+            logger.debug(
+              { scope },
+              'User set to %s with API token',
+              apiToken.mspId
+            );
+            done(null, apiToken.mspId, scope);
+          })
+          .catch((err) => done(err));
       }
     }
   );
@@ -40,7 +65,7 @@ export const authenticateApiKey = (
   passport.authenticate(
     'headerapikey',
     { session: false },
-    (err, user, _info) => {
+    (err, user, info) => {
       if (err) return next(err);
       if (!user)
         return res.status(UNAUTHORIZED).json({
@@ -53,8 +78,55 @@ export const authenticateApiKey = (
         if (err) {
           return next(err);
         }
+        req.authInfo = info;
         return next();
       });
     }
   )(req, res, next);
 };
+
+/**
+ * Reject requests authenticated with a scoped API token which is not
+ * permitted to invoke the chaincode function
+ */
+export const requireFunction =
+  (functionName: string) =>
+  (req: Request, res: Response, next: NextFunction): unknown => {
+    const scope = req.authInfo as ApiTokenScope | undefined;
+    if (scope === undefined || scope.functions.includes(functionName)) {
+      return next();
+    }
+
+    logger.debug(
+      { scope },
+      'API token is not permitted to invoke %s',
+      functionName
+    );
+    return res.status(FORBIDDEN).json({
+      status: getReasonPhrase(FORBIDDEN),
+      reason: 'FUNCTION_NOT_PERMITTED',
+      message: `The API token is not permitted to invoke ${functionName}`,
+      timestamp: new Date().toISOString(),
+    });
+  };
+
+/**
+ * Reject requests authenticated with a scoped API token, so that tokens
+ * cannot be used to manage other tokens
+ */
+export const requireOrgApiKey = (
+  req: Request,
+  res: Response,
+  next: NextFunction
+): unknown => {
+  if (req.authInfo === undefined) {
+    return next();
+  }
+
+  return res.status(FORBIDDEN).json({
+    status: getReasonPhrase(FORBIDDEN),
+    reason: 'ORG_APIKEY_REQUIRED',
+    message: 'API tokens can only be managed with an organization API key',
+    timestamp: new Date().toISOString(),
+  });
+};
@@ -137,6 +137,41 @@ export const evatuateTransaction = async (
   }
 };
 
+/**
+ * A warning the chaincode returned with a successful transaction
+ */
+export interface Warning {
+  code: string;
+  message: string;
+}
+
+/**
+ * The envelope the chaincode wraps the result of every successful
+ * transaction in
+ */
+export interface ResponseEnvelope {
+  result: unknown;
+  warnings: Warning[];
+}
+
+/**
+ * Parse the result envelope of a transaction payload
+ *
+ * Transactions without a result, such as DeleteAsset, may return an empty
+ * payload
+ */
+export const parseResponseEnvelope = (payload: Buffer): ResponseEnvelope => {
+  if (payload.length === 0) {
+    return { result: null, warnings: [] };
+  }
+
+  const envelope = JSON.parse(payload.toString());
+  return {
+    result: envelope.result ?? null,
+    warnings: envelope.warnings ?? [],
+  };
+};
+
 /**
  * Submit a transaction and handle any errors
  */
@@ -20,12 +20,14 @@ import {
 } from './jobs';
 import { logger } from './logger';
 import { createServer } from './server';
-import { isMaxmemoryPolicyNoeviction } from './redis';
+import { createRedis, isMaxmemoryPolicyNoeviction } from './redis';
 import { Queue, QueueScheduler, Worker } from 'bullmq';
+import { Redis } from 'ioredis';
 
 let jobQueue: Queue | undefined;
 let jobQueueWorker: Worker | undefined;
 let jobQueueScheduler: QueueScheduler | undefined;
+let redis: Redis | undefined;
 
 async function main() {
   logger.info('Checking Redis config');
@@ -71,6 +73,10 @@ async function main() {
   }
   app.locals.jobq = jobQueue;
 
+  logger.info('Connecting to Redis for API tokens');
+  redis = createRedis();
+  app.locals.redis = redis;
+
   logger.info('Starting REST server');
   app.listen(config.port, () => {
     logger.info('REST server started on port: %d', config.port);
@@ -94,4 +100,9 @@ main().catch(async (err) => {
     logger.debug('Closing job queue');
     await jobQueue.close();
   }
+
+  if (redis != undefined) {
+    logger.debug('Closing Redis connection');
+    redis.disconnect();
+  }
 });
@@ -86,6 +86,7 @@ describe('getJobSummary', () => {
       transactionIds: ['txn1'],
       transactionError: undefined,
       transactionPayload: 'MOCK PAYLOAD',
+      transactionWarnings: [],
     });
   });
 
@@ -104,6 +105,30 @@ describe('getJobSummary', () => {
       transactionIds: ['txn1'],
       transactionError: undefined,
       transactionPayload: '',
+      transactionWarnings: [],
+    });
+  });
+
+  it('gets a job summary with the warnings of the transaction payload', async () => {
+    const payload = JSON.stringify({
+      result: null,
+      warnings: [{ code: 'NEAR_QUOTA', message: 'MOCK WARNING' }],
+    });
+    mockQueue.getJob.calledWith('1').mockResolvedValue(mockJob);
+    mockJob.id = '1';
+    mockJob.data = {
+      transactionIds: ['txn1'],
+    };
+    mockJob.returnvalue = {
+      transactionPayload: Buffer.from(payload),
+    };
+
+    expect(await getJobSummary(mockQueue, '1')).toStrictEqual({
+      jobId: '1',
+      transactionIds: ['txn1'],
+      transactionError: undefined,
+      transactionPayload: payload,
+      transactionWarnings: [{ code: 'NEAR_QUOTA', message: 'MOCK WARNING' }],
     });
   });
 
@@ -122,6 +147,7 @@ describe('getJobSummary', () => {
       transactionIds: ['txn1'],
       transactionError: 'MOCK ERROR',
       transactionPayload: '',
+      transactionWarnings: [],
     });
   });
 
@@ -138,6 +164,7 @@ describe('getJobSummary', () => {
       transactionIds: ['txn1'],
       transactionError: undefined,
       transactionPayload: undefined,
+      transactionWarnings: undefined,
     });
   });
 
@@ -154,6 +181,7 @@ describe('getJobSummary', () => {
       transactionIds: [],
       transactionError: undefined,
       transactionPayload: 'MOCK PAYLOAD',
+      transactionWarnings: [],
     });
   });
 });
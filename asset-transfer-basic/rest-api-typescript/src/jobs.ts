@@ -10,7 +10,7 @@ import { Application } from 'express';
 import { Contract, Transaction } from 'fabric-network';
 import * as config from './config';
 import { getRetryAction, RetryAction } from './errors';
-import { submitTransaction } from './fabric';
+import { parseResponseEnvelope, submitTransaction, Warning } from './fabric';
 import { logger } from './logger';
 
 export type JobData = {
@@ -30,6 +30,7 @@ export type JobSummary = {
   jobId: string;
   transactionIds: string[];
   transactionPayload?: string;
+  transactionWarnings?: Warning[];
   transactionError?: string;
 };
 
@@ -274,6 +275,19 @@ export const updateJobData = async (
   await job.update(newData);
 };
 
+/**
+ * Get the warnings of a transaction payload, or none if the payload is not
+ * a result envelope
+ */
+const getTransactionWarnings = (payload: Buffer): Warning[] => {
+  try {
+    return parseResponseEnvelope(payload).warnings;
+  } catch (err) {
+    logger.debug({ err }, 'Transaction payload is not a result envelope');
+    return [];
+  }
+};
+
 /**
  * Gets a job summary
  *
@@ -299,6 +313,7 @@ export const getJobSummary = async (
 
   let transactionError;
   let transactionPayload;
+  let transactionWarnings;
   const returnValue = job.returnvalue;
   if (returnValue) {
     if (returnValue.transactionError) {
@@ -310,8 +325,12 @@ export const getJobSummary = async (
       returnValue.transactionPayload.length > 0
     ) {
       transactionPayload = returnValue.transactionPayload.toString();
+      transactionWarnings = getTransactionWarnings(
+        returnValue.transactionPayload
+      );
     } else {
       transactionPayload = '';
+      transactionWarnings = [];
     }
   }
 
@@ -320,6 +339,7 @@ export const getJobSummary = async (
     transactionIds,
     transactionError,
     transactionPayload,
+    transactionWarnings,
   };
 
   return jobSummary;
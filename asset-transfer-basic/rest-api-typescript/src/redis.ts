@@ -9,6 +9,21 @@ import IORedis, { Redis, RedisOptions } from 'ioredis';
 import * as config from './config';
 import { logger } from './logger';
 
+const redisOptions: RedisOptions = {
+  port: config.redisPort,
+  host: config.redisHost,
+  username: config.redisUsername,
+  password: config.redisPassword,
+};
+
+/**
+ * Create a Redis connection for data stored by the REST server itself, such
+ * as scoped API tokens
+ */
+export const createRedis = (): Redis => {
+  return new IORedis(redisOptions);
+};
+
 /**
  * Check whether the maxmemory-policy config is set to noeviction
  *
@@ -18,13 +33,6 @@ import { logger } from './logger';
 export const isMaxmemoryPolicyNoeviction = async (): Promise<boolean> => {
   let redis: Redis | undefined;
 
-  const redisOptions: RedisOptions = {
-    port: config.redisPort,
-    host: config.redisHost,
-    username: config.redisUsername,
-    password: config.redisPassword,
-  };
-
   try {
     redis = new IORedis(redisOptions);
 
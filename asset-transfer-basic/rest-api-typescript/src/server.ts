@@ -8,10 +8,15 @@ import { getReasonPhrase, StatusCodes } from 'http-status-codes';
 import passport from 'passport';
 import pinoMiddleware from 'pino-http';
 import { assetsRouter } from './assets.router';
-import { authenticateApiKey, fabricAPIKeyStrategy } from './auth';
+import {
+  authenticateApiKey,
+  fabricAPIKeyStrategy,
+  requireOrgApiKey,
+} from './auth';
 import { healthRouter } from './health.router';
 import { jobsRouter } from './jobs.router';
 import { logger } from './logger';
+import { tokensRouter } from './tokens.router';
 import { transactionsRouter } from './transactions.router';
 import cors from 'cors';
 
@@ -64,6 +69,7 @@ export const createServer = async (): Promise<Application> => {
   app.use('/', healthRouter);
   app.use('/api/assets', authenticateApiKey, assetsRouter);
   app.use('/api/jobs', authenticateApiKey, jobsRouter);
+  app.use('/api/tokens', authenticateApiKey, requireOrgApiKey, tokensRouter);
   app.use('/api/transactions', authenticateApiKey, transactionsRouter);
 
   // For everything else
@@ -0,0 +1,128 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Scoped API tokens can only be managed with an organization API key, and
+ * are always bound to the Fabric identity of that organization
+ */
+
+import express, { Request, Response } from 'express';
+import { body, validationResult } from 'express-validator';
+import { getReasonPhrase, StatusCodes } from 'http-status-codes';
+import { Redis } from 'ioredis';
+import { logger } from './logger';
+import {
+  createToken,
+  listTokens,
+  revokeToken,
+  TOKEN_FUNCTIONS,
+} from './tokens';
+
+const { BAD_REQUEST, CREATED, INTERNAL_SERVER_ERROR, NOT_FOUND, OK } =
+  StatusCodes;
+
+export const tokensRouter = express.Router();
+
+tokensRouter.get('/', async (req: Request, res: Response) => {
+  logger.debug('Get all API tokens request received');
+  try {
+    const mspId = req.user as string;
+    const redis = req.app.locals.redis as Redis;
+
+    const tokens = await listTokens(redis, mspId);
+
+    return res.status(OK).json(tokens);
+  } catch (err) {
+    logger.error({ err }, 'Error processing get all API tokens request');
+    return res.status(INTERNAL_SERVER_ERROR).json({
+      status: getReasonPhrase(INTERNAL_SERVER_ERROR),
+      timestamp: new Date().toISOString(),
+    });
+  }
+});
+
+tokensRouter.post(
+  '/',
+  body().isObject().withMessage('body must contain an API token object'),
+  body('functions', 'must be a non-empty array of function names').isArray({
+    min: 1,
+  }),
+  body('functions.*', `must be one of ${TOKEN_FUNCTIONS.join(', ')}`).isIn(
+    TOKEN_FUNCTIONS
+  ),
+  body('description', 'must be a string').optional().isString(),
+  async (req: Request, res: Response) => {
+    logger.debug(req.body, 'Create API token request received');
+
+    const errors = validationResult(req);
+    if (!errors.isEmpty()) {
+      return res.status(BAD_REQUEST).json({
+        status: getReasonPhrase(BAD_REQUEST),
+        reason: 'VALIDATION_ERROR',
+        message: 'Invalid request body',
+        timestamp: new Date().toISOString(),
+        errors: errors.array(),
+      });
+    }
+
+    const mspId = req.user as string;
+
+    try {
+      const redis = req.app.locals.redis as Redis;
+      const { token, apiToken } = await createToken(
+        redis,
+        mspId,
+        req.body.functions,
+        req.body.description
+      );
+
+      return res.status(CREATED).json({
+        status: getReasonPhrase(CREATED),
+        token,
+        ...apiToken,
+        timestamp: new Date().toISOString(),
+      });
+    } catch (err) {
+      logger.error({ err }, 'Error processing create API token request');
+
+      return res.status(INTERNAL_SERVER_ERROR).json({
+        status: getReasonPhrase(INTERNAL_SERVER_ERROR),
+        timestamp: new Date().toISOString(),
+      });
+    }
+  }
+);
+
+tokensRouter.delete('/:tokenId', async (req: Request, res: Response) => {
+  const tokenId = req.params.tokenId;
+  logger.debug('Revoke API token request received for token ID %s', tokenId);
+
+  const mspId = req.user as string;
+
+  try {
+    const redis = req.app.locals.redis as Redis;
+    const revoked = await revokeToken(redis, mspId, tokenId);
+
+    if (!revoked) {
+      return res.status(NOT_FOUND).json({
+        status: getReasonPhrase(NOT_FOUND),
+        timestamp: new Date().toISOString(),
+      });
+    }
+
+    return res.status(OK).json({
+      status: getReasonPhrase(OK),
+      timestamp: new Date().toISOString(),
+    });
+  } catch (err) {
+    logger.error(
+      { err },
+      'Error processing revoke API token request for token ID %s',
+      tokenId
+    );
+
+    return res.status(INTERNAL_SERVER_ERROR).json({
+      status: getReasonPhrase(INTERNAL_SERVER_ERROR),
+      timestamp: new Date().toISOString(),
+    });
+  }
+});
@@ -0,0 +1,108 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+import IORedis, { Redis } from 'ioredis';
+import { createToken, listTokens, revokeToken, verifyToken } from './tokens';
+
+jest.mock('ioredis', () => require('ioredis-mock/jest'));
+
+describe('API tokens', () => {
+  let redis: Redis;
+
+  beforeEach(() => {
+    redis = new IORedis();
+  });
+
+  afterEach(async () => {
+    await redis.flushall();
+  });
+
+  describe('createToken', () => {
+    it('returns a token bound to the MSP ID with the sorted set of functions', async () => {
+      const { token, apiToken } = await createToken(
+        redis,
+        'Org1MSP',
+        ['ReadAsset', 'GetAllAssets', 'ReadAsset'],
+        'Mock integration'
+      );
+
+      expect(token).toMatch(new RegExp(`^${apiToken.tokenId}\\.[0-9a-f]{64}$`));
+      expect(apiToken).toEqual({
+        tokenId: expect.stringMatching(/^[0-9a-f]{16}$/),
+        mspId: 'Org1MSP',
+        functions: ['GetAllAssets', 'ReadAsset'],
+        description: 'Mock integration',
+        createdAt: expect.any(String),
+      });
+    });
+
+    it('does not store the token secret', async () => {
+      const { token, apiToken } = await createToken(redis, 'Org1MSP', [
+        'GetAllAssets',
+      ]);
+
+      const stored = await redis.get(`apitoken:${apiToken.tokenId}`);
+      expect(stored).not.toContain(token.split('.')[1]);
+    });
+  });
+
+  describe('verifyToken', () => {
+    it('returns the token details for a valid token', async () => {
+      const { token, apiToken } = await createToken(redis, 'Org1MSP', [
+        'GetAllAssets',
+      ]);
+
+      expect(await verifyToken(redis, token)).toEqual(apiToken);
+    });
+
+    it('returns undefined for an invalid secret', async () => {
+      const { apiToken } = await createToken(redis, 'Org1MSP', [
+        'GetAllAssets',
+      ]);
+
+      expect(
+        await verifyToken(redis, `${apiToken.tokenId}.NOTTHERIGHTSECRET`)
+      ).toBeUndefined();
+    });
+
+    it('returns undefined for an unknown or malformed token', async () => {
+      expect(await verifyToken(redis, 'NOTATOKEN')).toBeUndefined();
+      expect(await verifyToken(redis, '0123456789abcdef.00')).toBeUndefined();
+    });
+  });
+
+  describe('listTokens', () => {
+    it('returns the tokens created by the MSP ID', async () => {
+      const { apiToken: org1Token } = await createToken(redis, 'Org1MSP', [
+        'GetAllAssets',
+      ]);
+      await createToken(redis, 'Org2MSP', ['ReadAsset']);
+
+      expect(await listTokens(redis, 'Org1MSP')).toEqual([org1Token]);
+    });
+  });
+
+  describe('revokeToken', () => {
+    it('revokes a token created by the MSP ID', async () => {
+      const { token, apiToken } = await createToken(redis, 'Org1MSP', [
+        'GetAllAssets',
+      ]);
+
+      expect(await revokeToken(redis, 'Org1MSP', apiToken.tokenId)).toBe(true);
+      expect(await verifyToken(redis, token)).toBeUndefined();
+      expect(await listTokens(redis, 'Org1MSP')).toEqual([]);
+    });
+
+    it('does not revoke a token created by another MSP ID', async () => {
+      const { token, apiToken } = await createToken(redis, 'Org1MSP', [
+        'GetAllAssets',
+      ]);
+
+      expect(await revokeToken(redis, 'Org2MSP', apiToken.tokenId)).toBe(
+        false
+      );
+      expect(await verifyToken(redis, token)).toEqual(apiToken);
+    });
+  });
+});
@@ -0,0 +1,190 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Scoped API tokens allow an organization to grant third-party integrations
+ * narrow HTTP access without distributing enrollment certificates
+ *
+ * Each token acts with the Fabric identity of the organization which created
+ * it, but may only invoke the chaincode functions it was created for. Only the
+ * digest of a token secret is stored, so a token cannot be recovered after
+ * it has been returned from createToken
+ */
+
+import { createHash, randomBytes, timingSafeEqual } from 'crypto';
+import { Redis } from 'ioredis';
+import { logger } from './logger';
+
+/**
+ * The chaincode functions which the REST API can invoke, and which a scoped
+ * API token can therefore be permitted to use
+ */
+export const TOKEN_FUNCTIONS = [
+  'AssetExists',
+  'CreateAsset',
+  'DeleteAsset',
+  'GetAllAssets',
+  'ReadAsset',
+  'TransferAsset',
+  'UpdateAsset',
+];
+
+const TOKEN_KEY_PREFIX = 'apitoken:';
+const ORG_TOKENS_KEY_PREFIX = 'apitokens:';
+
+export type ApiToken = {
+  tokenId: string;
+  mspId: string;
+  functions: string[];
+  description?: string;
+  createdAt: string;
+};
+
+export type StoredApiToken = ApiToken & {
+  secretDigest: string;
+};
+
+/**
+ * The scope of a request authenticated with a scoped API token, which is
+ * available as req.authInfo
+ *
+ * Requests authenticated with an organization API key have no scope
+ */
+export type ApiTokenScope = {
+  tokenId: string;
+  functions: string[];
+};
+
+const digest = (secret: string): string => {
+  return createHash('sha256').update(secret).digest('hex');
+};
+
+/**
+ * Create a scoped API token bound to the Fabric identity of mspId
+ *
+ * Returns the token details and the token itself, in the form
+ * <tokenId>.<secret>, which cannot be retrieved again later
+ */
+export const createToken = async (
+  redis: Redis,
+  mspId: string,
+  functions: string[],
+  description?: string
+): Promise<{ token: string; apiToken: ApiToken }> => {
+  const tokenId = randomBytes(8).toString('hex');
+  const secret = randomBytes(32).toString('hex');
+
+  const apiToken: ApiToken = {
+    tokenId,
+    mspId,
+    functions: [...new Set(functions)].sort(),
+    description,
+    createdAt: new Date().toISOString(),
+  };
+  const storedToken: StoredApiToken = {
+    ...apiToken,
+    secretDigest: digest(secret),
+  };
+
+  await redis
+    .multi()
+    .set(TOKEN_KEY_PREFIX + tokenId, JSON.stringify(storedToken))
+    .sadd(ORG_TOKENS_KEY_PREFIX + mspId, tokenId)
+    .exec();
+  logger.debug({ tokenId, mspId, functions }, 'Created API token');
+
+  return { token: `${tokenId}.${secret}`, apiToken };
+};
+
+/**
+ * Get the scoped API tokens created by mspId, ordered by token ID
+ */
+export const listTokens = async (
+  redis: Redis,
+  mspId: string
+): Promise<ApiToken[]> => {
+  const tokenIds = (await redis.smembers(ORG_TOKENS_KEY_PREFIX + mspId)).sort();
+
+  const tokens: ApiToken[] = [];
+  for (const tokenId of tokenIds) {
+    const storedToken = await getStoredToken(redis, tokenId);
+    if (storedToken !== undefined) {
+      tokens.push(withoutSecret(storedToken));
+    }
+  }
+
+  return tokens;
+};
+
+/**
+ * Revoke a scoped API token created by mspId
+ *
+ * Returns false if no such token exists, including when the token was
+ * created by another organization
+ */
+export const revokeToken = async (
+  redis: Redis,
+  mspId: string,
+  tokenId: string
+): Promise<boolean> => {
+  const storedToken = await getStoredToken(redis, tokenId);
+  if (storedToken === undefined || storedToken.mspId !== mspId) {
+    return false;
+  }
+
+  await redis
+    .multi()
+    .del(TOKEN_KEY_PREFIX + tokenId)
+    .srem(ORG_TOKENS_KEY_PREFIX + mspId, tokenId)
+    .exec();
+  logger.debug({ tokenId, mspId }, 'Revoked API token');
+
+  return true;
+};
+
+/**
+ * Check a scoped API token, in the form <tokenId>.<secret>
+ *
+ * Returns the token details, or undefined if the token is not valid
+ */
+export const verifyToken = async (
+  redis: Redis,
+  token: string
+): Promise<ApiToken | undefined> => {
+  const separator = token.indexOf('.');
+  if (separator < 1) {
+    return undefined;
+  }
+  const tokenId = token.substring(0, separator);
+  const secret = token.substring(separator + 1);
+
+  const storedToken = await getStoredToken(redis, tokenId);
+  if (storedToken === undefined) {
+    return undefined;
+  }
+
+  const expected = Buffer.from(storedToken.secretDigest, 'hex');
+  const actual = Buffer.from(digest(secret), 'hex');
+  if (!timingSafeEqual(expected, actual)) {
+    return undefined;
+  }
+
+  return withoutSecret(storedToken);
+};
+
+const getStoredToken = async (
+  redis: Redis,
+  tokenId: string
+): Promise<StoredApiToken | undefined> => {
+  const data = await redis.get(TOKEN_KEY_PREFIX + tokenId);
+  if (data === null) {
+    return undefined;
+  }
+
+  return JSON.parse(data) as StoredApiToken;
+};
+
+const withoutSecret = (storedToken: StoredApiToken): ApiToken => {
+  // eslint-disable-next-line @typescript-eslint/no-unused-vars
+  const { secretDigest, ...apiToken } = storedToken;
+  return apiToken;
+};